@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,52 +11,91 @@ import (
 
 	"database/sql"
 	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/cache"
 	"github.com/arcaintelligence/arca-gateway/internal/config"
+	"github.com/arcaintelligence/arca-gateway/internal/geoip"
 	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/lock"
 	"github.com/arcaintelligence/arca-gateway/internal/mcp"
 	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/monitoring"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
+	"github.com/arcaintelligence/arca-gateway/internal/scheduler"
 	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/arcaintelligence/arca-gateway/pkg/buildinfo"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/arcaintelligence/arca-gateway/pkg/urlnorm"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
-	version = "1.0.0"
-	banner  = `
+	banner = `
    _    ____   ____    _    
   / \  |  _ \ / ___|  / \   
  / _ \ | |_) | |     / _ \  
 / ___ \|  _ <| |___ / ___ \ 
 /_/   \_\_| \_\\____/_/   \_\
                             
-ARCA Gateway v%s
+ARCA Gateway %s
 High-Performance API Gateway for ARCA Intelligence Platform
 `
 )
 
 func main() {
+	// Flags de linha de comando têm precedência sobre o ambiente e o arquivo de configuração
+	sources := parseFlags()
+
 	// Banner
-	fmt.Printf(banner, version)
+	fmt.Printf(banner, buildinfo.Get())
+
+	// Carregar configuração (CONFIG_ENV_FILE e o arquivo de configuração são relidos no SIGHUP)
+	envFileErr := config.LoadEnvFile(os.Getenv("CONFIG_ENV_FILE"))
+	cfg, configErr := config.LoadWithSources(sources)
+	if configErr != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", configErr)
+		os.Exit(1)
+	}
+	cfg.Normalize()
+
+	// Logger estruturado
+	appLogger := logger.New(logger.Config{
+		Level:           logger.ParseLevel(cfg.Log.Level),
+		Output:          os.Stdout,
+		DebugSampleRate: cfg.Log.DebugSampleRate,
+		CaptureStack:    cfg.Log.CaptureStack,
+	})
+	logger.SetDefault(appLogger)
+	appLogger.Info("Environment: %s", cfg.Server.Environment)
 
-	// Carregar configuração
-	cfg := config.Load()
-	log.Printf("Environment: %s", cfg.Server.Environment)
+	// Falhar cedo com configuração inválida (em produção, também com defaults inseguros)
+	if envFileErr != nil {
+		appLogger.Fatal("Failed to load CONFIG_ENV_FILE: %v", envFileErr)
+	}
+	if err := cfg.Validate(); err != nil {
+		appLogger.Fatal("Invalid configuration: %v", err)
+	}
 
-	// Conectar ao Banco de Dados
-	dbConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name, cfg.Database.SSLMode)
-	
-	db, err := sql.Open("postgres", dbConnStr)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Conectar ao Banco de Dados. As migrações rodam sem statement_timeout: DDL
+	// (ex.: criação de índices) pode levar mais que DB_QUERY_TIMEOUT.
+	migrate := flag.Arg(0) == "migrate"
+	statementTimeout := cfg.Database.QueryTimeout
+	if migrate {
+		statementTimeout = 0
 	}
-	defer db.Close()
+	db := openDatabase(cfg, statementTimeout, appLogger)
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	// Subcomando "migrate": aplica ou reverte migrações e encerra
+	if migrate {
+		code := runMigrate(db, flag.Args()[1:], appLogger)
+		db.Close()
+		os.Exit(code)
 	}
-	log.Println("Connected to database successfully")
 
 	// Criar JWT Manager
 	jwtManager := auth.NewJWTManager(
@@ -67,24 +106,263 @@ func main() {
 		cfg.JWT.Audience,
 	)
 
+	// Hash de senhas com custo configurável (BCRYPT_COST)
+	passwordHasher, err := auth.NewPasswordHasher(cfg.Auth.BcryptCost)
+	if err != nil {
+		appLogger.Fatal("Invalid password hashing configuration: %v", err)
+	}
+
+	// Label tenant_id das métricas de negócio (cardinalidade no Prometheus)
+	tenantLabelMode, err := middleware.ParseTenantLabelMode(cfg.Metrics.TenantLabels)
+	if err != nil {
+		appLogger.Fatal("Invalid metrics configuration: %v", err)
+	}
+	if err := middleware.ConfigureTenantLabels(middleware.TenantLabelConfig{
+		Mode:      tenantLabelMode,
+		Buckets:   cfg.Metrics.TenantBuckets,
+		Allowlist: cfg.Metrics.TenantAllowlist,
+	}); err != nil {
+		appLogger.Fatal("Invalid metrics configuration: %v", err)
+	}
+
 	// Criar MCP Client
-	mcpClient := mcp.NewMCPClient(mcp.MCPConfig{
-		BaseURL:    cfg.MCP.BaseURL,
-		Timeout:    cfg.MCP.Timeout,
-		MaxRetries: cfg.MCP.MaxRetries,
-		RetryDelay: cfg.MCP.RetryDelay,
+	mcpActions, err := mcp.ParseActionMap(cfg.MCP.Actions)
+	if err != nil {
+		appLogger.Fatal("Invalid MCP action mapping: %v", err)
+	}
+
+	// Scopes exigidos por ferramenta do MCP; toda operação precisa de um mapeamento
+	toolScopes, err := middleware.ParseToolScopes(cfg.MCP.ToolScopes)
+	if err != nil {
+		appLogger.Fatal("Invalid MCP tool scopes: %v", err)
+	}
+	for op, mapping := range mcpActions {
+		if _, ok := toolScopes.Required(mapping.Tool, mapping.Action); !ok {
+			appLogger.Fatal("MCP tool %q (operation %s) has no required scope; set it in MCP_TOOL_SCOPES", mapping.Tool, op)
+		}
+	}
+	requireOp := func(op string) fiber.Handler {
+		mapping := mcpActions[op]
+		return toolScopes.Require(mapping.Tool, mapping.Action)
+	}
+
+	// Proteção contra SSRF nos alvos de hunt/scan/análise (opcional: ambientes on-prem
+	// podem precisar escanear a própria rede)
+	var ssrfGuard *ssrf.Guard
+	if cfg.Targets.SSRFGuard {
+		ssrfGuard, err = ssrf.NewGuard(ssrf.Config{
+			Allow:          cfg.Targets.SSRFAllow,
+			ResolveTimeout: cfg.Targets.ResolveTimeout,
+		})
+		if err != nil {
+			appLogger.Fatal("Invalid SSRF guard configuration: %v", err)
+		}
+	}
+
+	// Callbacks de jobs assíncronos são chamados pelo próprio gateway, então passam
+	// sempre pela proteção contra SSRF, sem as exceções de TARGET_SSRF_ALLOW
+	callbackGuard, err := ssrf.NewGuard(ssrf.Config{ResolveTimeout: cfg.Targets.ResolveTimeout})
+	if err != nil {
+		appLogger.Fatal("Invalid callback SSRF guard configuration: %v", err)
+	}
+
+	// Credenciais do gateway no MCP (mTLS e/ou token de serviço)
+	mcpTLS, err := mcp.LoadClientTLS(cfg.MCP.ClientCert, cfg.MCP.ClientKey, cfg.MCP.CAFile)
+	if err != nil {
+		appLogger.Fatal("Invalid MCP TLS configuration: %v", err)
+	}
+
+	// Sem MCP_BASE_URL (ou com MCP_FAKE=true) o gateway roda com o MCP em memória
+	var mcpClient mcp.Client
+	if cfg.MCP.UseFake() {
+		mcpClient = mcp.NewFakeMCPClient(mcpActions)
+		appLogger.Warn("MCP_BASE_URL not set or MCP_FAKE enabled: using the in-memory fake MCP (canned responses, development only)")
+	} else {
+		mcpClient = mcp.NewMCPClient(mcp.MCPConfig{
+			BaseURL:    cfg.MCP.BaseURL,
+			ToolURLs:   cfg.MCP.ToolURLs,
+			Timeout:    cfg.MCP.Timeout,
+			MaxRetries: cfg.MCP.MaxRetries,
+			RetryDelay: cfg.MCP.RetryDelay,
+			Actions:    mcpActions,
+
+			MaxIdleConns:        cfg.MCP.MaxIdleConns,
+			MaxIdleConnsPerHost: cfg.MCP.MaxIdleConnsPerHost,
+			IdleConnTimeout:     cfg.MCP.IdleConnTimeout,
+			DialTimeout:         cfg.MCP.DialTimeout,
+			HTTP2:               cfg.MCP.HTTP2,
+
+			ServiceToken: cfg.MCP.ServiceToken,
+			TLSConfig:    mcpTLS,
+		})
+	}
+
+	// Criar Services; cada consulta tem o prazo de DB_QUERY_TIMEOUT
+	store := services.NewDB(db, cfg.Database.QueryTimeout, cfg.Database.PrepareStatements)
+	userService := services.NewUserService(store)
+	clientService := services.NewClientService(store)
+	brandService := services.NewBrandService(store)
+	tenantService := services.NewTenantService(store)
+	alertService := services.NewAlertService(store)
+	reportService := services.NewReportService(store)
+	webhookDeliveryService := services.NewWebhookDeliveryService(store)
+	asyncJobService := services.NewAsyncJobService(store)
+	auditService := services.NewAuditService(store)
+	loginEventService := services.NewLoginEventService(store)
+
+	// Redis (opcional): estado compartilhado entre instâncias (rate limiting, revogação
+	// de tokens, slots de jobs, streams de alertas e cache de usuários/tenants). Sem REDIS_HOST o estado
+	// fica em memória na instância.
+	var (
+		redisClient     *redis.Client
+		revocationStore auth.RevocationStore    = auth.NewMemoryRevocationStore()
+		jobSlots        middleware.JobSlotStore = middleware.NewMemoryJobSlotStore()
+		entityCache     services.EntityCache    = services.NewMemoryEntityCache(cfg.Auth.EntityCacheSize, cfg.Auth.EntityCacheTTL)
+		rateLimitStore  middleware.RateLimitStore
+		alertBroker     notify.AlertBroker
+		leader          lock.Leader
+	)
+	if cfg.Redis.Host != "" {
+		redisClient, err = cache.NewClient(cache.Config{
+			Host:     cfg.Redis.Host,
+			Port:     cfg.Redis.Port,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+			PoolSize: cfg.Redis.PoolSize,
+		})
+		if err != nil {
+			appLogger.Fatal("Failed to connect to Redis: %v", err)
+		}
+		revocationStore = cache.NewRevocationStore(redisClient)
+		jobSlots = cache.NewJobSlotStore(redisClient)
+		rateLimitStore = cache.NewRateLimitStore(redisClient)
+		entityCache = cache.NewEntityCache(redisClient, cfg.Auth.EntityCacheTTL)
+		alertBroker = cache.NewAlertBroker(redisClient, cfg.Notify.StreamMaxPerTenant)
+		leader = lock.NewRedisLeader(redisClient, lock.RedisConfig{TTL: cfg.Scheduler.LeaderTTL})
+		appLogger.Info("Connected to Redis successfully")
+	} else {
+		alertBroker = notify.NewMemoryAlertBroker(cfg.Notify.StreamMaxPerTenant)
+		leader = lock.NewSingleNode()
+		appLogger.Warn("REDIS_HOST not set: rate limiting, token revocation, job slots, alert streams and the user/tenant cache are kept in memory (single instance only)")
+	}
+
+	// Prefork: cada processo tem seu próprio registry Prometheus, então um scrape de
+	// /metrics enxerga apenas o worker que atendeu. Avisa uma vez, no processo pai.
+	if cfg.Server.Prefork && !fiber.IsChild() {
+		appLogger.Warn("SERVER_PREFORK enabled: Prometheus metrics are per-process and each /metrics scrape reports a single worker")
+	}
+
+	// Usuários e tenants lidos por ID nos handlers ficam em cache e são invalidados
+	// nas alterações; AUTH_ENTITY_CACHE_TTL=0 desabilita o cache
+	if cfg.Auth.EntityCacheTTL == 0 {
+		entityCache = nil
+	}
+	cachedUsers := services.NewCachedUserService(userService, entityCache, middleware.RecordEntityCache)
+	cachedTenants := services.NewCachedTenantService(tenantService, entityCache, middleware.RecordEntityCache)
+
+	// Revogação de tokens: logout (jti/sessão) e logout-all (versão de tokens do usuário)
+	jwtManager.EnableRevocation(auth.RevocationConfig{
+		Store:           revocationStore,
+		Versions:        userService,
+		VersionCacheTTL: cfg.Auth.TokenVersionCacheTTL,
 	})
 
-	// Criar Services
-	userService := services.NewUserService(db)
-	clientService := services.NewClientService(db)
-	brandService := services.NewBrandService(db)
+	// Criar Webhook Dispatcher (entrega assíncrona de alertas)
+	webhookDispatcher := notify.NewWebhookDispatcher(notify.DispatcherConfig{
+		Workers:    cfg.Notify.Workers,
+		QueueSize:  cfg.Notify.QueueSize,
+		MaxRetries: cfg.Notify.MaxRetries,
+		RetryDelay: cfg.Notify.RetryDelay,
+		Timeout:    cfg.Notify.Timeout,
+		Guard:      callbackGuard,
+	}, webhookDeliveryService)
+
+	// Limites customizados por tenant (persistidos no banco, cacheados em memória)
+	tenantLimits := middleware.NewTenantLimitCache(tenantService, cfg.RateLimit.TenantCacheTTL)
+	jobLimiter := middleware.NewJobLimiter(jobSlots, tenantService, cfg.RateLimit.MaxConcurrentJobs, cfg.RateLimit.TenantCacheTTL)
+
+	// Suspensão de tenants: status cacheado e verificado em todas as rotas autenticadas
+	platformTenantID := uuid.Nil
+	if cfg.Auth.PlatformTenantID != "" {
+		platformTenantID, err = uuid.Parse(cfg.Auth.PlatformTenantID)
+		if err != nil {
+			appLogger.Fatal("Invalid AUTH_PLATFORM_TENANT_ID: %v", err)
+		}
+	}
+	tenantStatus := middleware.NewTenantStatusCache(tenantService, cfg.Auth.TenantStatusCacheTTL)
+	activeTenant := middleware.RequireActiveTenant(tenantStatus, platformTenantID)
+
+	// Limite de per_page das listagens
+	response.SetMaxPerPage(cfg.Server.MaxPerPage)
+	// Idioma das mensagens de erro sem Accept-Language suportado
+	response.SetDefaultLocale(cfg.Server.DefaultLocale)
 
 	// Criar Handlers
-	authHandler := handlers.NewAuthHandler(jwtManager, userService)
-	clientHandler := handlers.NewClientHandler(clientService, brandService)
-	huntingHandler := handlers.NewHuntingHandler(mcpClient)
+	authHandler := handlers.NewAuthHandler(jwtManager, cachedUsers, cachedTenants, loginEventService, handlers.AuthHandlerConfig{
+		TenantDomain:   cfg.Auth.TenantDomain,
+		PasswordHasher: passwordHasher,
+		PasswordPolicy: auth.PasswordPolicy{
+			MinLength:  cfg.Auth.PasswordMinLength,
+			MinClasses: cfg.Auth.PasswordMinClasses,
+		},
+		Mailer: notify.NewMailer(notify.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		}),
+		EmailVerification: handlers.EmailVerificationConfig{
+			Required:    cfg.Auth.RequireEmailVerification,
+			GracePeriod: cfg.Auth.VerificationGracePeriod,
+			TokenExpiry: cfg.Auth.VerificationTokenExpiry,
+			URL:         cfg.Auth.VerificationURL,
+		},
+		PlatformTenantID: platformTenantID,
+		HardDelete:       cfg.Auth.DeletionMode == "hard",
+	})
+	userHandler := handlers.NewUserHandler(cachedUsers, cachedTenants, jwtManager)
+	monitorReconciler := monitoring.NewReconciler(mcpClient, brandService, monitoring.Config{
+		Timeout: cfg.MCP.Timeout,
+	})
+	clientHandler := handlers.NewClientHandler(clientService, brandService, cachedTenants, mcpClient, monitorReconciler)
+	exportHandler := handlers.NewExportHandler(clientService, brandService, alertService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	streamHandler := handlers.NewStreamHandler(alertBroker)
+	huntingHandler := handlers.NewHuntingHandler(mcpClient, handlers.HuntingHandlerConfig{
+		JobLimiter: jobLimiter,
+		SSRFGuard:  ssrfGuard,
+		URLOptions: urlnorm.Options{
+			StripFragment: cfg.Targets.StripFragment,
+			BlockPrivate:  cfg.Targets.BlockPrivate,
+		},
+
+		AsyncJobs:     asyncJobService,
+		Tenants:       cachedTenants,
+		Dispatcher:    webhookDispatcher,
+		CallbackGuard: callbackGuard,
+		PriorityPlans: cfg.MCP.PriorityPlans,
+	})
 	onboardingHandler := handlers.NewOnboardingHandler(mcpClient)
+	alertHandler := handlers.NewAlertHandler(alertService, brandService, cachedTenants, webhookDispatcher, alertBroker)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	tenantHandler := handlers.NewTenantHandler(cachedTenants, handlers.TenantHandlerConfig{
+		Brands:       brandService,
+		MCP:          mcpClient,
+		RateLimits:   tenantLimits,
+		JobLimiter:   jobLimiter,
+		Statuses:     tenantStatus,
+		WebhookGuard: callbackGuard,
+		HardDelete:   cfg.Auth.DeletionMode == "hard",
+	})
+	healthHandler := handlers.NewHealthHandler(buildinfo.Get().Version, cfg.Server.HealthCheckTimeout, db, mcpClient)
+	if redisClient != nil {
+		healthHandler.AddCheck("redis", cache.HealthCheck(redisClient))
+	}
+	docsHandler, err := handlers.NewDocsHandler(buildinfo.Get().Version)
+	if err != nil {
+		appLogger.Fatal("Failed to generate OpenAPI spec: %v", err)
+	}
 
 	// Criar Auth Middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
@@ -98,7 +376,15 @@ func main() {
 		IdleTimeout:           cfg.Server.IdleTimeout,
 		DisableStartupMessage: false,
 		Prefork:               cfg.Server.Prefork,
+		BodyLimit:             cfg.Server.MaxBodyBytes,
 		ErrorHandler:          errorHandler,
+
+		// c.IP() só lê o ProxyHeader de proxies confiáveis; o IP usado pelo gateway
+		// vem de middleware.ClientIP, que percorre a cadeia pulando os proxies
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	// Setup Security Middlewares
@@ -109,95 +395,251 @@ func main() {
 		AllowCredentials: cfg.CORS.AllowCredentials,
 		MaxAge:           cfg.CORS.MaxAge,
 		Environment:      cfg.Server.Environment,
+		LogSampleRates:   cfg.Log.SampleRates,
+		Logger:           appLogger,
+		ClientIP: middleware.ClientIPConfig{
+			ProxyHeader:    cfg.Server.ProxyHeader,
+			TrustedProxies: cfg.Server.TrustedProxies,
+		},
+		TrustedHeaders: middleware.TrustedHeadersConfig{
+			TrustedSources: cfg.Server.TrustedHeaderSources,
+		},
 	})
 
-	// Audit Middleware
-	app.Use(middleware.AuditMiddleware())
+	// Métricas HTTP; rotas que esperam o MCP também vão para um histograma com
+	// buckets de até minutos
+	slowBuckets, err := middleware.ParseBuckets(cfg.Metrics.SlowBuckets)
+	if err != nil {
+		appLogger.Fatal("Invalid metrics configuration: %v", err)
+	}
+	app.Use(middleware.MetricsMiddleware(middleware.MetricsConfig{
+		SlowRoutes:  cfg.Metrics.SlowRoutes,
+		SlowBuckets: slowBuckets,
+	}))
 
 	// ==========================================================================
-	// ROUTES
+	// OBSERVABILITY ROUTES
 	// ==========================================================================
 
-	// Health Check
-	app.Get("/health", func(c *fiber.Ctx) error {
-		services := map[string]string{
-			"gateway": "healthy",
-		}
+	// Registradas antes da auditoria e do rate limiting: scrapes e probes
+	// não geram registros de auditoria nem consomem limites.
 
-		// Check MCP
-		if err := mcpClient.HealthCheck(c.Context()); err != nil {
-			services["mcp"] = "unhealthy"
-		} else {
-			services["mcp"] = "healthy"
+	// Prometheus metrics (inclui build info e métricas de runtime do Go e do processo)
+	if err := middleware.RegisterProcessMetrics(buildinfo.Get()); err != nil {
+		appLogger.Fatal("Failed to register process metrics: %v", err)
+	}
+	registerObservabilityRoutes(app, healthHandler, docsHandler)
+
+	// Filtro de IP/CIDR e país (GeoIP). Registrado depois de health e métricas para não
+	// bloquear probes e scrapes; as listas são recarregadas no SIGHUP.
+	var geoReader *geoip.Reader
+	var countryResolver middleware.CountryResolver
+	if cfg.IPFilter.GeoIPDBPath != "" {
+		geoReader, err = geoip.Open(cfg.IPFilter.GeoIPDBPath)
+		if err != nil {
+			appLogger.Fatal("Failed to open GeoIP database: %v", err)
 		}
+		countryResolver = geoReader
+	}
+	ipFilter, err := middleware.NewIPFilter(ipFilterRules(cfg.IPFilter), countryResolver)
+	if err != nil {
+		appLogger.Fatal("Invalid IP filter configuration: %v", err)
+	}
+	app.Use(ipFilter.Handler())
+
+	// Log de payloads (headers e corpos com campos sensíveis mascarados), apenas com LOG_LEVEL=debug
+	if cfg.Log.Payloads {
+		app.Use(middleware.PayloadLogger(middleware.PayloadLoggerConfig{
+			Logger:       appLogger,
+			RedactFields: cfg.Log.RedactFields,
+		}))
+	}
+
+	// Rate limiting por tenant (aplicado nas rotas autenticadas)
+	rateLimitConfig := middleware.RateLimitConfig{
+		Limit:           cfg.RateLimit.RequestsPerMinute,
+		WindowSize:      time.Minute,
+		CleanupInterval: cfg.RateLimit.CleanupInterval,
+		PlanLimits:      middleware.DefaultPlanLimits(),
+		TenantLimits:    tenantLimits,
+		AnonymousLimit:  cfg.RateLimit.AnonymousRPM,
+		Store:           rateLimitStore,
+	}
+	tenantRateLimiter := middleware.NewRateLimiter(rateLimitConfig)
+	tenantRateLimit := middleware.RateLimitHandler(tenantRateLimiter, rateLimitConfig)
+
+	// Rotas públicas: OptionalAuth antes do rate limit. Com token válido o limite é o do
+	// tenant (mesmo limiter das rotas protegidas); anônimas têm um limite por IP mais restrito.
+	publicRateLimitConfig := rateLimitConfig
+	publicRateLimitConfig.KeyExtractor = middleware.ClaimsKeyExtractor
+	optionalAuth := authMiddleware.OptionalAuth()
+	publicRateLimit := middleware.RateLimitHandler(tenantRateLimiter, publicRateLimitConfig)
+	// Login sempre por IP, sem OptionalAuth: um token válido não amplia as tentativas de senha
+	loginRateLimitConfig := rateLimitConfig
+	loginRateLimitConfig.KeyExtractor = middleware.AnonymousKeyExtractor
+	loginRateLimit := middleware.RateLimitHandler(tenantRateLimiter, loginRateLimitConfig)
+	adminHandler := handlers.NewAdminHandler(tenantRateLimiter, monitorReconciler)
 
-		return response.Health(c, version, services)
+	// Audit Middleware
+	auditOverflow, err := middleware.ParseAuditOverflowPolicy(cfg.Audit.OverflowPolicy)
+	if err != nil {
+		appLogger.Fatal("Invalid audit configuration: %v", err)
+	}
+	auditWriter := middleware.NewAuditWriter(auditService, middleware.AuditWriterConfig{
+		BufferSize:     cfg.Audit.BufferSize,
+		OverflowPolicy: auditOverflow,
 	})
+	app.Use(middleware.AuditMiddleware(middleware.AuditConfig{
+		Writer:         auditWriter,
+		ReadSampleRate: cfg.Audit.ReadSampleRate,
+	}))
+
+	// CAPTCHA nas rotas públicas de registro (no-op quando desabilitado)
+	var captchaVerifier middleware.CaptchaVerifier
+	if cfg.Captcha.Enabled {
+		verifier, err := middleware.NewCaptchaVerifier(cfg.Captcha.Provider, cfg.Captcha.Secret, cfg.Captcha.VerifyURL, cfg.Captcha.Timeout)
+		if err != nil {
+			appLogger.Fatal("Invalid captcha configuration: %v", err)
+		}
+		captchaVerifier = verifier
+	}
+	captcha := middleware.CaptchaMiddleware(captchaVerifier)
+
+	// ==========================================================================
+	// ROUTES
+	// ==========================================================================
+
+	// Limites de corpo por rota, abaixo de SERVER_MAX_BODY_BYTES: credenciais e
+	// registro são pequenos; hunting aceita listas de keywords, mas não arquivos
+	smallBodyLimit := middleware.BodyLimit(16 * 1024)
+	huntingBodyLimit := middleware.BodyLimit(256 * 1024)
 
 	// API v1
 	v1 := app.Group("/v1")
 
 	// Auth routes (public)
-	authRoutes := v1.Group("/auth")
-	authRoutes.Post("/login", authHandler.Login)
-	authRoutes.Post("/register", authHandler.Register)
-	authRoutes.Post("/refresh", authHandler.RefreshToken)
+	authRoutes := v1.Group("/auth", smallBodyLimit)
+	authRoutes.Post("/login", loginRateLimit, authHandler.Login)
+	authRoutes.Post("/register", optionalAuth, publicRateLimit, captcha, authHandler.Register)
+	authRoutes.Post("/refresh", optionalAuth, publicRateLimit, authHandler.RefreshToken)
+	authRoutes.Get("/verify-email", optionalAuth, publicRateLimit, authHandler.VerifyEmail)
+	authRoutes.Post("/verify-email", optionalAuth, publicRateLimit, authHandler.VerifyEmail)
 
 	// Onboarding routes (public - registro inicial)
-	onboardingRoutes := v1.Group("/onboarding")
-	onboardingRoutes.Post("/register", onboardingHandler.Register)
+	onboardingRoutes := v1.Group("/onboarding", smallBodyLimit, optionalAuth, publicRateLimit)
+	onboardingRoutes.Post("/register", captcha, onboardingHandler.Register)
 	onboardingRoutes.Post("/verify-email", onboardingHandler.VerifyEmail)
 
 	// Brand routes (protected - via onboarding handler que faz proxy para Core Python)
-	brandRoutesNew := v1.Group("/brands", authMiddleware.Authenticate())
-	brandRoutesNew.Get("/", onboardingHandler.ListBrands)
-	brandRoutesNew.Post("/", onboardingHandler.CreateBrand)
-	brandRoutesNew.Get("/:brand_id", onboardingHandler.GetBrand)
-	brandRoutesNew.Post("/:brand_id/monitoring/start", onboardingHandler.StartMonitoring)
-	brandRoutesNew.Post("/:brand_id/monitoring/stop", onboardingHandler.StopMonitoring)
-	brandRoutesNew.Get("/:brand_id/monitoring/status", onboardingHandler.GetMonitoringStatus)
+	clientAccess := middleware.RequireClientAccess(clientService)
+	brandRoutesNew := v1.Group("/brands", authMiddleware.Authenticate(), activeTenant, tenantRateLimit, clientAccess)
+	brandRoutesNew.Get("/", toolScopes.Require("onboarding", "list_brands"), onboardingHandler.ListBrands)
+	brandRoutesNew.Post("/", toolScopes.Require("onboarding", "create_brand"), onboardingHandler.CreateBrand)
+	brandRoutesNew.Get("/:brand_id", toolScopes.Require("onboarding", "get_monitoring_status"), onboardingHandler.GetBrand)
+	brandRoutesNew.Post("/:brand_id/monitoring/start", toolScopes.Require("monitoring", "start"), onboardingHandler.StartMonitoring)
+	brandRoutesNew.Post("/:brand_id/monitoring/stop", toolScopes.Require("monitoring", "stop"), onboardingHandler.StopMonitoring)
+	brandRoutesNew.Get("/:brand_id/monitoring/status", toolScopes.Require("monitoring", "status"), onboardingHandler.GetMonitoringStatus)
 
 	// Threats routes (protected)
-	threatsRoutes := v1.Group("/threats", authMiddleware.Authenticate())
-	threatsRoutes.Get("/", onboardingHandler.GetThreats)
+	threatsRoutes := v1.Group("/threats", authMiddleware.Authenticate(), activeTenant, tenantRateLimit, clientAccess)
+	threatsRoutes.Get("/", toolScopes.Require("threats", "list"), onboardingHandler.GetThreats)
 
 	// Auth routes (protected)
-	authProtected := authRoutes.Group("", authMiddleware.Authenticate())
+	authProtected := authRoutes.Group("", authMiddleware.Authenticate(), tenantRateLimit)
 	authProtected.Post("/logout", authHandler.Logout)
+	authProtected.Post("/logout-all", authHandler.LogoutAll)
 	authProtected.Get("/me", authHandler.Me)
+	authProtected.Delete("/me", authHandler.DeleteMe)
+	authProtected.Put("/password", authHandler.ChangePassword)
+	authProtected.Get("/sessions", authHandler.Sessions)
 	authProtected.Post("/api-key", authHandler.GenerateAPIKey)
 
 	// Client routes (protected)
-	clientRoutes := v1.Group("/clients", authMiddleware.Authenticate())
+	clientRoutes := v1.Group("/clients", authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
 	clientRoutes.Use(middleware.RequireScope(middleware.ScopeClientsRead))
 	clientRoutes.Get("/", clientHandler.ListClients)
+	clientRoutes.Get("/export", middleware.RequireScope(middleware.ScopeReportsRead), exportHandler.ExportClients)
 	clientRoutes.Get("/:client_id", clientHandler.GetClient)
 	clientRoutes.Post("/", middleware.RequireScope(middleware.ScopeClientsWrite), clientHandler.CreateClient)
 	clientRoutes.Put("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), clientHandler.UpdateClient)
+	clientRoutes.Patch("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), clientHandler.PatchClient)
 	clientRoutes.Delete("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), clientHandler.DeleteClient)
 
 	// Brand routes (nested under clients)
 	brandRoutes := clientRoutes.Group("/:client_id/brands")
 	brandRoutes.Use(middleware.RequireScope(middleware.ScopeBrandsRead))
 	brandRoutes.Get("/", clientHandler.ListBrands)
+	brandRoutes.Get("/export", middleware.RequireScope(middleware.ScopeReportsRead), exportHandler.ExportBrands)
 	brandRoutes.Get("/:brand_id", clientHandler.GetBrand)
 	brandRoutes.Post("/", middleware.RequireScope(middleware.ScopeBrandsWrite), clientHandler.CreateBrand)
+	brandRoutes.Post("/bulk", middleware.RequireScope(middleware.ScopeBrandsWrite), clientHandler.BulkCreateBrands)
 	brandRoutes.Put("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsWrite), clientHandler.UpdateBrand)
+	brandRoutes.Patch("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsWrite), clientHandler.PatchBrand)
 	brandRoutes.Delete("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsWrite), clientHandler.DeleteBrand)
 	brandRoutes.Post("/:brand_id/monitoring/start", middleware.RequireScope(middleware.ScopeMonitorWrite), clientHandler.StartMonitoring)
 	brandRoutes.Post("/:brand_id/monitoring/stop", middleware.RequireScope(middleware.ScopeMonitorWrite), clientHandler.StopMonitoring)
+	brandRoutes.Get("/:brand_id/monitoring", middleware.RequireScope(middleware.ScopeMonitorRead), clientHandler.GetMonitoringStatus)
+
+	// Alert routes (protected)
+	alertRoutes := v1.Group("/alerts", authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
+	alertRoutes.Use(middleware.RequireScope(middleware.ScopeAlertsRead))
+	alertRoutes.Get("/export", middleware.RequireScope(middleware.ScopeReportsRead), exportHandler.ExportAlerts)
+
+	// Report routes (protected)
+	reportRoutes := v1.Group("/reports", authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
+	reportRoutes.Use(middleware.RequireScope(middleware.ScopeReportsRead))
+	reportRoutes.Get("/summary", reportHandler.Summary)
+	reportRoutes.Post("/summary/jobs", reportHandler.CreateSummaryJob)
+	reportRoutes.Get("/jobs/:job_id", reportHandler.GetJob)
+	reportRoutes.Get("/jobs/:job_id/download", reportHandler.DownloadJob)
+
+	// Stream routes (protected - token via header ou ?access_token= para EventSource)
+	streamRoutes := v1.Group("/stream", middleware.QueryTokenAuth(), authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
+	streamRoutes.Get("/alerts", middleware.RequireScope(middleware.ScopeAlertsRead), streamHandler.StreamAlerts)
 
 	// Hunting routes (protected)
-	huntingRoutes := v1.Group("/hunting", authMiddleware.Authenticate())
-	huntingRoutes.Post("/hunt", huntingHandler.Hunt)
-	huntingRoutes.Post("/scan", huntingHandler.ScanURL)
-	huntingRoutes.Post("/analyze", huntingHandler.AnalyzeURL)
-	huntingRoutes.Post("/leaks/search", huntingHandler.SearchLeaks)
+	huntingRoutes := v1.Group("/hunting", huntingBodyLimit, authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
+	huntingRoutes.Post("/hunt", requireOp(mcp.OpHunt), huntingHandler.Hunt)
+	huntingRoutes.Post("/hunt/batch", requireOp(mcp.OpHunt), huntingHandler.HuntBatch)
+	huntingRoutes.Post("/scan", requireOp(mcp.OpScanURL), huntingHandler.ScanURL)
+	huntingRoutes.Post("/analyze", requireOp(mcp.OpAnalyzeURL), huntingHandler.AnalyzeURL)
+	huntingRoutes.Post("/leaks/search", requireOp(mcp.OpSearchLeaks), huntingHandler.SearchLeaks)
+	huntingRoutes.Get("/jobs/:job_id", middleware.RequireScope(middleware.ScopeHuntingRead), huntingHandler.GetJob)
 
 	// Monitor routes (protected)
-	monitorRoutes := v1.Group("/monitor", authMiddleware.Authenticate())
-	monitorRoutes.Post("/jobs", huntingHandler.CreateMonitorJob)
-	monitorRoutes.Post("/jobs/:job_id/stop", huntingHandler.StopMonitorJob)
+	monitorRoutes := v1.Group("/monitor", authMiddleware.Authenticate(), activeTenant, tenantRateLimit)
+	monitorRoutes.Post("/jobs", requireOp(mcp.OpCreateMonitorJob), huntingHandler.CreateMonitorJob)
+	monitorRoutes.Post("/jobs/:job_id/stop", requireOp(mcp.OpStopMonitorJob), huntingHandler.StopMonitorJob)
+
+	// User management routes (protected - admin/manager)
+	userRoutes := v1.Group("/users", authMiddleware.Authenticate(), activeTenant, tenantRateLimit, authMiddleware.RequireRole(models.RoleAdmin, models.RoleManager))
+	userRoutes.Patch("/:user_id/role", userHandler.UpdateRole)
+	userRoutes.Patch("/:user_id/scopes", userHandler.UpdateScopes)
+
+	// Admin routes (protected - admin only)
+	adminRoutes := v1.Group("/admin", authMiddleware.Authenticate(), activeTenant, tenantRateLimit, authMiddleware.RequireRole(models.RoleAdmin))
+	platformAdmin := middleware.RequirePlatformAdmin(platformTenantID)
+	adminRoutes.Get("/tenants/:tenant_id", platformAdmin, middleware.RequireScope(middleware.ScopeAdminRead), tenantHandler.GetTenant)
+	adminRoutes.Patch("/tenants/:tenant_id", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.UpdateTenant)
+	adminRoutes.Delete("/tenants/:tenant_id", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.DeleteTenant)
+	adminRoutes.Put("/tenants/:tenant_id/plan", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.UpdatePlan)
+	adminRoutes.Put("/tenants/:tenant_id/quotas", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.UpdateQuotas)
+	adminRoutes.Put("/tenants/:tenant_id/settings", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.UpdateSettings)
+	adminRoutes.Get("/tenants/:tenant_id/rate-limit", platformAdmin, middleware.RequireScope(middleware.ScopeAdminRead), tenantHandler.GetRateLimit)
+	adminRoutes.Put("/tenants/:tenant_id/rate-limit", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), tenantHandler.SetRateLimit)
+	adminRoutes.Get("/ratelimit/:key", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), adminHandler.InspectRateLimit)
+	adminRoutes.Delete("/ratelimit/:key", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), adminHandler.ResetRateLimit)
+	adminRoutes.Post("/monitoring/reconcile", platformAdmin, middleware.RequireScope(middleware.ScopeAdminWrite), adminHandler.ReconcileMonitoring)
+
+	// Audit routes (protected - admin only)
+	auditRoutes := v1.Group("/audit", authMiddleware.Authenticate(), activeTenant, tenantRateLimit, authMiddleware.RequireRole(models.RoleAdmin))
+	auditRoutes.Get("/", auditHandler.ListAuditLogs)
+
+	// Internal routes (service-to-service - Core/MCP)
+	internalRoutes := v1.Group("/internal", authMiddleware.AuthenticateService(cfg.Internal.ServiceToken))
+	internalRoutes.Post("/alerts", alertHandler.IngestAlert)
+	internalRoutes.Get("/tenants/:tenant_id/policy", middleware.RequireServiceToken(), tenantHandler.GetPolicy)
+	internalRoutes.Post("/jobs/:job_id/complete", middleware.RequireServiceToken(), huntingHandler.CompleteJob)
 
 	// ==========================================================================
 	// START SERVER
@@ -207,25 +649,290 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// SIGHUP recarrega a configuração que pode ser aplicada sem reiniciar
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		current := cfg
+		for range hup {
+			current = reloadConfig(current, sources, appLogger, tenantRateLimiter, jobLimiter, ipFilter)
+		}
+	}()
+
+	// Tarefas periódicas; com Redis as singleton rodam apenas na instância líder
+	jobScheduler := scheduler.New(scheduler.Config{Leader: leader})
+	if err := registerJobs(jobScheduler, cfg, revocationStore, monitorReconciler, userService, tenantService); err != nil {
+		appLogger.Fatal("Failed to register background jobs: %v", err)
+	}
+	jobScheduler.Start()
+
 	go func() {
 		addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-		log.Printf("Starting server on %s", addr)
+		appLogger.Info("Starting server on %s", addr)
 		if err := app.Listen(addr); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
+			appLogger.Fatal("Failed to start server: %v", err)
 		}
 	}()
 
 	<-quit
-	log.Println("Shutting down server...")
+	appLogger.Info("Shutting down server...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	// Encerrar os streams abertos para que o shutdown não espere por eles
+	alertBroker.Close()
+
 	if err := app.ShutdownWithContext(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		appLogger.Fatal("Server forced to shutdown: %v", err)
 	}
 
-	log.Println("Server exited gracefully")
+	// Cancelar as tarefas periódicas em andamento
+	jobScheduler.Stop()
+	// Liberar a liderança para que outra instância assuma sem esperar o TTL
+	leader.Stop()
+
+	// Drenar entregas de webhook e registros de auditoria pendentes
+	webhookDispatcher.Stop()
+	auditWriter.Stop()
+
+	// Parar goroutines de limpeza do rate limiting e fechar o banco
+	tenantRateLimiter.Stop()
+	if redisClient != nil {
+		if err := redisClient.Close(); err != nil {
+			appLogger.WithError(err).Warn("Failed to close Redis")
+		}
+	}
+	if geoReader != nil {
+		if err := geoReader.Close(); err != nil {
+			appLogger.WithError(err).Warn("Failed to close GeoIP database")
+		}
+	}
+	if err := store.CloseStatements(); err != nil {
+		appLogger.WithError(err).Warn("Failed to close prepared statements")
+	}
+	if err := db.Close(); err != nil {
+		appLogger.WithError(err).Warn("Failed to close database")
+	}
+
+	appLogger.Info("Server exited gracefully")
+}
+
+// registerObservabilityRoutes registra health checks, versão, /metrics e a documentação.
+// Deve ser chamada antes de app.Use da auditoria e do rate limiting.
+func registerObservabilityRoutes(app *fiber.App, healthHandler *handlers.HealthHandler, docsHandler *handlers.DocsHandler) {
+	// Health Checks: liveness não depende de serviços externos; readiness verifica
+	// MCP e banco em paralelo e retorna 503 se algum estiver fora. /health é alias de readiness.
+	app.Get("/health/live", healthHandler.Live)
+	app.Get("/health/ready", healthHandler.Ready)
+	app.Get("/health", healthHandler.Ready)
+
+	// Versão, commit e data do build em execução
+	app.Get("/version", healthHandler.Version)
+
+	// Prometheus metrics (inclui build info e métricas de runtime do Go e do processo)
+	app.Get("/metrics", middleware.MetricsHandler())
+
+	// Documentação: especificação OpenAPI e Swagger UI
+	app.Get("/openapi.json", docsHandler.OpenAPI)
+	app.Get("/docs", docsHandler.SwaggerUI)
+	app.Get("/docs/swagger-initializer.js", docsHandler.SwaggerUIInit)
+}
+
+// registerJobs registra as tarefas periódicas habilitadas na configuração
+func registerJobs(jobs *scheduler.Scheduler, cfg *config.Config, revocations auth.RevocationStore, reconciler *monitoring.Reconciler, users *services.UserService, tenants *services.TenantService) error {
+	// Revogações em memória são por instância; no Redis elas expiram sozinhas
+	if memory, ok := revocations.(*auth.MemoryRevocationStore); ok {
+		err := jobs.Register(scheduler.Job{
+			Name:     "revocation_prune",
+			Interval: cfg.Scheduler.RevocationPruneInterval,
+			Run: func(ctx context.Context) error {
+				_, err := memory.Prune(ctx)
+				return err
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.MCP.MonitorSyncInterval > 0 {
+		err := jobs.Register(scheduler.Job{
+			Name:      "monitoring_sync",
+			Interval:  cfg.MCP.MonitorSyncInterval,
+			Singleton: true,
+			Run:       reconciler.Sync,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Auth.DeletionRetention > 0 {
+		retention := cfg.Auth.DeletionRetention
+		err := jobs.Register(scheduler.Job{
+			Name:      "deletion_purge",
+			Interval:  cfg.Scheduler.PurgeInterval,
+			Singleton: true,
+			Run: func(ctx context.Context) error {
+				before := time.Now().Add(-retention)
+				purgedUsers, err := users.PurgeDeleted(ctx, before)
+				if err != nil {
+					return err
+				}
+				purgedTenants, err := tenants.PurgeDeleted(ctx, before)
+				if err != nil {
+					return err
+				}
+				if purgedUsers > 0 || purgedTenants > 0 {
+					logger.WithFields(map[string]interface{}{
+						"users":   purgedUsers,
+						"tenants": purgedTenants,
+					}).Info("soft-deleted accounts purged")
+				}
+				return nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openDatabase abre o pool de conexões com o PostgreSQL e verifica a conexão.
+// statementTimeout > 0 define o statement_timeout das conexões, para que o próprio
+// PostgreSQL cancele comandos lentos (inclusive os que perderam o contexto).
+func openDatabase(cfg *config.Config, statementTimeout time.Duration, appLogger *logger.Logger) *sql.DB {
+	dbConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name, cfg.Database.SSLMode)
+	if statementTimeout > 0 {
+		// Parâmetros desconhecidos da DSN são enviados pelo lib/pq como parâmetros de sessão
+		dbConnStr += fmt.Sprintf(" statement_timeout=%d", statementTimeout.Milliseconds())
+	}
+
+	db, err := sql.Open("postgres", dbConnStr)
+	if err != nil {
+		appLogger.Fatal("Failed to connect to database: %v", err)
+	}
+
+	// Pool de conexões: MinConns conexões ociosas mantidas, no máximo MaxConns abertas
+	db.SetMaxOpenConns(cfg.Database.MaxConns)
+	db.SetMaxIdleConns(cfg.Database.MinConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+	appLogger.WithFields(map[string]interface{}{
+		"max_open_conns":     cfg.Database.MaxConns,
+		"max_idle_conns":     cfg.Database.MinConns,
+		"conn_max_lifetime":  cfg.Database.ConnMaxLifetime.String(),
+		"conn_max_idle_time": cfg.Database.ConnMaxIdleTime.String(),
+		"statement_timeout":  statementTimeout.String(),
+	}).Info("Database pool configured")
+
+	if err := db.Ping(); err != nil {
+		appLogger.Fatal("Failed to ping database: %v", err)
+	}
+	appLogger.Info("Connected to database successfully")
+	return db
+}
+
+// parseFlags lê as flags de linha de comando. Flags não informadas não sobrescrevem
+// o ambiente; --config-file usa CONFIG_FILE como padrão.
+func parseFlags() config.Sources {
+	host := flag.String("host", "", "endereço de escuta (SERVER_HOST)")
+	port := flag.String("port", "", "porta HTTP (SERVER_PORT)")
+	env := flag.String("env", "", "ambiente: development, staging ou production (ENVIRONMENT)")
+	logLevel := flag.String("log-level", "", "nível de log: debug, info, warn ou error (LOG_LEVEL)")
+	mcpURL := flag.String("mcp-url", "", "URL base do MCP Control Plane (MCP_BASE_URL)")
+	configFile := flag.String("config-file", os.Getenv("CONFIG_FILE"), "arquivo YAML de configuração (CONFIG_FILE)")
+	showVersion := flag.Bool("version", false, "mostra a versão do build e encerra")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [migrate up [N] | down [N] | version]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Printf("arca-gateway %s\n", buildinfo.Get())
+		os.Exit(0)
+	}
+
+	return config.Sources{
+		Flags: map[string]string{
+			"SERVER_HOST":  *host,
+			"SERVER_PORT":  *port,
+			"ENVIRONMENT":  *env,
+			"LOG_LEVEL":    *logLevel,
+			"MCP_BASE_URL": *mcpURL,
+		},
+		File: *configFile,
+	}
+}
+
+// reloadConfig relê o CONFIG_ENV_FILE, o ambiente e o arquivo de configuração e aplica as configurações recarregáveis
+// (nível de log, limites de rate limiting/jobs e filtro de IP). As demais alterações são ignoradas com
+// um aviso. Retorna a configuração em vigor após o reload.
+func reloadConfig(current *config.Config, sources config.Sources, appLogger *logger.Logger, rateLimiter *middleware.RateLimiter, jobLimiter *middleware.JobLimiter, ipFilter *middleware.IPFilter) *config.Config {
+	if err := config.LoadEnvFile(os.Getenv("CONFIG_ENV_FILE")); err != nil {
+		appLogger.WithError(err).Error("Configuration reload failed; keeping current configuration")
+		return current
+	}
+
+	next, err := config.LoadWithSources(sources)
+	if err != nil {
+		appLogger.WithError(err).Error("Configuration reload failed; keeping current configuration")
+		return current
+	}
+	next.Normalize()
+	if err := next.Validate(); err != nil {
+		appLogger.WithError(err).Error("Configuration reload rejected; keeping current configuration")
+		return current
+	}
+
+	if ignored := current.RestartRequired(next); len(ignored) > 0 {
+		appLogger.WithField("settings", ignored).Warn("Configuration changes require a restart and were ignored")
+	}
+
+	if err := ipFilter.Update(ipFilterRules(next.IPFilter)); err != nil {
+		appLogger.WithError(err).Error("Configuration reload rejected; keeping current configuration")
+		return current
+	}
+	appLogger.SetLevel(logger.ParseLevel(next.Log.Level))
+	rateLimiter.SetLimit(next.RateLimit.RequestsPerMinute)
+	rateLimiter.SetAnonymousLimit(next.RateLimit.AnonymousRPM)
+	jobLimiter.SetDefaultLimit(next.RateLimit.MaxConcurrentJobs)
+
+	appLogger.WithFields(map[string]interface{}{
+		"log_level":           next.Log.Level,
+		"rate_limit_rpm":      next.RateLimit.RequestsPerMinute,
+		"anonymous_rpm":       next.RateLimit.AnonymousRPM,
+		"max_concurrent_jobs": next.RateLimit.MaxConcurrentJobs,
+		"ip_allow":            len(next.IPFilter.Allow),
+		"ip_deny":             len(next.IPFilter.Deny),
+		"countries_allow":     len(next.IPFilter.AllowCountries),
+		"countries_deny":      len(next.IPFilter.DenyCountries),
+	}).Info("Configuration reloaded")
+
+	// Mantém os valores em uso para os campos que não foram aplicados
+	applied := *current
+	applied.Log.Level = next.Log.Level
+	applied.RateLimit.RequestsPerMinute = next.RateLimit.RequestsPerMinute
+	applied.RateLimit.AnonymousRPM = next.RateLimit.AnonymousRPM
+	applied.RateLimit.MaxConcurrentJobs = next.RateLimit.MaxConcurrentJobs
+	applied.IPFilter.Allow, applied.IPFilter.Deny = next.IPFilter.Allow, next.IPFilter.Deny
+	applied.IPFilter.AllowCountries, applied.IPFilter.DenyCountries = next.IPFilter.AllowCountries, next.IPFilter.DenyCountries
+	return &applied
+}
+
+// ipFilterRules converte a configuração do filtro de IP para as regras do middleware
+func ipFilterRules(cfg config.IPFilterConfig) middleware.IPFilterRules {
+	return middleware.IPFilterRules{
+		Allow:          cfg.Allow,
+		Deny:           cfg.Deny,
+		AllowCountries: cfg.AllowCountries,
+		DenyCountries:  cfg.DenyCountries,
+	}
 }
 
 // errorHandler handler de erros global
@@ -238,12 +945,13 @@ func errorHandler(c *fiber.Ctx, err error) error {
 		message = e.Message
 	}
 
-	return c.Status(code).JSON(fiber.Map{
-		"success":   false,
-		"error": fiber.Map{
-			"code":    code,
-			"message": message,
-		},
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-	})
+	// Corpo acima de fiber.Config.BodyLimit: rejeitado pelo fasthttp antes dos handlers
+	if code == fiber.StatusRequestEntityTooLarge {
+		return response.PayloadTooLarge(c, "Request body too large")
+	}
+
+	// Mesmo envelope dos handlers (código string, request_id, idioma e formato
+	// negociados); erros que não são *fiber.Error (ex.: panics recuperados) não
+	// expõem a mensagem original
+	return response.Error(c, code, response.CodeForStatus(code), message)
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// recordingAuditStore guarda os paths auditados
+type recordingAuditStore struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (s *recordingAuditStore) Log(ctx context.Context, entry *models.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, _ := entry.Details["path"].(string)
+	s.paths = append(s.paths, path)
+	return nil
+}
+
+// Scrapes de /metrics não passam pela auditoria nem pelo rate limiting registrados
+// depois das rotas de observabilidade, mesmo com claims e limite de 1 request
+func TestMetricsScrapeIsNotAuditedOrRateLimited(t *testing.T) {
+	healthHandler := handlers.NewHealthHandler("test", time.Second, nil, mcp.NewFakeMCPClient(nil))
+	docsHandler, err := handlers.NewDocsHandler("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &recordingAuditStore{}
+	writer := middleware.NewAuditWriter(store, middleware.AuditWriterConfig{})
+	limitConfig := middleware.RateLimitConfig{Limit: 1, WindowSize: time.Minute}
+	limiter := middleware.NewRateLimiter(limitConfig)
+	defer limiter.Stop()
+
+	tenantID := uuid.New()
+	app := fiber.New()
+	registerObservabilityRoutes(app, healthHandler, docsHandler)
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(middleware.ContextKeyClaims, &auth.Claims{UserID: uuid.New(), TenantID: tenantID, Role: models.RoleAdmin})
+		c.Locals(middleware.ContextKeyTenantID, tenantID)
+		return c.Next()
+	})
+	app.Use(middleware.AuditMiddleware(middleware.AuditConfig{Writer: writer, ReadSampleRate: 1}))
+	app.Use(middleware.RateLimitHandler(limiter, limitConfig))
+	app.Get("/v1/clients", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for i := 0; i < 5; i++ {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/metrics", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("scrape %d: status = %d, want 200", i+1, resp.StatusCode)
+		}
+		if limit := resp.Header.Get("X-RateLimit-Limit"); limit != "" {
+			t.Errorf("scrape %d: X-RateLimit-Limit = %q, want none", i+1, limit)
+		}
+	}
+
+	// Controle: uma rota comum é auditada e limitada pelos mesmos middlewares
+	var statuses []int
+	for i := 0; i < 2; i++ {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/v1/clients", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		statuses = append(statuses, resp.StatusCode)
+	}
+	if statuses[0] != fiber.StatusOK || statuses[1] != fiber.StatusTooManyRequests {
+		t.Errorf("/v1/clients statuses = %v, want [200 429]", statuses)
+	}
+
+	writer.Stop()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if want := []string{"/v1/clients", "/v1/clients"}; !reflect.DeepEqual(store.paths, want) {
+		t.Errorf("audited paths = %v, want %v", store.paths, want)
+	}
+}
+
+// Corpo acima de fiber.Config.BodyLimit é rejeitado pelo fasthttp antes das rotas; o
+// errorHandler responde 413 no envelope padrão
+func TestOversizedBodyReturnsStructured413(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 1024, ErrorHandler: errorHandler, DisableStartupMessage: true})
+	app.Post("/v1/scan", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	// app.Test devolve o erro do fasthttp em vez da resposta: a request passa por um listener real
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+
+	body := strings.NewReader(`{"keywords":"` + strings.Repeat("a", 2048) + `"}`)
+	resp, err := http.Post("http://"+ln.Addr().String()+"/v1/scan", fiber.MIMEApplicationJSON, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var envelope response.Response
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusRequestEntityTooLarge || envelope.Success || envelope.Error == nil || envelope.Error.Code != response.CodePayloadTooLarge {
+		t.Errorf("status = %d, envelope = %+v", resp.StatusCode, envelope)
+	}
+}
+
+// Panics, erros comuns e *fiber.Error saem no envelope padrão: código string,
+// request_id igual ao header e nada do panic ou do erro original na resposta
+func TestErrorHandlerUsesStandardEnvelope(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: errorHandler})
+	middleware.SetupSecurityMiddlewares(app, middleware.SecurityConfig{
+		Logger: logger.New(logger.Config{Level: logger.ErrorLevel, Output: io.Discard}),
+	})
+	app.Get("/panic", func(c *fiber.Ctx) error { panic("pq: password authentication failed for user arca") })
+	app.Get("/error", func(c *fiber.Ctx) error { return errors.New("pq: password authentication failed for user arca") })
+
+	for _, tc := range []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/panic", fiber.StatusInternalServerError, response.CodeInternalServerError},
+		{"/error", fiber.StatusInternalServerError, response.CodeInternalServerError},
+		{"/missing", fiber.StatusNotFound, response.CodeNotFound},
+	} {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tc.target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var envelope struct {
+			Success bool `json:"success"`
+			Error   struct {
+				Code    interface{} `json:"code"`
+				Message string      `json:"message"`
+			} `json:"error"`
+			RequestID string `json:"request_id"`
+			Timestamp string `json:"timestamp"`
+		}
+		if err := json.Unmarshal(raw, &envelope); err != nil {
+			t.Fatalf("%s: decode %q: %v", tc.target, raw, err)
+		}
+		if resp.StatusCode != tc.status || envelope.Success || envelope.Error.Code != tc.code {
+			t.Errorf("%s: status = %d, envelope = %s", tc.target, resp.StatusCode, raw)
+		}
+		if envelope.RequestID == "" || envelope.RequestID != resp.Header.Get(response.HeaderRequestID) || envelope.Timestamp == "" {
+			t.Errorf("%s: request_id = %q (header %q), timestamp = %q", tc.target, envelope.RequestID, resp.Header.Get(response.HeaderRequestID), envelope.Timestamp)
+		}
+		if strings.Contains(string(raw), "password") {
+			t.Errorf("%s: response leaks the original error: %s", tc.target, raw)
+		}
+	}
+}
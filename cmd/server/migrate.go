@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/arcaintelligence/arca-gateway/internal/migrations"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+)
+
+const migrateUsage = "usage: arca-gateway migrate up [N] | down [N] | version"
+
+// runMigrate executa o subcomando migrate e retorna o código de saída.
+//
+//	migrate up [N]    aplica as N próximas migrações pendentes (padrão: todas)
+//	migrate down [N]  reverte as N últimas migrações aplicadas (padrão: 1)
+//	migrate version   mostra a versão atual e quantas migrações estão pendentes
+func runMigrate(db *sql.DB, args []string, appLogger *logger.Logger) int {
+	if len(args) == 0 || len(args) > 2 {
+		appLogger.Error(migrateUsage)
+		return 2
+	}
+
+	steps := 0
+	if args[0] == "down" {
+		steps = 1
+	}
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			appLogger.Error("invalid number of steps %q; %s", args[1], migrateUsage)
+			return 2
+		}
+		steps = n
+	}
+
+	runner, err := migrations.NewRunner(db)
+	if err != nil {
+		appLogger.WithError(err).Error("Failed to load migrations")
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx, steps)
+		for _, m := range applied {
+			appLogger.Info("Applied migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			appLogger.WithError(err).Error("Migration failed")
+			return 1
+		}
+		if len(applied) == 0 {
+			appLogger.Info("No pending migrations")
+		}
+	case "down":
+		reverted, err := runner.Down(ctx, steps)
+		for _, m := range reverted {
+			appLogger.Info("Reverted migration %d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			appLogger.WithError(err).Error("Migration rollback failed")
+			return 1
+		}
+		if len(reverted) == 0 {
+			appLogger.Info("No applied migrations to revert")
+		}
+	case "version":
+		version, pending, err := runner.Version(ctx)
+		if err != nil {
+			appLogger.WithError(err).Error("Failed to read migration version")
+			return 1
+		}
+		appLogger.WithFields(map[string]interface{}{
+			"version": version,
+			"pending": pending,
+		}).Info("Schema version %d (%d pending)", version, pending)
+	default:
+		appLogger.Error(migrateUsage)
+		return 2
+	}
+	return 0
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -15,6 +16,7 @@ var (
 	ErrInvalidClaims    = errors.New("invalid token claims")
 	ErrMissingToken     = errors.New("missing authorization token")
 	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrRevokedToken     = errors.New("token has been revoked")
 )
 
 // TokenType representa o tipo de token
@@ -24,6 +26,8 @@ const (
 	TokenTypeAccess  TokenType = "access"
 	TokenTypeRefresh TokenType = "refresh"
 	TokenTypeAPI     TokenType = "api"
+	// TokenTypeEmailVerification token enviado por email para confirmar o endereço
+	TokenTypeEmailVerification TokenType = "email_verification"
 )
 
 // Claims representa os claims customizados do JWT
@@ -44,6 +48,10 @@ type Claims struct {
 	Name      string    `json:"name,omitempty"`
 	ClientID  string    `json:"client_id,omitempty"`
 	Plan      string    `json:"plan,omitempty"`
+
+	// Sessão: access e refresh emitidos juntos compartilham o SessionID
+	SessionID    string `json:"sid,omitempty"`
+	TokenVersion int    `json:"tv,omitempty"`
 }
 
 // JWTManager gerencia operações com JWT
@@ -53,6 +61,10 @@ type JWTManager struct {
 	refreshExpiry time.Duration
 	issuer        string
 	audience      string
+
+	// Revogação (opcional, ver EnableRevocation)
+	revocations RevocationStore
+	versions    *tokenVersionCache
 }
 
 // NewJWTManager cria um novo gerenciador JWT
@@ -66,29 +78,59 @@ func NewJWTManager(secret string, accessExpiry, refreshExpiry time.Duration, iss
 	}
 }
 
+// EnableRevocation habilita a verificação de tokens revogados (logout) e da versão
+// de tokens do usuário (logout-all) em ValidateToken
+func (m *JWTManager) EnableRevocation(config RevocationConfig) {
+	m.revocations = config.Store
+	if config.Versions != nil {
+		m.versions = newTokenVersionCache(config.Versions, config.VersionCacheTTL)
+	}
+}
+
 // GenerateAccessToken gera um token de acesso
 func (m *JWTManager) GenerateAccessToken(user *models.User) (string, error) {
-	return m.generateToken(user, TokenTypeAccess, m.accessExpiry)
+	return m.generateToken(user, TokenTypeAccess, m.accessExpiry, "")
 }
 
 // GenerateRefreshToken gera um token de refresh
 func (m *JWTManager) GenerateRefreshToken(user *models.User) (string, error) {
-	return m.generateToken(user, TokenTypeRefresh, m.refreshExpiry)
+	return m.generateToken(user, TokenTypeRefresh, m.refreshExpiry, "")
 }
 
 // GenerateAPIToken gera um token de API (longa duração)
 func (m *JWTManager) GenerateAPIToken(user *models.User, expiry time.Duration) (string, error) {
-	return m.generateToken(user, TokenTypeAPI, expiry)
+	return m.generateToken(user, TokenTypeAPI, expiry, "")
+}
+
+// GenerateEmailVerificationToken gera um token assinado de verificação de email
+func (m *JWTManager) GenerateEmailVerificationToken(user *models.User, expiry time.Duration) (string, error) {
+	return m.generateToken(user, TokenTypeEmailVerification, expiry, "")
 }
 
-// GenerateTokenPair gera um par de tokens (access + refresh)
+// ValidateEmailVerificationToken valida um token de verificação de email
+func (m *JWTManager) ValidateEmailVerificationToken(tokenString string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeEmailVerification {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// GenerateTokenPair gera um par de tokens (access + refresh) de uma mesma sessão
 func (m *JWTManager) GenerateTokenPair(user *models.User) (accessToken, refreshToken string, err error) {
-	accessToken, err = m.GenerateAccessToken(user)
+	sessionID := uuid.New().String()
+
+	accessToken, err = m.generateToken(user, TokenTypeAccess, m.accessExpiry, sessionID)
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshToken, err = m.GenerateRefreshToken(user)
+	refreshToken, err = m.generateToken(user, TokenTypeRefresh, m.refreshExpiry, sessionID)
 	if err != nil {
 		return "", "", err
 	}
@@ -97,7 +139,7 @@ func (m *JWTManager) GenerateTokenPair(user *models.User) (accessToken, refreshT
 }
 
 // generateToken gera um token JWT
-func (m *JWTManager) generateToken(user *models.User, tokenType TokenType, expiry time.Duration) (string, error) {
+func (m *JWTManager) generateToken(user *models.User, tokenType TokenType, expiry time.Duration, sessionID string) (string, error) {
 	now := time.Now()
 	
 	claims := &Claims{
@@ -117,6 +159,9 @@ func (m *JWTManager) generateToken(user *models.User, tokenType TokenType, expir
 		TokenType: tokenType,
 		Email:     user.Email,
 		Name:      user.Name,
+
+		SessionID:    sessionID,
+		TokenVersion: user.TokenVersion,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -125,6 +170,12 @@ func (m *JWTManager) generateToken(user *models.User, tokenType TokenType, expir
 
 // ValidateToken valida um token JWT e retorna os claims
 func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
+	return m.ValidateTokenContext(context.Background(), tokenString)
+}
+
+// ValidateTokenContext valida um token JWT e, com a revogação habilitada, rejeita
+// tokens revogados no logout ou emitidos antes do último logout-all do usuário
+func (m *JWTManager) ValidateTokenContext(ctx context.Context, tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		// Verificar algoritmo de assinatura
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -145,9 +196,77 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidClaims
 	}
 
+	if err := m.checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
+// checkRevoked verifica o jti, a sessão e a versão de tokens do usuário
+func (m *JWTManager) checkRevoked(ctx context.Context, claims *Claims) error {
+	if m.revocations != nil {
+		for _, id := range []string{claims.ID, claims.SessionID} {
+			if id == "" {
+				continue
+			}
+			revoked, err := m.revocations.IsRevoked(ctx, id)
+			if err != nil {
+				return err
+			}
+			if revoked {
+				return ErrRevokedToken
+			}
+		}
+	}
+
+	if m.versions != nil {
+		version, err := m.versions.Version(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+		if claims.TokenVersion < version {
+			return ErrRevokedToken
+		}
+	}
+
+	return nil
+}
+
+// Revoke revoga o token (jti) e toda a sessão a que ele pertence. A sessão fica
+// revogada até a expiração do refresh token, cobrindo access tokens renovados.
+func (m *JWTManager) Revoke(ctx context.Context, claims *Claims) error {
+	if m.revocations == nil {
+		return nil
+	}
+
+	if claims.ExpiresAt != nil {
+		if err := m.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID != "" {
+		until := time.Now().Add(m.refreshExpiry)
+		if claims.IssuedAt != nil {
+			until = claims.IssuedAt.Time.Add(m.refreshExpiry)
+		}
+		if err := m.revocations.Revoke(ctx, claims.SessionID, until); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidateTokenVersion descarta a versão cacheada do usuário, aplicando um
+// logout-all imediatamente nesta instância
+func (m *JWTManager) InvalidateTokenVersion(userID uuid.UUID) {
+	if m.versions != nil {
+		m.versions.Invalidate(userID)
+	}
+}
+
 // RefreshAccessToken gera um novo access token a partir de um refresh token
 func (m *JWTManager) RefreshAccessToken(refreshToken string) (string, error) {
 	claims, err := m.ValidateToken(refreshToken)
@@ -167,9 +286,11 @@ func (m *JWTManager) RefreshAccessToken(refreshToken string) (string, error) {
 		Name:     claims.Name,
 		Role:     claims.Role,
 		Scopes:   claims.Scopes,
+
+		TokenVersion: claims.TokenVersion,
 	}
 
-	return m.GenerateAccessToken(user)
+	return m.generateToken(user, TokenTypeAccess, m.accessExpiry, claims.SessionID)
 }
 
 // ExtractTokenFromHeader extrai o token do header Authorization
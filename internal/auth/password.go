@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Limite do bcrypt: bytes além de 72 são ignorados pelo algoritmo
+const maxPasswordBytes = 72
+
+var (
+	ErrInvalidPassword = errors.New("invalid password")
+	ErrInvalidCost     = errors.New("invalid bcrypt cost")
+)
+
+// commonPasswords senhas mais usadas em vazamentos públicos, comparadas sem diferenciar maiúsculas
+var commonPasswords = map[string]struct{}{
+	"123456": {}, "12345678": {}, "123456789": {}, "1234567890": {}, "12345678910": {},
+	"password": {}, "password1": {}, "password123": {}, "passw0rd": {}, "p@ssw0rd": {},
+	"qwerty": {}, "qwerty123": {}, "qwertyuiop": {}, "1q2w3e4r": {}, "1q2w3e4r5t": {},
+	"abc123": {}, "abcd1234": {}, "iloveyou": {}, "admin": {}, "admin123": {},
+	"administrator": {}, "welcome": {}, "welcome1": {}, "welcome123": {}, "letmein": {},
+	"monkey": {}, "dragon": {}, "football": {}, "baseball": {}, "sunshine": {},
+	"princess": {}, "trustno1": {}, "changeme": {}, "changeme123": {}, "mudar123": {},
+	"senha": {}, "senha123": {}, "senha@123": {}, "s3nh4": {}, "brasil": {},
+	"brasil123": {}, "minhasenha": {}, "arca": {}, "arca123": {}, "arca@123": {},
+}
+
+// =============================================================================
+// PASSWORD POLICY
+// =============================================================================
+
+// PasswordPolicy regras mínimas de força de senha
+type PasswordPolicy struct {
+	// Tamanho mínimo em caracteres
+	MinLength int
+	// Número mínimo de classes de caracteres (minúsculas, maiúsculas, dígitos, símbolos)
+	MinClasses int
+}
+
+// Validate retorna as violações da política (vazio quando a senha é aceita)
+func (p PasswordPolicy) Validate(password string) []string {
+	var violations []string
+
+	if n := len([]rune(password)); n < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < p.MinClasses {
+		violations = append(violations, fmt.Sprintf("must contain at least %d of: lowercase, uppercase, digits, symbols", p.MinClasses))
+	}
+
+	if _, common := commonPasswords[strings.ToLower(password)]; common {
+		violations = append(violations, "is too common")
+	}
+
+	return violations
+}
+
+// =============================================================================
+// PASSWORD HASHER
+// =============================================================================
+
+// PasswordHasher centraliza o hash e a verificação de senhas
+type PasswordHasher struct {
+	cost int
+}
+
+// NewPasswordHasher cria um novo hasher com o custo do bcrypt (0 usa bcrypt.DefaultCost)
+func NewPasswordHasher(cost int) (*PasswordHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("%w: %d (expected %d-%d)", ErrInvalidCost, cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return &PasswordHasher{cost: cost}, nil
+}
+
+// Hash gera o hash bcrypt da senha
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify compara a senha com o hash, retornando ErrInvalidPassword se não conferirem
+func (h *PasswordHasher) Verify(hash, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
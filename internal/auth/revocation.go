@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Intervalo mínimo entre varreduras de revogações expiradas no store em memória
+const revocationSweepInterval = time.Minute
+
+// RevocationStore guarda identificadores revogados (jti de tokens e ids de sessão)
+// até o instante em que os tokens correspondentes expirariam
+type RevocationStore interface {
+	Revoke(ctx context.Context, id string, until time.Time) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// TokenVersionStore fornece a versão atual dos tokens do usuário. Tokens emitidos
+// com versão menor que a atual são rejeitados (logout de todas as sessões).
+type TokenVersionStore interface {
+	TokenVersion(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// RevocationConfig configuração da revogação de tokens do JWTManager
+type RevocationConfig struct {
+	// Store de jti/sessões revogados (logout)
+	Store RevocationStore
+	// Fonte da versão de tokens por usuário (logout-all)
+	Versions TokenVersionStore
+	// Tempo de cache da versão de tokens (padrão 30s)
+	VersionCacheTTL time.Duration
+}
+
+// =============================================================================
+// MEMORY REVOCATION STORE
+// =============================================================================
+
+// MemoryRevocationStore implementação em memória do RevocationStore (instância única)
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	revoked   map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryRevocationStore cria um novo store de revogação em memória
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Revoke marca o id como revogado até until
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= revocationSweepInterval {
+		s.sweep(now)
+	}
+
+	if until.After(now) {
+		s.revoked[id] = until
+	}
+	return nil
+}
+
+// Prune remove as revogações expiradas e retorna quantas foram removidas. Revoke já
+// varre o store periodicamente; Prune libera a memória mesmo sem novas revogações.
+func (s *MemoryRevocationStore) Prune(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sweep(time.Now()), nil
+}
+
+// sweep remove as revogações expiradas em now; requer s.mu
+func (s *MemoryRevocationStore) sweep(now time.Time) int {
+	removed := 0
+	for key, expiry := range s.revoked {
+		if now.After(expiry) {
+			delete(s.revoked, key)
+			removed++
+		}
+	}
+	s.lastSweep = now
+	return removed
+}
+
+// IsRevoked indica se o id está revogado
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	until, ok := s.revoked[id]
+	return ok && time.Now().Before(until), nil
+}
+
+// =============================================================================
+// TOKEN VERSION CACHE
+// =============================================================================
+
+// cachedVersion versão de tokens com o instante de carregamento
+type cachedVersion struct {
+	version  int
+	loadedAt time.Time
+}
+
+// tokenVersionCache cacheia as versões de tokens para evitar uma consulta por request
+type tokenVersionCache struct {
+	store TokenVersionStore
+	ttl   time.Duration
+
+	mu       sync.RWMutex
+	versions map[uuid.UUID]cachedVersion
+}
+
+// newTokenVersionCache cria um novo cache de versões de tokens
+func newTokenVersionCache(store TokenVersionStore, ttl time.Duration) *tokenVersionCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &tokenVersionCache{
+		store:    store,
+		ttl:      ttl,
+		versions: make(map[uuid.UUID]cachedVersion),
+	}
+}
+
+// Version retorna a versão atual do usuário. Se o store falhar, usa o último valor
+// conhecido (mesmo expirado); sem valor conhecido, retorna o erro.
+func (c *tokenVersionCache) Version(ctx context.Context, userID uuid.UUID) (int, error) {
+	c.mu.RLock()
+	cached, ok := c.versions[userID]
+	c.mu.RUnlock()
+
+	if ok && time.Since(cached.loadedAt) < c.ttl {
+		return cached.version, nil
+	}
+
+	version, err := c.store.TokenVersion(ctx, userID)
+	if err != nil {
+		if ok {
+			return cached.version, nil
+		}
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.versions[userID] = cachedVersion{version: version, loadedAt: time.Now()}
+	c.mu.Unlock()
+
+	return version, nil
+}
+
+// Invalidate remove a versão cacheada do usuário (após logout-all)
+func (c *tokenVersionCache) Invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.versions, userID)
+	c.mu.Unlock()
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Prefixo de todas as chaves gravadas pelo gateway
+const keyPrefix = "arca:"
+
+// Config configuração da conexão com o Redis
+type Config struct {
+	Host     string
+	Port     string
+	Password string
+	DB       int
+	PoolSize int
+	// Timeout do ping inicial (padrão 5s)
+	ConnectTimeout time.Duration
+}
+
+// NewClient cria o client do Redis e verifica a conexão com um ping
+func NewClient(cfg Config) (*redis.Client, error) {
+	if cfg.ConnectTimeout == 0 {
+		cfg.ConnectTimeout = 5 * time.Second
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("ping redis at %s: %w", client.Options().Addr, err)
+	}
+	return client, nil
+}
+
+// HealthCheck retorna a verificação de disponibilidade do Redis para o /health
+func HealthCheck(client *redis.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
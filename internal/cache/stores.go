@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// =============================================================================
+// RATE LIMIT STORE
+// =============================================================================
+
+// Janela deslizante em um sorted set (score = timestamp em ms). Retorna
+// {permitido, restantes, ms até liberar a próxima request}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count >= limit then
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local reset = window
+	if oldest[2] then
+		reset = tonumber(oldest[2]) + window - now
+	end
+	return {0, 0, reset}
+end
+
+redis.call('ZADD', key, now, member)
+redis.call('PEXPIRE', key, window)
+return {1, limit - count - 1, 0}
+`)
+
+// RateLimitStore janelas de rate limiting compartilhadas entre instâncias
+// (implementa middleware.RateLimitStore)
+type RateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRateLimitStore cria um novo store de rate limiting no Redis
+func NewRateLimitStore(client *redis.Client) *RateLimitStore {
+	return &RateLimitStore{client: client}
+}
+
+// Allow registra a request na janela da chave se houver capacidade
+func (s *RateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	now := time.Now()
+	result, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{keyPrefix + "ratelimit:" + key},
+		now.UnixMilli(), window.Milliseconds(), limit, uuid.NewString(),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, 0, err
+	}
+	return result[0] == 1, int(result[1]), time.Duration(result[2]) * time.Millisecond, nil
+}
+
+// Conta as requests na janela sem registrar. Retorna {requests, ms até a mais
+// antiga sair da janela}.
+var inspectWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local reset = 0
+if count > 0 then
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	reset = tonumber(oldest[2]) + window - now
+end
+return {count, reset}
+`)
+
+// Inspect retorna quantas requests estão na janela da chave e o tempo até a mais
+// antiga sair dela
+func (s *RateLimitStore) Inspect(ctx context.Context, key string, window time.Duration) (int, time.Duration, error) {
+	result, err := inspectWindowScript.Run(ctx, s.client,
+		[]string{keyPrefix + "ratelimit:" + key},
+		time.Now().UnixMilli(), window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(result[0]), time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// Reset apaga a janela da chave
+func (s *RateLimitStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, keyPrefix+"ratelimit:"+key).Err()
+}
+
+// =============================================================================
+// REVOCATION STORE
+// =============================================================================
+
+// RevocationStore jti/sessões revogados compartilhados entre instâncias
+// (implementa auth.RevocationStore). Cada id expira junto com o token.
+type RevocationStore struct {
+	client *redis.Client
+}
+
+// NewRevocationStore cria um novo store de revogação no Redis
+func NewRevocationStore(client *redis.Client) *RevocationStore {
+	return &RevocationStore{client: client}
+}
+
+// Revoke marca o id como revogado até until
+func (s *RevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, keyPrefix+"revoked:"+id, 1, ttl).Err()
+}
+
+// IsRevoked indica se o id está revogado
+func (s *RevocationStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Exists(ctx, keyPrefix+"revoked:"+id).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// =============================================================================
+// JOB SLOT STORE
+// =============================================================================
+
+// Por quanto tempo um contador de jobs sobrevive sem Acquire/Release. Evita que
+// slots de uma instância que morreu no meio de um job fiquem ocupados para sempre.
+const jobSlotTTL = time.Hour
+
+// Ocupa um slot se o contador estiver abaixo do limite
+var acquireJobSlotScript = redis.NewScript(`
+local running = tonumber(redis.call('GET', KEYS[1]) or '0')
+if running >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// Libera um slot, removendo o contador quando chega a zero
+var releaseJobSlotScript = redis.NewScript(`
+local running = redis.call('DECR', KEYS[1])
+if running <= 0 then
+	redis.call('DEL', KEYS[1])
+end
+return running
+`)
+
+// JobSlotStore contador de jobs em execução compartilhado entre instâncias
+// (implementa middleware.JobSlotStore)
+type JobSlotStore struct {
+	client *redis.Client
+}
+
+// NewJobSlotStore cria um novo store de slots de job no Redis
+func NewJobSlotStore(client *redis.Client) *JobSlotStore {
+	return &JobSlotStore{client: client}
+}
+
+// Acquire ocupa um slot se o tenant tiver menos de limit jobs em execução
+func (s *JobSlotStore) Acquire(ctx context.Context, tenantID uuid.UUID, limit int) (bool, error) {
+	ok, err := acquireJobSlotScript.Run(ctx, s.client,
+		[]string{keyPrefix + "jobs:" + tenantID.String()}, limit, jobSlotTTL.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return ok == 1, nil
+}
+
+// Release libera um slot do tenant
+func (s *JobSlotStore) Release(ctx context.Context, tenantID uuid.UUID) error {
+	return releaseJobSlotScript.Run(ctx, s.client, []string{keyPrefix + "jobs:" + tenantID.String()}).Err()
+}
+
+// =============================================================================
+// ENTITY CACHE
+// =============================================================================
+
+// EntityCache cache de usuários/tenants compartilhado entre instâncias (implementa
+// services.EntityCache): uma invalidação vale para todas as instâncias. Cada
+// tenant tem um set com as chaves das suas entradas, para DeleteTenant.
+type EntityCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewEntityCache cria um novo cache de entidades no Redis
+func NewEntityCache(client *redis.Client, ttl time.Duration) *EntityCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &EntityCache{client: client, ttl: ttl}
+}
+
+// Get retorna o valor da chave
+func (c *EntityCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, keyPrefix+"entity:"+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set grava o valor da chave com o TTL e a registra no set do tenant, que expira
+// junto com a entrada mais recente
+func (c *EntityCache) Set(ctx context.Context, tenantID uuid.UUID, key string, value []byte) error {
+	tenantKey := keyPrefix + "entity-tenant:" + tenantID.String()
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, keyPrefix+"entity:"+key, value, c.ttl)
+		pipe.SAdd(ctx, tenantKey, key)
+		pipe.PExpire(ctx, tenantKey, c.ttl)
+		return nil
+	})
+	return err
+}
+
+// Delete remove a chave
+func (c *EntityCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, keyPrefix+"entity:"+key).Err()
+}
+
+// DeleteTenant remove todas as entradas do tenant
+func (c *EntityCache) DeleteTenant(ctx context.Context, tenantID uuid.UUID) error {
+	tenantKey := keyPrefix + "entity-tenant:" + tenantID.String()
+	keys, err := c.client.SMembers(ctx, tenantKey).Result()
+	if err != nil {
+		return err
+	}
+	redisKeys := make([]string, 0, len(keys)+1)
+	for _, key := range keys {
+		redisKeys = append(redisKeys, keyPrefix+"entity:"+key)
+	}
+	redisKeys = append(redisKeys, tenantKey)
+	return c.client.Del(ctx, redisKeys...).Err()
+}
+
+// =============================================================================
+// ALERT BROKER
+// =============================================================================
+
+// AlertBroker broker dos streams de alertas compartilhado entre instâncias
+// (implementa notify.AlertBroker). Publish publica o alerta em um canal pub/sub do
+// Redis; cada instância assina o canal e repassa os alertas aos seus streams por um
+// broker em memória, então o limite de streams por tenant vale por instância.
+type AlertBroker struct {
+	client *redis.Client
+	pubsub *redis.PubSub
+	local  *notify.MemoryAlertBroker
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewAlertBroker cria um novo broker de alertas no Redis com no máximo maxPerTenant
+// streams simultâneos por tenant em cada instância (0 = sem limite)
+func NewAlertBroker(client *redis.Client, maxPerTenant int) *AlertBroker {
+	b := &AlertBroker{
+		client: client,
+		pubsub: client.Subscribe(context.Background(), keyPrefix+"alerts"),
+		local:  notify.NewMemoryAlertBroker(maxPerTenant),
+		done:   make(chan struct{}),
+	}
+
+	// Aguarda a confirmação da assinatura para não perder os primeiros alertas; se
+	// falhar, o client do Redis reconecta e assina de novo em segundo plano
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := b.pubsub.Receive(ctx); err != nil {
+		logger.Warn("alert broker subscription not confirmed: %v", err)
+	}
+
+	go b.forward()
+	return b
+}
+
+// Publish publica o alerta para os streams do tenant em todas as instâncias
+func (b *AlertBroker) Publish(ctx context.Context, alert *models.Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, keyPrefix+"alerts", data).Err()
+}
+
+// Subscribe abre uma assinatura dos alertas do tenant nesta instância
+func (b *AlertBroker) Subscribe(tenantID uuid.UUID) (*notify.Subscription, error) {
+	return b.local.Subscribe(tenantID)
+}
+
+// Close cancela a assinatura do canal e encerra os streams (shutdown)
+func (b *AlertBroker) Close() {
+	b.once.Do(func() {
+		if err := b.pubsub.Close(); err != nil {
+			logger.Warn("failed to close alert broker subscription: %v", err)
+		}
+		<-b.done
+		b.local.Close()
+	})
+}
+
+// forward repassa os alertas recebidos do canal aos streams locais até o Close
+func (b *AlertBroker) forward() {
+	defer close(b.done)
+
+	for msg := range b.pubsub.Channel() {
+		var alert models.Alert
+		if err := json.Unmarshal([]byte(msg.Payload), &alert); err != nil {
+			logger.Warn("invalid alert on broker channel: %v", err)
+			continue
+		}
+		if err := b.local.Publish(context.Background(), &alert); err != nil {
+			return
+		}
+	}
+}
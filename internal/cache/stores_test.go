@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestClient client de um Redis em memória (miniredis), fechado no fim do teste
+func newTestClient(t *testing.T) *redis.Client {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// Alertas publicados em uma instância chegam aos streams abertos em outra
+func TestAlertBrokerDeliversAcrossInstances(t *testing.T) {
+	client := newTestClient(t)
+	publisher := NewAlertBroker(client, 0)
+	defer publisher.Close()
+	subscriber := NewAlertBroker(client, 0)
+	defer subscriber.Close()
+
+	tenantID := uuid.New()
+	sub, err := subscriber.Subscribe(tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	other, err := subscriber.Subscribe(uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	alert := &models.Alert{ID: uuid.New(), TenantID: tenantID, Severity: "high", Title: "Phishing"}
+	if err := publisher.Publish(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-sub.C:
+		if got.ID != alert.ID || got.Title != alert.Title {
+			t.Errorf("alert = %+v, want %+v", got, alert)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert was not delivered")
+	}
+	select {
+	case got := <-other.C:
+		t.Errorf("other tenant received %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestAlertBrokerCloseEndsStreams(t *testing.T) {
+	broker := NewAlertBroker(newTestClient(t), 1)
+	tenantID := uuid.New()
+	sub, err := broker.Subscribe(tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := broker.Subscribe(tenantID); err == nil {
+		t.Error("second stream accepted above the per-tenant limit")
+	}
+
+	broker.Close()
+	broker.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("stream still open after Close")
+	}
+}
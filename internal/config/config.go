@@ -1,8 +1,16 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,23 +18,50 @@ import (
 type Config struct {
 	Server   ServerConfig
 	JWT      JWTConfig
+	Auth     AuthConfig
+	Captcha  CaptchaConfig
+	SMTP     SMTPConfig
 	Database DatabaseConfig
 	Redis    RedisConfig
 	MCP      MCPConfig
+	Targets  TargetsConfig
 	RateLimit RateLimitConfig
 	CORS     CORSConfig
+	IPFilter IPFilterConfig
+	Notify   NotifyConfig
+	Internal InternalConfig
+	Log      LogConfig
+	Audit    AuditConfig
+	Metrics  MetricsConfig
+	Scheduler SchedulerConfig
 }
 
 // ServerConfig holds server-specific configuration
 type ServerConfig struct {
-	Host            string
-	Port            string
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
-	IdleTimeout     time.Duration
-	ShutdownTimeout time.Duration
-	Prefork         bool
-	Environment     string
+	Host               string
+	Port               string
+	ReadTimeout        time.Duration
+	WriteTimeout       time.Duration
+	IdleTimeout        time.Duration
+	ShutdownTimeout    time.Duration
+	Prefork            bool
+	Environment        string
+	// HealthCheckTimeout bounds the dependency checks run by /health
+	HealthCheckTimeout time.Duration
+	// MaxBodyBytes is the global request body limit; routes may set tighter limits
+	MaxBodyBytes int
+	// MaxPerPage caps the per_page query parameter of list endpoints
+	MaxPerPage int
+	// DefaultLocale is the language of error messages when Accept-Language has no
+	// supported locale (en, pt-BR or es)
+	DefaultLocale string
+	// ProxyHeader carries the client IP chain set by proxies (e.g. X-Forwarded-For);
+	// it is only honored for connections coming from TrustedProxies
+	ProxyHeader    string
+	TrustedProxies []string
+	// TrustedHeaderSources lists the IPs/CIDRs (e.g. internal services) allowed to
+	// pick the tenant with X-Tenant-ID; for everyone else the header is stripped
+	TrustedHeaderSources []string
 }
 
 // JWTConfig holds JWT-specific configuration
@@ -38,6 +73,68 @@ type JWTConfig struct {
 	Audience         string
 }
 
+// AuthConfig holds user authentication configuration
+type AuthConfig struct {
+	// TenantDomain is the base domain whose subdomains identify the tenant on login
+	// (e.g. "app.arca.io" makes "acme.app.arca.io" resolve to the tenant slug "acme")
+	TenantDomain string
+	// BcryptCost is the bcrypt work factor used to hash passwords
+	BcryptCost int
+	// PasswordMinLength is the minimum password length in characters
+	PasswordMinLength int
+	// PasswordMinClasses is the minimum number of character classes (lower, upper, digit, symbol)
+	PasswordMinClasses int
+	// RequireEmailVerification creates registered users as pending until they verify their email
+	RequireEmailVerification bool
+	// VerificationGracePeriod lets pending users log in for this long after registering (0 = never)
+	VerificationGracePeriod time.Duration
+	// VerificationTokenExpiry is the lifetime of the emailed verification token
+	VerificationTokenExpiry time.Duration
+	// VerificationURL is the link sent by email; the token is appended as ?token=
+	VerificationURL string
+	// TokenVersionCacheTTL bounds how long a logout-all takes to reach other gateway instances
+	TokenVersionCacheTTL time.Duration
+	// PlatformTenantID is the tenant whose admins operate the platform; they are not
+	// blocked by tenant suspension (empty disables the bypass)
+	PlatformTenantID string
+	// TenantStatusCacheTTL bounds how long a tenant suspension takes to reach other gateway instances
+	TenantStatusCacheTTL time.Duration
+	// EntityCacheTTL is how long users and tenants loaded by ID are cached (0 disables
+	// the cache); without Redis it also bounds how long changes take to reach other instances
+	EntityCacheTTL time.Duration
+	// EntityCacheSize bounds the in-memory entity cache; least recently used entries
+	// are evicted (unused with Redis)
+	EntityCacheSize int
+	// DeletionMode is soft (deactivate, keep data) or hard (erase) for account and tenant deletion
+	DeletionMode string
+	// DeletionRetention is how long soft-deleted users and tenants are kept before
+	// being erased (0 keeps them forever)
+	DeletionRetention time.Duration
+}
+
+// SMTPConfig holds outgoing email configuration; with no host emails are only logged
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// CaptchaConfig holds CAPTCHA verification for public endpoints (register/onboarding)
+type CaptchaConfig struct {
+	// Enabled turns verification on; when disabled the middleware is a no-op (local dev)
+	Enabled bool
+	// Provider is turnstile or hcaptcha
+	Provider string
+	// Secret is the provider secret key used on siteverify
+	Secret string
+	// VerifyURL overrides the provider's siteverify endpoint
+	VerifyURL string
+	// Timeout bounds the siteverify call
+	Timeout time.Duration
+}
+
 // DatabaseConfig holds database-specific configuration
 type DatabaseConfig struct {
 	Host     string
@@ -48,10 +145,20 @@ type DatabaseConfig struct {
 	SSLMode  string
 	MaxConns int
 	MinConns int
+	// ConnMaxLifetime recycles connections older than this (0 = never)
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections idle for longer than this (0 = never)
+	ConnMaxIdleTime time.Duration
+	// QueryTimeout bounds each query (context deadline and Postgres statement_timeout; 0 = no limit)
+	QueryTimeout time.Duration
+	// PrepareStatements prepares the hot-path queries once and reuses them; queries
+	// the database refuses to prepare fall back to unprepared execution
+	PrepareStatements bool
 }
 
 // RedisConfig holds Redis-specific configuration
 type RedisConfig struct {
+	// Host empty disables Redis: shared state falls back to in-memory stores
 	Host     string
 	Port     string
 	Password string
@@ -61,10 +168,57 @@ type RedisConfig struct {
 
 // MCPConfig holds MCP/AGNO Control Plane configuration
 type MCPConfig struct {
+	// BaseURL is the default MCP endpoint; empty runs against the in-memory fake
 	BaseURL        string
+	// Fake replaces the MCP with canned in-memory responses (local development
+	// and CI); rejected in production
+	Fake           bool
 	Timeout        time.Duration
 	MaxRetries     int
 	RetryDelay     time.Duration
+	// Actions overrides the tool/action used per gateway operation (operation -> "tool:action")
+	Actions map[string]string
+	// ToolURLs routes MCP tools to dedicated base URLs (tool -> URL); tools not
+	// listed use BaseURL
+	ToolURLs map[string]string
+	// ToolScopes overrides the scope required per MCP tool ("tool" or "tool:action" -> scope)
+	ToolScopes map[string]string
+	// Connection pool of the HTTP transport. Timeout bounds each request; DialTimeout
+	// bounds only establishing the TCP connection.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	DialTimeout         time.Duration
+	// HTTP2 lets the transport negotiate HTTP/2 over TLS (https base URLs)
+	HTTP2 bool
+	// ServiceToken is sent to MCP as "Authorization: Bearer <token>"
+	ServiceToken string
+	// ClientCert and ClientKey are PEM files presented to MCP for mTLS; CAFile
+	// optionally replaces the system roots when verifying the MCP certificate
+	ClientCert string
+	ClientKey  string
+	CAFile     string
+	// RequireAuth fails startup unless a service token or client certificate is set
+	RequireAuth bool
+	// PriorityPlans lists the tenant plans allowed to set the priority of async jobs
+	PriorityPlans []string
+	// MonitorSyncInterval is how often brand monitoring state is reconciled with
+	// the MCP jobs in the background (0 disables the background sync)
+	MonitorSyncInterval time.Duration
+}
+
+// TargetsConfig holds validation of the targets submitted for hunt, scan and analysis
+type TargetsConfig struct {
+	// StripFragment drops the #fragment when normalizing target URLs
+	StripFragment bool
+	// BlockPrivate rejects scan/analyze URLs whose host is a private, loopback or link-local IP
+	BlockPrivate bool
+	// SSRFGuard resolves hunt/scan/analyze target hosts and rejects internal addresses
+	SSRFGuard bool
+	// SSRFAllow lists internal IPs or CIDR ranges that targets may still point to
+	SSRFAllow []string
+	// ResolveTimeout bounds the DNS lookup of each target
+	ResolveTimeout time.Duration
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -72,10 +226,108 @@ type RateLimitConfig struct {
 	RequestsPerMinute int
 	BurstSize         int
 	CleanupInterval   time.Duration
+	// TenantCacheTTL is how long per-tenant custom limits loaded from the DB are cached
+	TenantCacheTTL time.Duration
+	// AnonymousRPM is the per-IP limit for unauthenticated requests on public routes
+	AnonymousRPM int
+	// MaxConcurrentJobs is the default per-tenant cap on simultaneous hunts/scans
+	// when the tenant settings don't define max_concurrent_jobs
+	MaxConcurrentJobs int
+}
+
+// NotifyConfig holds alert webhook delivery configuration
+type NotifyConfig struct {
+	Workers    int
+	QueueSize  int
+	MaxRetries int
+	RetryDelay time.Duration
+	Timeout    time.Duration
+	// StreamMaxPerTenant caps concurrent alert streams (SSE) per tenant
+	StreamMaxPerTenant int
+}
+
+// InternalConfig holds configuration for service-to-service (Core/MCP) endpoints
+type InternalConfig struct {
+	ServiceToken string
+}
+
+// LogConfig holds logging configuration
+type LogConfig struct {
+	// Level is the minimum log level (debug, info, warn, error)
+	Level string
+	// SampleRates maps a route template to a 1-in-N sampling rate for successful requests
+	SampleRates map[string]int
+	// DebugSampleRate writes 1 in N debug entries per tenant (0 or 1 disables sampling)
+	DebugSampleRate int
+	// CaptureStack attaches a short stack trace to error-level entries. Defaults to on
+	// only in development, where the extra output and the capture cost do not matter.
+	CaptureStack bool
+	// Payloads logs request/response headers and bodies while Level is debug
+	Payloads bool
+	// RedactFields are masked in payload logs in addition to the built-in list
+	// (any key containing password, secret or token, API keys, Authorization and
+	// cookies); "*" matches a prefix/suffix/substring, e.g. "*_pin"
+	RedactFields []string
+}
+
+// IPFilterConfig holds the IP and country access rules applied to every API route
+type IPFilterConfig struct {
+	// Allow and Deny are IPs or CIDR ranges; an empty Allow list admits every IP
+	Allow []string
+	Deny  []string
+	// AllowCountries and DenyCountries are ISO 3166-1 alpha-2 codes resolved with
+	// the GeoIP database; an empty AllowCountries admits every country
+	AllowCountries []string
+	DenyCountries  []string
+	// GeoIPDBPath is a MaxMind GeoIP2/GeoLite2 Country or City database
+	GeoIPDBPath string
+}
+
+// AuditConfig holds audit logging configuration
+type AuditConfig struct {
+	// ReadSampleRate audits 1 in N read (GET) requests; 0 disables read auditing
+	ReadSampleRate int
+	// BufferSize is the capacity of the async audit writer queue
+	BufferSize int
+	// OverflowPolicy is applied when the queue is full: block, drop-oldest or drop-newest
+	OverflowPolicy string
+}
+
+// SchedulerConfig holds the intervals of the periodic background jobs
+type SchedulerConfig struct {
+	// RevocationPruneInterval sweeps expired entries from the in-memory token
+	// revocation list (with Redis they expire on their own)
+	RevocationPruneInterval time.Duration
+	// PurgeInterval is how often soft-deleted users and tenants older than
+	// AUTH_DELETION_RETENTION are erased
+	PurgeInterval time.Duration
+	// LeaderTTL is how long the Redis leader lock stays valid without renewal;
+	// singleton jobs move to another instance at most this long after the
+	// leader dies. The lock is renewed every LeaderTTL/3.
+	LeaderTTL time.Duration
+}
+
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	// TenantLabels controls the tenant_id label of business metrics: off (aggregate
+	// only), all (raw tenant UUID), hash (fixed number of buckets) or allowlist
+	TenantLabels string
+	// TenantBuckets is the number of buckets used by the hash mode
+	TenantBuckets int
+	// TenantAllowlist lists the tenant ids labeled individually in allowlist mode;
+	// every other tenant is reported as "other"
+	TenantAllowlist []string
+	// SlowRoutes lists route prefixes (templates) backed by MCP whose durations are
+	// also recorded in a histogram with buckets up to minutes
+	SlowRoutes []string
+	// SlowBuckets are the bucket upper bounds, in seconds, of that histogram
+	SlowBuckets []string
 }
 
 // CORSConfig holds CORS configuration
 type CORSConfig struct {
+	// Allowed origins: exact ("https://app.example.com"), subdomain wildcard
+	// ("https://*.preview.example.com") or regular expression ("regex:^https://pr-[0-9]+\.example\.com$")
 	AllowOrigins     []string
 	AllowMethods     []string
 	AllowHeaders     []string
@@ -83,74 +335,455 @@ type CORSConfig struct {
 	MaxAge           int
 }
 
+// defaultJWTSecret is the development fallback for JWT_SECRET; production must override it
+const defaultJWTSecret = "your-super-secret-key-change-in-production"
+
+// Normalize canonicalizes enumerated settings (trimmed, lower-case) so Validate and
+// the rest of the server compare them exactly: ENVIRONMENT=Production must still
+// get the production checks. Call it before Validate.
+func (c *Config) Normalize() {
+	for _, value := range []*string{
+		&c.Server.Environment,
+		&c.Log.Level,
+		&c.Auth.DeletionMode,
+		&c.Captcha.Provider,
+		&c.Audit.OverflowPolicy,
+		&c.Metrics.TenantLabels,
+	} {
+		*value = strings.ToLower(strings.TrimSpace(*value))
+	}
+}
+
+// minJWTSecretLength is the minimum JWT_SECRET size accepted in production (HS256 key size)
+const minJWTSecretLength = 32
+
+// Validate checks the configuration at startup and reports every problem found.
+// Production rejects insecure defaults; other environments only reject settings
+// that cannot work at all.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Environment == "production" {
+		if c.JWT.Secret == defaultJWTSecret {
+			errs = append(errs, errors.New("JWT_SECRET must be set in production"))
+		} else if len(c.JWT.Secret) < minJWTSecretLength {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d bytes in production", minJWTSecretLength))
+		}
+		if c.Database.Password == "" {
+			errs = append(errs, errors.New("DB_PASSWORD must be set in production"))
+		}
+		if c.MCP.UseFake() {
+			errs = append(errs, errors.New("MCP_BASE_URL must be set and MCP_FAKE disabled in production"))
+		}
+		// Without SMTP the verification links are only logged and new users could never activate
+		if c.Auth.RequireEmailVerification && c.SMTP.Host == "" {
+			errs = append(errs, errors.New("SMTP_HOST must be set in production when AUTH_REQUIRE_EMAIL_VERIFICATION is enabled"))
+		}
+	}
+
+	// Prefork runs one process per CPU; in-memory stores would give each fork its own
+	// rate limit windows, job slots and revocation list
+	if c.Server.Prefork && c.Redis.Host == "" {
+		errs = append(errs, errors.New("SERVER_PREFORK requires REDIS_HOST: in-memory rate limiting, job slots and token revocation are per-process"))
+	}
+
+	if c.Server.MaxPerPage < 1 {
+		errs = append(errs, fmt.Errorf("SERVER_MAX_PER_PAGE must be at least 1, got %d", c.Server.MaxPerPage))
+	}
+
+	switch strings.ToLower(c.Server.DefaultLocale) {
+	case "en", "pt-br", "es":
+	default:
+		errs = append(errs, fmt.Errorf("SERVER_DEFAULT_LOCALE must be en, pt-BR or es, got %q", c.Server.DefaultLocale))
+	}
+
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		errs = append(errs, errors.New("SERVER_PROXY_HEADER requires SERVER_TRUSTED_PROXIES"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("invalid SERVER_TRUSTED_PROXIES entry %q: must be an IP or CIDR", proxy))
+			}
+		}
+	}
+	for _, source := range c.Server.TrustedHeaderSources {
+		if _, err := netip.ParsePrefix(source); err != nil {
+			if _, err := netip.ParseAddr(source); err != nil {
+				errs = append(errs, fmt.Errorf("invalid SERVER_TRUSTED_HEADER_SOURCES entry %q: must be an IP or CIDR", source))
+			}
+		}
+	}
+
+	if c.Auth.DeletionMode != "soft" && c.Auth.DeletionMode != "hard" {
+		errs = append(errs, fmt.Errorf("AUTH_DELETION_MODE must be soft or hard, got %q", c.Auth.DeletionMode))
+	}
+	if c.Auth.DeletionRetention < 0 {
+		errs = append(errs, errors.New("AUTH_DELETION_RETENTION must not be negative"))
+	}
+	if c.Auth.EntityCacheTTL < 0 {
+		errs = append(errs, errors.New("AUTH_ENTITY_CACHE_TTL must not be negative"))
+	}
+	if c.Auth.EntityCacheSize <= 0 {
+		errs = append(errs, fmt.Errorf("AUTH_ENTITY_CACHE_SIZE must be positive, got %d", c.Auth.EntityCacheSize))
+	}
+
+	for _, setting := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"SCHEDULER_REVOCATION_PRUNE_INTERVAL", c.Scheduler.RevocationPruneInterval},
+		{"SCHEDULER_PURGE_INTERVAL", c.Scheduler.PurgeInterval},
+		{"SCHEDULER_LEADER_TTL", c.Scheduler.LeaderTTL},
+	} {
+		if setting.interval <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", setting.name, setting.interval))
+		}
+	}
+
+	if c.Database.QueryTimeout < 0 {
+		errs = append(errs, errors.New("DB_QUERY_TIMEOUT must not be negative"))
+	}
+	if c.Database.MaxConns > 0 && c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS (%d) cannot exceed DB_MAX_CONNS (%d)", c.Database.MinConns, c.Database.MaxConns))
+	}
+
+	if err := c.CORS.Validate(c.Server.Environment); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.IPFilter.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.MCP.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, entry := range c.Targets.SSRFAllow {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			errs = append(errs, fmt.Errorf("invalid TARGET_SSRF_ALLOW entry %q: must be an IP or CIDR", entry))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RestartRequired returns the settings that differ between c and next but cannot
+// be applied to a running server. Only LOG_LEVEL, RATE_LIMIT_RPM,
+// RATE_LIMIT_ANONYMOUS_RPM, RATE_LIMIT_MAX_CONCURRENT_JOBS and the IP filter
+// lists are reloadable; everything else (port, prefork, database, CORS,
+// GEOIP_DB_PATH, ...) needs a restart.
+func (c *Config) RestartRequired(next *Config) []string {
+	// Ignore the reloadable fields by copying the current values over them
+	fixed := *next
+	fixed.Log.Level = c.Log.Level
+	fixed.RateLimit.RequestsPerMinute = c.RateLimit.RequestsPerMinute
+	fixed.RateLimit.AnonymousRPM = c.RateLimit.AnonymousRPM
+	fixed.RateLimit.MaxConcurrentJobs = c.RateLimit.MaxConcurrentJobs
+	fixed.IPFilter.Allow, fixed.IPFilter.Deny = c.IPFilter.Allow, c.IPFilter.Deny
+	fixed.IPFilter.AllowCountries, fixed.IPFilter.DenyCountries = c.IPFilter.AllowCountries, c.IPFilter.DenyCountries
+
+	var changed []string
+	current := reflect.ValueOf(*c)
+	updated := reflect.ValueOf(fixed)
+	for i := 0; i < current.NumField(); i++ {
+		section := current.Type().Field(i)
+		currentSection, updatedSection := current.Field(i), updated.Field(i)
+		for j := 0; j < currentSection.NumField(); j++ {
+			if !reflect.DeepEqual(currentSection.Field(j).Interface(), updatedSection.Field(j).Interface()) {
+				changed = append(changed, section.Name+"."+section.Type.Field(j).Name)
+			}
+		}
+	}
+	return changed
+}
+
+// Validate checks that the IP rules are IPs or CIDR ranges and that country rules
+// are two-letter codes backed by a GeoIP database
+func (c IPFilterConfig) Validate() error {
+	var errs []error
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{{"IP_ALLOW_LIST", c.Allow}, {"IP_DENY_LIST", c.Deny}} {
+		for _, entry := range list.entries {
+			if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+				errs = append(errs, fmt.Errorf("invalid %s entry %q: must be an IP or CIDR", list.name, entry))
+			}
+		}
+	}
+
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{{"IP_ALLOW_COUNTRIES", c.AllowCountries}, {"IP_DENY_COUNTRIES", c.DenyCountries}} {
+		if len(list.entries) > 0 && c.GeoIPDBPath == "" {
+			errs = append(errs, fmt.Errorf("%s requires GEOIP_DB_PATH", list.name))
+		}
+		for _, code := range list.entries {
+			if len(code) != 2 {
+				errs = append(errs, fmt.Errorf("invalid %s entry %q: must be an ISO 3166-1 alpha-2 code", list.name, code))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// corsOriginRegexPrefix marks an AllowOrigins entry as a regular expression
+const corsOriginRegexPrefix = "regex:"
+
+// Validate checks that the MCP client certificate is complete and that MCP
+// authentication is configured when required
+func (c MCPConfig) Validate() error {
+	var errs []error
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		errs = append(errs, errors.New("MCP_CLIENT_CERT and MCP_CLIENT_KEY must be set together"))
+	}
+	if c.RequireAuth && c.ServiceToken == "" && c.ClientCert == "" {
+		errs = append(errs, errors.New("MCP_REQUIRE_AUTH is set but neither MCP_SERVICE_TOKEN nor MCP_CLIENT_CERT/MCP_CLIENT_KEY is configured"))
+	}
+	if c.MonitorSyncInterval < 0 {
+		errs = append(errs, errors.New("MCP_MONITOR_SYNC_INTERVAL must not be negative"))
+	}
+
+	tools := make([]string, 0, len(c.ToolURLs))
+	for tool := range c.ToolURLs {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		if err := validateBaseURL(c.ToolURLs[tool]); err != nil {
+			errs = append(errs, fmt.Errorf("MCP_TOOL_URLS: tool %q: %w", tool, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UseFake reports whether the gateway should run against the in-memory MCP
+func (c MCPConfig) UseFake() bool {
+	return c.Fake || c.BaseURL == ""
+}
+
+// validateBaseURL checks that an MCP base URL is an absolute http(s) URL
+func validateBaseURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q must be an absolute http or https URL", raw)
+	}
+	return nil
+}
+
+// Validate checks the CORS settings at startup. Regex origins must compile, and
+// in production a wildcard origin cannot be combined with credentials (browsers
+// reject that combination anyway).
+func (c CORSConfig) Validate(environment string) error {
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" {
+			if c.AllowCredentials && environment == "production" {
+				return fmt.Errorf("CORS_ALLOW_ORIGINS cannot be \"*\" when CORS_ALLOW_CREDENTIALS is true")
+			}
+			continue
+		}
+		if pattern, ok := strings.CutPrefix(origin, corsOriginRegexPrefix); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid CORS origin pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
+	cfg, _ := LoadWithSources(Sources{})
+	return cfg
+}
+
+// load builds the configuration from the active sources (see lookup)
+func load() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Host:            getEnv("SERVER_HOST", "0.0.0.0"),
-			Port:            getEnv("SERVER_PORT", "8080"),
-			ReadTimeout:     getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
-			WriteTimeout:    getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
-			IdleTimeout:     getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
-			ShutdownTimeout: getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
-			Prefork:         getBoolEnv("SERVER_PREFORK", false),
-			Environment:     getEnv("ENVIRONMENT", "development"),
+			Host:               getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:               getEnv("SERVER_PORT", "8080"),
+			ReadTimeout:        getDurationEnv("SERVER_READ_TIMEOUT", 10*time.Second),
+			WriteTimeout:       getDurationEnv("SERVER_WRITE_TIMEOUT", 10*time.Second),
+			IdleTimeout:        getDurationEnv("SERVER_IDLE_TIMEOUT", 120*time.Second),
+			ShutdownTimeout:    getDurationEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			Prefork:            getBoolEnv("SERVER_PREFORK", false),
+			Environment:        getEnv("ENVIRONMENT", "development"),
+			HealthCheckTimeout: getDurationEnv("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+			MaxBodyBytes:       getIntEnv("SERVER_MAX_BODY_BYTES", 2*1024*1024),
+			MaxPerPage:         getIntEnv("SERVER_MAX_PER_PAGE", 100),
+			DefaultLocale:      getEnv("SERVER_DEFAULT_LOCALE", "en"),
+			ProxyHeader:        getEnv("SERVER_PROXY_HEADER", ""),
+			TrustedProxies:     getListEnv("SERVER_TRUSTED_PROXIES", nil),
+			TrustedHeaderSources: getListEnv("SERVER_TRUSTED_HEADER_SOURCES", nil),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
+			Secret:        getEnv("JWT_SECRET", defaultJWTSecret),
 			AccessExpiry:  getDurationEnv("JWT_ACCESS_EXPIRY", 15*time.Minute),
 			RefreshExpiry: getDurationEnv("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
 			Issuer:        getEnv("JWT_ISSUER", "arca-gateway"),
 			Audience:      getEnv("JWT_AUDIENCE", "arca-platform"),
 		},
+		Auth: AuthConfig{
+			TenantDomain:       getEnv("AUTH_TENANT_DOMAIN", ""),
+			BcryptCost:         getIntEnv("BCRYPT_COST", 10),
+			PasswordMinLength:  getIntEnv("PASSWORD_MIN_LENGTH", 10),
+			PasswordMinClasses: getIntEnv("PASSWORD_MIN_CLASSES", 3),
+
+			RequireEmailVerification: getBoolEnv("AUTH_REQUIRE_EMAIL_VERIFICATION", true),
+			VerificationGracePeriod:  getDurationEnv("AUTH_VERIFICATION_GRACE_PERIOD", 0),
+			VerificationTokenExpiry:  getDurationEnv("AUTH_VERIFICATION_TOKEN_EXPIRY", 48*time.Hour),
+			VerificationURL:          getEnv("AUTH_VERIFICATION_URL", "http://localhost:8080/v1/auth/verify-email"),
+			TokenVersionCacheTTL:     getDurationEnv("AUTH_TOKEN_VERSION_CACHE_TTL", 30*time.Second),
+			PlatformTenantID:         getEnv("AUTH_PLATFORM_TENANT_ID", ""),
+			TenantStatusCacheTTL:     getDurationEnv("AUTH_TENANT_STATUS_CACHE_TTL", 30*time.Second),
+			EntityCacheTTL:           getDurationEnv("AUTH_ENTITY_CACHE_TTL", 30*time.Second),
+			EntityCacheSize:          getIntEnv("AUTH_ENTITY_CACHE_SIZE", 10000),
+			DeletionMode:             getEnv("AUTH_DELETION_MODE", "soft"),
+			DeletionRetention:        getDurationEnv("AUTH_DELETION_RETENTION", 0),
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "no-reply@arca.intelligence"),
+		},
+		Captcha: CaptchaConfig{
+			Enabled:   getBoolEnv("CAPTCHA_ENABLED", false),
+			Provider:  getEnv("CAPTCHA_PROVIDER", "turnstile"),
+			Secret:    getEnv("CAPTCHA_SECRET", ""),
+			VerifyURL: getEnv("CAPTCHA_VERIFY_URL", ""),
+			Timeout:   getDurationEnv("CAPTCHA_TIMEOUT", 5*time.Second),
+		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "arca"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", "arca"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-			MaxConns: getIntEnv("DB_MAX_CONNS", 100),
-			MinConns: getIntEnv("DB_MIN_CONNS", 10),
+			Host:              getEnv("DB_HOST", "localhost"),
+			Port:              getEnv("DB_PORT", "5432"),
+			User:              getEnv("DB_USER", "arca"),
+			Password:          getEnv("DB_PASSWORD", ""),
+			Name:              getEnv("DB_NAME", "arca"),
+			SSLMode:           getEnv("DB_SSLMODE", "disable"),
+			MaxConns:          getIntEnv("DB_MAX_CONNS", 100),
+			MinConns:          getIntEnv("DB_MIN_CONNS", 10),
+			ConnMaxLifetime:   getDurationEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+			ConnMaxIdleTime:   getDurationEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute),
+			QueryTimeout:      getDurationEnv("DB_QUERY_TIMEOUT", 5*time.Second),
+			PrepareStatements: getBoolEnv("DB_PREPARE_STATEMENTS", false),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
+			Host:     getEnv("REDIS_HOST", ""),
 			Port:     getEnv("REDIS_PORT", "6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getIntEnv("REDIS_DB", 0),
 			PoolSize: getIntEnv("REDIS_POOL_SIZE", 100),
 		},
 		MCP: MCPConfig{
-			BaseURL:    getEnv("MCP_BASE_URL", "http://localhost:8000"),
+			BaseURL:    getEnv("MCP_BASE_URL", ""),
+			Fake:       getBoolEnv("MCP_FAKE", false),
 			Timeout:    getDurationEnv("MCP_TIMEOUT", 30*time.Second),
 			MaxRetries: getIntEnv("MCP_MAX_RETRIES", 3),
 			RetryDelay: getDurationEnv("MCP_RETRY_DELAY", 1*time.Second),
+			Actions:    getStringMapEnv("MCP_ACTIONS", nil),
+			ToolURLs:   getStringMapEnv("MCP_TOOL_URLS", nil),
+			ToolScopes: getStringMapEnv("MCP_TOOL_SCOPES", nil),
+
+			MaxIdleConns:        getIntEnv("MCP_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost: getIntEnv("MCP_MAX_IDLE_CONNS_PER_HOST", 100),
+			IdleConnTimeout:     getDurationEnv("MCP_IDLE_CONN_TIMEOUT", 90*time.Second),
+			DialTimeout:         getDurationEnv("MCP_DIAL_TIMEOUT", 5*time.Second),
+			HTTP2:               getBoolEnv("MCP_HTTP2", true),
+
+			ServiceToken: getEnv("MCP_SERVICE_TOKEN", ""),
+			ClientCert:   getEnv("MCP_CLIENT_CERT", ""),
+			ClientKey:    getEnv("MCP_CLIENT_KEY", ""),
+			CAFile:       getEnv("MCP_CA_FILE", ""),
+			RequireAuth:  getBoolEnv("MCP_REQUIRE_AUTH", false),
+
+			PriorityPlans: getListEnv("MCP_PRIORITY_PLANS", []string{"pro", "enterprise"}),
+
+			MonitorSyncInterval: getDurationEnv("MCP_MONITOR_SYNC_INTERVAL", 0),
+		},
+		Targets: TargetsConfig{
+			StripFragment:  getBoolEnv("TARGET_STRIP_FRAGMENT", true),
+			BlockPrivate:   getBoolEnv("TARGET_BLOCK_PRIVATE", false),
+			SSRFGuard:      getBoolEnv("TARGET_SSRF_GUARD", false),
+			SSRFAllow:      getListEnv("TARGET_SSRF_ALLOW", nil),
+			ResolveTimeout: getDurationEnv("TARGET_RESOLVE_TIMEOUT", 2*time.Second),
 		},
 		RateLimit: RateLimitConfig{
 			RequestsPerMinute: getIntEnv("RATE_LIMIT_RPM", 1000),
 			BurstSize:         getIntEnv("RATE_LIMIT_BURST", 100),
 			CleanupInterval:   getDurationEnv("RATE_LIMIT_CLEANUP", 1*time.Minute),
+			TenantCacheTTL:    getDurationEnv("RATE_LIMIT_TENANT_CACHE_TTL", 1*time.Minute),
+			AnonymousRPM:      getIntEnv("RATE_LIMIT_ANONYMOUS_RPM", 60),
+			MaxConcurrentJobs: getIntEnv("RATE_LIMIT_MAX_CONCURRENT_JOBS", 5),
 		},
 		CORS: CORSConfig{
-			AllowOrigins:     []string{"http://localhost:3000", "http://localhost:8080", "https://arca.intelligence"},
-			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Tenant-ID", "X-Client-ID", "X-Request-ID"},
-			AllowCredentials: true,
-			MaxAge:           86400,
+			AllowOrigins:     getListEnv("CORS_ALLOW_ORIGINS", []string{"http://localhost:3000", "http://localhost:8080", "https://arca.intelligence"}),
+			AllowMethods:     getListEnv("CORS_ALLOW_METHODS", []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"}),
+			AllowHeaders:     getListEnv("CORS_ALLOW_HEADERS", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Tenant-ID", "X-Client-ID", "X-Request-ID"}),
+			AllowCredentials: getBoolEnv("CORS_ALLOW_CREDENTIALS", true),
+			MaxAge:           getIntEnv("CORS_MAX_AGE", 86400),
+		},
+		Notify: NotifyConfig{
+			Workers:            getIntEnv("NOTIFY_WORKERS", 4),
+			QueueSize:          getIntEnv("NOTIFY_QUEUE_SIZE", 1000),
+			MaxRetries:         getIntEnv("NOTIFY_MAX_RETRIES", 3),
+			RetryDelay:         getDurationEnv("NOTIFY_RETRY_DELAY", 1*time.Second),
+			Timeout:            getDurationEnv("NOTIFY_TIMEOUT", 10*time.Second),
+			StreamMaxPerTenant: getIntEnv("STREAM_MAX_PER_TENANT", 10),
+		},
+		Internal: InternalConfig{
+			ServiceToken: getEnv("INTERNAL_SERVICE_TOKEN", ""),
+		},
+		Log: LogConfig{
+			Level:           getEnv("LOG_LEVEL", "info"),
+			SampleRates:     getIntMapEnv("LOG_SAMPLE_ROUTES", map[string]int{"/health": 100, "/health/live": 100, "/health/ready": 100, "/metrics": 100}),
+			DebugSampleRate: getIntEnv("LOG_DEBUG_SAMPLE_RATE", 0),
+			CaptureStack:    getBoolEnv("LOG_CAPTURE_STACK", strings.EqualFold(strings.TrimSpace(getEnv("ENVIRONMENT", "development")), "development")),
+			Payloads:        getBoolEnv("LOG_PAYLOADS", false),
+			RedactFields:    getListEnv("LOG_REDACT_FIELDS", nil),
+		},
+		IPFilter: IPFilterConfig{
+			Allow:          getListEnv("IP_ALLOW_LIST", nil),
+			Deny:           getListEnv("IP_DENY_LIST", nil),
+			AllowCountries: getListEnv("IP_ALLOW_COUNTRIES", nil),
+			DenyCountries:  getListEnv("IP_DENY_COUNTRIES", nil),
+			GeoIPDBPath:    getEnv("GEOIP_DB_PATH", ""),
+		},
+		Audit: AuditConfig{
+			ReadSampleRate: getIntEnv("AUDIT_READ_SAMPLE_RATE", 0),
+			BufferSize:     getIntEnv("AUDIT_BUFFER_SIZE", 1000),
+			OverflowPolicy: getEnv("AUDIT_OVERFLOW_POLICY", "drop-newest"),
+		},
+		Metrics: MetricsConfig{
+			TenantLabels:    getEnv("METRICS_TENANT_LABELS", "off"),
+			TenantBuckets:   getIntEnv("METRICS_TENANT_BUCKETS", 32),
+			TenantAllowlist: getListEnv("METRICS_TENANT_ALLOWLIST", nil),
+			SlowRoutes:      getListEnv("METRICS_SLOW_ROUTES", nil),
+			SlowBuckets:     getListEnv("METRICS_SLOW_BUCKETS", nil),
+		},
+		Scheduler: SchedulerConfig{
+			RevocationPruneInterval: getDurationEnv("SCHEDULER_REVOCATION_PRUNE_INTERVAL", 5*time.Minute),
+			PurgeInterval:           getDurationEnv("SCHEDULER_PURGE_INTERVAL", time.Hour),
+			LeaderTTL:               getDurationEnv("SCHEDULER_LEADER_TTL", 30*time.Second),
 		},
 	}
 }
 
 // Helper functions
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -159,7 +792,7 @@ func getIntEnv(key string, defaultValue int) int {
 }
 
 func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if boolValue, err := strconv.ParseBool(value); err == nil {
 			return boolValue
 		}
@@ -168,10 +801,67 @@ func getBoolEnv(key string, defaultValue bool) bool {
 }
 
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
 	}
 	return defaultValue
 }
+
+// getListEnv parses a comma-separated list (e.g. "https://a.example.com,https://b.example.com")
+func getListEnv(key string, defaultValue []string) []string {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getIntMapEnv parses a comma-separated list of key=value pairs (e.g. "/health=100,/v1/threats=10")
+func getIntMapEnv(key string, defaultValue map[string]int) map[string]int {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if intValue, err := strconv.Atoi(strings.TrimSpace(parts[1])); err == nil {
+			result[strings.TrimSpace(parts[0])] = intValue
+		}
+	}
+	return result
+}
+
+// getStringMapEnv parses a comma-separated list of key=value pairs (e.g. "analyze_url=analyzer_v2:analyze")
+func getStringMapEnv(key string, defaultValue map[string]string) map[string]string {
+	value := lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result
+}
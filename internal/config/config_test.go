@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// productionConfig loads a production configuration that passes Validate
+func productionConfig(t *testing.T, overrides map[string]string) *Config {
+	t.Helper()
+	flags := map[string]string{
+		"ENVIRONMENT":  "production",
+		"JWT_SECRET":   strings.Repeat("k", minJWTSecretLength),
+		"DB_PASSWORD":  "s3cret",
+		"MCP_BASE_URL": "https://mcp.acme.com",
+		"SMTP_HOST":    "smtp.acme.com",
+	}
+	for key, value := range overrides {
+		flags[key] = value
+	}
+	cfg, err := LoadWithSources(Sources{Flags: flags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestValidateRequiresSMTPForEmailVerificationInProduction(t *testing.T) {
+	cfg := productionConfig(t, nil)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid production config rejected: %v", err)
+	}
+
+	cfg.SMTP.Host = ""
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "SMTP_HOST") {
+		t.Fatalf("Validate = %v, want SMTP_HOST error", err)
+	}
+
+	cfg.Auth.RequireEmailVerification = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("verification disabled: %v", err)
+	}
+
+	cfg = productionConfig(t, map[string]string{"ENVIRONMENT": "development", "SMTP_HOST": ""})
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("development without SMTP: %v", err)
+	}
+}
+
+// Normalize runs before Validate, so a differently spelled ENVIRONMENT still gets the production checks
+func TestNormalizeAppliesProductionChecks(t *testing.T) {
+	cfg := productionConfig(t, map[string]string{"ENVIRONMENT": " Production ", "AUTH_DELETION_MODE": "HARD"})
+	cfg.Normalize()
+	if cfg.Server.Environment != "production" || cfg.Auth.DeletionMode != "hard" {
+		t.Fatalf("normalized environment = %q, deletion mode = %q", cfg.Server.Environment, cfg.Auth.DeletionMode)
+	}
+
+	cfg.Database.Password = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") {
+		t.Errorf("Validate = %v, want the production DB_PASSWORD error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// envFile tracks the variables applied from CONFIG_ENV_FILE so a reload can
+// update and remove them without touching the real process environment.
+var envFile struct {
+	mu      sync.Mutex
+	process map[string]bool
+	applied map[string]bool
+}
+
+// LoadEnvFile applies KEY=VALUE lines from path to the process environment.
+// Variables set in the real environment take precedence and are never
+// overwritten; variables applied by a previous call and no longer present in
+// the file are unset. An empty path is a no-op.
+func LoadEnvFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	values, err := readEnvFile(path)
+	if err != nil {
+		return err
+	}
+
+	envFile.mu.Lock()
+	defer envFile.mu.Unlock()
+
+	if envFile.process == nil {
+		envFile.process = make(map[string]bool)
+		for _, kv := range os.Environ() {
+			key, _, _ := strings.Cut(kv, "=")
+			envFile.process[key] = true
+		}
+	}
+
+	applied := make(map[string]bool, len(values))
+	for key, value := range values {
+		if envFile.process[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("set %s from %s: %w", key, path, err)
+		}
+		applied[key] = true
+	}
+	for key := range envFile.applied {
+		if !applied[key] {
+			os.Unsetenv(key)
+		}
+	}
+	envFile.applied = applied
+	return nil
+}
+
+// readEnvFile parses a dotenv-style file: blank lines and # comments are
+// skipped, an optional "export " prefix is accepted and surrounding quotes
+// are removed from values.
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sources are the configuration layers besides the process environment.
+// Precedence is Flags > environment (including CONFIG_ENV_FILE) > File > defaults.
+type Sources struct {
+	// Flags maps environment variable names to values given on the command
+	// line; empty values are treated as not set
+	Flags map[string]string
+	// File is an optional YAML config file (see readConfigFile)
+	File string
+}
+
+// active holds the layers used by lookup while a Load is in progress
+var active struct {
+	mu    sync.Mutex
+	flags map[string]string
+	file  map[string]string
+	used  map[string]bool
+}
+
+// LoadWithSources loads the configuration from flags, environment, config file
+// and defaults. Keys in the file that do not match any setting are rejected so
+// typos do not go unnoticed.
+func LoadWithSources(src Sources) (*Config, error) {
+	var file map[string]string
+	if src.File != "" {
+		var err error
+		if file, err = readConfigFile(src.File); err != nil {
+			return nil, err
+		}
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	active.flags, active.file, active.used = src.Flags, file, make(map[string]bool)
+	defer func() { active.flags, active.file, active.used = nil, nil, nil }()
+
+	cfg := load()
+
+	var unknown []string
+	for key := range file {
+		if !active.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s: unknown settings %s", src.File, strings.Join(unknown, ", "))
+	}
+	return cfg, nil
+}
+
+// lookup returns the value of a setting from the highest-precedence layer that has it
+func lookup(key string) string {
+	if active.used != nil {
+		active.used[key] = true
+	}
+	if value := active.flags[key]; value != "" {
+		return value
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return active.file[key]
+}
+
+// readConfigFile reads a YAML config file into environment-style keys. Nested
+// maps are joined with "_" and upper-cased, so both
+//
+//	server:
+//	  port: 8080
+//
+// and SERVER_PORT: 8080 set SERVER_PORT. Lists become comma-separated values.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flattenConfig("", doc, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flattenConfig(prefix string, node map[string]interface{}, values map[string]string) error {
+	for key, value := range node {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if err := flattenConfig(name, v, values); err != nil {
+				return err
+			}
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				if _, nested := item.(map[string]interface{}); nested {
+					return fmt.Errorf("%s: lists of maps are not supported", name)
+				}
+				items[i] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package geoip resolve o país de um IP a partir de um banco MaxMind (GeoIP2/GeoLite2)
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Reader banco GeoIP aberto (implementa middleware.CountryResolver)
+type Reader struct {
+	db *geoip2.Reader
+}
+
+// Open abre um banco GeoIP2/GeoLite2 Country ou City
+func Open(path string) (*Reader, error) {
+	db, err := geoip2.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{db: db}, nil
+}
+
+// Country retorna o código ISO 3166-1 alpha-2 do país do IP, ou "" se desconhecido.
+// IPs sem país (ex.: anycast) caem no país de registro do bloco.
+func (r *Reader) Country(ip net.IP) (string, error) {
+	record, err := r.db.Country(ip)
+	if err != nil {
+		return "", err
+	}
+	if record.Country.IsoCode != "" {
+		return record.Country.IsoCode, nil
+	}
+	return record.RegisteredCountry.IsoCode, nil
+}
+
+// Close fecha o banco
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/monitoring"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AdminHandler handlers administrativos de operação do gateway
+type AdminHandler struct {
+	rateLimiter *middleware.RateLimiter
+	monitor     *monitoring.Reconciler
+}
+
+// NewAdminHandler cria um novo handler administrativo
+func NewAdminHandler(rateLimiter *middleware.RateLimiter, monitor *monitoring.Reconciler) *AdminHandler {
+	return &AdminHandler{
+		rateLimiter: rateLimiter,
+		monitor:     monitor,
+	}
+}
+
+// InspectRateLimit retorna a contagem, o limite e quando a janela de uma chave de
+// rate limit libera a próxima request. A chave é a usada pelo limiter
+// ("tenant:<id>", "anon:<ip>", "ip:<ip>"), com escape de URL se necessário.
+func (h *AdminHandler) InspectRateLimit(c *fiber.Ctx) error {
+	key, ok := rateLimitKey(c)
+	if !ok {
+		return response.BadRequest(c, "Invalid rate limit key")
+	}
+
+	state, err := h.rateLimiter.Inspect(c.Context(), key)
+	if err != nil {
+		return response.ServiceUnavailable(c, "Rate limit store unavailable")
+	}
+
+	return response.Success(c, state)
+}
+
+// ResetRateLimit apaga a janela de uma chave de rate limit, liberando-a imediatamente
+func (h *AdminHandler) ResetRateLimit(c *fiber.Ctx) error {
+	key, ok := rateLimitKey(c)
+	if !ok {
+		return response.BadRequest(c, "Invalid rate limit key")
+	}
+
+	if err := h.rateLimiter.Reset(c.Context(), key); err != nil {
+		return response.ServiceUnavailable(c, "Rate limit store unavailable")
+	}
+
+	logger.FromContext(c).WithField("rate_limit_key", key).Info("rate limit reset")
+	return response.NoContent(c)
+}
+
+// ReconcileMonitoring reconcilia com o MCP o estado de monitoramento das marcas com
+// job, de todos os tenants ou só do informado em ?tenant_id=, e retorna as marcas
+// corrigidas
+func (h *AdminHandler) ReconcileMonitoring(c *fiber.Ctx) error {
+	var tenantID *uuid.UUID
+	if raw := c.Query("tenant_id"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid tenant_id")
+		}
+		tenantID = &parsed
+	}
+
+	summary, err := h.monitor.ReconcileAll(c.Context(), tenantID)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to reconcile monitoring")
+	}
+
+	logger.FromContext(c).WithFields(map[string]interface{}{
+		"checked": summary.Checked,
+		"drifted": summary.Drifted,
+		"failed":  summary.Failed,
+	}).Info("monitoring reconciled")
+	return response.Success(c, summary)
+}
+
+func rateLimitKey(c *fiber.Ctx) (string, bool) {
+	key, err := url.PathUnescape(c.Params("key"))
+	if err != nil {
+		return "", false
+	}
+	key = strings.TrimSpace(key)
+	return key, key != ""
+}
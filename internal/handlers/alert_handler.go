@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Valores aceitos na ingestão de alertas
+var (
+	validAlertTypes      = map[string]bool{"phishing": true, "leak": true, "domain": true, "ssl": true}
+	validAlertSeverities = map[string]bool{"info": true, "low": true, "medium": true, "high": true, "critical": true}
+)
+
+// AlertHandler handlers de alertas
+type AlertHandler struct {
+	alertService  AlertStore
+	brandService  BrandStore
+	tenantService TenantStore
+	dispatcher    *notify.WebhookDispatcher
+	broker        notify.AlertBroker
+}
+
+// NewAlertHandler cria um novo handler de alertas
+func NewAlertHandler(alertService AlertStore, brandService BrandStore, tenantService TenantStore, dispatcher *notify.WebhookDispatcher, broker notify.AlertBroker) *AlertHandler {
+	return &AlertHandler{
+		alertService:  alertService,
+		brandService:  brandService,
+		tenantService: tenantService,
+		dispatcher:    dispatcher,
+		broker:        broker,
+	}
+}
+
+// IngestAlertRequest request de ingestão de alerta enviado pelo Core/MCP
+type IngestAlertRequest struct {
+	TenantID    string              `json:"tenant_id"`
+	BrandID     string              `json:"brand_id"`
+	Type        string              `json:"type"`
+	Severity    string              `json:"severity"`
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Details     models.AlertDetails `json:"details"`
+	DedupKey    string              `json:"dedup_key,omitempty"`
+}
+
+// IngestAlert recebe uma ameaça detectada pelo Core/MCP, persiste o alerta e dispara notificações
+func (h *AlertHandler) IngestAlert(c *fiber.Ctx) error {
+	var req IngestAlertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	var validationErrors []response.ValidationError
+	tenantID, err := uuid.Parse(req.TenantID)
+	if err != nil {
+		validationErrors = append(validationErrors, response.ValidationError{Field: "tenant_id", Message: "must be a valid UUID"})
+	}
+	brandID, err := uuid.Parse(req.BrandID)
+	if err != nil {
+		validationErrors = append(validationErrors, response.ValidationError{Field: "brand_id", Message: "must be a valid UUID"})
+	}
+	if !validAlertTypes[req.Type] {
+		validationErrors = append(validationErrors, response.ValidationError{Field: "type", Message: "must be one of phishing, leak, domain, ssl"})
+	}
+	if !validAlertSeverities[req.Severity] {
+		validationErrors = append(validationErrors, response.ValidationError{Field: "severity", Message: "must be one of info, low, medium, high, critical"})
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		validationErrors = append(validationErrors, response.ValidationError{Field: "title", Message: "is required"})
+	}
+	if len(validationErrors) > 0 {
+		return response.ValidationErrors(c, validationErrors)
+	}
+
+	// Tokens de API só podem ingerir alertas do próprio tenant
+	if !middleware.IsServiceRequest(c) && middleware.GetTenantID(c) != tenantID {
+		return response.Fail(c, response.CodeTenantAccessDenied, "Access denied to this tenant")
+	}
+
+	// A marca precisa pertencer ao tenant informado
+	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Brand not found for tenant")
+		}
+		return handleStoreError(c, err, "Failed to load brand")
+	}
+
+	if req.DedupKey == "" {
+		req.DedupKey = alertDedupKey(brandID, req.Type, req.Details)
+	}
+
+	now := time.Now()
+	alert := &models.Alert{
+		ID:          uuid.New(),
+		BrandID:     brand.ID,
+		ClientID:    brand.ClientID,
+		TenantID:    tenantID,
+		Type:        req.Type,
+		Severity:    req.Severity,
+		Title:       req.Title,
+		Description: req.Description,
+		Details:     req.Details,
+		Status:      "new",
+		DedupKey:    req.DedupKey,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := h.alertService.Create(c.Context(), alert); err != nil {
+		if errors.Is(err, services.ErrAlreadyExists) {
+			return response.Conflict(c, "Duplicate alert")
+		}
+		return handleStoreError(c, err, "Failed to create alert")
+	}
+
+	if err := h.brandService.IncrementThreatsFound(c.Context(), brand.ID, tenantID); err != nil {
+		logger.FromContext(c).WithField("brand_id", brand.ID.String()).Warn("failed to increment threats counter: %v", err)
+	}
+
+	middleware.RecordThreatDetected(tenantID.String(), alert.Severity, alert.Type)
+
+	if err := h.broker.Publish(c.Context(), alert); err != nil {
+		logger.FromContext(c).WithField("tenant_id", tenantID.String()).Warn("failed to publish alert to streams: %v", err)
+	}
+
+	settings, err := h.tenantService.GetSettings(c.Context(), tenantID)
+	if err != nil {
+		logger.FromContext(c).WithField("tenant_id", tenantID.String()).Warn("failed to load tenant settings for alert delivery: %v", err)
+	} else {
+		h.dispatcher.Dispatch(*settings, alert)
+	}
+
+	return response.Created(c, alert)
+}
+
+// alertDedupKey gera uma chave de deduplicação a partir dos dados que identificam a ameaça
+func alertDedupKey(brandID uuid.UUID, alertType string, details models.AlertDetails) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		brandID.String(), alertType, details.URL, details.Domain, details.IP,
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
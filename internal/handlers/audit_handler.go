@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AuditHandler handlers de auditoria
+type AuditHandler struct {
+	auditService AuditStore
+}
+
+// NewAuditHandler cria um novo handler de auditoria
+func NewAuditHandler(auditService AuditStore) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs lista o log de auditoria do tenant.
+// Filtros: action, user_id, from e to (RFC3339).
+func (h *AuditHandler) ListAuditLogs(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == uuid.Nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	page, perPage := response.ParsePagination(c)
+
+	filter := services.AuditFilter{
+		Action: c.Query("action"),
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid user_id")
+		}
+		filter.UserID = &userID
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid from: must be RFC3339")
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid to: must be RFC3339")
+		}
+		filter.To = &to
+	}
+
+	entries, total, err := h.auditService.List(c.Context(), tenantID, filter, page, perPage)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to list audit logs")
+	}
+
+	return response.PaginatedWithLinks(c, entries, page, perPage, total)
+}
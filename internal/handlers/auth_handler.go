@@ -1,35 +1,105 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
 	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
 	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/request"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/arcaintelligence/arca-gateway/pkg/slug"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// Motivos de falha registrados no histórico de login
+const (
+	loginFailureInvalidPassword = "invalid_password"
+	loginFailureInactive        = "inactive"
+	loginFailureNotVerified     = "email_not_verified"
+	loginFailureTenantInactive  = "tenant_suspended"
+)
+
+// Limite de logins retornados por GET /v1/auth/sessions
+const maxLoginEvents = 100
+
 // AuthHandler handlers de autenticação
 type AuthHandler struct {
-	jwtManager  *auth.JWTManager
-	userService *services.UserService
+	jwtManager        *auth.JWTManager
+	userService       UserStore
+	tenantService     TenantStore
+	loginEventService LoginEventStore
+	tenantDomain      string
+	passwordHasher    *auth.PasswordHasher
+	passwordPolicy    auth.PasswordPolicy
+	mailer            notify.Mailer
+	verification      EmailVerificationConfig
+	platformTenantID  uuid.UUID
+	hardDelete        bool
+}
+
+// AuthHandlerConfig configuração do handler de autenticação
+type AuthHandlerConfig struct {
+	// Domínio base cujos subdomínios identificam o tenant no login (vazio desabilita)
+	TenantDomain string
+	// Hash e verificação de senhas
+	PasswordHasher *auth.PasswordHasher
+	// Política de força aplicada no registro e na troca de senha
+	PasswordPolicy auth.PasswordPolicy
+	// Envio do email de verificação
+	Mailer notify.Mailer
+	// Verificação de email dos novos registros
+	EmailVerification EmailVerificationConfig
+	// Tenant cujos admins operam a plataforma e logam mesmo com o tenant suspenso
+	PlatformTenantID uuid.UUID
+	// HardDelete apaga a conta em DELETE /v1/auth/me; false apenas a desativa
+	HardDelete bool
+}
+
+// EmailVerificationConfig verificação de email dos usuários registrados via /v1/auth/register
+type EmailVerificationConfig struct {
+	// Cria o usuário como pendente até a verificação do email
+	Required bool
+	// Período após o registro em que o usuário pendente ainda pode logar (0 = nenhum)
+	GracePeriod time.Duration
+	// Validade do token enviado por email
+	TokenExpiry time.Duration
+	// Link de verificação; o token é anexado como ?token=
+	URL string
 }
 
 // NewAuthHandler cria um novo handler de autenticação
-func NewAuthHandler(jwtManager *auth.JWTManager, userService *services.UserService) *AuthHandler {
+func NewAuthHandler(jwtManager *auth.JWTManager, userService UserStore, tenantService TenantStore, loginEventService LoginEventStore, config AuthHandlerConfig) *AuthHandler {
 	return &AuthHandler{
-		jwtManager:  jwtManager,
-		userService: userService,
+		jwtManager:        jwtManager,
+		userService:       userService,
+		tenantService:     tenantService,
+		loginEventService: loginEventService,
+		tenantDomain:      strings.ToLower(strings.Trim(config.TenantDomain, ".")),
+		passwordHasher:    config.PasswordHasher,
+		passwordPolicy:    config.PasswordPolicy,
+		mailer:            config.Mailer,
+		verification:      config.EmailVerification,
+		platformTenantID:  config.PlatformTenantID,
+		hardDelete:        config.HardDelete,
 	}
 }
 
 // LoginRequest request de login
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	// Slug do tenant; opcional quando o email pertence a um único tenant ou o subdomínio o identifica
+	Tenant string `json:"tenant,omitempty"`
 }
 
 // LoginResponse response de login
@@ -43,49 +113,118 @@ type LoginResponse struct {
 
 // UserResponse response de usuário
 type UserResponse struct {
-	ID       uuid.UUID      `json:"id"`
-	TenantID uuid.UUID      `json:"tenant_id"`
-	Email    string         `json:"email"`
-	Name     string         `json:"name"`
-	Role     models.Role    `json:"role"`
-	Scopes   []models.Scope `json:"scopes"`
+	ID          uuid.UUID      `json:"id"`
+	TenantID    uuid.UUID      `json:"tenant_id"`
+	Email       string         `json:"email"`
+	Name        string         `json:"name"`
+	Role        models.Role    `json:"role"`
+	Scopes      []models.Scope `json:"scopes"`
+	LastLoginAt *time.Time     `json:"last_login_at,omitempty"`
 }
 
 // RegisterRequest request de registro
 type RegisterRequest struct {
-	TenantName string `json:"tenant_name"`
-	Email      string `json:"email"`
-	Password   string `json:"password"`
-	Name       string `json:"name"`
+	TenantName string `json:"tenant_name" validate:"required,max=200"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	Name       string `json:"name" validate:"required,max=200"`
+}
+
+// LogoutRequest request de logout; o refresh token é opcional
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// ChangePasswordRequest request de troca de senha
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// VerifyEmailTokenRequest request de verificação de email (POST)
+type VerifyEmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// DeleteAccountRequest request de exclusão da própria conta
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+	// Confirm precisa ser true: a exclusão encerra todas as sessões e não pode ser desfeita pelo usuário
+	Confirm bool `json:"confirm" validate:"eq=true"`
 }
 
 // RefreshRequest request de refresh token
 type RefreshRequest struct {
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // Login autentica um usuário
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
-	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
+	req, err := request.BindAndValidate[LoginRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
-
-	if req.Email == "" || req.Password == "" {
-		return response.BadRequest(c, "Email and password are required")
+	req.Email = models.NormalizeEmail(req.Email)
+
+	tenantID := uuid.Nil
+	if tenantSlug := h.loginTenant(c, req.Tenant); tenantSlug != "" {
+		tenant, err := h.tenantService.GetBySlug(c.Context(), tenantSlug)
+		if err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				return response.Fail(c, response.CodeAuthInvalidCredentials, "Invalid credentials")
+			}
+			return handleStoreError(c, err, "Failed to resolve tenant")
+		}
+		tenantID = tenant.ID
 	}
 
-	user, err := h.userService.GetByEmail(c.Context(), req.Email)
+	candidates, err := h.userService.ListByEmail(c.Context(), req.Email, tenantID)
 	if err != nil {
-		return response.Unauthorized(c, "Invalid credentials")
+		return handleStoreError(c, err, "Failed to load user")
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return response.Unauthorized(c, "Invalid credentials")
+	// Sem tenant o email pode existir em mais de um; a senha é conferida em todos antes de
+	// pedir o tenant, para que TENANT_REQUIRED não revele onde o email está cadastrado
+	var matched []*models.User
+	for _, candidate := range candidates {
+		if err := h.passwordHasher.Verify(candidate.PasswordHash, req.Password); err != nil {
+			h.recordLogin(c, candidate, loginFailureInvalidPassword)
+			continue
+		}
+		matched = append(matched, candidate)
+	}
+	switch {
+	case len(matched) == 0:
+		return response.Fail(c, response.CodeAuthInvalidCredentials, "Invalid credentials")
+	case len(matched) > 1:
+		return response.Fail(c, response.CodeTenantRequired, "Email is registered in multiple tenants; provide the tenant slug")
+	}
+	user := matched[0]
+
+	if user.Status == models.StatusPending && !h.inVerificationGrace(user) {
+		h.recordLogin(c, user, loginFailureNotVerified)
+		return response.Fail(c, response.CodeEmailNotVerified, "Email not verified")
 	}
 
-	if user.Status != models.StatusActive {
-		return response.Forbidden(c, "Account is not active")
+	if user.Status != models.StatusActive && user.Status != models.StatusPending {
+		h.recordLogin(c, user, loginFailureInactive)
+		return response.Fail(c, response.CodeAccountInactive, "Account is not active")
+	}
+
+	if !middleware.IsPlatformAdmin(user.Role, user.TenantID, h.platformTenantID) {
+		tenantStatus, err := h.tenantService.GetStatus(c.Context(), user.TenantID)
+		if err != nil && !errors.Is(err, services.ErrNotFound) {
+			return handleStoreError(c, err, "Failed to resolve tenant")
+		}
+		if tenantStatus != models.StatusActive {
+			h.recordLogin(c, user, loginFailureTenantInactive)
+			return response.Fail(c, response.CodeTenantSuspended, "Tenant suspended")
+		}
+	}
+
+	user, err = h.grantScopes(c.Context(), user)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to resolve tenant scopes")
 	}
 
 	accessToken, refreshToken, err := h.jwtManager.GenerateTokenPair(user)
@@ -93,9 +232,12 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.InternalServerError(c, "Failed to generate tokens")
 	}
 
-	now := time.Now()
-	user.LastLoginAt = &now
-	_ = h.userService.Update(c.Context(), user)
+	// A resposta traz o login anterior, para o usuário identificar acessos que não reconhece
+	previousLogin := user.LastLoginAt
+	if err := h.userService.TouchLastLogin(c.Context(), user.ID, time.Now()); err != nil {
+		logger.FromContext(c).WithField("user_id", user.ID.String()).Warn("failed to update last login: %v", err)
+	}
+	h.recordLogin(c, user, "")
 
 	return response.Success(c, LoginResponse{
 		AccessToken:  accessToken,
@@ -103,41 +245,43 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		TokenType:    "Bearer",
 		ExpiresIn:    900,
 		User: UserResponse{
-			ID:       user.ID,
-			TenantID: user.TenantID,
-			Email:    user.Email,
-			Name:     user.Name,
-			Role:     user.Role,
-			Scopes:   user.Scopes,
+			ID:          user.ID,
+			TenantID:    user.TenantID,
+			Email:       user.Email,
+			Name:        user.Name,
+			Role:        user.Role,
+			Scopes:      user.Scopes,
+			LastLoginAt: previousLogin,
 		},
 	})
 }
 
 // Register registra um novo tenant e usuário admin
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
-	var req RegisterRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
+	req, err := request.BindAndValidate[RegisterRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
+	req.Email = models.NormalizeEmail(req.Email)
 
-	if req.TenantName == "" || req.Email == "" || req.Password == "" || req.Name == "" {
-		return response.BadRequest(c, "All fields are required")
+	if violations := h.passwordPolicy.Validate(req.Password); len(violations) > 0 {
+		return response.ValidationErrors(c, passwordErrors("password", violations))
 	}
 
-	existingUser, _ := h.userService.GetByEmail(c.Context(), req.Email)
-	if existingUser != nil {
-		return response.Conflict(c, "Email already registered")
+	hashedPassword, err := h.passwordHasher.Hash(req.Password)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to process password")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	tenantSlug, err := slug.Unique(c.Context(), req.TenantName, h.tenantService.SlugExists)
 	if err != nil {
-		return response.InternalServerError(c, "Failed to process password")
+		return handleStoreError(c, err, "Failed to generate tenant slug")
 	}
 
 	tenant := &models.Tenant{
 		ID:     uuid.New(),
 		Name:   req.TenantName,
-		Slug:   generateSlug(req.TenantName),
+		Slug:   tenantSlug,
 		Email:  req.Email,
 		Plan:   "free",
 		Status: models.StatusActive,
@@ -163,17 +307,42 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 		ID:           uuid.New(),
 		TenantID:     tenant.ID,
 		Email:        req.Email,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Name:         req.Name,
 		Role:         models.RoleAdmin,
-		Scopes:       models.GetDefaultScopesForRole(models.RoleAdmin),
+		Scopes:       models.EffectiveScopes(models.RoleAdmin, nil, tenant.Settings.AllowedScopes),
 		Status:       models.StatusActive,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
+	if h.verification.Required {
+		user.Status = models.StatusPending
+	}
 
 	if err := h.userService.CreateWithTenant(c.Context(), tenant, user); err != nil {
-		return response.InternalServerError(c, "Failed to create account")
+		return handleStoreError(c, err, "Failed to create account")
+	}
+
+	if user.Status == models.StatusPending {
+		// Falha no envio não desfaz o registro; o erro fica no log para reenvio manual
+		if err := h.sendVerificationEmail(c, user); err != nil {
+			logger.FromContext(c).WithField("user_id", user.ID.String()).Error("failed to send verification email: %v", err)
+		}
+
+		if !h.inVerificationGrace(user) {
+			return response.Created(c, fiber.Map{
+				"user": UserResponse{
+					ID:       user.ID,
+					TenantID: user.TenantID,
+					Email:    user.Email,
+					Name:     user.Name,
+					Role:     user.Role,
+					Scopes:   user.Scopes,
+				},
+				"email_verification_required": true,
+				"message":                     "Verification email sent; verify your email to log in",
+			})
+		}
 	}
 
 	accessToken, refreshToken, err := h.jwtManager.GenerateTokenPair(user)
@@ -199,18 +368,14 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // RefreshToken renova o access token
 func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
-	var req RefreshRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
-	}
-
-	if req.RefreshToken == "" {
-		return response.BadRequest(c, "Refresh token is required")
+	req, err := request.BindAndValidate[RefreshRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
 
 	accessToken, err := h.jwtManager.RefreshAccessToken(req.RefreshToken)
 	if err != nil {
-		return response.Unauthorized(c, "Invalid or expired refresh token")
+		return response.Fail(c, response.CodeAuthTokenInvalid, "Invalid or expired refresh token")
 	}
 
 	return response.Success(c, fiber.Map{
@@ -222,11 +387,144 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 
 // Logout invalida o token
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	var req LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	// Revoga o access token atual e a sessão dele (inclui o refresh token emitido junto)
+	if err := h.jwtManager.Revoke(c.Context(), claims); err != nil {
+		return response.InternalServerError(c, "Failed to revoke session")
+	}
+
+	// Refresh token informado explicitamente (ex: emitido antes de sessões existirem)
+	if req.RefreshToken != "" {
+		refreshClaims, err := h.jwtManager.ValidateTokenContext(c.Context(), req.RefreshToken)
+		switch {
+		case errors.Is(err, auth.ErrExpiredToken), errors.Is(err, auth.ErrRevokedToken):
+			// Já não pode ser usado
+		case err != nil, refreshClaims.TokenType != auth.TokenTypeRefresh, refreshClaims.UserID != claims.UserID:
+			return response.BadRequest(c, "Invalid refresh token")
+		default:
+			if err := h.jwtManager.Revoke(c.Context(), refreshClaims); err != nil {
+				return response.InternalServerError(c, "Failed to revoke session")
+			}
+		}
+	}
+
 	return response.Success(c, fiber.Map{
 		"message": "Logged out successfully",
 	})
 }
 
+// LogoutAll encerra todas as sessões do usuário (inclusive API keys) incrementando
+// a versão dos tokens; tokens emitidos antes passam a ser rejeitados
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	if _, err := h.userService.BumpTokenVersion(c.Context(), claims.UserID); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to revoke sessions")
+	}
+	h.jwtManager.InvalidateTokenVersion(claims.UserID)
+
+	return response.Success(c, fiber.Map{
+		"message": "All sessions logged out successfully",
+	})
+}
+
+// ChangePassword troca a senha do usuário autenticado, exigindo a senha atual
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	req, err := request.BindAndValidate[ChangePasswordRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return response.ValidationErrors(c, []response.ValidationError{{Field: "new_password", Message: "must differ from the current password"}})
+	}
+	if violations := h.passwordPolicy.Validate(req.NewPassword); len(violations) > 0 {
+		return response.ValidationErrors(c, passwordErrors("new_password", violations))
+	}
+
+	user, err := h.userService.GetWithCredentials(c.Context(), claims.UserID)
+	if err != nil {
+		return handleServiceError(c, err, "User not found", "Failed to load user")
+	}
+
+	if err := h.passwordHasher.Verify(user.PasswordHash, req.CurrentPassword); err != nil {
+		return response.Fail(c, response.CodeAuthInvalidCredentials, "Current password is incorrect")
+	}
+
+	hashedPassword, err := h.passwordHasher.Hash(req.NewPassword)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to process password")
+	}
+
+	if err := h.userService.UpdatePassword(c.Context(), user.ID, hashedPassword); err != nil {
+		return handleStoreError(c, err, "Failed to update password")
+	}
+
+	return response.Success(c, fiber.Map{
+		"message": "Password changed successfully",
+	})
+}
+
+// VerifyEmail confirma o email do usuário a partir do token enviado por email.
+// Aceita GET ?token= (link do email) ou POST {"token": "..."}.
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if c.Method() == fiber.MethodPost {
+		var req VerifyEmailTokenRequest
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body")
+		}
+		token = req.Token
+	}
+
+	if token == "" {
+		return response.UnprocessableEntity(c, "Token is required")
+	}
+
+	claims, err := h.jwtManager.ValidateEmailVerificationToken(token)
+	if err != nil {
+		return response.BadRequest(c, "Invalid or expired verification token")
+	}
+
+	if err := h.userService.VerifyEmail(c.Context(), claims.UserID, claims.Email); err != nil {
+		if !errors.Is(err, services.ErrNotFound) {
+			return handleStoreError(c, err, "Failed to verify email")
+		}
+
+		// Link usado mais de uma vez: sucesso se o usuário já está ativo com o mesmo email
+		user, err := h.userService.GetByID(c.Context(), claims.UserID)
+		if err != nil || user.Email != claims.Email || user.Status != models.StatusActive {
+			return response.BadRequest(c, "Invalid or expired verification token")
+		}
+	}
+
+	return response.Success(c, fiber.Map{
+		"message": "Email verified successfully",
+	})
+}
+
 // Me retorna informações do usuário autenticado
 func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	claims := getClaims(c)
@@ -236,19 +534,67 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 
 	user, err := h.userService.GetByID(c.Context(), claims.UserID)
 	if err != nil {
-		return response.NotFound(c, "User not found")
+		return handleServiceError(c, err, "User not found", "Failed to load user")
+	}
+
+	// Os mesmos scopes efetivos do token e de /users/:id
+	user, err = h.grantScopes(c.Context(), user)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to resolve tenant scopes")
 	}
 
 	return response.Success(c, UserResponse{
-		ID:       user.ID,
-		TenantID: user.TenantID,
-		Email:    user.Email,
-		Name:     user.Name,
-		Role:     user.Role,
-		Scopes:   user.Scopes,
+		ID:          user.ID,
+		TenantID:    user.TenantID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        user.Role,
+		Scopes:      user.Scopes,
+		LastLoginAt: user.LastLoginAt,
 	})
 }
 
+// DeleteMe exclui a conta do usuário autenticado após reconfirmar a senha. Conforme
+// AUTH_DELETION_MODE a conta é desativada ou apagada; em ambos os casos todas as
+// sessões são encerradas. O último admin ativo do tenant não pode se excluir.
+func (h *AuthHandler) DeleteMe(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	req, err := request.BindAndValidate[DeleteAccountRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
+	}
+
+	user, err := h.userService.GetWithCredentials(c.Context(), claims.UserID)
+	if err != nil {
+		return handleServiceError(c, err, "User not found", "Failed to load user")
+	}
+
+	if err := h.passwordHasher.Verify(user.PasswordHash, req.Password); err != nil {
+		return response.Fail(c, response.CodeAuthInvalidCredentials, "Password is incorrect")
+	}
+
+	if err := h.userService.DeleteAccount(c.Context(), user, h.hardDelete); err != nil {
+		switch {
+		case errors.Is(err, services.ErrLastAdmin):
+			return response.Fail(c, response.CodeLastAdmin,
+				"You are the last admin of this tenant; promote another user to admin before deleting your account")
+		case errors.Is(err, services.ErrNotFound):
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to delete account")
+	}
+	h.jwtManager.InvalidateTokenVersion(user.ID)
+	if h.hardDelete {
+		middleware.AnonymizeAudit(c)
+	}
+
+	return response.NoContent(c)
+}
+
 // GenerateAPIKey gera uma API key para o usuário
 func (h *AuthHandler) GenerateAPIKey(c *fiber.Ctx) error {
 	claims := getClaims(c)
@@ -257,12 +603,17 @@ func (h *AuthHandler) GenerateAPIKey(c *fiber.Ctx) error {
 	}
 
 	if claims.Role != models.RoleAdmin && claims.Role != models.RoleManager {
-		return response.Forbidden(c, "Only admin and manager can generate API keys")
+		return response.Fail(c, response.CodeRoleRequired, "Only admin and manager can generate API keys")
 	}
 
-	user, err := h.userService.GetByID(c.Context(), claims.UserID)
+	user, err := h.userService.GetWithCredentials(c.Context(), claims.UserID)
+	if err != nil {
+		return handleServiceError(c, err, "User not found", "Failed to load user")
+	}
+
+	user, err = h.grantScopes(c.Context(), user)
 	if err != nil {
-		return response.NotFound(c, "User not found")
+		return handleStoreError(c, err, "Failed to resolve tenant scopes")
 	}
 
 	expiry := 365 * 24 * time.Hour
@@ -278,6 +629,107 @@ func (h *AuthHandler) GenerateAPIKey(c *fiber.Ctx) error {
 	})
 }
 
+// grantScopes retorna uma cópia do usuário com os scopes limitados ao teto do tenant
+// (ver models.EffectiveScopes), usada ao emitir tokens
+func (h *AuthHandler) grantScopes(ctx context.Context, user *models.User) (*models.User, error) {
+	settings, err := h.tenantService.GetSettings(ctx, user.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	granted := *user
+	granted.Scopes = models.EffectiveScopes(user.Role, user.Scopes, settings.AllowedScopes)
+	return &granted, nil
+}
+
+// loginTenant retorna o slug do tenant informado no login: o campo explícito tem
+// precedência sobre o subdomínio do host (ex: acme.<tenantDomain>)
+func (h *AuthHandler) loginTenant(c *fiber.Ctx, explicit string) string {
+	if explicit = strings.ToLower(strings.TrimSpace(explicit)); explicit != "" {
+		return explicit
+	}
+	if h.tenantDomain == "" {
+		return ""
+	}
+
+	host, _, _ := strings.Cut(strings.ToLower(c.Hostname()), ":")
+	sub, ok := strings.CutSuffix(host, "."+h.tenantDomain)
+	if !ok || sub == "" || strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
+
+// Sessions lista os logins recentes (bem-sucedidos e falhos) do usuário autenticado
+func (h *AuthHandler) Sessions(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > maxLoginEvents {
+		limit = maxLoginEvents
+	}
+
+	events, err := h.loginEventService.ListByUser(c.Context(), claims.UserID, limit)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to list sessions")
+	}
+
+	return response.Success(c, events)
+}
+
+// inVerificationGrace indica se o usuário pendente ainda está no período de carência
+func (h *AuthHandler) inVerificationGrace(user *models.User) bool {
+	return h.verification.GracePeriod > 0 && time.Since(user.CreatedAt) < h.verification.GracePeriod
+}
+
+// sendVerificationEmail envia o link de verificação com um token assinado
+func (h *AuthHandler) sendVerificationEmail(c *fiber.Ctx, user *models.User) error {
+	token, err := h.jwtManager.GenerateEmailVerificationToken(user, h.verification.TokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := h.verification.URL + "?token=" + url.QueryEscape(token)
+	return h.mailer.Send(c.Context(), &notify.Email{
+		To:      user.Email,
+		Subject: "Confirme seu email - ARCA Intelligence",
+		Body: fmt.Sprintf("Olá, %s!\n\nConfirme seu email para ativar sua conta:\n%s\n\nO link expira em %s.\n",
+			user.Name, link, h.verification.TokenExpiry),
+	})
+}
+
+// recordLogin registra a tentativa de login; reason vazio indica sucesso.
+// Falhas de gravação não interrompem o login.
+func (h *AuthHandler) recordLogin(c *fiber.Ctx, user *models.User, reason string) {
+	event := &models.LoginEvent{
+		ID:            uuid.New(),
+		TenantID:      user.TenantID,
+		UserID:        user.ID,
+		Success:       reason == "",
+		FailureReason: reason,
+		IP:            middleware.ClientIP(c),
+		UserAgent:     c.Get("User-Agent"),
+		CreatedAt:     time.Now(),
+	}
+
+	if err := h.loginEventService.Record(c.Context(), event); err != nil {
+		logger.FromContext(c).WithField("user_id", user.ID.String()).Warn("failed to record login event: %v", err)
+	}
+}
+
+// passwordErrors converte as violações da política em um erro de validação do campo
+func passwordErrors(field string, violations []string) []response.ValidationError {
+	return []response.ValidationError{{
+		Field:   field,
+		Message: "Password " + strings.Join(violations, "; "),
+	}}
+}
+
 func getClaims(c *fiber.Ctx) *auth.Claims {
 	claims, ok := c.Locals("claims").(*auth.Claims)
 	if !ok {
@@ -285,17 +737,3 @@ func getClaims(c *fiber.Ctx) *auth.Claims {
 	}
 	return claims
 }
-
-func generateSlug(name string) string {
-	slug := ""
-	for _, char := range name {
-		if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') {
-			slug += string(char)
-		} else if char >= 'A' && char <= 'Z' {
-			slug += string(char + 32)
-		} else if char == ' ' || char == '-' {
-			slug += "-"
-		}
-	}
-	return slug
-}
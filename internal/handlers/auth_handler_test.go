@@ -0,0 +1,334 @@
+package handlers_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/testutil"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const testPassword = "Correct-Horse-9"
+
+// authEnv app com login e registro sobre stores em memória
+type authEnv struct {
+	app *fiber.App
+	mem *testutil.Memory
+	jwt *auth.JWTManager
+}
+
+func newAuthEnv(t *testing.T) *authEnv {
+	t.Helper()
+	env := &authEnv{
+		app: testutil.NewApp(),
+		mem: testutil.NewMemory(),
+		jwt: testutil.NewJWTManager(),
+	}
+	h := handlers.NewAuthHandler(env.jwt, env.mem.Users(), env.mem.Tenants(), env.mem.LoginEvents(), handlers.AuthHandlerConfig{
+		PasswordHasher: testutil.NewPasswordHasher(),
+		PasswordPolicy: auth.PasswordPolicy{MinLength: 12, MinClasses: 3},
+	})
+	env.app.Post("/v1/auth/login", h.Login)
+	env.app.Post("/v1/auth/register", h.Register)
+	env.app.Get("/v1/auth/me", middleware.NewAuthMiddleware(env.jwt).Authenticate(), h.Me)
+	return env
+}
+
+// post envia a request sem autenticação e decodifica data em dest
+func (env *authEnv) post(t *testing.T, target string, body, dest interface{}) (int, *response.Response) {
+	t.Helper()
+	resp, err := env.app.Test(testutil.NewRequest(t, fiber.MethodPost, target, body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := testutil.Decode(t, resp, dest)
+	return resp.StatusCode, envelope
+}
+
+func TestLoginIssuesTokensForValidCredentials(t *testing.T) {
+	env := newAuthEnv(t)
+	user := testutil.NewUser(models.RoleAnalyst)
+	user.Email = "ana@acme.com"
+	env.mem.AddUser(testutil.SetPassword(t, user, testPassword))
+
+	var got handlers.LoginResponse
+	status, _ := env.post(t, "/v1/auth/login", map[string]string{"email": "  Ana@ACME.com ", "password": testPassword}, &got)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if got.User.ID != user.ID || got.TokenType != "Bearer" || got.RefreshToken == "" {
+		t.Errorf("login response = %+v", got)
+	}
+	claims, err := env.jwt.ValidateToken(got.AccessToken)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if claims.UserID != user.ID || claims.TenantID != user.TenantID || claims.Role != models.RoleAnalyst {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	events, _ := env.mem.LoginEvents().ListByUser(context.Background(), user.ID, 10)
+	if len(events) != 1 || !events[0].Success {
+		t.Errorf("login events = %+v, want one success", events)
+	}
+}
+
+// Senha errada e email desconhecido respondem igual, sem revelar quais emails existem
+func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	env := newAuthEnv(t)
+	user := testutil.NewUser(models.RoleAdmin)
+	env.mem.AddUser(testutil.SetPassword(t, user, testPassword))
+
+	for _, body := range []map[string]string{
+		{"email": user.Email, "password": "wrong-password"},
+		{"email": "nobody@acme.com", "password": testPassword},
+		{"email": user.Email, "password": testPassword, "tenant": "unknown-tenant"},
+	} {
+		status, envelope := env.post(t, "/v1/auth/login", body, nil)
+		if status != fiber.StatusUnauthorized || envelope.Error == nil || envelope.Error.Code != response.CodeAuthInvalidCredentials {
+			t.Errorf("login %v: status = %d, error = %+v", body, status, envelope.Error)
+		}
+	}
+
+	status, envelope := env.post(t, "/v1/auth/login", map[string]string{"email": user.Email}, nil)
+	if status != fiber.StatusUnprocessableEntity || envelope.Error == nil || envelope.Error.Code != response.CodeValidationError {
+		t.Errorf("missing password: status = %d, error = %+v", status, envelope.Error)
+	}
+}
+
+func TestLoginRejectsSuspendedTenant(t *testing.T) {
+	env := newAuthEnv(t)
+	user := testutil.NewUser(models.RoleAdmin)
+	env.mem.AddTenant(&models.Tenant{ID: user.TenantID, Plan: "pro", Status: models.StatusSuspended})
+	env.mem.AddUser(testutil.SetPassword(t, user, testPassword))
+
+	status, envelope := env.post(t, "/v1/auth/login", map[string]string{"email": user.Email, "password": testPassword}, nil)
+	if status != fiber.StatusForbidden || envelope.Error == nil || envelope.Error.Code != response.CodeTenantSuspended {
+		t.Errorf("status = %d, error = %+v", status, envelope.Error)
+	}
+}
+
+func TestRegisterCreatesTenantAndAdmin(t *testing.T) {
+	env := newAuthEnv(t)
+	body := map[string]string{"tenant_name": "Acme Segurança", "email": "Owner@Acme.com", "password": testPassword, "name": "Owner"}
+
+	var got handlers.LoginResponse
+	status, _ := env.post(t, "/v1/auth/register", body, &got)
+	if status != fiber.StatusCreated {
+		t.Fatalf("status = %d, want 201", status)
+	}
+	if got.User.Email != "owner@acme.com" || got.User.Role != models.RoleAdmin || got.AccessToken == "" {
+		t.Errorf("register response = %+v", got)
+	}
+
+	ctx := context.Background()
+	tenant, err := env.mem.Tenants().GetByID(ctx, got.User.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant.Slug != "acme-seguranca" || tenant.Status != models.StatusActive {
+		t.Errorf("tenant = %+v", tenant)
+	}
+
+	// O usuário registrado consegue logar
+	status, _ = env.post(t, "/v1/auth/login", map[string]string{"email": "owner@acme.com", "password": testPassword}, nil)
+	if status != fiber.StatusOK {
+		t.Errorf("login after register: status = %d", status)
+	}
+
+	// Outro tenant com o mesmo nome recebe um slug com sufixo
+	body["email"] = "other@acme.com"
+	if status, _ = env.post(t, "/v1/auth/register", body, &got); status != fiber.StatusCreated {
+		t.Fatalf("second register: status = %d", status)
+	}
+	if other, _ := env.mem.Tenants().GetByID(ctx, got.User.TenantID); other == nil || other.Slug == tenant.Slug {
+		t.Errorf("second tenant = %+v, want a distinct slug", other)
+	}
+}
+
+func TestRegisterRejectsWeakPassword(t *testing.T) {
+	env := newAuthEnv(t)
+	body := map[string]string{"tenant_name": "Acme", "email": "owner@acme.com", "password": "short", "name": "Owner"}
+
+	status, envelope := env.post(t, "/v1/auth/register", body, nil)
+	if status != fiber.StatusUnprocessableEntity || envelope.Error == nil || envelope.Error.Code != response.CodeValidationError {
+		t.Errorf("status = %d, error = %+v", status, envelope.Error)
+	}
+	if _, err := env.mem.Users().GetByEmail(context.Background(), "owner@acme.com", uuid.Nil); err == nil {
+		t.Error("user created with a weak password")
+	}
+}
+
+// Com o email em dois tenants, TENANT_REQUIRED só sai depois de a senha conferir; senha
+// errada recebe o 401 genérico, sem revelar que o email existe em mais de um tenant
+func TestLoginAmbiguousEmailChecksPasswordFirst(t *testing.T) {
+	env := newAuthEnv(t)
+	acme := env.mem.AddTenant(&models.Tenant{Slug: "acme", Plan: "pro"})
+	globex := env.mem.AddTenant(&models.Tenant{Slug: "globex", Plan: "pro"})
+
+	addUser := func(tenantID uuid.UUID, password string) *models.User {
+		user := testutil.NewUser(models.RoleAnalyst)
+		user.TenantID, user.Email = tenantID, "ana@acme.com"
+		return env.mem.AddUser(testutil.SetPassword(t, user, password))
+	}
+	acmeUser := addUser(acme.ID, testPassword)
+	addUser(globex.ID, "Globex-Password-1")
+
+	login := func(body map[string]string) (int, *response.Response, *handlers.LoginResponse) {
+		t.Helper()
+		var got handlers.LoginResponse
+		status, envelope := env.post(t, "/v1/auth/login", body, &got)
+		return status, envelope, &got
+	}
+
+	status, envelope, _ := login(map[string]string{"email": "ana@acme.com", "password": "wrong-password"})
+	if status != fiber.StatusUnauthorized || envelope.Error == nil || envelope.Error.Code != response.CodeAuthInvalidCredentials {
+		t.Errorf("wrong password: status = %d, error = %+v", status, envelope.Error)
+	}
+
+	// A senha confere com uma só conta: o login segue com ela
+	status, _, got := login(map[string]string{"email": "ana@acme.com", "password": testPassword})
+	if status != fiber.StatusOK || got.User.ID != acmeUser.ID {
+		t.Errorf("single match: status = %d, user = %s, want %s", status, got.User.ID, acmeUser.ID)
+	}
+
+	// A mesma senha nas duas contas exige o tenant
+	addUser(env.mem.AddTenant(&models.Tenant{Slug: "initech", Plan: "pro"}).ID, testPassword)
+	status, envelope, _ = login(map[string]string{"email": "ana@acme.com", "password": testPassword})
+	if status != fiber.StatusConflict || envelope.Error == nil || envelope.Error.Code != response.CodeTenantRequired {
+		t.Errorf("multiple matches: status = %d, error = %+v", status, envelope.Error)
+	}
+	status, _, got = login(map[string]string{"email": "ana@acme.com", "password": testPassword, "tenant": "acme"})
+	if status != fiber.StatusOK || got.User.ID != acmeUser.ID {
+		t.Errorf("with tenant: status = %d, user = %s", status, got.User.ID)
+	}
+}
+
+// Sem tenant a senha é conferida em todas as contas com o email, não só nas duas
+// primeiras: com três tenants, a conta do terceiro ainda faz login
+func TestLoginChecksEveryTenantWithTheEmail(t *testing.T) {
+	env := newAuthEnv(t)
+	var third *models.User
+	for i, password := range []string{"Acme-Password-1", "Globex-Password-2", testPassword} {
+		user := testutil.NewUser(models.RoleAnalyst)
+		user.TenantID, user.Email = env.mem.AddTenant(&models.Tenant{Plan: "pro"}).ID, "ana@acme.com"
+		user.CreatedAt = user.CreatedAt.Add(time.Duration(i) * time.Second)
+		third = env.mem.AddUser(testutil.SetPassword(t, user, password))
+	}
+
+	var got handlers.LoginResponse
+	status, envelope := env.post(t, "/v1/auth/login", map[string]string{"email": "ana@acme.com", "password": testPassword}, &got)
+	if status != fiber.StatusOK || got.User.ID != third.ID {
+		t.Errorf("status = %d, user = %s, error = %+v; want the third tenant's account %s", status, got.User.ID, envelope.Error, third.ID)
+	}
+}
+
+// settings.allowed_scopes é o teto dos scopes emitidos no login: restringe os padrão do
+// role, deixa um grant explícito ampliar dentro do teto e não se aplica a admins
+func TestLoginCapsScopesByTenantCeiling(t *testing.T) {
+	env := newAuthEnv(t)
+	tenant := env.mem.AddTenant(&models.Tenant{Plan: "enterprise", Settings: models.TenantSettings{
+		AllowedScopes: []models.Scope{
+			models.ScopeHuntingRead, models.ScopeMonitorRead, models.ScopeMonitorWrite, models.ScopeClientsRead,
+		},
+	}})
+
+	addUser := func(role models.Role, scopes ...models.Scope) *models.User {
+		user := testutil.NewUser(role)
+		user.TenantID, user.Scopes = tenant.ID, scopes
+		return env.mem.AddUser(testutil.SetPassword(t, user, testPassword))
+	}
+
+	for _, tc := range []struct {
+		name string
+		user *models.User
+		want []models.Scope
+	}{
+		{
+			// Padrão do analyst sem reports:read, alerts:* etc., fora do teto
+			name: "role defaults restricted",
+			user: addUser(models.RoleAnalyst),
+			want: []models.Scope{models.ScopeHuntingRead, models.ScopeMonitorRead, models.ScopeClientsRead},
+		},
+		{
+			// monitor:write não é padrão do analyst, mas está no teto
+			name: "explicit grant within ceiling",
+			user: addUser(models.RoleAnalyst, models.ScopeMonitorRead, models.ScopeMonitorWrite, models.ScopeReportsWrite),
+			want: []models.Scope{models.ScopeMonitorRead, models.ScopeMonitorWrite},
+		},
+		{
+			name: "admin bypass",
+			user: addUser(models.RoleAdmin),
+			want: models.GetDefaultScopesForRole(models.RoleAdmin),
+		},
+	} {
+		var got handlers.LoginResponse
+		status, envelope := env.post(t, "/v1/auth/login", map[string]string{"email": tc.user.Email, "password": testPassword}, &got)
+		if status != fiber.StatusOK {
+			t.Fatalf("%s: status = %d, error = %+v", tc.name, status, envelope.Error)
+		}
+		claims, err := env.jwt.ValidateToken(got.AccessToken)
+		if err != nil {
+			t.Fatalf("%s: access token: %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(claims.Scopes, tc.want) {
+			t.Errorf("%s: token scopes = %v, want %v", tc.name, claims.Scopes, tc.want)
+		}
+	}
+}
+
+// /auth/me devolve os scopes efetivos, os mesmos do token: os padrão do role quando o
+// usuário não tem grants e nada fora do teto do tenant
+func TestMeReturnsEffectiveScopes(t *testing.T) {
+	env := newAuthEnv(t)
+	tenant := env.mem.AddTenant(&models.Tenant{Plan: "enterprise", Settings: models.TenantSettings{
+		AllowedScopes: []models.Scope{models.ScopeHuntingRead, models.ScopeMonitorRead, models.ScopeClientsRead},
+	}})
+
+	for _, tc := range []struct {
+		name   string
+		scopes []models.Scope
+		want   []models.Scope
+	}{
+		{"role defaults", nil, []models.Scope{models.ScopeHuntingRead, models.ScopeMonitorRead, models.ScopeClientsRead}},
+		{"grants outside ceiling", []models.Scope{models.ScopeMonitorRead, models.ScopeReportsWrite}, []models.Scope{models.ScopeMonitorRead}},
+	} {
+		user := testutil.NewUser(models.RoleAnalyst)
+		user.TenantID, user.Scopes = tenant.ID, tc.scopes
+		env.mem.AddUser(user)
+
+		resp, err := env.app.Test(testutil.AuthRequest(t, env.jwt, user, fiber.MethodGet, "/v1/auth/me", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got handlers.UserResponse
+		testutil.Decode(t, resp, &got)
+		if resp.StatusCode != fiber.StatusOK || !reflect.DeepEqual(got.Scopes, tc.want) {
+			t.Errorf("%s: status = %d, scopes = %v, want %v", tc.name, resp.StatusCode, got.Scopes, tc.want)
+		}
+	}
+}
+
+// Variações de caixa e espaços do email encontram a mesma conta
+func TestLoginMatchesNormalizedEmailVariants(t *testing.T) {
+	env := newAuthEnv(t)
+	user := testutil.NewUser(models.RoleAnalyst)
+	user.Email = "ana.souza@acme.com"
+	env.mem.AddUser(testutil.SetPassword(t, user, testPassword))
+
+	for _, email := range []string{"ana.souza@acme.com", "Ana.Souza@Acme.com", "ANA.SOUZA@ACME.COM", "  ana.souza@acme.com", "Ana.Souza@ACME.com\t "} {
+		var got handlers.LoginResponse
+		status, envelope := env.post(t, "/v1/auth/login", map[string]string{"email": email, "password": testPassword}, &got)
+		if status != fiber.StatusOK || got.User.ID != user.ID {
+			t.Errorf("login as %q: status = %d, error = %+v", email, status, envelope.Error)
+		}
+	}
+}
@@ -1,33 +1,62 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
 	"github.com/arcaintelligence/arca-gateway/internal/middleware"
 	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/monitoring"
 	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/domain"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/request"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/arcaintelligence/arca-gateway/pkg/slug"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
+// maxBulkBrands limite de marcas por importação em lote
+const maxBulkBrands = 100
+
+// Limites de BrandConfig.ScanFrequencyMins: de 5 minutos a 1 dia
+const (
+	minScanFrequencyMins = 5
+	maxScanFrequencyMins = 1440
+)
+
+// brandAlertChannels canais aceitos em BrandConfig.AlertChannels
+var brandAlertChannels = map[string]bool{"email": true, "slack": true, "webhook": true, "sms": true}
+
 // ClientHandler handlers de clientes
 type ClientHandler struct {
-	clientService *services.ClientService
-	brandService  *services.BrandService
+	clientService ClientStore
+	brandService  BrandStore
+	tenantService TenantStore
+	mcpClient     mcp.Client
+	monitor       *monitoring.Reconciler
 }
 
 // NewClientHandler cria um novo handler de clientes
-func NewClientHandler(clientService *services.ClientService, brandService *services.BrandService) *ClientHandler {
+func NewClientHandler(clientService ClientStore, brandService BrandStore, tenantService TenantStore, mcpClient mcp.Client, monitor *monitoring.Reconciler) *ClientHandler {
 	return &ClientHandler{
 		clientService: clientService,
 		brandService:  brandService,
+		tenantService: tenantService,
+		mcpClient:     mcpClient,
+		monitor:       monitor,
 	}
 }
 
 // CreateClientRequest request para criar cliente
 type CreateClientRequest struct {
-	Name        string                `json:"name"`
+	Name        string                `json:"name" validate:"required,max=200"`
 	Description string                `json:"description,omitempty"`
 	Industry    string                `json:"industry,omitempty"`
 	Settings    models.ClientSettings `json:"settings,omitempty"`
@@ -35,11 +64,93 @@ type CreateClientRequest struct {
 
 // CreateBrandRequest request para criar marca
 type CreateBrandRequest struct {
-	Name          string             `json:"name"`
-	PrimaryDomain string             `json:"primary_domain"`
+	Name          string             `json:"name" validate:"required,max=200"`
+	PrimaryDomain string             `json:"primary_domain" validate:"required"`
 	Config        models.BrandConfig `json:"config,omitempty"`
 }
 
+// UpdateClientRequest request para substituir cliente (PUT). Sem settings, as
+// configurações atuais são mantidas.
+type UpdateClientRequest struct {
+	Name        string                 `json:"name" validate:"required,max=200"`
+	Description string                 `json:"description,omitempty"`
+	Industry    string                 `json:"industry,omitempty"`
+	Settings    *models.ClientSettings `json:"settings,omitempty"`
+	// Versão lida pelo cliente (updated_at); alternativa ao header If-Unmodified-Since
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateBrandRequest request para substituir marca (PUT). Sem config, a
+// configuração atual é mantida.
+type UpdateBrandRequest struct {
+	Name          string              `json:"name" validate:"required,max=200"`
+	PrimaryDomain string              `json:"primary_domain" validate:"required"`
+	Config        *models.BrandConfig `json:"config,omitempty"`
+	// Versão lida pelo cliente (updated_at); alternativa ao header If-Unmodified-Since
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PatchClientRequest request para atualização parcial de cliente: campos
+// ausentes (nil) são mantidos
+type PatchClientRequest struct {
+	Name        *string              `json:"name,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Industry    *string              `json:"industry,omitempty"`
+	Settings    *ClientSettingsPatch `json:"settings,omitempty"`
+	// Versão lida pelo cliente (updated_at); alternativa ao header If-Unmodified-Since
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ClientSettingsPatch atualização parcial de models.ClientSettings
+type ClientSettingsPatch struct {
+	AlertEmail       *string   `json:"alert_email,omitempty"`
+	AlertWebhook     *string   `json:"alert_webhook,omitempty"`
+	ScanFrequency    *string   `json:"scan_frequency,omitempty"`
+	Priority         *string   `json:"priority,omitempty"`
+	AutoTakedown     *bool     `json:"auto_takedown,omitempty"`
+	WhitelistDomains *[]string `json:"whitelist_domains,omitempty"`
+}
+
+// PatchBrandRequest request para atualização parcial de marca: campos
+// ausentes (nil) são mantidos
+type PatchBrandRequest struct {
+	Name          *string           `json:"name,omitempty"`
+	PrimaryDomain *string           `json:"primary_domain,omitempty"`
+	Config        *BrandConfigPatch `json:"config,omitempty"`
+	// Versão lida pelo cliente (updated_at); alternativa ao header If-Unmodified-Since
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// BrandConfigPatch atualização parcial de models.BrandConfig
+type BrandConfigPatch struct {
+	AdditionalDomains  *[]string `json:"additional_domains,omitempty"`
+	KnownVariations    *[]string `json:"known_variations,omitempty"`
+	Keywords           *[]string `json:"keywords,omitempty"`
+	ScanFrequencyMins  *int      `json:"scan_frequency_mins,omitempty"`
+	EnableLeakSearch   *bool     `json:"enable_leak_search,omitempty"`
+	EnableDomainWatch  *bool     `json:"enable_domain_watch,omitempty"`
+	EnableDeepAnalysis *bool     `json:"enable_deep_analysis,omitempty"`
+	AlertSeverityMin   *string   `json:"alert_severity_min,omitempty"`
+	AlertChannels      *[]string `json:"alert_channels,omitempty"`
+	WhitelistDomains   *[]string `json:"whitelist_domains,omitempty"`
+	WhitelistIPs       *[]string `json:"whitelist_ips,omitempty"`
+}
+
+// BulkBrandResult resultado de um item da importação em lote
+type BulkBrandResult struct {
+	Index   int            `json:"index"`
+	Success bool           `json:"success"`
+	Brand   *BrandResponse `json:"brand,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// BulkBrandResponse response da importação em lote
+type BulkBrandResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BulkBrandResult `json:"results"`
+}
+
 // ClientResponse response de cliente
 type ClientResponse struct {
 	ID          uuid.UUID             `json:"id"`
@@ -82,17 +193,38 @@ func (h *ClientHandler) ListClients(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	page := c.QueryInt("page", 1)
-	perPage := c.QueryInt("per_page", 20)
+	page, perPage := response.ParsePagination(c)
+	after, byCursor, err := parseCursor(c)
+	if err != nil {
+		return err
+	}
 
-	clients, total, err := h.clientService.ListByTenant(c.Context(), tenantID, page, perPage)
+	var (
+		clients []*models.Client
+		total   int64
+		more    bool
+	)
+	if byCursor {
+		clients, more, err = h.clientService.ListByTenantAfter(c.Context(), tenantID, after, perPage)
+	} else {
+		clients, total, err = h.clientService.ListByTenant(c.Context(), tenantID, page, perPage)
+	}
 	if err != nil {
-		return response.InternalServerError(c, "Failed to list clients")
+		return handleStoreError(c, err, "Failed to list clients")
+	}
+
+	// Contagem de marcas da página em uma consulta; sem ela os clientes saem com 0
+	clientIDs := make([]uuid.UUID, len(clients))
+	for i, client := range clients {
+		clientIDs[i] = client.ID
+	}
+	brandsCounts, err := h.brandService.CountByClients(c.Context(), clientIDs)
+	if err != nil {
+		logger.FromContext(c).WithError(err).Warn("failed to count client brands")
 	}
 
 	clientResponses := make([]ClientResponse, len(clients))
 	for i, client := range clients {
-		brandsCount, _ := h.brandService.CountByClient(c.Context(), client.ID)
 		clientResponses[i] = ClientResponse{
 			ID:          client.ID,
 			TenantID:    client.TenantID,
@@ -102,31 +234,39 @@ func (h *ClientHandler) ListClients(c *fiber.Ctx) error {
 			Industry:    client.Industry,
 			Status:      client.Status,
 			Settings:    client.Settings,
-			BrandsCount: brandsCount,
+			BrandsCount: brandsCounts[client.ID],
 			CreatedAt:   client.CreatedAt,
 			UpdatedAt:   client.UpdatedAt,
 		}
 	}
 
-	return response.Paginated(c, clientResponses, page, perPage, total)
+	if byCursor {
+		var next string
+		if more {
+			last := clients[len(clients)-1]
+			next = response.EncodeCursor(last.CreatedAt, last.ID)
+		}
+		return response.CursorPaginated(c, clientResponses, perPage, next)
+	}
+	return response.PaginatedWithLinks(c, clientResponses, page, perPage, total)
 }
 
 // GetClient retorna um cliente específico
 func (h *ClientHandler) GetClient(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	clientID, err := uuid.Parse(c.Params("client_id"))
+	clientID, err := response.ParseUUIDParam(c, "client_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid client ID")
+		return err
 	}
 
 	client, err := h.clientService.GetByID(c.Context(), clientID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Client not found")
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
 	}
 
 	brandsCount, _ := h.brandService.CountByClient(c.Context(), client.ID)
 
-	return response.Success(c, ClientResponse{
+	return successWithETag(c, ClientResponse{
 		ID:          client.ID,
 		TenantID:    client.TenantID,
 		Name:        client.Name,
@@ -148,28 +288,24 @@ func (h *ClientHandler) CreateClient(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	var req CreateClientRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
+	req, err := request.BindAndValidate[CreateClientRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
 
-	if req.Name == "" {
-		return response.BadRequest(c, "Name is required")
-	}
+	applyClientDefaults(&req.Settings)
 
-	// Configurações padrão
-	if req.Settings.ScanFrequency == "" {
-		req.Settings.ScanFrequency = "daily"
-	}
-	if req.Settings.Priority == "" {
-		req.Settings.Priority = "medium"
+	clientID := uuid.New()
+	clientSlug, err := slug.Unique(c.Context(), req.Name, h.slugExists(tenantID, clientID))
+	if err != nil {
+		return handleStoreError(c, err, "Failed to generate client slug")
 	}
 
 	client := &models.Client{
-		ID:          uuid.New(),
+		ID:          clientID,
 		TenantID:    tenantID,
 		Name:        req.Name,
-		Slug:        generateSlug(req.Name),
+		Slug:        clientSlug,
 		Description: req.Description,
 		Industry:    req.Industry,
 		Status:      models.StatusActive,
@@ -179,7 +315,7 @@ func (h *ClientHandler) CreateClient(c *fiber.Ctx) error {
 	}
 
 	if err := h.clientService.Create(c.Context(), client); err != nil {
-		return response.InternalServerError(c, "Failed to create client")
+		return handleStoreError(c, err, "Failed to create client")
 	}
 
 	return response.Created(c, ClientResponse{
@@ -197,39 +333,109 @@ func (h *ClientHandler) CreateClient(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateClient atualiza um cliente
+// UpdateClient substitui um cliente (PUT)
 func (h *ClientHandler) UpdateClient(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	clientID, err := uuid.Parse(c.Params("client_id"))
+	clientID, err := response.ParseUUIDParam(c, "client_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid client ID")
+		return err
 	}
 
 	client, err := h.clientService.GetByID(c.Context(), clientID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Client not found")
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
+	}
+
+	req, err := request.BindAndValidate[UpdateClientRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
+	}
+	since, err := unmodifiedSince(c, req.UpdatedAt)
+	if err != nil {
+		return response.BadRequest(c, "Invalid If-Unmodified-Since header")
+	}
+
+	if err := h.renameClient(c.Context(), client, req.Name); err != nil {
+		return handleStoreError(c, err, "Failed to generate client slug")
+	}
+	client.Description = req.Description
+	client.Industry = req.Industry
+	if req.Settings != nil {
+		client.Settings = replaceClientSettings(client.Settings, *req.Settings)
+	}
+
+	return h.saveClient(c, client, since)
+}
+
+// PatchClient atualiza parcialmente um cliente (PATCH): só os campos enviados são alterados
+func (h *ClientHandler) PatchClient(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	clientID, err := response.ParseUUIDParam(c, "client_id")
+	if err != nil {
+		return err
 	}
 
-	var req CreateClientRequest
+	client, err := h.clientService.GetByID(c.Context(), clientID, tenantID)
+	if err != nil {
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
+	}
+
+	var req PatchClientRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
+	since, err := unmodifiedSince(c, req.UpdatedAt)
+	if err != nil {
+		return response.BadRequest(c, "Invalid If-Unmodified-Since header")
+	}
 
-	if req.Name != "" {
-		client.Name = req.Name
-		client.Slug = generateSlug(req.Name)
+	if req.Name != nil {
+		if *req.Name == "" {
+			return response.UnprocessableEntity(c, "Name cannot be empty")
+		}
+		if err := h.renameClient(c.Context(), client, *req.Name); err != nil {
+			return handleStoreError(c, err, "Failed to generate client slug")
+		}
 	}
-	if req.Description != "" {
-		client.Description = req.Description
+	if req.Description != nil {
+		client.Description = *req.Description
 	}
-	if req.Industry != "" {
-		client.Industry = req.Industry
+	if req.Industry != nil {
+		client.Industry = *req.Industry
+	}
+	if req.Settings != nil {
+		req.Settings.applyTo(&client.Settings)
+		applyClientDefaults(&client.Settings)
 	}
-	client.Settings = req.Settings
-	client.UpdatedAt = time.Now()
 
-	if err := h.clientService.Update(c.Context(), client); err != nil {
-		return response.InternalServerError(c, "Failed to update client")
+	return h.saveClient(c, client, since)
+}
+
+// renameClient troca o nome do cliente, gerando um novo slug se o nome mudou
+func (h *ClientHandler) renameClient(ctx context.Context, client *models.Client, name string) error {
+	if name == client.Name {
+		return nil
+	}
+	clientSlug, err := slug.Unique(ctx, name, h.slugExists(client.TenantID, client.ID))
+	if err != nil {
+		return err
+	}
+	client.Name = name
+	client.Slug = clientSlug
+	return nil
+}
+
+// saveClient persiste as alterações do cliente e responde com a versão atualizada.
+// Com unmodifiedSince, retorna 409 se o cliente foi alterado depois dessa versão.
+func (h *ClientHandler) saveClient(c *fiber.Ctx, client *models.Client, unmodifiedSince *time.Time) error {
+	if err := h.clientService.Update(c.Context(), client, unmodifiedSince); err != nil {
+		switch {
+		case errors.Is(err, services.ErrStaleVersion):
+			return response.Fail(c, response.CodeVersionConflict, "Client was modified by another request; reload it and retry")
+		case errors.Is(err, services.ErrNotFound):
+			return response.NotFound(c, "Client not found")
+		}
+		return handleStoreError(c, err, "Failed to update client")
 	}
 
 	brandsCount, _ := h.brandService.CountByClient(c.Context(), client.ID)
@@ -252,18 +458,47 @@ func (h *ClientHandler) UpdateClient(c *fiber.Ctx) error {
 // DeleteClient remove um cliente
 func (h *ClientHandler) DeleteClient(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	clientID, err := uuid.Parse(c.Params("client_id"))
+	clientID, err := response.ParseUUIDParam(c, "client_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid client ID")
+		return err
 	}
 
 	if err := h.clientService.Delete(c.Context(), clientID, tenantID); err != nil {
-		return response.InternalServerError(c, "Failed to delete client")
+		return handleStoreError(c, err, "Failed to delete client")
 	}
 
 	return response.NoContent(c)
 }
 
+// slugExists verifica colisões de slug entre os clientes do tenant, ignorando o próprio cliente
+func (h *ClientHandler) slugExists(tenantID, clientID uuid.UUID) slug.ExistsFunc {
+	return func(ctx context.Context, s string) (bool, error) {
+		return h.clientService.SlugExists(ctx, tenantID, s, clientID)
+	}
+}
+
+// parseCursor lê o parâmetro cursor das listagens, alternativa a page: byCursor indica
+// a paginação por cursor e cursor vazio (?cursor=) pede a primeira página
+func parseCursor(c *fiber.Ctx) (after services.Keyset, byCursor bool, err error) {
+	args := c.Request().URI().QueryArgs()
+	if !args.Has("cursor") {
+		return services.Keyset{}, false, nil
+	}
+	if args.Has("page") {
+		return services.Keyset{}, false, fiber.NewError(fiber.StatusBadRequest, "Use either page or cursor, not both")
+	}
+
+	token := c.Query("cursor")
+	if token == "" {
+		return services.Keyset{}, true, nil
+	}
+	createdAt, id, err := response.DecodeCursor(token)
+	if err != nil {
+		return services.Keyset{}, false, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor")
+	}
+	return services.Keyset{CreatedAt: createdAt, ID: id}, true, nil
+}
+
 // =============================================================================
 // BRAND HANDLERS
 // =============================================================================
@@ -271,17 +506,29 @@ func (h *ClientHandler) DeleteClient(c *fiber.Ctx) error {
 // ListBrands lista todas as marcas de um cliente
 func (h *ClientHandler) ListBrands(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	clientID, err := uuid.Parse(c.Params("client_id"))
+	clientID, err := response.ParseUUIDParam(c, "client_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid client ID")
+		return err
 	}
 
-	page := c.QueryInt("page", 1)
-	perPage := c.QueryInt("per_page", 20)
+	page, perPage := response.ParsePagination(c)
+	after, byCursor, err := parseCursor(c)
+	if err != nil {
+		return err
+	}
 
-	brands, total, err := h.brandService.ListByClient(c.Context(), clientID, tenantID, page, perPage)
+	var (
+		brands []*models.Brand
+		total  int64
+		more   bool
+	)
+	if byCursor {
+		brands, more, err = h.brandService.ListByClientAfter(c.Context(), clientID, tenantID, after, perPage)
+	} else {
+		brands, total, err = h.brandService.ListByClient(c.Context(), clientID, tenantID, page, perPage)
+	}
 	if err != nil {
-		return response.InternalServerError(c, "Failed to list brands")
+		return handleStoreError(c, err, "Failed to list brands")
 	}
 
 	brandResponses := make([]BrandResponse, len(brands))
@@ -302,23 +549,31 @@ func (h *ClientHandler) ListBrands(c *fiber.Ctx) error {
 		}
 	}
 
-	return response.Paginated(c, brandResponses, page, perPage, total)
+	if byCursor {
+		var next string
+		if more {
+			last := brands[len(brands)-1]
+			next = response.EncodeCursor(last.CreatedAt, last.ID)
+		}
+		return response.CursorPaginated(c, brandResponses, perPage, next)
+	}
+	return response.PaginatedWithLinks(c, brandResponses, page, perPage, total)
 }
 
 // GetBrand retorna uma marca específica
 func (h *ClientHandler) GetBrand(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	brandID, err := uuid.Parse(c.Params("brand_id"))
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand ID")
+		return err
 	}
 
 	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Brand not found")
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
 	}
 
-	return response.Success(c, BrandResponse{
+	return successWithETag(c, BrandResponse{
 		ID:              brand.ID,
 		ClientID:        brand.ClientID,
 		TenantID:        brand.TenantID,
@@ -337,35 +592,32 @@ func (h *ClientHandler) GetBrand(c *fiber.Ctx) error {
 // CreateBrand cria uma nova marca e inicia monitoramento
 func (h *ClientHandler) CreateBrand(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	clientID, err := uuid.Parse(c.Params("client_id"))
+	clientID, err := response.ParseUUIDParam(c, "client_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid client ID")
+		return err
 	}
 
 	// Verificar se cliente existe
 	_, err = h.clientService.GetByID(c.Context(), clientID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Client not found")
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
 	}
 
-	var req CreateBrandRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body")
+	req, err := request.BindAndValidate[CreateBrandRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
-
-	if req.Name == "" || req.PrimaryDomain == "" {
-		return response.BadRequest(c, "Name and primary_domain are required")
+	if err := normalizeBrandDomains(&req.PrimaryDomain, &req.Config); err != nil {
+		return response.BadRequest(c, err.Error())
 	}
 
-	// Configurações padrão
-	if req.Config.ScanFrequencyMins == 0 {
-		req.Config.ScanFrequencyMins = 60 // 1 hora
-	}
-	if req.Config.AlertSeverityMin == "" {
-		req.Config.AlertSeverityMin = "medium"
+	applyBrandDefaults(&req.Config)
+	configErrors, err := h.brandConfigErrors(c.Context(), tenantID, req.Config)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to load tenant settings")
 	}
-	if len(req.Config.AlertChannels) == 0 {
-		req.Config.AlertChannels = []string{"email"}
+	if len(configErrors) > 0 {
+		return response.ValidationErrors(c, configErrors)
 	}
 
 	brand := &models.Brand{
@@ -382,7 +634,7 @@ func (h *ClientHandler) CreateBrand(c *fiber.Ctx) error {
 	}
 
 	if err := h.brandService.Create(c.Context(), brand); err != nil {
-		return response.InternalServerError(c, "Failed to create brand")
+		return handleStoreError(c, err, "Failed to create brand")
 	}
 
 	// TODO: Iniciar job de monitoramento automaticamente
@@ -405,35 +657,271 @@ func (h *ClientHandler) CreateBrand(c *fiber.Ctx) error {
 	})
 }
 
-// UpdateBrand atualiza uma marca
+// BulkCreateBrands importa até maxBulkBrands marcas de uma vez. Por padrão é
+// tudo-ou-nada; com ?allow_partial=true os itens inválidos ou que falharem são
+// reportados e os demais são criados.
+func (h *ClientHandler) BulkCreateBrands(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	clientID, err := response.ParseUUIDParam(c, "client_id")
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.clientService.GetByID(c.Context(), clientID, tenantID); err != nil {
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
+	}
+
+	var reqs []CreateBrandRequest
+	if err := c.BodyParser(&reqs); err != nil {
+		return response.BadRequest(c, "Invalid request body: expected an array of brands")
+	}
+
+	if len(reqs) == 0 {
+		return response.UnprocessableEntity(c, "At least one brand is required")
+	}
+	if len(reqs) > maxBulkBrands {
+		return response.UnprocessableEntity(c, fmt.Sprintf("At most %d brands per import", maxBulkBrands))
+	}
+
+	allowPartial := c.QueryBool("allow_partial")
+
+	configs := make([]models.BrandConfig, len(reqs))
+	for i, req := range reqs {
+		configs[i] = req.Config
+		applyBrandDefaults(&configs[i])
+	}
+	slackConfigured, err := h.slackConfigured(c.Context(), tenantID, configs...)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to load tenant settings")
+	}
+
+	// Validar itens: campos obrigatórios, domínios repetidos no lote e config
+	results := make([]BulkBrandResult, len(reqs))
+	var validationErrors []response.ValidationError
+	seen := make(map[string]int)
+	for i, req := range reqs {
+		results[i].Index = i
+
+		domainErr := normalizeBrandDomains(&reqs[i].PrimaryDomain, &reqs[i].Config)
+		primaryDomain := reqs[i].PrimaryDomain
+		configErrors := validateBrandConfig(configs[i], slackConfigured, "config.")
+		switch {
+		case req.Name == "" || strings.TrimSpace(req.PrimaryDomain) == "":
+			results[i].Error = "name and primary_domain are required"
+		case domainErr != nil:
+			results[i].Error = domainErr.Error()
+		case seen[primaryDomain] > 0:
+			results[i].Error = fmt.Sprintf("duplicate primary_domain (same as item %d)", seen[primaryDomain]-1)
+		case len(configErrors) > 0:
+			results[i].Error = configErrors[0].Field + " " + configErrors[0].Message
+		default:
+			seen[primaryDomain] = i + 1
+			continue
+		}
+		validationErrors = append(validationErrors, response.ValidationError{
+			Field:   fmt.Sprintf("[%d]", i),
+			Message: results[i].Error,
+		})
+	}
+
+	if len(validationErrors) > 0 && !allowPartial {
+		return response.ValidationErrors(c, validationErrors)
+	}
+
+	// Montar as marcas válidas, mantendo o índice original de cada uma
+	now := time.Now()
+	var brands []*models.Brand
+	var indexes []int
+	for i, req := range reqs {
+		if results[i].Error != "" {
+			continue
+		}
+
+		applyBrandDefaults(&req.Config)
+		brands = append(brands, &models.Brand{
+			ID:            uuid.New(),
+			ClientID:      clientID,
+			TenantID:      tenantID,
+			Name:          req.Name,
+			PrimaryDomain: req.PrimaryDomain,
+			Status:        models.StatusActive,
+			Config:        req.Config,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		indexes = append(indexes, i)
+	}
+
+	policy, err := h.tenantService.GetPolicy(c.Context(), tenantID)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to load tenant quotas")
+	}
+
+	var itemErrors []error
+	if len(brands) > 0 {
+		itemErrors, err = h.brandService.CreateMany(c.Context(), tenantID, brands, policy.Quotas.MaxBrands, allowPartial)
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			return response.Fail(c, response.CodeQuotaExceeded,
+				fmt.Sprintf("Import would exceed the tenant brand quota (%d)", policy.Quotas.MaxBrands))
+		}
+		if err != nil {
+			return handleStoreError(c, err, "Failed to import brands; no brands were created")
+		}
+	}
+
+	bulk := BulkBrandResponse{Results: results}
+	for j, brand := range brands {
+		result := &bulk.Results[indexes[j]]
+		switch {
+		case errors.Is(itemErrors[j], services.ErrQuotaExceeded):
+			result.Error = "tenant brand quota exceeded"
+		case itemErrors[j] != nil:
+			result.Error = "failed to create brand"
+		default:
+			result.Success = true
+			result.Brand = &BrandResponse{
+				ID:            brand.ID,
+				ClientID:      brand.ClientID,
+				TenantID:      brand.TenantID,
+				Name:          brand.Name,
+				PrimaryDomain: brand.PrimaryDomain,
+				Status:        brand.Status,
+				Config:        brand.Config,
+				CreatedAt:     brand.CreatedAt,
+				UpdatedAt:     brand.UpdatedAt,
+			}
+		}
+	}
+	for _, result := range bulk.Results {
+		if result.Success {
+			bulk.Created++
+		} else {
+			bulk.Failed++
+		}
+	}
+
+	// Importação parcial sem nenhuma marca criada: os motivos estão em results
+	if bulk.Created == 0 {
+		return response.Success(c, bulk)
+	}
+	return response.Created(c, bulk)
+}
+
+// UpdateBrand substitui uma marca (PUT)
 func (h *ClientHandler) UpdateBrand(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	brandID, err := uuid.Parse(c.Params("brand_id"))
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
+	if err != nil {
+		return err
+	}
+
+	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
+	if err != nil {
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
+	}
+
+	req, err := request.BindAndValidate[UpdateBrandRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
+	}
+	since, err := unmodifiedSince(c, req.UpdatedAt)
+	if err != nil {
+		return response.BadRequest(c, "Invalid If-Unmodified-Since header")
+	}
+
+	primaryDomain, err := domain.Normalize(req.PrimaryDomain)
+	if err != nil {
+		return response.BadRequest(c, "primary_domain: "+err.Error())
+	}
+	if req.Config != nil {
+		if err := normalizeConfigDomains(req.Config); err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+	}
+
+	brand.Name = req.Name
+	brand.PrimaryDomain = primaryDomain
+	if req.Config != nil {
+		brand.Config = replaceBrandConfig(brand.Config, *req.Config)
+		configErrors, err := h.brandConfigErrors(c.Context(), tenantID, brand.Config)
+		if err != nil {
+			return handleStoreError(c, err, "Failed to load tenant settings")
+		}
+		if len(configErrors) > 0 {
+			return response.ValidationErrors(c, configErrors)
+		}
+	}
+
+	return h.saveBrand(c, brand, since)
+}
+
+// PatchBrand atualiza parcialmente uma marca (PATCH): só os campos enviados são alterados
+func (h *ClientHandler) PatchBrand(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand ID")
+		return err
 	}
 
 	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Brand not found")
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
 	}
 
-	var req CreateBrandRequest
+	var req PatchBrandRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
+	since, err := unmodifiedSince(c, req.UpdatedAt)
+	if err != nil {
+		return response.BadRequest(c, "Invalid If-Unmodified-Since header")
+	}
 
-	if req.Name != "" {
-		brand.Name = req.Name
+	if req.Name != nil {
+		if *req.Name == "" {
+			return response.UnprocessableEntity(c, "Name cannot be empty")
+		}
+		brand.Name = *req.Name
 	}
-	if req.PrimaryDomain != "" {
-		brand.PrimaryDomain = req.PrimaryDomain
+	if req.PrimaryDomain != nil {
+		if *req.PrimaryDomain == "" {
+			return response.UnprocessableEntity(c, "primary_domain cannot be empty")
+		}
+		primaryDomain, err := domain.Normalize(*req.PrimaryDomain)
+		if err != nil {
+			return response.BadRequest(c, "primary_domain: "+err.Error())
+		}
+		brand.PrimaryDomain = primaryDomain
 	}
-	brand.Config = req.Config
-	brand.UpdatedAt = time.Now()
+	if req.Config != nil {
+		if err := req.Config.normalizeDomains(); err != nil {
+			return response.BadRequest(c, err.Error())
+		}
+		req.Config.applyTo(&brand.Config)
+		applyBrandDefaults(&brand.Config)
+		configErrors, err := h.brandConfigErrors(c.Context(), tenantID, brand.Config)
+		if err != nil {
+			return handleStoreError(c, err, "Failed to load tenant settings")
+		}
+		if len(configErrors) > 0 {
+			return response.ValidationErrors(c, configErrors)
+		}
+	}
+
+	return h.saveBrand(c, brand, since)
+}
 
-	if err := h.brandService.Update(c.Context(), brand); err != nil {
-		return response.InternalServerError(c, "Failed to update brand")
+// saveBrand persiste as alterações da marca e responde com a versão atualizada.
+// Com unmodifiedSince, retorna 409 se a marca foi alterada depois dessa versão.
+func (h *ClientHandler) saveBrand(c *fiber.Ctx, brand *models.Brand, unmodifiedSince *time.Time) error {
+	if err := h.brandService.Update(c.Context(), brand, unmodifiedSince); err != nil {
+		switch {
+		case errors.Is(err, services.ErrStaleVersion):
+			return response.Fail(c, response.CodeVersionConflict, "Brand was modified by another request; reload it and retry")
+		case errors.Is(err, services.ErrNotFound):
+			return response.NotFound(c, "Brand not found")
+		}
+		return handleStoreError(c, err, "Failed to update brand")
 	}
 
 	return response.Success(c, BrandResponse{
@@ -455,80 +943,397 @@ func (h *ClientHandler) UpdateBrand(c *fiber.Ctx) error {
 // DeleteBrand remove uma marca
 func (h *ClientHandler) DeleteBrand(c *fiber.Ctx) error {
 	tenantID := middleware.GetTenantID(c)
-	brandID, err := uuid.Parse(c.Params("brand_id"))
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand ID")
+		return err
 	}
 
 	// TODO: Parar job de monitoramento antes de deletar
 
 	if err := h.brandService.Delete(c.Context(), brandID, tenantID); err != nil {
-		return response.InternalServerError(c, "Failed to delete brand")
+		return handleStoreError(c, err, "Failed to delete brand")
 	}
 
 	return response.NoContent(c)
 }
 
-// StartMonitoring inicia o monitoramento de uma marca
+// StartMonitoring cria o job de monitoramento da marca no MCP
 func (h *ClientHandler) StartMonitoring(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	tenantID := middleware.GetTenantID(c)
-	brandID, err := uuid.Parse(c.Params("brand_id"))
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand ID")
+		return err
 	}
 
 	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Brand not found")
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
 	}
 
 	if brand.MonitoringJobID != nil {
-		return response.Conflict(c, "Monitoring already running")
+		// O job gravado pode já ter terminado no MCP
+		if _, err := h.monitor.Reconcile(c.Context(), brand); err != nil {
+			return handleReconcileError(c, err)
+		}
+		if brand.MonitoringJobID != nil {
+			return response.Conflict(c, "Monitoring already running")
+		}
+	}
+
+	intervalMins := brand.Config.ScanFrequencyMins
+	if intervalMins == 0 {
+		intervalMins = 60
 	}
 
-	// TODO: Chamar MCP para iniciar monitoramento
-	jobID := uuid.New()
-	brand.MonitoringJobID = &jobID
+	mcpReq := &mcp.MCPRequest{
+		RequestID: response.RequestID(c),
+		TenantID:  tenantID,
+		ClientID:  &brand.ClientID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
+	}
+
+	job, err := h.mcpClient.CreateMonitorJob(c.Context(), mcpReq, &mcp.MonitorJobRequest{
+		BrandID:       brand.ID,
+		Target:        brand.PrimaryDomain,
+		IntervalMins:  intervalMins,
+		EnabledChecks: []string{"phishing", "domain", "ssl"},
+	})
+	if err != nil {
+		return handleMCPError(c, err)
+	}
+
+	brand.MonitoringJobID = &job.JobID
 	brand.Status = models.StatusActive
-	brand.UpdatedAt = time.Now()
 
-	if err := h.brandService.Update(c.Context(), brand); err != nil {
-		return response.InternalServerError(c, "Failed to start monitoring")
+	if err := h.brandService.UpdateMonitoring(c.Context(), brand); err != nil {
+		// Sem o job_id gravado o job ficaria órfão no MCP
+		if stopErr := h.mcpClient.StopMonitorJob(c.Context(), mcpReq, job.JobID); stopErr != nil {
+			logger.FromContext(c).WithError(stopErr).WithField("job_id", job.JobID.String()).Error("failed to stop orphaned monitor job")
+		}
+		return handleStoreError(c, err, "Failed to start monitoring")
 	}
 
 	return response.Success(c, fiber.Map{
 		"message": "Monitoring started",
-		"job_id":  jobID,
+		"job_id":  job.JobID,
 	})
 }
 
-// StopMonitoring para o monitoramento de uma marca
+// StopMonitoring para o job de monitoramento da marca no MCP. Um job que o MCP não
+// conhece mais é tratado como já parado.
 func (h *ClientHandler) StopMonitoring(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	tenantID := middleware.GetTenantID(c)
-	brandID, err := uuid.Parse(c.Params("brand_id"))
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand ID")
+		return err
 	}
 
 	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
 	if err != nil {
-		return response.NotFound(c, "Brand not found")
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
 	}
 
 	if brand.MonitoringJobID == nil {
 		return response.BadRequest(c, "Monitoring not running")
 	}
 
-	// TODO: Chamar MCP para parar monitoramento
+	mcpReq := &mcp.MCPRequest{
+		RequestID: response.RequestID(c),
+		TenantID:  tenantID,
+		ClientID:  &brand.ClientID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
+	}
+
+	if err := h.mcpClient.StopMonitorJob(c.Context(), mcpReq, *brand.MonitoringJobID); err != nil && !errors.Is(err, mcp.ErrMCPNotFound) {
+		return handleMCPError(c, err)
+	}
+
 	brand.MonitoringJobID = nil
 	brand.Status = models.StatusInactive
-	brand.UpdatedAt = time.Now()
 
-	if err := h.brandService.Update(c.Context(), brand); err != nil {
-		return response.InternalServerError(c, "Failed to stop monitoring")
+	if err := h.brandService.UpdateMonitoring(c.Context(), brand); err != nil {
+		return handleStoreError(c, err, "Failed to stop monitoring")
 	}
 
 	return response.Success(c, fiber.Map{
 		"message": "Monitoring stopped",
 	})
 }
+
+// GetMonitoringStatus retorna o estado de monitoramento da marca após reconciliá-lo
+// com o job no MCP; campos divergentes são corrigidos e listados em drift
+func (h *ClientHandler) GetMonitoringStatus(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	brandID, err := response.ParseUUIDParam(c, "brand_id")
+	if err != nil {
+		return err
+	}
+
+	brand, err := h.brandService.GetByID(c.Context(), brandID, tenantID)
+	if err != nil {
+		return handleServiceError(c, err, "Brand not found", "Failed to load brand")
+	}
+
+	result, err := h.monitor.Reconcile(c.Context(), brand)
+	if err != nil {
+		return handleReconcileError(c, err)
+	}
+
+	return response.Success(c, result)
+}
+
+// handleReconcileError responde a uma falha de monitoring.Reconciler.Reconcile
+func handleReconcileError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, monitoring.ErrStateNotSaved) {
+		return handleStoreError(c, err, "Failed to update monitoring state")
+	}
+	return handleMCPError(c, err)
+}
+
+// unmodifiedSince retorna a versão esperada para a atualização: o updated_at do corpo
+// ou, na falta dele, o header If-Unmodified-Since. O header tem precisão de segundos,
+// então qualquer alteração dentro do segundo informado ainda é aceita. nil = sem verificação.
+func unmodifiedSince(c *fiber.Ctx, version *time.Time) (*time.Time, error) {
+	if version != nil {
+		return version, nil
+	}
+	header := c.Get(fiber.HeaderIfUnmodifiedSince)
+	if header == "" {
+		return nil, nil
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return nil, err
+	}
+	since = since.Add(time.Second - time.Microsecond)
+	return &since, nil
+}
+
+// applyClientDefaults preenche as configurações do cliente não informadas
+func applyClientDefaults(settings *models.ClientSettings) {
+	if settings.ScanFrequency == "" {
+		settings.ScanFrequency = "daily"
+	}
+	if settings.Priority == "" {
+		settings.Priority = "medium"
+	}
+}
+
+// replaceClientSettings substitui as configurações do cliente, mantendo os
+// valores atuais de frequência de scan e prioridade quando não informados
+func replaceClientSettings(current, next models.ClientSettings) models.ClientSettings {
+	if next.ScanFrequency == "" {
+		next.ScanFrequency = current.ScanFrequency
+	}
+	if next.Priority == "" {
+		next.Priority = current.Priority
+	}
+	applyClientDefaults(&next)
+	return next
+}
+
+// applyTo copia para settings apenas os campos informados no patch
+func (p *ClientSettingsPatch) applyTo(settings *models.ClientSettings) {
+	if p.AlertEmail != nil {
+		settings.AlertEmail = *p.AlertEmail
+	}
+	if p.AlertWebhook != nil {
+		settings.AlertWebhook = *p.AlertWebhook
+	}
+	if p.ScanFrequency != nil {
+		settings.ScanFrequency = *p.ScanFrequency
+	}
+	if p.Priority != nil {
+		settings.Priority = *p.Priority
+	}
+	if p.AutoTakedown != nil {
+		settings.AutoTakedown = *p.AutoTakedown
+	}
+	if p.WhitelistDomains != nil {
+		settings.WhitelistDomains = *p.WhitelistDomains
+	}
+}
+
+// replaceBrandConfig substitui a configuração da marca, mantendo os valores
+// atuais de frequência de scan, severidade mínima e canais de alerta quando
+// não informados: zerá-los desligaria o monitoramento e os alertas
+func replaceBrandConfig(current, next models.BrandConfig) models.BrandConfig {
+	if next.ScanFrequencyMins == 0 {
+		next.ScanFrequencyMins = current.ScanFrequencyMins
+	}
+	if next.AlertSeverityMin == "" {
+		next.AlertSeverityMin = current.AlertSeverityMin
+	}
+	if len(next.AlertChannels) == 0 {
+		next.AlertChannels = current.AlertChannels
+	}
+	applyBrandDefaults(&next)
+	return next
+}
+
+// applyTo copia para config apenas os campos informados no patch
+func (p *BrandConfigPatch) applyTo(config *models.BrandConfig) {
+	if p.AdditionalDomains != nil {
+		config.AdditionalDomains = *p.AdditionalDomains
+	}
+	if p.KnownVariations != nil {
+		config.KnownVariations = *p.KnownVariations
+	}
+	if p.Keywords != nil {
+		config.Keywords = *p.Keywords
+	}
+	if p.ScanFrequencyMins != nil {
+		config.ScanFrequencyMins = *p.ScanFrequencyMins
+	}
+	if p.EnableLeakSearch != nil {
+		config.EnableLeakSearch = *p.EnableLeakSearch
+	}
+	if p.EnableDomainWatch != nil {
+		config.EnableDomainWatch = *p.EnableDomainWatch
+	}
+	if p.EnableDeepAnalysis != nil {
+		config.EnableDeepAnalysis = *p.EnableDeepAnalysis
+	}
+	if p.AlertSeverityMin != nil {
+		config.AlertSeverityMin = *p.AlertSeverityMin
+	}
+	if p.AlertChannels != nil {
+		config.AlertChannels = *p.AlertChannels
+	}
+	if p.WhitelistDomains != nil {
+		config.WhitelistDomains = *p.WhitelistDomains
+	}
+	if p.WhitelistIPs != nil {
+		config.WhitelistIPs = *p.WhitelistIPs
+	}
+}
+
+// normalizeDomains normaliza as listas de domínios informadas no patch (ver
+// normalizeConfigDomains)
+func (p *BrandConfigPatch) normalizeDomains() error {
+	if p.AdditionalDomains != nil {
+		normalized, err := domain.NormalizeList(*p.AdditionalDomains)
+		if err != nil {
+			return fmt.Errorf("config.additional_domains%w", err)
+		}
+		p.AdditionalDomains = &normalized
+	}
+	if p.KnownVariations != nil {
+		normalized, err := domain.NormalizeList(*p.KnownVariations)
+		if err != nil {
+			return fmt.Errorf("config.known_variations%w", err)
+		}
+		p.KnownVariations = &normalized
+	}
+	return nil
+}
+
+// normalizeBrandDomains normaliza o domínio principal e os domínios da config
+// da marca. O erro informa o campo inválido.
+func normalizeBrandDomains(primaryDomain *string, config *models.BrandConfig) error {
+	normalized, err := domain.Normalize(*primaryDomain)
+	if err != nil {
+		return fmt.Errorf("primary_domain: %w", err)
+	}
+	*primaryDomain = normalized
+	return normalizeConfigDomains(config)
+}
+
+// normalizeConfigDomains normaliza additional_domains e known_variations e remove
+// os repetidos (monitorados como alvos pelo MCP)
+func normalizeConfigDomains(config *models.BrandConfig) error {
+	additional, err := domain.NormalizeList(config.AdditionalDomains)
+	if err != nil {
+		return fmt.Errorf("config.additional_domains%w", err)
+	}
+	variations, err := domain.NormalizeList(config.KnownVariations)
+	if err != nil {
+		return fmt.Errorf("config.known_variations%w", err)
+	}
+	config.AdditionalDomains = additional
+	config.KnownVariations = variations
+	return nil
+}
+
+// applyBrandDefaults preenche as configurações de monitoramento não informadas
+func applyBrandDefaults(config *models.BrandConfig) {
+	if config.ScanFrequencyMins == 0 {
+		config.ScanFrequencyMins = 60 // 1 hora
+	}
+	if config.AlertSeverityMin == "" {
+		config.AlertSeverityMin = "medium"
+	}
+	if len(config.AlertChannels) == 0 {
+		config.AlertChannels = []string{"email"}
+	}
+}
+
+// brandConfigErrors valida a config de uma marca (ver validateBrandConfig), com
+// os campos prefixados por "config."
+func (h *ClientHandler) brandConfigErrors(ctx context.Context, tenantID uuid.UUID, config models.BrandConfig) ([]response.ValidationError, error) {
+	slackConfigured, err := h.slackConfigured(ctx, tenantID, config)
+	if err != nil {
+		return nil, err
+	}
+	return validateBrandConfig(config, slackConfigured, "config."), nil
+}
+
+// slackConfigured informa se o tenant tem webhook do Slack configurado. As
+// settings só são consultadas se alguma das configs usa o canal slack.
+func (h *ClientHandler) slackConfigured(ctx context.Context, tenantID uuid.UUID, configs ...models.BrandConfig) (bool, error) {
+	for _, config := range configs {
+		for _, channel := range config.AlertChannels {
+			if channel != "slack" {
+				continue
+			}
+			settings, err := h.tenantService.GetSettings(ctx, tenantID)
+			if err != nil {
+				return false, err
+			}
+			return settings.SlackWebhook != "", nil
+		}
+	}
+	return false, nil
+}
+
+// validateBrandConfig valida os valores de monitoramento e alertas de uma config
+// com os defaults já aplicados: frequência de scan entre minScanFrequencyMins e
+// maxScanFrequencyMins, severidade mínima conhecida e canais de brandAlertChannels.
+// O canal slack exige o webhook do Slack nas settings do tenant.
+func validateBrandConfig(config models.BrandConfig, slackConfigured bool, prefix string) []response.ValidationError {
+	var errs []response.ValidationError
+	if config.ScanFrequencyMins < minScanFrequencyMins || config.ScanFrequencyMins > maxScanFrequencyMins {
+		errs = append(errs, response.ValidationError{
+			Field:   prefix + "scan_frequency_mins",
+			Message: fmt.Sprintf("must be between %d and %d", minScanFrequencyMins, maxScanFrequencyMins),
+		})
+	}
+	if !validAlertSeverities[config.AlertSeverityMin] {
+		errs = append(errs, response.ValidationError{
+			Field:   prefix + "alert_severity_min",
+			Message: "must be one of: info, low, medium, high, critical",
+		})
+	}
+	for i, channel := range config.AlertChannels {
+		field := fmt.Sprintf("%salert_channels[%d]", prefix, i)
+		switch {
+		case !brandAlertChannels[channel]:
+			errs = append(errs, response.ValidationError{Field: field, Message: "must be one of: email, slack, webhook, sms"})
+		case channel == "slack" && !slackConfigured:
+			errs = append(errs, response.ValidationError{Field: field, Message: "requires a Slack webhook in the tenant settings"})
+		}
+	}
+	return errs
+}
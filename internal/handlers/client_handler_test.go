@@ -0,0 +1,344 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/internal/testutil"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// clientEnv app com as rotas de clientes e marcas sobre stores em memória
+type clientEnv struct {
+	app  *fiber.App
+	mem  *testutil.Memory
+	mcp  *mcp.FakeMCPClient
+	jwt  *auth.JWTManager
+	user *models.User
+}
+
+func newClientEnv(t *testing.T) *clientEnv {
+	t.Helper()
+	env := &clientEnv{
+		app: testutil.NewApp(),
+		mem: testutil.NewMemory(),
+		mcp: mcp.NewFakeMCPClient(nil),
+		jwt: testutil.NewJWTManager(),
+	}
+	env.user = testutil.NewUser(models.RoleAdmin)
+	env.mem.AddTenant(&models.Tenant{ID: env.user.TenantID, Plan: "pro"})
+	env.mem.AddUser(env.user)
+
+	h := handlers.NewClientHandler(env.mem.Clients(), env.mem.Brands(), env.mem.Tenants(), env.mcp, nil)
+	clients := env.app.Group("/v1/clients", middleware.NewAuthMiddleware(env.jwt).Authenticate())
+	clients.Get("/", middleware.RequireScope(middleware.ScopeClientsRead), h.ListClients)
+	clients.Get("/:client_id", middleware.RequireScope(middleware.ScopeClientsRead), h.GetClient)
+	clients.Post("/", middleware.RequireScope(middleware.ScopeClientsWrite), h.CreateClient)
+	clients.Put("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), h.UpdateClient)
+	clients.Patch("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), h.PatchClient)
+	clients.Delete("/:client_id", middleware.RequireScope(middleware.ScopeClientsWrite), h.DeleteClient)
+	brands := clients.Group("/:client_id/brands")
+	brands.Get("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsRead), h.GetBrand)
+	brands.Post("/", middleware.RequireScope(middleware.ScopeBrandsWrite), h.CreateBrand)
+	brands.Put("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsWrite), h.UpdateBrand)
+	brands.Patch("/:brand_id", middleware.RequireScope(middleware.ScopeBrandsWrite), h.PatchBrand)
+	return env
+}
+
+// do envia uma request autenticada como env.user e decodifica data em dest
+func (env *clientEnv) do(t *testing.T, method, target string, body, dest interface{}) int {
+	t.Helper()
+	resp, err := env.app.Test(testutil.AuthRequest(t, env.jwt, env.user, method, target, body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.Decode(t, resp, dest)
+	return resp.StatusCode
+}
+
+func TestPatchClientUpdatesOnlyProvidedFields(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{
+		TenantID:    env.user.TenantID,
+		Name:        "Acme",
+		Slug:        "acme",
+		Description: "Retail",
+		Settings:    models.ClientSettings{AlertEmail: "soc@acme.com", ScanFrequency: "hourly", Priority: "high", AutoTakedown: true},
+	})
+	target := "/v1/clients/" + client.ID.String()
+
+	var got handlers.ClientResponse
+	if status := env.do(t, fiber.MethodPatch, target, map[string]interface{}{"name": "Acme Corp"}, &got); status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if got.Name != "Acme Corp" || got.Description != "Retail" || !reflect.DeepEqual(got.Settings, client.Settings) {
+		t.Errorf("name-only patch changed other fields: %+v", got)
+	}
+
+	patch := map[string]interface{}{"settings": map[string]interface{}{"priority": "critical"}}
+	if status := env.do(t, fiber.MethodPatch, target, patch, &got); status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	want := client.Settings
+	want.Priority = "critical"
+	if !reflect.DeepEqual(got.Settings, want) {
+		t.Errorf("settings = %+v, want %+v", got.Settings, want)
+	}
+
+	// A versão persistida é a mesma da resposta
+	var stored handlers.ClientResponse
+	env.do(t, fiber.MethodGet, target, nil, &stored)
+	if stored.Name != "Acme Corp" || stored.Description != "Retail" || !reflect.DeepEqual(stored.Settings, want) {
+		t.Errorf("stored client = %+v", stored)
+	}
+}
+
+func TestPatchBrandUpdatesOnlyProvidedConfigFields(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{TenantID: env.user.TenantID, Name: "Acme", Slug: "acme"})
+	brand := env.mem.AddBrand(&models.Brand{
+		TenantID:      env.user.TenantID,
+		ClientID:      client.ID,
+		Name:          "Acme",
+		PrimaryDomain: "acme.com",
+		Config: models.BrandConfig{
+			Keywords:          []string{"acme"},
+			ScanFrequencyMins: 30,
+			AlertSeverityMin:  "high",
+			AlertChannels:     []string{"email"},
+		},
+	})
+	target := "/v1/clients/" + client.ID.String() + "/brands/" + brand.ID.String()
+
+	var got handlers.BrandResponse
+	patch := map[string]interface{}{"config": map[string]interface{}{"keywords": []string{"acme", "acme corp"}}}
+	if status := env.do(t, fiber.MethodPatch, target, patch, &got); status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	if len(got.Config.Keywords) != 2 {
+		t.Errorf("keywords = %v, want 2 keywords", got.Config.Keywords)
+	}
+	if got.Name != "Acme" || got.Config.ScanFrequencyMins != 30 || got.Config.AlertSeverityMin != "high" ||
+		len(got.Config.AlertChannels) != 1 {
+		t.Errorf("patch changed fields it did not send: %+v", got)
+	}
+}
+
+// Regressão: um PUT sem config (ou com config parcial) não pode zerar a frequência de
+// scan nem os canais de alerta da marca
+func TestUpdateBrandKeepsScanFrequencyAndAlertChannels(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{TenantID: env.user.TenantID, Name: "Acme", Slug: "acme"})
+	brand := env.mem.AddBrand(&models.Brand{
+		TenantID:      env.user.TenantID,
+		ClientID:      client.ID,
+		Name:          "Acme",
+		PrimaryDomain: "acme.com",
+		Config:        models.BrandConfig{ScanFrequencyMins: 30, AlertSeverityMin: "high", AlertChannels: []string{"email", "webhook"}},
+	})
+	target := "/v1/clients/" + client.ID.String() + "/brands/" + brand.ID.String()
+
+	for _, body := range []map[string]interface{}{
+		{"name": "x", "primary_domain": "acme.com"},
+		{"name": "x", "primary_domain": "acme.com", "config": map[string]interface{}{"keywords": []string{"acme"}}},
+	} {
+		var got handlers.BrandResponse
+		if status := env.do(t, fiber.MethodPut, target, body, &got); status != fiber.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+		if got.Name != "x" || got.Config.ScanFrequencyMins != 30 || !reflect.DeepEqual(got.Config.AlertChannels, []string{"email", "webhook"}) {
+			t.Errorf("PUT %v: config = %+v", body, got.Config)
+		}
+
+		var stored handlers.BrandResponse
+		env.do(t, fiber.MethodGet, target, nil, &stored)
+		if stored.Config.ScanFrequencyMins != 30 || len(stored.Config.AlertChannels) != 2 {
+			t.Errorf("PUT %v: stored config = %+v", body, stored.Config)
+		}
+	}
+}
+
+func TestCreateBrandPersistsConfig(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{TenantID: env.user.TenantID, Name: "Acme", Slug: "acme"})
+	target := "/v1/clients/" + client.ID.String() + "/brands"
+
+	body := map[string]interface{}{
+		"name":           "Acme",
+		"primary_domain": "acme.com",
+		"config":         map[string]interface{}{"scan_frequency_mins": 15, "alert_channels": []string{"webhook"}},
+	}
+	var created handlers.BrandResponse
+	if status := env.do(t, fiber.MethodPost, target, body, &created); status != fiber.StatusCreated {
+		t.Fatalf("status = %d, want 201", status)
+	}
+
+	var stored handlers.BrandResponse
+	env.do(t, fiber.MethodGet, target+"/"+created.ID.String(), nil, &stored)
+	if stored.Config.ScanFrequencyMins != 15 || !reflect.DeepEqual(stored.Config.AlertChannels, []string{"webhook"}) {
+		t.Errorf("stored config = %+v", stored.Config)
+	}
+}
+
+func TestClientCRUD(t *testing.T) {
+	env := newClientEnv(t)
+
+	var created handlers.ClientResponse
+	body := map[string]interface{}{"name": "Acme Bank", "industry": "finance"}
+	if status := env.do(t, fiber.MethodPost, "/v1/clients", body, &created); status != fiber.StatusCreated {
+		t.Fatalf("create: status = %d, want 201", status)
+	}
+	if created.Slug != "acme-bank" || created.TenantID != env.user.TenantID || created.Status != models.StatusActive {
+		t.Errorf("created client = %+v", created)
+	}
+	target := "/v1/clients/" + created.ID.String()
+
+	// Clientes de outro tenant não aparecem na listagem
+	env.mem.AddClient(&models.Client{TenantID: uuid.New(), Name: "Other", Slug: "other"})
+	var list struct {
+		Items []handlers.ClientResponse `json:"items"`
+	}
+	if status := env.do(t, fiber.MethodGet, "/v1/clients", nil, &list); status != fiber.StatusOK {
+		t.Fatalf("list: status = %d, want 200", status)
+	}
+	if len(list.Items) != 1 || list.Items[0].ID != created.ID {
+		t.Errorf("list = %+v, want only the created client", list.Items)
+	}
+
+	var updated handlers.ClientResponse
+	if status := env.do(t, fiber.MethodPut, target, map[string]interface{}{"name": "Acme Bank SA"}, &updated); status != fiber.StatusOK {
+		t.Fatalf("update: status = %d, want 200", status)
+	}
+	var stored handlers.ClientResponse
+	if status := env.do(t, fiber.MethodGet, target, nil, &stored); status != fiber.StatusOK || stored.Name != "Acme Bank SA" {
+		t.Errorf("get after update: status = %d, client = %+v", status, stored)
+	}
+
+	resp, err := env.app.Test(testutil.AuthRequest(t, env.jwt, env.user, fiber.MethodDelete, target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("delete: status = %d, want 204", resp.StatusCode)
+	}
+	if status := env.do(t, fiber.MethodGet, target, nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("get after delete: status = %d, want 404", status)
+	}
+}
+
+func TestClientWritesRequireScope(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{TenantID: env.user.TenantID, Name: "Acme", Slug: "acme"})
+	env.user = testutil.NewUser(models.RoleViewer, models.ScopeClientsRead)
+	env.user.TenantID = client.TenantID
+
+	if status := env.do(t, fiber.MethodGet, "/v1/clients/"+client.ID.String(), nil, nil); status != fiber.StatusOK {
+		t.Errorf("read: status = %d, want 200", status)
+	}
+	if status := env.do(t, fiber.MethodPost, "/v1/clients", map[string]interface{}{"name": "Other"}, nil); status != fiber.StatusForbidden {
+		t.Errorf("create: status = %d, want 403", status)
+	}
+	if status := env.do(t, fiber.MethodDelete, "/v1/clients/"+client.ID.String(), nil, nil); status != fiber.StatusForbidden {
+		t.Errorf("delete: status = %d, want 403", status)
+	}
+}
+
+// JSON malformado é 400 (BAD_REQUEST); JSON válido que falha na validação é 422
+func TestMalformedJSONIs400AndValidationFailureIs422(t *testing.T) {
+	env := newClientEnv(t)
+	client := env.mem.AddClient(&models.Client{TenantID: env.user.TenantID, Name: "Acme", Slug: "acme"})
+	brands := "/v1/clients/" + client.ID.String() + "/brands"
+
+	for _, tc := range []struct {
+		target string
+		body   interface{}
+		status int
+		code   string
+	}{
+		{"/v1/clients", `{"name": "Acme"`, fiber.StatusBadRequest, response.CodeBadRequest},
+		{"/v1/clients", `["not", "an", "object"]`, fiber.StatusBadRequest, response.CodeBadRequest},
+		{"/v1/clients", map[string]interface{}{"description": "no name"}, fiber.StatusUnprocessableEntity, response.CodeValidationError},
+		{brands, `{"name": "Acme", "primary_domain": }`, fiber.StatusBadRequest, response.CodeBadRequest},
+		{brands, map[string]interface{}{"name": "Acme"}, fiber.StatusUnprocessableEntity, response.CodeValidationError},
+	} {
+		resp, err := env.app.Test(testutil.AuthRequest(t, env.jwt, env.user, fiber.MethodPost, tc.target, tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope := testutil.Decode(t, resp, nil)
+		if resp.StatusCode != tc.status || envelope.Error == nil || envelope.Error.Code != tc.code {
+			t.Errorf("POST %s %v: status = %d, error = %+v, want %d %s", tc.target, tc.body, resp.StatusCode, envelope.Error, tc.status, tc.code)
+		}
+	}
+}
+
+// failingClients e failingBrands simulam uma falha do banco em GetByID
+type failingClients struct {
+	handlers.ClientStore
+	err error
+}
+
+func (s failingClients) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Client, error) {
+	return nil, s.err
+}
+
+type failingBrands struct {
+	handlers.BrandStore
+	err error
+}
+
+func (s failingBrands) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Brand, error) {
+	return nil, s.err
+}
+
+// Linha inexistente (services.ErrNotFound) é 404; erro de consulta é 500, sem expor o erro
+func TestLookupDistinguishesMissingRowFromQueryError(t *testing.T) {
+	env := newClientEnv(t)
+	queryErr := errors.New("dial tcp 10.0.0.5:5432: connection refused")
+
+	for _, tc := range []struct {
+		name    string
+		clients handlers.ClientStore
+		brands  handlers.BrandStore
+		status  int
+		code    string
+	}{
+		{"missing row", env.mem.Clients(), env.mem.Brands(), fiber.StatusNotFound, response.CodeNotFound},
+		{"query error", failingClients{env.mem.Clients(), queryErr}, failingBrands{env.mem.Brands(), queryErr}, fiber.StatusInternalServerError, response.CodeInternalServerError},
+		{"wrapped not found", failingClients{env.mem.Clients(), fmt.Errorf("load: %w", services.ErrNotFound)}, failingBrands{env.mem.Brands(), fmt.Errorf("load: %w", services.ErrNotFound)}, fiber.StatusNotFound, response.CodeNotFound},
+	} {
+		h := handlers.NewClientHandler(tc.clients, tc.brands, env.mem.Tenants(), env.mcp, nil)
+		app := testutil.NewApp()
+		clients := app.Group("/v1/clients", middleware.NewAuthMiddleware(env.jwt).Authenticate())
+		clients.Get("/:client_id", h.GetClient)
+		clients.Get("/:client_id/brands/:brand_id", h.GetBrand)
+
+		clientTarget := "/v1/clients/" + uuid.NewString()
+		for _, target := range []string{clientTarget, clientTarget + "/brands/" + uuid.NewString()} {
+			resp, err := app.Test(testutil.AuthRequest(t, env.jwt, env.user, fiber.MethodGet, target, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			envelope := testutil.Decode(t, resp, nil)
+			if resp.StatusCode != tc.status || envelope.Error == nil || envelope.Error.Code != tc.code {
+				t.Errorf("%s: GET %s: status = %d, error = %+v", tc.name, target, resp.StatusCode, envelope.Error)
+				continue
+			}
+			if strings.Contains(envelope.Error.Message, "connection refused") {
+				t.Errorf("%s: error message leaks the query error: %q", tc.name, envelope.Error.Message)
+			}
+		}
+	}
+}
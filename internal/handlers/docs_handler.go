@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/monitoring"
+	"github.com/arcaintelligence/arca-gateway/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Versão do swagger-ui-dist carregada do CDN pela página /docs
+const swaggerUIVersion = "5.17.14"
+
+// DocsHandler serve a especificação OpenAPI e a Swagger UI
+type DocsHandler struct {
+	spec []byte
+}
+
+// NewDocsHandler cria um novo handler de documentação, gerando a especificação uma única vez
+func NewDocsHandler(version string) (*DocsHandler, error) {
+	spec, err := json.Marshal(APISpec(version))
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{spec: spec}, nil
+}
+
+// OpenAPI retorna a especificação OpenAPI 3 da API
+func (h *DocsHandler) OpenAPI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Send(h.spec)
+}
+
+// SwaggerUI retorna a página da Swagger UI apontando para /openapi.json
+func (h *DocsHandler) SwaggerUI(c *fiber.Ctx) error {
+	// Os assets da Swagger UI vêm do CDN: a CSP padrão do gateway só permite 'self'
+	c.Set("Content-Security-Policy", "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' 'unsafe-inline' https://unpkg.com; img-src 'self' data: https:; connect-src 'self'; frame-ancestors 'none'; base-uri 'self'")
+	c.Set("Cross-Origin-Embedder-Policy", "unsafe-none")
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(swaggerUIPage)
+}
+
+// SwaggerUIInit retorna o script que inicializa a Swagger UI (externo à página por causa da CSP)
+func (h *DocsHandler) SwaggerUIInit(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJavaScriptCharsetUTF8)
+	return c.SendString(swaggerUIInit)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ARCA Gateway API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css" crossorigin="anonymous">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin="anonymous"></script>
+<script src="/docs/swagger-initializer.js"></script>
+</body>
+</html>
+`
+
+const swaggerUIInit = `window.ui = SwaggerUIBundle({
+  url: "/openapi.json",
+  dom_id: "#swagger-ui",
+  deepLinking: true,
+  persistAuthorization: true
+});
+`
+
+// =============================================================================
+// API SPEC
+// =============================================================================
+
+// APISpec descreve as rotas públicas da API. Os schemas de request/response são
+// gerados dos tipos dos handlers; ao adicionar ou alterar uma rota em
+// cmd/server/main.go, atualize a lista correspondente aqui.
+func APISpec(version string) *openapi.Document {
+	return openapi.New("ARCA Gateway API", version,
+		"API Gateway do ARCA Intelligence. Todas as respostas usam o envelope {success, data | error, request_id, timestamp}.").
+		Tag("Auth", "Autenticação, sessões e API keys").
+		Tag("Onboarding", "Registro inicial de clientes").
+		Tag("Clients", "Clientes do tenant").
+		Tag("Brands", "Marcas monitoradas").
+		Tag("Hunting", "Hunting, scan e análise de ameaças (via MCP)").
+		Tag("Monitoring", "Jobs de monitoramento (via MCP)").
+		Add(authRoutes()...).
+		Add(onboardingRoutes()...).
+		Add(clientRoutes()...).
+		Add(brandRoutes()...).
+		Add(huntingRoutes()...).
+		Document()
+}
+
+// anyObject resposta repassada do Core sem schema fixo
+type anyObject map[string]interface{}
+
+func authRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/v1/auth/login", Tag: "Auth", Summary: "Login com email e senha",
+			Request: LoginRequest{}, Response: LoginResponse{}, Public: true},
+		{Method: http.MethodPost, Path: "/v1/auth/register", Tag: "Auth", Summary: "Registra um tenant e seu usuário admin",
+			Request: RegisterRequest{}, Response: LoginResponse{}, Status: http.StatusCreated, Public: true},
+		{Method: http.MethodPost, Path: "/v1/auth/refresh", Tag: "Auth", Summary: "Troca o refresh token por um novo par de tokens",
+			Request: RefreshRequest{}, Response: anyObject{}, Public: true},
+		{Method: http.MethodPost, Path: "/v1/auth/verify-email", Tag: "Auth", Summary: "Confirma o email com o token recebido",
+			Request: VerifyEmailTokenRequest{}, Response: anyObject{}, Public: true},
+		{Method: http.MethodPost, Path: "/v1/auth/logout", Tag: "Auth", Summary: "Encerra a sessão atual",
+			Request: LogoutRequest{}, Response: anyObject{}},
+		{Method: http.MethodPost, Path: "/v1/auth/logout-all", Tag: "Auth", Summary: "Encerra todas as sessões e API keys do usuário",
+			Response: anyObject{}},
+		{Method: http.MethodGet, Path: "/v1/auth/me", Tag: "Auth", Summary: "Usuário autenticado",
+			Response: UserResponse{}},
+		{Method: http.MethodPut, Path: "/v1/auth/password", Tag: "Auth", Summary: "Troca a senha",
+			Request: ChangePasswordRequest{}, Response: anyObject{}},
+		{Method: http.MethodGet, Path: "/v1/auth/sessions", Tag: "Auth", Summary: "Tentativas de login recentes",
+			Response: []models.LoginEvent{}},
+		{Method: http.MethodPost, Path: "/v1/auth/api-key", Tag: "Auth", Summary: "Gera uma API key (admin e manager)",
+			Response: anyObject{}},
+	}
+}
+
+func onboardingRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/v1/onboarding/register", Tag: "Onboarding", Summary: "Registra um cliente",
+			Request: OnboardingRegisterRequest{}, Response: anyObject{}, Public: true},
+		{Method: http.MethodPost, Path: "/v1/onboarding/verify-email", Tag: "Onboarding", Summary: "Confirma o email do cliente",
+			Request: VerifyEmailRequest{}, Response: anyObject{}, Public: true},
+		{Method: http.MethodGet, Path: "/v1/brands", Tag: "Onboarding", Summary: "Lista as marcas do cliente",
+			Response: anyObject{}},
+		{Method: http.MethodPost, Path: "/v1/brands", Tag: "Onboarding", Summary: "Cadastra uma marca",
+			Request: BrandCreateRequest{}, Response: anyObject{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/v1/brands/:brand_id", Tag: "Onboarding", Summary: "Detalhes de uma marca",
+			Response: anyObject{}},
+		{Method: http.MethodPost, Path: "/v1/brands/:brand_id/monitoring/start", Tag: "Onboarding", Summary: "Inicia o monitoramento da marca",
+			Request: StartMonitoringRequest{}, Response: anyObject{}},
+		{Method: http.MethodPost, Path: "/v1/brands/:brand_id/monitoring/stop", Tag: "Onboarding", Summary: "Para o monitoramento da marca",
+			Response: anyObject{}},
+		{Method: http.MethodGet, Path: "/v1/brands/:brand_id/monitoring/status", Tag: "Onboarding", Summary: "Status do monitoramento da marca",
+			Response: anyObject{}},
+	}
+}
+
+// paginationQuery parâmetros de paginação das listagens
+var paginationQuery = []openapi.Parameter{
+	{Name: "page", In: "query", Schema: &openapi.Schema{Type: "integer"}, Description: "Página (padrão 1)"},
+	{Name: "per_page", In: "query", Schema: &openapi.Schema{Type: "integer"}, Description: "Itens por página (padrão 20, máximo SERVER_MAX_PER_PAGE)"},
+}
+
+// conditionalGet header de GET condicional (ver response.SuccessWithETag)
+var conditionalGet = []openapi.Parameter{
+	{Name: "If-None-Match", In: "header", Schema: &openapi.Schema{Type: "string"}, Description: "ETag recebido; retorna 304 se o recurso não mudou"},
+}
+
+// unmodifiedSinceHeader header de concorrência otimista das atualizações
+var unmodifiedSinceHeader = []openapi.Parameter{
+	{Name: "If-Unmodified-Since", In: "header", Schema: &openapi.Schema{Type: "string"}, Description: "Retorna 409 se o recurso foi alterado depois dessa data (alternativa ao updated_at no corpo)"},
+}
+
+func clientRoutes() []openapi.Route {
+	read := []string{string(middleware.ScopeClientsRead)}
+	write := []string{string(middleware.ScopeClientsRead), string(middleware.ScopeClientsWrite)}
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: "/v1/clients", Tag: "Clients", Summary: "Lista os clientes",
+			Response: ClientResponse{}, Paginated: true, Parameters: paginationQuery, Scopes: read},
+		{Method: http.MethodPost, Path: "/v1/clients", Tag: "Clients", Summary: "Cria um cliente",
+			Request: CreateClientRequest{}, Response: ClientResponse{}, Status: http.StatusCreated, Scopes: write},
+		{Method: http.MethodGet, Path: "/v1/clients/:client_id", Tag: "Clients", Summary: "Detalhes de um cliente",
+			Response: ClientResponse{}, Parameters: conditionalGet, Scopes: read},
+		{Method: http.MethodPut, Path: "/v1/clients/:client_id", Tag: "Clients", Summary: "Substitui um cliente",
+			Request: UpdateClientRequest{}, Response: ClientResponse{}, Parameters: unmodifiedSinceHeader, Scopes: write},
+		{Method: http.MethodPatch, Path: "/v1/clients/:client_id", Tag: "Clients", Summary: "Atualiza parcialmente um cliente",
+			Request: PatchClientRequest{}, Response: ClientResponse{}, Parameters: unmodifiedSinceHeader, Scopes: write},
+		{Method: http.MethodDelete, Path: "/v1/clients/:client_id", Tag: "Clients", Summary: "Remove um cliente",
+			Status: http.StatusNoContent, Scopes: write},
+	}
+}
+
+func brandRoutes() []openapi.Route {
+	read := []string{string(middleware.ScopeClientsRead), string(middleware.ScopeBrandsRead)}
+	write := append(read, string(middleware.ScopeBrandsWrite))
+	monitor := append(read, string(middleware.ScopeMonitorWrite))
+	return []openapi.Route{
+		{Method: http.MethodGet, Path: "/v1/clients/:client_id/brands", Tag: "Brands", Summary: "Lista as marcas de um cliente",
+			Response: BrandResponse{}, Paginated: true, Parameters: paginationQuery, Scopes: read},
+		{Method: http.MethodPost, Path: "/v1/clients/:client_id/brands", Tag: "Brands", Summary: "Cria uma marca",
+			Request: CreateBrandRequest{}, Response: BrandResponse{}, Status: http.StatusCreated, Scopes: write},
+		{Method: http.MethodPost, Path: "/v1/clients/:client_id/brands/bulk", Tag: "Brands", Summary: "Importa marcas em lote",
+			Request: []CreateBrandRequest{}, Response: BulkBrandResponse{}, Status: http.StatusCreated, Scopes: write,
+			Parameters: []openapi.Parameter{{Name: "allow_partial", In: "query", Schema: &openapi.Schema{Type: "boolean"}, Description: "Cria os itens válidos e reporta as falhas"}}},
+		{Method: http.MethodGet, Path: "/v1/clients/:client_id/brands/:brand_id", Tag: "Brands", Summary: "Detalhes de uma marca",
+			Response: BrandResponse{}, Parameters: conditionalGet, Scopes: read},
+		{Method: http.MethodPut, Path: "/v1/clients/:client_id/brands/:brand_id", Tag: "Brands", Summary: "Substitui uma marca",
+			Request: UpdateBrandRequest{}, Response: BrandResponse{}, Parameters: unmodifiedSinceHeader, Scopes: write},
+		{Method: http.MethodPatch, Path: "/v1/clients/:client_id/brands/:brand_id", Tag: "Brands", Summary: "Atualiza parcialmente uma marca",
+			Request: PatchBrandRequest{}, Response: BrandResponse{}, Parameters: unmodifiedSinceHeader, Scopes: write},
+		{Method: http.MethodDelete, Path: "/v1/clients/:client_id/brands/:brand_id", Tag: "Brands", Summary: "Remove uma marca",
+			Status: http.StatusNoContent, Scopes: write},
+		{Method: http.MethodPost, Path: "/v1/clients/:client_id/brands/:brand_id/monitoring/start", Tag: "Brands", Summary: "Inicia o monitoramento da marca",
+			Response: anyObject{}, Scopes: monitor},
+		{Method: http.MethodPost, Path: "/v1/clients/:client_id/brands/:brand_id/monitoring/stop", Tag: "Brands", Summary: "Para o monitoramento da marca",
+			Response: anyObject{}, Scopes: monitor},
+		{Method: http.MethodGet, Path: "/v1/clients/:client_id/brands/:brand_id/monitoring", Tag: "Brands", Summary: "Estado do monitoramento da marca, reconciliado com o MCP",
+			Response: monitoring.Result{}, Scopes: append(read, string(middleware.ScopeMonitorRead))},
+	}
+}
+
+func huntingRoutes() []openapi.Route {
+	return []openapi.Route{
+		{Method: http.MethodPost, Path: "/v1/hunting/hunt", Tag: "Hunting", Summary: "Executa um hunting",
+			Request: HuntRequest{}, Response: mcp.HuntResponse{}, Scopes: []string{string(models.ScopeHuntingWrite)}},
+		{Method: http.MethodPost, Path: "/v1/hunting/scan", Tag: "Hunting", Summary: "Executa o scan de uma URL",
+			Request: ScanURLRequest{}, Response: mcp.ScanResponse{}, Scopes: []string{string(models.ScopeHuntingWrite)}},
+		{Method: http.MethodPost, Path: "/v1/hunting/analyze", Tag: "Hunting", Summary: "Analisa uma URL",
+			Request: AnalyzeURLRequest{}, Response: mcp.AnalyzeResponse{}, Scopes: []string{string(models.ScopeAnalyzeWrite)}},
+		{Method: http.MethodPost, Path: "/v1/hunting/leaks/search", Tag: "Hunting", Summary: "Busca vazamentos",
+			Request: LeakSearchReq{}, Response: mcp.LeakSearchResponse{}, Scopes: []string{string(models.ScopeHuntingRead)}},
+		{Method: http.MethodGet, Path: "/v1/hunting/jobs/:job_id", Tag: "Hunting", Summary: "Status de um hunt/scan assíncrono",
+			Response: models.AsyncJob{}, Scopes: []string{string(models.ScopeHuntingRead)}},
+		{Method: http.MethodPost, Path: "/v1/monitor/jobs", Tag: "Monitoring", Summary: "Cria um job de monitoramento (com dry_run, só retorna a prévia)",
+			Request: CreateMonitorJobRequest{}, Response: mcp.MonitorJobResponse{}, Status: http.StatusCreated, Scopes: []string{string(models.ScopeMonitorWrite)}},
+		{Method: http.MethodPost, Path: "/v1/monitor/jobs/:job_id/stop", Tag: "Monitoring", Summary: "Para um job de monitoramento",
+			Response: anyObject{}, Scopes: []string{string(models.ScopeMonitorWrite)}},
+	}
+}
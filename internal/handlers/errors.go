@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// handleServiceError responde 404 quando o recurso não existe (services.ErrNotFound)
+// e trata as demais falhas como handleStoreError: uma falha no banco não pode
+// aparecer para o cliente como "não encontrado"
+func handleServiceError(c *fiber.Ctx, err error, notFound, failure string) error {
+	if errors.Is(err, services.ErrNotFound) {
+		return response.NotFound(c, notFound)
+	}
+	return handleStoreError(c, err, failure)
+}
+
+// handleStoreError responde a uma falha de persistência, registrando o erro: 504
+// quando a consulta excedeu o prazo (services.ErrQueryTimeout) e 500 nas demais
+func handleStoreError(c *fiber.Ctx, err error, failure string) error {
+	log := logger.FromContext(c).WithError(err)
+	if errors.Is(err, services.ErrQueryTimeout) {
+		log.Warn("%s: database query timed out", failure)
+		return response.Fail(c, response.CodeDatabaseTimeout, failure+": database query timed out")
+	}
+	log.Error("%s", failure)
+	return response.InternalServerError(c, failure)
+}
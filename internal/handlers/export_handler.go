@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Tempo máximo de uma exportação (consulta + escrita da resposta)
+const exportTimeout = 5 * time.Minute
+
+// Formatos de exportação suportados
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+)
+
+// Status considerados abertos no filtro status=open
+var openAlertStatuses = []string{"new", "acknowledged"}
+
+// ExportHandler handlers de exportação (CSV/JSON) de clientes, marcas e alertas
+type ExportHandler struct {
+	clientService ClientStore
+	brandService  BrandStore
+	alertService  AlertStore
+}
+
+// NewExportHandler cria um novo handler de exportação
+func NewExportHandler(clientService ClientStore, brandService BrandStore, alertService AlertStore) *ExportHandler {
+	return &ExportHandler{
+		clientService: clientService,
+		brandService:  brandService,
+		alertService:  alertService,
+	}
+}
+
+// exportWriter escreve as linhas de uma exportação no formato escolhido
+type exportWriter interface {
+	Write(record map[string]string) error
+	Close() error
+}
+
+// =============================================================================
+// EXPORT HANDLERS
+// =============================================================================
+
+// ExportClients exporta os clientes do tenant
+func (h *ExportHandler) ExportClients(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+
+	columns := []string{"id", "name", "slug", "industry", "status", "created_at", "updated_at"}
+	return h.stream(c, "clients", columns, func(ctx context.Context, w exportWriter) error {
+		return h.clientService.Each(ctx, tenantID, func(client *models.Client) error {
+			return w.Write(map[string]string{
+				"id":         client.ID.String(),
+				"name":       client.Name,
+				"slug":       client.Slug,
+				"industry":   client.Industry,
+				"status":     string(client.Status),
+				"created_at": client.CreatedAt.UTC().Format(time.RFC3339),
+				"updated_at": client.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+	})
+}
+
+// ExportBrands exporta as marcas de um cliente
+func (h *ExportHandler) ExportBrands(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+	clientID, err := response.ParseUUIDParam(c, "client_id")
+	if err != nil {
+		return err
+	}
+
+	client, err := h.clientService.GetByID(c.Context(), clientID, tenantID)
+	if err != nil {
+		return handleServiceError(c, err, "Client not found", "Failed to load client")
+	}
+
+	name := client.Slug
+	if name == "" {
+		name = client.ID.String()
+	}
+
+	columns := []string{"id", "client_id", "name", "primary_domain", "industry", "monitoring_enabled", "threats_found", "created_at", "updated_at"}
+	return h.stream(c, "brands-"+name, columns, func(ctx context.Context, w exportWriter) error {
+		return h.brandService.EachByClient(ctx, clientID, tenantID, func(brand *models.Brand) error {
+			return w.Write(map[string]string{
+				"id":                 brand.ID.String(),
+				"client_id":          brand.ClientID.String(),
+				"name":               brand.Name,
+				"primary_domain":     brand.PrimaryDomain,
+				"industry":           brand.Industry,
+				"monitoring_enabled": strconv.FormatBool(brand.MonitoringEnabled),
+				"threats_found":      strconv.Itoa(brand.ThreatsFound),
+				"created_at":         brand.CreatedAt.UTC().Format(time.RFC3339),
+				"updated_at":         brand.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+	})
+}
+
+// ExportAlerts exporta os alertas do tenant.
+// Filtros: status (lista separada por vírgula; "open" = new e acknowledged),
+// severity, type, client_id, brand_id, from e to (RFC3339).
+func (h *ExportHandler) ExportAlerts(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+
+	filter := services.AlertFilter{
+		Severity: c.Query("severity"),
+		Type:     c.Query("type"),
+	}
+	for _, status := range strings.Split(c.Query("status"), ",") {
+		switch status = strings.TrimSpace(status); status {
+		case "":
+		case "open":
+			filter.Statuses = append(filter.Statuses, openAlertStatuses...)
+		default:
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	if v := c.Query("client_id"); v != "" {
+		clientID, err := uuid.Parse(v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid client_id")
+		}
+		filter.ClientID = &clientID
+	}
+	if v := c.Query("brand_id"); v != "" {
+		brandID, err := uuid.Parse(v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid brand_id")
+		}
+		filter.BrandID = &brandID
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid from: must be RFC3339")
+		}
+		filter.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid to: must be RFC3339")
+		}
+		filter.To = &to
+	}
+
+	columns := []string{"id", "client_id", "brand_id", "type", "severity", "status", "title", "domain", "url", "confidence", "created_at"}
+	return h.stream(c, "alerts", columns, func(ctx context.Context, w exportWriter) error {
+		return h.alertService.Each(ctx, tenantID, filter, func(alert *models.Alert) error {
+			return w.Write(map[string]string{
+				"id":         alert.ID.String(),
+				"client_id":  alert.ClientID.String(),
+				"brand_id":   alert.BrandID.String(),
+				"type":       alert.Type,
+				"severity":   alert.Severity,
+				"status":     alert.Status,
+				"title":      alert.Title,
+				"domain":     alert.Details.Domain,
+				"url":        alert.Details.URL,
+				"confidence": strconv.FormatFloat(alert.Details.Confidence, 'f', -1, 64),
+				"created_at": alert.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		})
+	})
+}
+
+// =============================================================================
+// STREAMING
+// =============================================================================
+
+// stream define os headers da exportação e escreve as linhas à medida que são lidas
+// do banco. A consulta roda depois do handler retornar, então um erro no meio da
+// exportação só pode truncar o arquivo (e é logado).
+func (h *ExportHandler) stream(c *fiber.Ctx, name string, columns []string, export func(ctx context.Context, w exportWriter) error) error {
+	format, ok := exportFormat(c)
+	if !ok {
+		return response.BadRequest(c, "Invalid format: expected csv or json")
+	}
+
+	contentType := fiber.MIMEApplicationJSONCharsetUTF8
+	if format == exportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102-150405"), format)
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// O contexto da request não pode ser usado após o handler retornar
+	log := logger.FromContext(c)
+	c.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+		defer cancel()
+
+		var w exportWriter
+		if format == exportFormatCSV {
+			w = newCSVExportWriter(bw, columns)
+		} else {
+			w = newJSONExportWriter(bw)
+		}
+
+		if err := export(ctx, w); err != nil {
+			log.WithError(err).Error("export %s failed", name)
+			return
+		}
+		if err := w.Close(); err != nil {
+			log.WithError(err).Error("export %s failed", name)
+		}
+	})
+	return nil
+}
+
+// exportFormat resolve o formato pelo parâmetro format ou, na ausência dele, pelo Accept
+func exportFormat(c *fiber.Ctx) (string, bool) {
+	switch format := strings.ToLower(c.Query("format")); format {
+	case exportFormatCSV, exportFormatJSON:
+		return format, true
+	case "":
+		if c.Accepts(fiber.MIMEApplicationJSON, "text/csv") == "text/csv" {
+			return exportFormatCSV, true
+		}
+		return exportFormatJSON, true
+	default:
+		return "", false
+	}
+}
+
+// csvExportWriter escreve as linhas como CSV, com cabeçalho
+type csvExportWriter struct {
+	w       *csv.Writer
+	columns []string
+	header  bool
+	rows    int
+}
+
+func newCSVExportWriter(w *bufio.Writer, columns []string) *csvExportWriter {
+	return &csvExportWriter{w: csv.NewWriter(w), columns: columns}
+}
+
+func (e *csvExportWriter) Write(record map[string]string) error {
+	if !e.header {
+		if err := e.w.Write(e.columns); err != nil {
+			return err
+		}
+		e.header = true
+	}
+
+	row := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		row[i] = record[column]
+	}
+	if err := e.w.Write(row); err != nil {
+		return err
+	}
+
+	// Enviar ao cliente a cada bloco de linhas em vez de acumular a exportação
+	e.rows++
+	if e.rows%500 == 0 {
+		e.w.Flush()
+		return e.w.Error()
+	}
+	return nil
+}
+
+func (e *csvExportWriter) Close() error {
+	if !e.header {
+		if err := e.w.Write(e.columns); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonExportWriter escreve as linhas como um array JSON de objetos
+type jsonExportWriter struct {
+	w    *bufio.Writer
+	rows int
+}
+
+func newJSONExportWriter(w *bufio.Writer) *jsonExportWriter {
+	return &jsonExportWriter{w: w}
+}
+
+func (e *jsonExportWriter) Write(record map[string]string) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if e.rows == 0 {
+		sep = "["
+	}
+	if _, err := e.w.WriteString(sep); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%500 == 0 {
+		return e.w.Flush()
+	}
+	return nil
+}
+
+func (e *jsonExportWriter) Close() error {
+	closing := "]"
+	if e.rows == 0 {
+		closing = "[]"
+	}
+	if _, err := e.w.WriteString(closing); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/pkg/buildinfo"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Status reportados por serviço no health check
+const (
+	serviceHealthy   = "healthy"
+	serviceUnhealthy = "unhealthy"
+)
+
+// HealthCheck verifica a disponibilidade de uma dependência
+type HealthCheck func(ctx context.Context) error
+
+// healthProbe verificação que, além do erro, retorna detalhes do serviço para o
+// corpo do /health (nil quando não há detalhes)
+type healthProbe func(ctx context.Context) (interface{}, error)
+
+// HealthHandler handler de health check
+type HealthHandler struct {
+	version string
+	timeout time.Duration
+	checks  map[string]healthProbe
+}
+
+// MCPHealthDetails detalhes do MCP expostos no /health
+type MCPHealthDetails struct {
+	Version       string   `json:"version,omitempty"`
+	DegradedTools []string `json:"degraded_tools,omitempty"`
+	QueueDepth    *int     `json:"queue_depth,omitempty"`
+}
+
+// NewHealthHandler cria um novo handler de health check com as dependências do gateway
+func NewHealthHandler(version string, timeout time.Duration, db *sql.DB, mcpClient mcp.Client) *HealthHandler {
+	if timeout == 0 {
+		timeout = 2 * time.Second
+	}
+
+	return &HealthHandler{
+		version: version,
+		timeout: timeout,
+		checks: map[string]healthProbe{
+			"database": simpleProbe(db.PingContext),
+			"mcp":      mcpProbe(mcpClient),
+		},
+	}
+}
+
+// AddCheck registra uma dependência adicional (ex: Redis)
+func (h *HealthHandler) AddCheck(name string, check HealthCheck) {
+	h.checks[name] = simpleProbe(check)
+}
+
+func simpleProbe(check HealthCheck) healthProbe {
+	return func(ctx context.Context) (interface{}, error) {
+		return nil, check(ctx)
+	}
+}
+
+// mcpProbe verifica o MCP e expõe a versão, as ferramentas degradadas e a fila.
+// Ferramentas degradadas não tornam o MCP unhealthy (o readiness continua 200);
+// apenas aparecem nos detalhes.
+func mcpProbe(mcpClient mcp.Client) healthProbe {
+	return func(ctx context.Context) (interface{}, error) {
+		health, err := mcpClient.Health(ctx)
+		if health == nil {
+			return nil, err
+		}
+		details := MCPHealthDetails{
+			Version:       health.Version,
+			DegradedTools: health.DegradedTools(),
+			QueueDepth:    health.QueueDepth,
+		}
+		if details.Version == "" && len(details.DegradedTools) == 0 && details.QueueDepth == nil {
+			return nil, err
+		}
+		return details, err
+	}
+}
+
+// Live indica apenas que o processo está de pé (liveness). Não depende de serviços
+// externos, para que falhas transitórias do MCP/banco não reiniciem o pod.
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	return response.Health(c, h.version, map[string]string{
+		"gateway": serviceHealthy,
+	})
+}
+
+// Ready verifica as dependências em paralelo, limitadas pelo timeout do handler,
+// e retorna 503 se alguma estiver indisponível (readiness).
+// Usa um contexto próprio: verificações atrasadas podem terminar após a resposta,
+// quando o contexto da request já foi reciclado pelo fasthttp.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	services, details := h.runChecks(context.Background())
+	return response.Readiness(c, h.version, services, details)
+}
+
+// Version retorna a versão, o commit, a data do build e a versão do Go em execução
+func (h *HealthHandler) Version(c *fiber.Ctx) error {
+	return c.JSON(buildinfo.Get())
+}
+
+// runChecks executa todas as verificações em paralelo e retorna o status e os
+// detalhes de cada serviço. Verificações que não terminam dentro do timeout são
+// reportadas como unhealthy.
+func (h *HealthHandler) runChecks(parent context.Context) (map[string]string, map[string]interface{}) {
+	ctx, cancel := context.WithTimeout(parent, h.timeout)
+	defer cancel()
+
+	type result struct {
+		name    string
+		status  string
+		details interface{}
+	}
+
+	results := make(chan result, len(h.checks))
+	for name, check := range h.checks {
+		go func(name string, check healthProbe) {
+			status := serviceHealthy
+			details, err := check(ctx)
+			if err != nil {
+				status = serviceUnhealthy
+			}
+			results <- result{name: name, status: status, details: details}
+		}(name, check)
+	}
+
+	services := map[string]string{
+		"gateway": serviceHealthy,
+	}
+	for name := range h.checks {
+		services[name] = serviceUnhealthy
+	}
+	details := make(map[string]interface{})
+
+	for pending := len(h.checks); pending > 0; pending-- {
+		select {
+		case r := <-results:
+			services[r.name] = r.status
+			if r.details != nil {
+				details[r.name] = r.details
+			}
+		case <-ctx.Done():
+			return services, details
+		}
+	}
+
+	return services, details
+}
@@ -1,22 +1,80 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
 	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/arcaintelligence/arca-gateway/pkg/urlnorm"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
-// HuntingHandler handlers de hunting e análise
+// HuntingHandler handlers de hunting e análise. Os scopes de cada operação são
+// exigidos nas rotas por middleware.ToolScopes, conforme a ferramenta do MCP chamada.
 type HuntingHandler struct {
-	mcpClient *mcp.MCPClient
+	mcpClient  mcp.Client
+	jobLimiter *middleware.JobLimiter
+	urlOptions urlnorm.Options
+	ssrfGuard  *ssrf.Guard
+
+	asyncJobs     AsyncJobStore
+	tenants       TenantStore
+	dispatcher    *notify.WebhookDispatcher
+	callbackGuard *ssrf.Guard
+	priorityPlans map[string]bool
+}
+
+// HuntingHandlerConfig dependências e opções do HuntingHandler
+type HuntingHandlerConfig struct {
+	// JobLimiter limita hunts/scans simultâneos por tenant (nil desabilita o limite)
+	JobLimiter *middleware.JobLimiter
+	// URLOptions normalização e validação das URLs de scan e análise
+	URLOptions urlnorm.Options
+	// SSRFGuard rejeita alvos que resolvem para endereços internos (nil desabilita)
+	SSRFGuard *ssrf.Guard
+	// AsyncJobs registra os jobs async=true; nil desabilita o modo assíncrono
+	AsyncJobs AsyncJobStore
+	// Tenants fornece o plano (prioridade) e o segredo de webhook (callbacks) do tenant
+	Tenants TenantStore
+	// Dispatcher entrega os resultados dos jobs nos callbacks
+	Dispatcher *notify.WebhookDispatcher
+	// CallbackGuard valida os callback_url; ao contrário de SSRFGuard, é sempre aplicado,
+	// já que é o próprio gateway que chama o callback
+	CallbackGuard *ssrf.Guard
+	// PriorityPlans planos que podem definir a prioridade das operações
+	PriorityPlans []string
 }
 
 // NewHuntingHandler cria um novo handler de hunting
-func NewHuntingHandler(mcpClient *mcp.MCPClient) *HuntingHandler {
+func NewHuntingHandler(mcpClient mcp.Client, config HuntingHandlerConfig) *HuntingHandler {
+	priorityPlans := make(map[string]bool, len(config.PriorityPlans))
+	for _, plan := range config.PriorityPlans {
+		priorityPlans[plan] = true
+	}
+
 	return &HuntingHandler{
-		mcpClient: mcpClient,
+		mcpClient:  mcpClient,
+		jobLimiter: config.JobLimiter,
+		urlOptions: config.URLOptions,
+		ssrfGuard:  config.SSRFGuard,
+
+		asyncJobs:     config.AsyncJobs,
+		tenants:       config.Tenants,
+		dispatcher:    config.Dispatcher,
+		callbackGuard: config.CallbackGuard,
+		priorityPlans: priorityPlans,
 	}
 }
 
@@ -27,6 +85,78 @@ type HuntRequest struct {
 	DeepAnalysis bool     `json:"deep_analysis"`
 	Keywords     []string `json:"keywords,omitempty"`
 	ClientID     *string  `json:"client_id,omitempty"`
+	AsyncOptions
+}
+
+// AsyncOptions opções de execução comuns ao hunt e ao scan. Com Async o MCP executa
+// em segundo plano, a resposta é 202 e o resultado é enviado para CallbackURL (se
+// informado) quando o MCP avisar a conclusão. Priority depende do plano do tenant.
+type AsyncOptions struct {
+	Async       bool   `json:"async"`
+	CallbackURL string `json:"callback_url,omitempty"`
+	Priority    string `json:"priority,omitempty"`
+}
+
+// Prioridades aceitas pelo MCP
+var validPriorities = map[string]bool{"low": true, "normal": true, "high": true}
+
+// Erros de validação das opções assíncronas
+var (
+	errAsyncUnavailable     = errors.New("async execution is not available")
+	errCallbackWithoutAsync = errors.New("callback_url requires async=true")
+	errCallbackNoSecret     = errors.New("callback_url requires a webhook_secret in the tenant settings")
+	errInvalidPriority      = errors.New("priority must be one of low, normal, high")
+	errPriorityNotInPlan    = errors.New("priority is not available in the tenant plan")
+)
+
+// Limites do hunting em lote
+const (
+	// Máximo de alvos por request
+	maxHuntBatchTargets = 50
+	// Máximo de hunts simultâneos de um lote, mesmo com limite de jobs maior
+	maxHuntBatchWorkers = 10
+	// Intervalo entre tentativas de ocupar um slot quando o tenant está no limite
+	huntBatchSlotRetry = 500 * time.Millisecond
+)
+
+// Status de um alvo do lote que não chegou a um resultado do MCP
+const (
+	huntBatchFailed    = "failed"
+	huntBatchCancelled = "cancelled"
+)
+
+// HuntBatchRequest request de hunting em lote; as opções valem para todos os alvos
+type HuntBatchRequest struct {
+	Targets      []string `json:"targets"`
+	IncludeLeaks bool     `json:"include_leaks"`
+	DeepAnalysis bool     `json:"deep_analysis"`
+	Keywords     []string `json:"keywords,omitempty"`
+	ClientID     *string  `json:"client_id,omitempty"`
+}
+
+// HuntBatchItem resultado de um alvo do lote. Status é o do hunt (completed, partial,
+// processing) ou failed/cancelled, com o motivo em Error.
+type HuntBatchItem struct {
+	Target string            `json:"target"`
+	Status string            `json:"status"`
+	Result *mcp.HuntResponse `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// HuntBatchSummary totais do lote por status
+type HuntBatchSummary struct {
+	Total      int `json:"total"`
+	Completed  int `json:"completed"`
+	Partial    int `json:"partial"`
+	Processing int `json:"processing"`
+	Failed     int `json:"failed"`
+	Cancelled  int `json:"cancelled"`
+}
+
+// HuntBatchResponse resultados na ordem dos alvos da request
+type HuntBatchResponse struct {
+	Results []HuntBatchItem  `json:"results"`
+	Summary HuntBatchSummary `json:"summary"`
 }
 
 // ScanRequest request de scan
@@ -35,6 +165,7 @@ type ScanURLRequest struct {
 	CaptureTypes    []string `json:"capture_types,omitempty"`
 	FollowRedirects bool     `json:"follow_redirects"`
 	ClientID        *string  `json:"client_id,omitempty"`
+	AsyncOptions
 }
 
 // AnalyzeRequest request de análise
@@ -53,6 +184,31 @@ type LeakSearchReq struct {
 	ClientID   *string `json:"client_id,omitempty"`
 }
 
+// CreateMonitorJobRequest request de criação de job de monitoramento
+type CreateMonitorJobRequest struct {
+	BrandID       string   `json:"brand_id"`
+	Target        string   `json:"target"`
+	IntervalMins  int      `json:"interval_mins"`
+	EnabledChecks []string `json:"enabled_checks"`
+	// DryRun retorna a prévia do job (checks, custo estimado e avisos) sem criá-lo;
+	// também aceito como ?dry_run=true
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// MonitorJobPreview resposta de CreateMonitorJob com dry_run: o plano do MCP e o
+// custo estimado frente à quota diária de scans do tenant. Cada execução de um
+// check conta como um scan.
+type MonitorJobPreview struct {
+	// DryRun sempre true: nenhum job foi criado
+	DryRun bool `json:"dry_run"`
+	*mcp.MonitorPlan
+	RunsPerDay           int `json:"runs_per_day"`
+	EstimatedScansPerDay int `json:"estimated_scans_per_day"`
+	// MaxScansPerDay quota do tenant (0 = sem limite)
+	MaxScansPerDay int  `json:"max_scans_per_day"`
+	ExceedsQuota   bool `json:"exceeds_quota"`
+}
+
 // =============================================================================
 // HUNTING HANDLERS
 // =============================================================================
@@ -64,18 +220,19 @@ func (h *HuntingHandler) Hunt(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	// Verificar scope
-	if !claims.HasAnyScope(models.ScopeHuntingWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: hunting:write")
-	}
-
 	var req HuntRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
 	if req.Target == "" {
-		return response.BadRequest(c, "Target is required")
+		return response.UnprocessableEntity(c, "Target is required")
+	}
+	if err := h.ssrfGuard.CheckTarget(c.Context(), req.Target); err != nil {
+		return handleTargetError(c, err)
+	}
+	if err := h.validateAsync(c.Context(), claims.TenantID, &req.AsyncOptions); err != nil {
+		return handleAsyncError(c, err)
 	}
 
 	// Preparar client_id
@@ -89,11 +246,13 @@ func (h *HuntingHandler) Hunt(c *fiber.Ctx) error {
 
 	// Criar request MCP
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		ClientID:  clientID,
 		UserID:    claims.UserID,
 		Scopes:    scopesToStrings(claims.Scopes),
+		Priority:  req.Priority,
+		Async:     req.Async,
 	}
 
 	huntReq := &mcp.HuntRequest{
@@ -104,23 +263,181 @@ func (h *HuntingHandler) Hunt(c *fiber.Ctx) error {
 	}
 
 	// Executar hunting via MCP
+	release, err := h.jobLimiter.Acquire(c.Context(), claims.TenantID)
+	if err != nil {
+		return handleJobLimitError(c, err)
+	}
+	defer release()
+
 	result, err := h.mcpClient.Hunt(c.Context(), mcpReq, huntReq)
 	if err != nil {
 		return handleMCPError(c, err)
 	}
+	if result.Status == mcp.StatusPartial {
+		logger.FromContext(c).WithField("warnings", result.Warnings).Warn("MCP returned partial hunt results for %s", result.Target)
+	}
+	if req.Async && result.Status == mcp.StatusProcessing {
+		return h.acceptAsync(c, result.HuntID, mcp.OpHunt, result.Target, req.AsyncOptions)
+	}
 
 	return response.Success(c, result)
 }
 
-// ScanURL executa um scan de URL
-func (h *HuntingHandler) ScanURL(c *fiber.Ctx) error {
+// HuntBatch executa o hunting de vários alvos. Os alvos são distribuídos entre
+// workers que ocupam um slot de job por alvo, então o lote nunca passa do limite de
+// jobs simultâneos do tenant (espera slots liberados em vez de falhar). Falhas são
+// reportadas por alvo; o lote só falha por erro na request. Cancelado o contexto, os
+// hunts em andamento são interrompidos e os alvos restantes saem como cancelled.
+func (h *HuntingHandler) HuntBatch(c *fiber.Ctx) error {
 	claims := getClaims(c)
 	if claims == nil {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	if !claims.HasAnyScope(models.ScopeHuntingWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: hunting:write")
+	var req HuntBatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if len(req.Targets) == 0 {
+		return response.UnprocessableEntity(c, "Targets is required")
+	}
+	if len(req.Targets) > maxHuntBatchTargets {
+		return response.UnprocessableEntity(c, fmt.Sprintf("At most %d targets per batch", maxHuntBatchTargets))
+	}
+	for i, target := range req.Targets {
+		req.Targets[i] = strings.TrimSpace(target)
+		if req.Targets[i] == "" {
+			return response.UnprocessableEntity(c, fmt.Sprintf("targets[%d] is empty", i))
+		}
+	}
+
+	var clientID *uuid.UUID
+	if req.ClientID != nil {
+		parsed, err := uuid.Parse(*req.ClientID)
+		if err == nil {
+			clientID = &parsed
+		}
+	}
+
+	ctx, cancel := context.WithCancel(c.Context())
+	defer cancel()
+
+	workers := maxHuntBatchWorkers
+	if h.jobLimiter != nil {
+		workers = min(workers, h.jobLimiter.Limit(ctx, claims.TenantID))
+	}
+	workers = min(workers, len(req.Targets))
+
+	log := logger.FromContext(c)
+	tenantID := claims.TenantID.String()
+	requestID := response.RequestID(c)
+	scopes := scopesToStrings(claims.Scopes)
+	items := make([]HuntBatchItem, len(req.Targets))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				mcpReq := &mcp.MCPRequest{
+					RequestID: requestID,
+					TenantID:  claims.TenantID,
+					ClientID:  clientID,
+					UserID:    claims.UserID,
+					Scopes:    scopes,
+				}
+				huntReq := &mcp.HuntRequest{
+					Target:       req.Targets[i],
+					IncludeLeaks: req.IncludeLeaks,
+					DeepAnalysis: req.DeepAnalysis,
+					Keywords:     req.Keywords,
+				}
+
+				items[i] = h.huntBatchTarget(ctx, mcpReq, huntReq)
+				middleware.RecordHuntingOperation(tenantID, mcp.OpHunt, items[i].Status)
+				if items[i].Status == huntBatchFailed {
+					log.WithField("target", huntReq.Target).Warn("batch hunt failed: %s", items[i].Error)
+				}
+			}
+		}()
+	}
+
+	for i := range req.Targets {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	result := HuntBatchResponse{
+		Results: items,
+		Summary: HuntBatchSummary{Total: len(items)},
+	}
+	for _, item := range items {
+		switch item.Status {
+		case mcp.StatusCompleted:
+			result.Summary.Completed++
+		case mcp.StatusPartial:
+			result.Summary.Partial++
+		case mcp.StatusProcessing:
+			result.Summary.Processing++
+		case huntBatchCancelled:
+			result.Summary.Cancelled++
+		default:
+			result.Summary.Failed++
+		}
+	}
+
+	return response.Success(c, result)
+}
+
+// huntBatchTarget executa o hunt de um alvo do lote, esperando um slot de job livre
+func (h *HuntingHandler) huntBatchTarget(ctx context.Context, mcpReq *mcp.MCPRequest, huntReq *mcp.HuntRequest) HuntBatchItem {
+	item := HuntBatchItem{Target: huntReq.Target}
+	if err := h.ssrfGuard.CheckTarget(ctx, huntReq.Target); err != nil {
+		item.Status, item.Error = huntBatchFailed, err.Error()
+		return item
+	}
+
+	var release func()
+	for release == nil {
+		if ctx.Err() != nil {
+			item.Status, item.Error = huntBatchCancelled, ctx.Err().Error()
+			return item
+		}
+
+		var err error
+		release, err = h.jobLimiter.Acquire(ctx, mcpReq.TenantID)
+		if errors.Is(err, middleware.ErrJobLimitReached) {
+			select {
+			case <-ctx.Done():
+			case <-time.After(huntBatchSlotRetry):
+			}
+		} else if err != nil {
+			item.Status, item.Error = huntBatchFailed, "failed to acquire job slot"
+			return item
+		}
+	}
+	defer release()
+
+	result, err := h.mcpClient.Hunt(ctx, mcpReq, huntReq)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		item.Status, item.Error = huntBatchCancelled, ctx.Err().Error()
+	case err != nil:
+		item.Status, item.Error = huntBatchFailed, err.Error()
+	default:
+		item.Status, item.Result = result.Status, result
+	}
+	return item
+}
+
+// ScanURL executa um scan de URL
+func (h *HuntingHandler) ScanURL(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
 	}
 
 	var req ScanURLRequest
@@ -129,7 +446,17 @@ func (h *HuntingHandler) ScanURL(c *fiber.Ctx) error {
 	}
 
 	if req.URL == "" {
-		return response.BadRequest(c, "URL is required")
+		return response.UnprocessableEntity(c, "URL is required")
+	}
+	targetURL, err := urlnorm.Normalize(req.URL, h.urlOptions)
+	if err != nil {
+		return handleTargetURLError(c, err)
+	}
+	if err := h.ssrfGuard.CheckTarget(c.Context(), targetURL); err != nil {
+		return handleTargetError(c, err)
+	}
+	if err := h.validateAsync(c.Context(), claims.TenantID, &req.AsyncOptions); err != nil {
+		return handleAsyncError(c, err)
 	}
 
 	// Capture types padrão
@@ -146,23 +473,37 @@ func (h *HuntingHandler) ScanURL(c *fiber.Ctx) error {
 	}
 
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		ClientID:  clientID,
 		UserID:    claims.UserID,
 		Scopes:    scopesToStrings(claims.Scopes),
+		Priority:  req.Priority,
+		Async:     req.Async,
 	}
 
 	scanReq := &mcp.ScanRequest{
-		URL:             req.URL,
+		URL:             targetURL,
 		CaptureTypes:    req.CaptureTypes,
 		FollowRedirects: req.FollowRedirects,
 	}
 
+	release, err := h.jobLimiter.Acquire(c.Context(), claims.TenantID)
+	if err != nil {
+		return handleJobLimitError(c, err)
+	}
+	defer release()
+
 	result, err := h.mcpClient.ScanURL(c.Context(), mcpReq, scanReq)
 	if err != nil {
 		return handleMCPError(c, err)
 	}
+	if result.Status == mcp.StatusPartial {
+		logger.FromContext(c).WithField("warnings", result.Warnings).Warn("MCP returned partial scan results for %s", result.URL)
+	}
+	if req.Async && result.Status == mcp.StatusProcessing {
+		return h.acceptAsync(c, result.ScanID, mcp.OpScanURL, result.URL, req.AsyncOptions)
+	}
 
 	return response.Success(c, result)
 }
@@ -174,17 +515,20 @@ func (h *HuntingHandler) AnalyzeURL(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	if !claims.HasAnyScope(models.ScopeAnalyzeWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: analyze:write")
-	}
-
 	var req AnalyzeURLRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
 	if req.URL == "" {
-		return response.BadRequest(c, "URL is required")
+		return response.UnprocessableEntity(c, "URL is required")
+	}
+	targetURL, err := urlnorm.Normalize(req.URL, h.urlOptions)
+	if err != nil {
+		return handleTargetURLError(c, err)
+	}
+	if err := h.ssrfGuard.CheckTarget(c.Context(), targetURL); err != nil {
+		return handleTargetError(c, err)
 	}
 
 	var clientID *uuid.UUID
@@ -196,7 +540,7 @@ func (h *HuntingHandler) AnalyzeURL(c *fiber.Ctx) error {
 	}
 
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		ClientID:  clientID,
 		UserID:    claims.UserID,
@@ -204,11 +548,17 @@ func (h *HuntingHandler) AnalyzeURL(c *fiber.Ctx) error {
 	}
 
 	analyzeReq := &mcp.AnalyzeRequest{
-		URL:          req.URL,
+		URL:          targetURL,
 		IncludeLeaks: req.IncludeLeaks,
 		DeepAnalysis: req.DeepAnalysis,
 	}
 
+	release, err := h.jobLimiter.Acquire(c.Context(), claims.TenantID)
+	if err != nil {
+		return handleJobLimitError(c, err)
+	}
+	defer release()
+
 	result, err := h.mcpClient.AnalyzeURL(c.Context(), mcpReq, analyzeReq)
 	if err != nil {
 		return handleMCPError(c, err)
@@ -224,17 +574,13 @@ func (h *HuntingHandler) SearchLeaks(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	if !claims.HasAnyScope(models.ScopeHuntingRead, models.ScopeHuntingWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: hunting:read or hunting:write")
-	}
-
 	var req LeakSearchReq
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
 	if req.Query == "" {
-		return response.BadRequest(c, "Query is required")
+		return response.UnprocessableEntity(c, "Query is required")
 	}
 
 	if req.Type == "" {
@@ -254,7 +600,7 @@ func (h *HuntingHandler) SearchLeaks(c *fiber.Ctx) error {
 	}
 
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		ClientID:  clientID,
 		UserID:    claims.UserID,
@@ -276,38 +622,211 @@ func (h *HuntingHandler) SearchLeaks(c *fiber.Ctx) error {
 }
 
 // =============================================================================
-// MONITOR HANDLERS
+// ASYNC JOBS
 // =============================================================================
 
-// CreateMonitorJob cria um job de monitoramento
-func (h *HuntingHandler) CreateMonitorJob(c *fiber.Ctx) error {
+// CompleteJobRequest aviso de conclusão de um job assíncrono enviado pelo MCP
+type CompleteJobRequest struct {
+	// completed (padrão) ou failed
+	Status string                 `json:"status"`
+	Data   map[string]interface{} `json:"data,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// GetJob retorna o status de um hunt/scan assíncrono do tenant
+func (h *HuntingHandler) GetJob(c *fiber.Ctx) error {
 	claims := getClaims(c)
 	if claims == nil {
 		return response.Unauthorized(c, "Authentication required")
 	}
+	if h.asyncJobs == nil {
+		return response.NotFound(c, "Job not found")
+	}
 
-	if !claims.HasAnyScope(models.ScopeMonitorWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: monitor:write")
+	jobID, err := response.ParseUUIDParam(c, "job_id")
+	if err != nil {
+		return err
 	}
 
-	var req struct {
-		BrandID       string   `json:"brand_id"`
-		Target        string   `json:"target"`
-		IntervalMins  int      `json:"interval_mins"`
-		EnabledChecks []string `json:"enabled_checks"`
+	job, err := h.asyncJobs.GetByID(c.Context(), jobID, claims.TenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Job not found")
+		}
+		return handleStoreError(c, err, "Failed to load job")
+	}
+
+	return response.Success(c, job)
+}
+
+// CompleteJob recebe do MCP a conclusão de um job assíncrono, grava o resultado e o
+// encaminha para o callback_url do job, assinado como os webhooks de alerta. Avisos
+// repetidos do mesmo job retornam 409 sem disparar o callback de novo.
+func (h *HuntingHandler) CompleteJob(c *fiber.Ctx) error {
+	if h.asyncJobs == nil {
+		return response.NotFound(c, "Job not found")
+	}
+
+	jobID, err := response.ParseUUIDParam(c, "job_id")
+	if err != nil {
+		return err
 	}
 
+	var req CompleteJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if req.Status == "" {
+		req.Status = models.AsyncJobCompleted
+	}
+	if req.Status != models.AsyncJobCompleted && req.Status != models.AsyncJobFailed {
+		return response.UnprocessableEntity(c, "status must be completed or failed")
+	}
+
+	job, err := h.asyncJobs.Complete(c.Context(), jobID, req.Status, req.Data, req.Error)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			return response.NotFound(c, "Job not found")
+		case errors.Is(err, services.ErrJobFinished):
+			return response.Conflict(c, "Job already finished")
+		default:
+			return handleStoreError(c, err, "Failed to complete job")
+		}
+	}
+
+	if job.CallbackURL != "" {
+		h.forwardJob(c, job)
+	}
+
+	return response.Success(c, job)
+}
+
+// forwardJob enfileira o callback do job. O host é validado de novo porque o DNS
+// pode ter mudado desde o registro, e o dispatcher valida o IP efetivamente
+// conectado (e os redirecionamentos) com o mesmo guard; falhas só são logadas, o
+// job já foi concluído.
+func (h *HuntingHandler) forwardJob(c *fiber.Ctx, job *models.AsyncJob) {
+	log := logger.FromContext(c).WithFields(map[string]interface{}{
+		"job_id":    job.ID.String(),
+		"tenant_id": job.TenantID.String(),
+	})
+
+	if h.dispatcher == nil {
+		log.Warn("job callback skipped: no webhook dispatcher configured")
+		return
+	}
+	if err := h.callbackGuard.CheckTarget(c.Context(), job.CallbackURL); err != nil {
+		log.Warn("job callback skipped: %v", err)
+		return
+	}
+
+	secret := ""
+	if h.tenants != nil {
+		settings, err := h.tenants.GetSettings(c.Context(), job.TenantID)
+		if err != nil {
+			log.Warn("failed to load tenant settings for job callback: %v", err)
+			return
+		}
+		secret = settings.WebhookSecret
+	}
+
+	h.dispatcher.DispatchJob(job, secret)
+}
+
+// validateAsync valida as opções assíncronas da request, normalizando o callback_url
+func (h *HuntingHandler) validateAsync(ctx context.Context, tenantID uuid.UUID, opts *AsyncOptions) error {
+	if opts.Async && h.asyncJobs == nil {
+		return errAsyncUnavailable
+	}
+	if opts.CallbackURL != "" {
+		if !opts.Async {
+			return errCallbackWithoutAsync
+		}
+		callbackURL, err := urlnorm.Normalize(opts.CallbackURL, urlnorm.Options{StripFragment: true, BlockPrivate: true})
+		if err != nil {
+			return err
+		}
+		if err := h.callbackGuard.CheckTarget(ctx, callbackURL); err != nil {
+			return err
+		}
+		// O callback é assinado com o segredo de webhook do tenant; sem ele seria recusado
+		if h.tenants == nil {
+			return errCallbackNoSecret
+		}
+		settings, err := h.tenants.GetSettings(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to load tenant settings: %w", err)
+		}
+		if settings.WebhookSecret == "" {
+			return errCallbackNoSecret
+		}
+		opts.CallbackURL = callbackURL
+	}
+
+	if opts.Priority != "" {
+		if !validPriorities[opts.Priority] {
+			return errInvalidPriority
+		}
+		if h.tenants == nil {
+			return errPriorityNotInPlan
+		}
+		policy, err := h.tenants.GetPolicy(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to load tenant plan: %w", err)
+		}
+		if !h.priorityPlans[policy.Plan] {
+			return errPriorityNotInPlan
+		}
+	}
+
+	return nil
+}
+
+// acceptAsync registra o job aceito pelo MCP e responde 202 com a URL de status
+func (h *HuntingHandler) acceptAsync(c *fiber.Ctx, jobID uuid.UUID, operation, target string, opts AsyncOptions) error {
+	claims := getClaims(c)
+	job := &models.AsyncJob{
+		ID:          jobID,
+		TenantID:    claims.TenantID,
+		UserID:      claims.UserID,
+		Operation:   operation,
+		Target:      target,
+		Priority:    opts.Priority,
+		CallbackURL: opts.CallbackURL,
+		Status:      models.AsyncJobPending,
+		CreatedAt:   time.Now(),
+	}
+	if err := h.asyncJobs.Create(c.Context(), job); err != nil {
+		return handleStoreError(c, err, "Failed to register async job")
+	}
+
+	return response.AsyncJob(c, jobID, "/v1/hunting/jobs/"+jobID.String())
+}
+
+// =============================================================================
+// MONITOR HANDLERS
+// =============================================================================
+
+// CreateMonitorJob cria um job de monitoramento
+func (h *HuntingHandler) CreateMonitorJob(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	var req CreateMonitorJobRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body")
 	}
 
 	if req.BrandID == "" || req.Target == "" {
-		return response.BadRequest(c, "brand_id and target are required")
+		return response.UnprocessableEntity(c, "brand_id and target are required")
 	}
 
 	brandID, err := uuid.Parse(req.BrandID)
 	if err != nil {
-		return response.BadRequest(c, "Invalid brand_id")
+		return response.UnprocessableEntity(c, "Invalid brand_id")
 	}
 
 	if req.IntervalMins == 0 {
@@ -319,7 +838,7 @@ func (h *HuntingHandler) CreateMonitorJob(c *fiber.Ctx) error {
 	}
 
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		UserID:    claims.UserID,
 		Scopes:    scopesToStrings(claims.Scopes),
@@ -332,6 +851,10 @@ func (h *HuntingHandler) CreateMonitorJob(c *fiber.Ctx) error {
 		EnabledChecks: req.EnabledChecks,
 	}
 
+	if req.DryRun || c.QueryBool("dry_run") {
+		return h.previewMonitorJob(c, mcpReq, monitorReq)
+	}
+
 	result, err := h.mcpClient.CreateMonitorJob(c.Context(), mcpReq, monitorReq)
 	if err != nil {
 		return handleMCPError(c, err)
@@ -340,6 +863,41 @@ func (h *HuntingHandler) CreateMonitorJob(c *fiber.Ctx) error {
 	return response.Created(c, result)
 }
 
+// previewMonitorJob responde o plano do job de monitoramento sem criá-lo
+func (h *HuntingHandler) previewMonitorJob(c *fiber.Ctx, mcpReq *mcp.MCPRequest, monitorReq *mcp.MonitorJobRequest) error {
+	if monitorReq.IntervalMins < 0 {
+		return response.UnprocessableEntity(c, "interval_mins must be positive")
+	}
+
+	plan, err := h.mcpClient.PlanMonitorJob(c.Context(), mcpReq, monitorReq)
+	if err != nil {
+		return handleMCPError(c, err)
+	}
+
+	runsPerDay := (24*60 + plan.IntervalMins - 1) / plan.IntervalMins
+	preview := MonitorJobPreview{
+		DryRun:               true,
+		MonitorPlan:          plan,
+		RunsPerDay:           runsPerDay,
+		EstimatedScansPerDay: runsPerDay * len(plan.PlannedChecks),
+	}
+
+	if h.tenants != nil {
+		policy, err := h.tenants.GetPolicy(c.Context(), mcpReq.TenantID)
+		if err != nil {
+			return handleStoreError(c, err, "Failed to load tenant quotas")
+		}
+		preview.MaxScansPerDay = policy.Quotas.MaxScansPerDay
+		if preview.MaxScansPerDay > 0 && preview.EstimatedScansPerDay > preview.MaxScansPerDay {
+			preview.ExceedsQuota = true
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf(
+				"estimated %d scans per day exceed the tenant quota of %d", preview.EstimatedScansPerDay, preview.MaxScansPerDay))
+		}
+	}
+
+	return response.Success(c, preview)
+}
+
 // StopMonitorJob para um job de monitoramento
 func (h *HuntingHandler) StopMonitorJob(c *fiber.Ctx) error {
 	claims := getClaims(c)
@@ -347,17 +905,13 @@ func (h *HuntingHandler) StopMonitorJob(c *fiber.Ctx) error {
 		return response.Unauthorized(c, "Authentication required")
 	}
 
-	if !claims.HasAnyScope(models.ScopeMonitorWrite) && !claims.IsAdmin() {
-		return response.Forbidden(c, "Missing scope: monitor:write")
-	}
-
-	jobID, err := uuid.Parse(c.Params("job_id"))
+	jobID, err := response.ParseUUIDParam(c, "job_id")
 	if err != nil {
-		return response.BadRequest(c, "Invalid job_id")
+		return err
 	}
 
 	mcpReq := &mcp.MCPRequest{
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: response.RequestID(c),
 		TenantID:  claims.TenantID,
 		UserID:    claims.UserID,
 		Scopes:    scopesToStrings(claims.Scopes),
@@ -385,19 +939,77 @@ func scopesToStrings(scopes []models.Scope) []string {
 	return result
 }
 
+func handleJobLimitError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, middleware.ErrJobLimitReached) {
+		return response.Fail(c, response.CodeJobLimitExceeded, "Tenant "+err.Error()+": retry when a running job finishes")
+	}
+	return response.InternalServerError(c, "Failed to acquire job slot")
+}
+
+// handleTargetURLError responde a uma URL de alvo rejeitada por urlnorm.Normalize
+func handleTargetURLError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, urlnorm.ErrPrivateAddress) {
+		return response.Fail(c, response.CodeTargetBlocked, "URL points to a private or local address")
+	}
+	return response.BadRequest(c, "Invalid URL: "+err.Error())
+}
+
+// handleTargetError responde a um alvo rejeitado pelo ssrf.Guard
+func handleTargetError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ssrf.ErrInvalidTarget) {
+		return response.BadRequest(c, "Invalid target")
+	}
+	return response.Fail(c, response.CodeTargetBlocked, "Target not allowed: "+err.Error())
+}
+
+// handleAsyncError responde a opções assíncronas rejeitadas por validateAsync
+func handleAsyncError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errAsyncUnavailable):
+		return response.UnprocessableEntity(c, "Async execution is not available")
+	case errors.Is(err, errCallbackWithoutAsync):
+		return response.UnprocessableEntity(c, "callback_url requires async=true")
+	case errors.Is(err, errCallbackNoSecret):
+		return response.UnprocessableEntity(c, "callback_url requires a webhook_secret in the tenant settings")
+	case errors.Is(err, errInvalidPriority):
+		return response.UnprocessableEntity(c, "priority must be one of low, normal, high")
+	case errors.Is(err, errPriorityNotInPlan):
+		return response.Fail(c, response.CodePlanRequired, "Priority is not available in your plan")
+	case errors.Is(err, urlnorm.ErrInvalidURL), errors.Is(err, urlnorm.ErrUnsupportedScheme), errors.Is(err, ssrf.ErrInvalidTarget):
+		return response.BadRequest(c, "Invalid callback_url: "+err.Error())
+	case errors.Is(err, urlnorm.ErrPrivateAddress), errors.Is(err, ssrf.ErrBlockedAddress), errors.Is(err, ssrf.ErrUnresolvable):
+		return response.Fail(c, response.CodeTargetBlocked, "Callback URL not allowed: "+err.Error())
+	default:
+		return response.InternalServerError(c, "Failed to validate async options")
+	}
+}
+
+// handleMCPError mapeia erros do cliente MCP para respostas. Os erros chegam
+// embrulhados (ex.: "failed after N attempts"), por isso a comparação usa errors.Is.
 func handleMCPError(c *fiber.Ctx, err error) error {
-	switch err {
-	case mcp.ErrMCPUnauthorized:
-		return response.Unauthorized(c, "MCP authentication failed")
-	case mcp.ErrMCPForbidden:
-		return response.Forbidden(c, "Tool not allowed by policy")
-	case mcp.ErrMCPNotFound:
-		return response.NotFound(c, "Resource not found")
-	case mcp.ErrMCPRateLimit:
-		return response.TooManyRequests(c, "Rate limit exceeded")
-	case mcp.ErrMCPUnavailable:
-		return response.ServiceUnavailable(c, "MCP service unavailable")
+	switch {
+	case errors.Is(err, mcp.ErrMCPUnauthorized):
+		return response.Fail(c, response.CodeMCPAuthFailed, "MCP authentication failed")
+	case errors.Is(err, mcp.ErrMCPForbidden):
+		return response.Fail(c, response.CodeMCPToolForbidden, "Tool not allowed by policy")
+	case errors.Is(err, mcp.ErrMCPNotFound):
+		return response.Fail(c, response.CodeMCPNotFound, "Resource not found")
+	case errors.Is(err, mcp.ErrMCPRateLimit):
+		return response.Fail(c, response.CodeMCPRateLimited, "Rate limit exceeded")
+	case errors.Is(err, mcp.ErrMCPTimeout):
+		return response.Fail(c, response.CodeMCPTimeout, "MCP request timed out")
+	case errors.Is(err, mcp.ErrMCPUnavailable):
+		return response.Fail(c, response.CodeMCPUnavailable, "MCP service unavailable")
 	default:
-		return response.InternalServerError(c, "MCP request failed: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "MCP request failed: "+err.Error())
+	}
+}
+
+// successWithETag responde com ETag calculado do conteúdo, ou 304 se o cliente já tem a versão atual
+func successWithETag(c *fiber.Ctx, data interface{}) error {
+	etag, err := response.ContentETag(data)
+	if err != nil {
+		return response.Success(c, data)
 	}
+	return response.SuccessWithETag(c, data, etag)
 }
@@ -0,0 +1,130 @@
+package handlers_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/testutil"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// newHuntApp monta POST /v1/hunt sobre o MCP fake
+func newHuntApp(jwtManager *auth.JWTManager, fake *mcp.FakeMCPClient) *fiber.App {
+	h := handlers.NewHuntingHandler(fake, handlers.HuntingHandlerConfig{})
+	app := testutil.NewApp()
+	app.Post("/v1/hunt", middleware.NewAuthMiddleware(jwtManager).Authenticate(), h.Hunt)
+	return app
+}
+
+// O MCP fake responde com os dados definidos pelo teste e registra a request enviada
+func TestHuntReturnsMCPResult(t *testing.T) {
+	jwtManager := testutil.NewJWTManager()
+	user := testutil.NewUser(models.RoleAnalyst)
+	huntID := uuid.New()
+
+	fake := mcp.NewFakeMCPClient(nil)
+	fake.HuntFunc = func(ctx context.Context, req *mcp.MCPRequest, huntReq *mcp.HuntRequest) (*mcp.HuntResponse, error) {
+		return &mcp.HuntResponse{
+			HuntID:   huntID,
+			TenantID: req.TenantID,
+			Target:   huntReq.Target,
+			Status:   mcp.StatusCompleted,
+			Results:  map[string]interface{}{"threats": []interface{}{"acme-login.com"}},
+		}, nil
+	}
+	app := newHuntApp(jwtManager, fake)
+
+	body := map[string]interface{}{"target": "acme.com", "include_leaks": true}
+	resp, err := app.Test(testutil.AuthRequest(t, jwtManager, user, fiber.MethodPost, "/v1/hunt", body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got mcp.HuntResponse
+	testutil.Decode(t, resp, &got)
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if got.HuntID != huntID || got.Target != "acme.com" || got.TenantID != user.TenantID {
+		t.Errorf("hunt response = %+v", got)
+	}
+
+	calls := fake.Calls()
+	if len(calls) != 1 || calls[0].Operation != mcp.OpHunt {
+		t.Fatalf("MCP calls = %+v, want one hunt", calls)
+	}
+	if req := calls[0].Request; req.TenantID != user.TenantID || req.UserID != user.ID || req.Tool != "hunting" {
+		t.Errorf("MCP request = %+v", req)
+	}
+}
+
+func TestHuntMapsMCPErrors(t *testing.T) {
+	jwtManager := testutil.NewJWTManager()
+	user := testutil.NewUser(models.RoleAnalyst)
+
+	for _, tc := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{mcp.ErrMCPUnavailable, fiber.StatusServiceUnavailable, response.CodeMCPUnavailable},
+		{fmt.Errorf("MCP request failed after 2 attempts: %w", mcp.ErrMCPTimeout), fiber.StatusGatewayTimeout, response.CodeMCPTimeout},
+		{mcp.ErrMCPRateLimit, fiber.StatusTooManyRequests, response.CodeMCPRateLimited},
+		{mcp.ErrMCPForbidden, fiber.StatusForbidden, response.CodeMCPToolForbidden},
+	} {
+		fake := mcp.NewFakeMCPClient(nil)
+		fake.HuntFunc = func(ctx context.Context, req *mcp.MCPRequest, huntReq *mcp.HuntRequest) (*mcp.HuntResponse, error) {
+			return nil, tc.err
+		}
+		app := newHuntApp(jwtManager, fake)
+
+		resp, err := app.Test(testutil.AuthRequest(t, jwtManager, user, fiber.MethodPost, "/v1/hunt", map[string]string{"target": "acme.com"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope := testutil.Decode(t, resp, nil)
+		if resp.StatusCode != tc.status || envelope.Error == nil || envelope.Error.Code != tc.code {
+			t.Errorf("%v: status = %d, error = %+v", tc.err, resp.StatusCode, envelope.Error)
+		}
+	}
+}
+
+// Um job_id que não é UUID é erro do cliente (400), sem chegar ao MCP
+func TestMalformedJobIDIs400(t *testing.T) {
+	jwtManager := testutil.NewJWTManager()
+	user := testutil.NewUser(models.RoleAnalyst)
+	fake := mcp.NewFakeMCPClient(nil)
+
+	h := handlers.NewHuntingHandler(fake, handlers.HuntingHandlerConfig{})
+	app := testutil.NewApp()
+	app.Post("/v1/monitor/jobs/:job_id/stop", middleware.NewAuthMiddleware(jwtManager).Authenticate(), h.StopMonitorJob)
+
+	for _, jobID := range []string{"not-a-uuid", "123", "6ba7b810-9dad-11d1-80b4"} {
+		resp, err := app.Test(testutil.AuthRequest(t, jwtManager, user, fiber.MethodPost, "/v1/monitor/jobs/"+jobID+"/stop", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope := testutil.Decode(t, resp, nil)
+		if resp.StatusCode != fiber.StatusBadRequest || envelope.Error == nil || envelope.Error.Code != response.CodeBadRequest {
+			t.Errorf("job %q: status = %d, error = %+v", jobID, resp.StatusCode, envelope.Error)
+		}
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("MCP calls = %+v, want none", calls)
+	}
+
+	resp, err := app.Test(testutil.AuthRequest(t, jwtManager, user, fiber.MethodPost, "/v1/monitor/jobs/"+uuid.NewString()+"/stop", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode == fiber.StatusBadRequest || len(fake.Calls()) != 1 {
+		t.Errorf("valid job id: status = %d, MCP calls = %d, want the request forwarded", resp.StatusCode, len(fake.Calls()))
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"net/http"
 
 	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/domain"
+	"github.com/arcaintelligence/arca-gateway/pkg/request"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -11,11 +15,11 @@ import (
 
 // OnboardingHandler handler para operações de onboarding
 type OnboardingHandler struct {
-	mcpClient *mcp.MCPClient
+	mcpClient mcp.Client
 }
 
 // NewOnboardingHandler cria um novo handler de onboarding
-func NewOnboardingHandler(mcpClient *mcp.MCPClient) *OnboardingHandler {
+func NewOnboardingHandler(mcpClient mcp.Client) *OnboardingHandler {
 	return &OnboardingHandler{
 		mcpClient: mcpClient,
 	}
@@ -83,10 +87,9 @@ func (h *OnboardingHandler) Register(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return response.BadRequest(c, "Invalid request body: "+err.Error())
 	}
-
-	// Validar campos obrigatórios
-	if req.CompanyName == "" || req.Email == "" || req.Plan == "" {
-		return response.BadRequest(c, "Missing required fields: company_name, email and plan are required")
+	req.Email = models.NormalizeEmail(req.Email)
+	if err := request.Validate(&req); err != nil {
+		return request.RespondError(c, err)
 	}
 
 	// Chamar Core Python via MCP
@@ -106,17 +109,17 @@ func (h *OnboardingHandler) Register(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodPost, "/v1/onboarding/register", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to register client: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to register client: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusCreated, resp)
 }
 
 // VerifyEmail verifica o email do cliente
 func (h *OnboardingHandler) VerifyEmail(c *fiber.Ctx) error {
-	var req VerifyEmailRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body: "+err.Error())
+	req, err := request.BindAndValidate[VerifyEmailRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
 	}
 
 	mcpReq := &mcp.MCPRequest{
@@ -132,31 +135,40 @@ func (h *OnboardingHandler) VerifyEmail(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodPost, "/v1/onboarding/verify-email", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to verify email: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to verify email: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // CreateBrand cria uma nova marca para o cliente
 func (h *OnboardingHandler) CreateBrand(c *fiber.Ctx) error {
-	var req BrandCreateRequest
-	if err := c.BodyParser(&req); err != nil {
-		return response.BadRequest(c, "Invalid request body: "+err.Error())
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
 	}
 
-	// Obter client_id do contexto (JWT) ou header
+	req, err := request.BindAndValidate[BrandCreateRequest](c)
+	if err != nil {
+		return request.RespondError(c, err)
+	}
+	domainName, err := domain.Normalize(req.Domain)
+	if err != nil {
+		return response.BadRequest(c, "domain: "+err.Error())
+	}
+
+	// Obter client_id do contexto (JWT ou X-Client-ID validado por RequireClientAccess)
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	// Preparar params
 	params := map[string]interface{}{
 		"name":      req.Name,
-		"domain":    req.Domain,
+		"domain":    domainName,
 		"client_id": clientID,
 	}
 
@@ -192,7 +204,9 @@ func (h *OnboardingHandler) CreateBrand(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "onboarding",
 		Action:    "create_brand",
@@ -201,20 +215,25 @@ func (h *OnboardingHandler) CreateBrand(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodPost, "/v1/brands", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to create brand: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to create brand: "+err.Error())
 	}
 
-	return response.Created(c, resp.Data)
+	return respondMCP(c, fiber.StatusCreated, resp)
 }
 
 // GetBrand obtém detalhes de uma marca
 func (h *OnboardingHandler) GetBrand(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	brandID := c.Params("brand_id")
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	var clientUUID *uuid.UUID
@@ -226,7 +245,9 @@ func (h *OnboardingHandler) GetBrand(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "onboarding",
 		Action:    "get_monitoring_status",
@@ -238,19 +259,24 @@ func (h *OnboardingHandler) GetBrand(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodGet, "/v1/brands/"+brandID, mcpReq)
 	if err != nil {
-		return response.NotFound(c, "Brand not found: "+err.Error())
+		return handleMCPError(c, err)
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // ListBrands lista todas as marcas do cliente
 func (h *OnboardingHandler) ListBrands(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	var clientUUID *uuid.UUID
@@ -262,7 +288,9 @@ func (h *OnboardingHandler) ListBrands(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "onboarding",
 		Action:    "list_brands",
@@ -273,20 +301,25 @@ func (h *OnboardingHandler) ListBrands(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodGet, "/v1/brands", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to list brands: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to list brands: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // StartMonitoring inicia o monitoramento de uma marca
 func (h *OnboardingHandler) StartMonitoring(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	brandID := c.Params("brand_id")
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	var req StartMonitoringRequest
@@ -305,7 +338,9 @@ func (h *OnboardingHandler) StartMonitoring(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "monitoring",
 		Action:    "start",
@@ -320,20 +355,25 @@ func (h *OnboardingHandler) StartMonitoring(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodPost, "/v1/brands/"+brandID+"/monitoring/start", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to start monitoring: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to start monitoring: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // StopMonitoring para o monitoramento de uma marca
 func (h *OnboardingHandler) StopMonitoring(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	brandID := c.Params("brand_id")
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	var clientUUID *uuid.UUID
@@ -345,7 +385,9 @@ func (h *OnboardingHandler) StopMonitoring(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "monitoring",
 		Action:    "stop",
@@ -357,20 +399,25 @@ func (h *OnboardingHandler) StopMonitoring(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodPost, "/v1/brands/"+brandID+"/monitoring/stop", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to stop monitoring: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to stop monitoring: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // GetMonitoringStatus obtém o status do monitoramento
 func (h *OnboardingHandler) GetMonitoringStatus(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	brandID := c.Params("brand_id")
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 
 	var clientUUID *uuid.UUID
@@ -382,7 +429,9 @@ func (h *OnboardingHandler) GetMonitoringStatus(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "monitoring",
 		Action:    "status",
@@ -394,19 +443,24 @@ func (h *OnboardingHandler) GetMonitoringStatus(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodGet, "/v1/brands/"+brandID+"/monitoring/status", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to get monitoring status: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to get monitoring status: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
 }
 
 // GetThreats obtém ameaças detectadas
 func (h *OnboardingHandler) GetThreats(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
 	clientID := ""
 	if cid, ok := c.Locals("client_id").(string); ok && cid != "" {
 		clientID = cid
-	} else {
-		clientID = c.Get("X-Client-ID")
+	} else if cid := middleware.GetClientID(c); cid != nil {
+		clientID = cid.String()
 	}
 	brandID := c.Query("brand_id")
 	status := c.Query("status")
@@ -421,7 +475,9 @@ func (h *OnboardingHandler) GetThreats(c *fiber.Ctx) error {
 
 	mcpReq := &mcp.MCPRequest{
 		RequestID: uuid.New().String(),
-		TenantID:  uuid.New(),
+		TenantID:  claims.TenantID,
+		UserID:    claims.UserID,
+		Scopes:    scopesToStrings(claims.Scopes),
 		ClientID:  clientUUID,
 		Tool:      "threats",
 		Action:    "list",
@@ -435,8 +491,32 @@ func (h *OnboardingHandler) GetThreats(c *fiber.Ctx) error {
 
 	resp, err := h.mcpClient.ProxyRequest(c.Context(), http.MethodGet, "/v1/threats", mcpReq)
 	if err != nil {
-		return response.Error(c, fiber.StatusBadGateway, "MCP_ERROR", "Failed to get threats: "+err.Error())
+		return response.Fail(c, response.CodeMCPError, "Failed to get threats: "+err.Error())
 	}
 
-	return response.Success(c, resp.Data)
+	return respondMCP(c, fiber.StatusOK, resp)
+}
+
+// =============================================================================
+// HELPERS
+// =============================================================================
+
+// respondMCP devolve a resposta do MCP no envelope padrão do gateway, sem o envelope
+// do próprio MCP. status é o da operação: 201 para criação, 200 para leituras e
+// comandos. Listagens paginadas saem como response.Paginated; quando o MCP aceita a
+// operação como job assíncrono, um 200 vira 202 e o job_id vai para meta.
+func respondMCP(c *fiber.Ctx, status int, resp *mcp.MCPResponse) error {
+	payload := resp.Payload()
+	if payload.Paginated {
+		return response.Paginated(c, payload.Data, payload.Page, payload.PerPage, payload.Total)
+	}
+
+	var meta *response.Meta
+	if payload.JobID != "" {
+		meta = &response.Meta{JobID: payload.JobID}
+		if status == fiber.StatusOK {
+			status = fiber.StatusAccepted
+		}
+	}
+	return response.SuccessWithMeta(c, status, payload.Data, meta)
 }
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// Período padrão do relatório quando from não é informado
+	defaultReportPeriod = 30 * 24 * time.Hour
+	// Maior período aceito por relatório
+	maxReportPeriod = 366 * 24 * time.Hour
+	// Tempo máximo de geração de um relatório assíncrono
+	reportJobTimeout = 5 * time.Minute
+	// Por quanto tempo o resultado de um relatório assíncrono fica disponível
+	reportJobRetention = time.Hour
+)
+
+// Status de um relatório assíncrono
+const (
+	reportJobPending   = "pending"
+	reportJobCompleted = "completed"
+	reportJobFailed    = "failed"
+)
+
+// ReportHandler handlers de relatórios
+type ReportHandler struct {
+	reportService ReportStore
+
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*reportJob
+}
+
+// reportJob relatório gerado em background (mantido em memória por reportJobRetention)
+type reportJob struct {
+	TenantID   uuid.UUID
+	Status     string
+	Summary    *models.ReportSummary
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// ReportJobResponse status de um relatório assíncrono
+type ReportJobResponse struct {
+	JobID       uuid.UUID  `json:"job_id"`
+	Status      string     `json:"status"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// NewReportHandler cria um novo handler de relatórios
+func NewReportHandler(reportService ReportStore) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		jobs:          make(map[uuid.UUID]*reportJob),
+	}
+}
+
+// =============================================================================
+// REPORT HANDLERS
+// =============================================================================
+
+// Summary retorna o resumo de alertas e monitoramento do período.
+// Parâmetros: client_id, from e to (RFC3339; padrão últimos 30 dias).
+func (h *ReportHandler) Summary(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	summary, err := h.reportService.Summary(c.Context(), tenantID, filter)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to generate report")
+	}
+
+	return response.Success(c, summary)
+}
+
+// CreateSummaryJob gera o resumo em background para períodos longos. O resultado
+// é consultado em GET /v1/reports/jobs/:job_id e baixado como JSON em .../download.
+func (h *ReportHandler) CreateSummaryJob(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+
+	if format := c.Query("format", "json"); format != "json" {
+		return response.UnprocessableEntity(c, "Unsupported report format: only json is available")
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return response.BadRequest(c, err.Error())
+	}
+
+	jobID := uuid.New()
+	h.mu.Lock()
+	h.sweepJobs()
+	h.jobs[jobID] = &reportJob{
+		TenantID:  tenantID,
+		Status:    reportJobPending,
+		CreatedAt: time.Now(),
+	}
+	h.mu.Unlock()
+
+	log := logger.FromContext(c)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), reportJobTimeout)
+		defer cancel()
+
+		summary, err := h.reportService.Summary(ctx, tenantID, filter)
+		finishedAt := time.Now()
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		job := h.jobs[jobID]
+		job.FinishedAt = &finishedAt
+		if err != nil {
+			log.WithError(err).Error("report job %s failed", jobID)
+			job.Status = reportJobFailed
+			job.Error = "Failed to generate report"
+			return
+		}
+		job.Status = reportJobCompleted
+		job.Summary = summary
+	}()
+
+	return response.AsyncJob(c, jobID, "/v1/reports/jobs/"+jobID.String())
+}
+
+// GetJob retorna o status de um relatório assíncrono
+func (h *ReportHandler) GetJob(c *fiber.Ctx) error {
+	jobID, err := response.ParseUUIDParam(c, "job_id")
+	if err != nil {
+		return err
+	}
+
+	job, ok := h.tenantJob(jobID, middleware.GetTenantID(c))
+	if !ok {
+		return response.NotFound(c, "Report job not found")
+	}
+
+	resp := ReportJobResponse{
+		JobID:      jobID,
+		Status:     job.Status,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.Status == reportJobCompleted {
+		resp.DownloadURL = "/v1/reports/jobs/" + jobID.String() + "/download"
+	}
+	return successWithETag(c, resp)
+}
+
+// DownloadJob baixa o resultado de um relatório assíncrono concluído
+func (h *ReportHandler) DownloadJob(c *fiber.Ctx) error {
+	jobID, err := response.ParseUUIDParam(c, "job_id")
+	if err != nil {
+		return err
+	}
+
+	job, ok := h.tenantJob(jobID, middleware.GetTenantID(c))
+	if !ok {
+		return response.NotFound(c, "Report job not found")
+	}
+
+	if job.Status != reportJobCompleted {
+		return response.Conflict(c, "Report is not ready: status "+job.Status)
+	}
+
+	data, err := json.Marshal(job.Summary)
+	if err != nil {
+		return response.InternalServerError(c, "Failed to encode report")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="report-%s.json"`, jobID))
+	return c.Send(data)
+}
+
+// =============================================================================
+// HELPERS
+// =============================================================================
+
+// tenantJob retorna uma cópia do job se ele existir e pertencer ao tenant
+func (h *ReportHandler) tenantJob(jobID, tenantID uuid.UUID) (reportJob, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	job, ok := h.jobs[jobID]
+	if !ok || job.TenantID != tenantID {
+		return reportJob{}, false
+	}
+	return *job, true
+}
+
+// sweepJobs remove jobs finalizados há mais de reportJobRetention (h.mu deve estar travado)
+func (h *ReportHandler) sweepJobs() {
+	for id, job := range h.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > reportJobRetention {
+			delete(h.jobs, id)
+		}
+	}
+}
+
+// reportFilter lê client_id, from e to da query
+func reportFilter(c *fiber.Ctx) (services.ReportFilter, error) {
+	filter := services.ReportFilter{To: time.Now().UTC()}
+
+	if v := c.Query("client_id"); v != "" {
+		clientID, err := uuid.Parse(v)
+		if err != nil {
+			return filter, errors.New("Invalid client_id")
+		}
+		filter.ClientID = &clientID
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, errors.New("Invalid to: must be RFC3339")
+		}
+		filter.To = to
+	}
+	filter.From = filter.To.Add(-defaultReportPeriod)
+	if v := c.Query("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, errors.New("Invalid from: must be RFC3339")
+		}
+		filter.From = from
+	}
+
+	if !filter.From.Before(filter.To) {
+		return filter, errors.New("Invalid period: from must be before to")
+	}
+	if filter.To.Sub(filter.From) > maxReportPeriod {
+		return filter, errors.New("Invalid period: at most 366 days")
+	}
+	return filter, nil
+}
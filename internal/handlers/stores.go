@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// STORES
+// =============================================================================
+
+// Persistência usada pelos handlers. Os services implementam cada interface sobre
+// o PostgreSQL; internal/testutil tem implementações em memória para testes.
+
+// UserStore usuários (auth e gerenciamento de usuários)
+type UserStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// GetWithCredentials lê o usuário sem cache, com o hash da senha e a versão dos tokens
+	GetWithCredentials(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// ListByEmail retorna todos os usuários com o email, de todos os tenants com uuid.Nil
+	ListByEmail(ctx context.Context, email string, tenantID uuid.UUID) ([]*models.User, error)
+	CreateWithTenant(ctx context.Context, tenant *models.Tenant, user *models.User) error
+	Update(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error
+	VerifyEmail(ctx context.Context, id uuid.UUID, email string) error
+	BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error)
+	DeleteAccount(ctx context.Context, user *models.User, hard bool) error
+}
+
+// ClientStore clientes do tenant
+type ClientStore interface {
+	GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Client, error)
+	ListByTenant(ctx context.Context, tenantID uuid.UUID, page, perPage int) ([]*models.Client, int64, error)
+	ListByTenantAfter(ctx context.Context, tenantID uuid.UUID, after services.Keyset, limit int) ([]*models.Client, bool, error)
+	Each(ctx context.Context, tenantID uuid.UUID, fn func(*models.Client) error) error
+	SlugExists(ctx context.Context, tenantID uuid.UUID, slug string, excludeID uuid.UUID) (bool, error)
+	Create(ctx context.Context, client *models.Client) error
+	Update(ctx context.Context, client *models.Client, unmodifiedSince *time.Time) error
+	Delete(ctx context.Context, id, tenantID uuid.UUID) error
+}
+
+// BrandStore marcas dos clientes
+type BrandStore interface {
+	GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Brand, error)
+	ListByClient(ctx context.Context, clientID, tenantID uuid.UUID, page, perPage int) ([]*models.Brand, int64, error)
+	ListByClientAfter(ctx context.Context, clientID, tenantID uuid.UUID, after services.Keyset, limit int) ([]*models.Brand, bool, error)
+	EachByClient(ctx context.Context, clientID, tenantID uuid.UUID, fn func(*models.Brand) error) error
+	CountByClient(ctx context.Context, clientID uuid.UUID) (int, error)
+	CountByClients(ctx context.Context, clientIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	Create(ctx context.Context, brand *models.Brand) error
+	CreateMany(ctx context.Context, tenantID uuid.UUID, brands []*models.Brand, maxBrands int, allowPartial bool) ([]error, error)
+	Update(ctx context.Context, brand *models.Brand, unmodifiedSince *time.Time) error
+	UpdateMonitoring(ctx context.Context, brand *models.Brand) error
+	IncrementThreatsFound(ctx context.Context, id, tenantID uuid.UUID) error
+	Delete(ctx context.Context, id, tenantID uuid.UUID) error
+}
+
+// TenantStore dados do tenant lidos por auth, clientes, usuários e alertas
+type TenantStore interface {
+	GetBySlug(ctx context.Context, slug string) (*models.Tenant, error)
+	SlugExists(ctx context.Context, slug string) (bool, error)
+	GetStatus(ctx context.Context, id uuid.UUID) (models.Status, error)
+	GetSettings(ctx context.Context, id uuid.UUID) (*models.TenantSettings, error)
+	GetPolicy(ctx context.Context, id uuid.UUID) (*models.TenantPolicy, error)
+}
+
+// TenantAdminStore tenants na administração da plataforma (TenantHandler)
+type TenantAdminStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error)
+	GetPolicy(ctx context.Context, id uuid.UUID) (*models.TenantPolicy, error)
+	Update(ctx context.Context, tenant *models.Tenant) error
+	UpdatePlan(ctx context.Context, id uuid.UUID, plan string) error
+	UpdateQuotas(ctx context.Context, id uuid.UUID, quotas models.TenantQuotas) error
+	UpdateSettings(ctx context.Context, id uuid.UUID, settings models.TenantSettings) error
+	GetRateLimit(ctx context.Context, id uuid.UUID) (int, error)
+	SetRateLimit(ctx context.Context, id uuid.UUID, limit int) error
+	Delete(ctx context.Context, id uuid.UUID, hard bool) error
+}
+
+// MonitoredBrandStore marcas com job de monitoramento no MCP
+type MonitoredBrandStore interface {
+	ListMonitored(ctx context.Context, tenantID *uuid.UUID) ([]*models.Brand, error)
+}
+
+// AlertStore alertas ingeridos e exportados
+type AlertStore interface {
+	Create(ctx context.Context, alert *models.Alert) error
+	Each(ctx context.Context, tenantID uuid.UUID, filter services.AlertFilter, fn func(*models.Alert) error) error
+}
+
+// AsyncJobStore jobs assíncronos de hunting/scan
+type AsyncJobStore interface {
+	Create(ctx context.Context, job *models.AsyncJob) error
+	GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.AsyncJob, error)
+	Complete(ctx context.Context, id uuid.UUID, status string, result map[string]interface{}, jobErr string) (*models.AsyncJob, error)
+}
+
+// AuditStore consulta dos registros de auditoria
+type AuditStore interface {
+	List(ctx context.Context, tenantID uuid.UUID, filter services.AuditFilter, page, perPage int) ([]*models.AuditLog, int64, error)
+}
+
+// ReportStore agregações dos relatórios
+type ReportStore interface {
+	Summary(ctx context.Context, tenantID uuid.UUID, filter services.ReportFilter) (*models.ReportSummary, error)
+}
+
+// LoginEventStore histórico de logins
+type LoginEventStore interface {
+	Record(ctx context.Context, event *models.LoginEvent) error
+	ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*models.LoginEvent, error)
+}
+
+var (
+	_ UserStore        = (*services.UserService)(nil)
+	_ ClientStore      = (*services.ClientService)(nil)
+	_ BrandStore       = (*services.BrandService)(nil)
+	_ TenantStore      = (*services.TenantService)(nil)
+	_ TenantAdminStore = (*services.TenantService)(nil)
+	_ AlertStore       = (*services.AlertService)(nil)
+	_ AsyncJobStore    = (*services.AsyncJobService)(nil)
+	_ AuditStore       = (*services.AuditService)(nil)
+	_ ReportStore      = (*services.ReportService)(nil)
+	_ LoginEventStore  = (*services.LoginEventService)(nil)
+)
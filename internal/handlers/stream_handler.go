@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/notify"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Intervalo dos comentários de keep-alive; também detecta clientes desconectados
+const streamHeartbeat = 15 * time.Second
+
+// Ordem das severidades para o filtro min_severity
+var severityRank = map[string]int{"info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// StreamHandler handlers de streaming (Server-Sent Events)
+type StreamHandler struct {
+	broker notify.AlertBroker
+}
+
+// NewStreamHandler cria um novo handler de streaming
+func NewStreamHandler(broker notify.AlertBroker) *StreamHandler {
+	return &StreamHandler{
+		broker: broker,
+	}
+}
+
+// StreamAlerts envia os alertas ingeridos para o tenant como eventos SSE "alert".
+// Filtros: brand_id e min_severity (info, low, medium, high, critical).
+func (h *StreamHandler) StreamAlerts(c *fiber.Ctx) error {
+	tenantID := middleware.GetTenantID(c)
+
+	var brandID *uuid.UUID
+	if v := c.Query("brand_id"); v != "" {
+		parsed, err := uuid.Parse(v)
+		if err != nil {
+			return response.BadRequest(c, "Invalid brand_id")
+		}
+		brandID = &parsed
+	}
+
+	minSeverity := 0
+	if v := c.Query("min_severity"); v != "" {
+		rank, ok := severityRank[v]
+		if !ok {
+			return response.BadRequest(c, "Invalid min_severity: must be one of info, low, medium, high, critical")
+		}
+		minSeverity = rank
+	}
+
+	sub, err := h.broker.Subscribe(tenantID)
+	if err != nil {
+		if errors.Is(err, notify.ErrTooManyStreams) {
+			return response.TooManyRequests(c, "Too many concurrent alert streams for this tenant")
+		}
+		return response.ServiceUnavailable(c, "Alert streaming unavailable")
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// O servidor aplica WriteTimeout à resposta inteira; o prazo é estendido a cada escrita
+	conn := c.Context().Conn()
+	log := logger.FromContext(c)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		// Uma escrita com erro indica que o cliente desconectou
+		flush := func() bool {
+			_ = conn.SetWriteDeadline(time.Now().Add(2 * streamHeartbeat))
+			return w.Flush() == nil
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+		if !flush() {
+			return
+		}
+
+		for {
+			select {
+			case alert, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if !streamMatches(alert, brandID, minSeverity) {
+					continue
+				}
+
+				data, err := json.Marshal(alert)
+				if err != nil {
+					log.WithError(err).Error("failed to encode streamed alert")
+					continue
+				}
+				fmt.Fprintf(w, "id: %s\nevent: alert\ndata: %s\n\n", alert.ID, data)
+				if !flush() {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+				if !flush() {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// streamMatches aplica os filtros do stream ao alerta
+func streamMatches(alert *models.Alert, brandID *uuid.UUID, minSeverity int) bool {
+	if brandID != nil && alert.BrandID != *brandID {
+		return false
+	}
+	return severityRank[alert.Severity] >= minSeverity
+}
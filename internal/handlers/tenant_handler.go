@@ -0,0 +1,499 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// TenantHandler handlers administrativos de tenants
+type TenantHandler struct {
+	tenantService TenantAdminStore
+	brands        MonitoredBrandStore
+	mcpClient     mcp.Client
+	tenantLimits  *middleware.TenantLimitCache
+	jobLimiter    *middleware.JobLimiter
+	tenantStatus  *middleware.TenantStatusCache
+	webhookGuard  *ssrf.Guard
+	hardDelete    bool
+}
+
+// TenantHandlerConfig configuração do handler de tenants. Os caches são invalidados
+// quando o tenant é alterado, aplicando a mudança nesta instância imediatamente;
+// qualquer um deles pode ser nil.
+type TenantHandlerConfig struct {
+	// Brands e MCP param os jobs de monitoramento das marcas do tenant excluído;
+	// sem eles os jobs continuam no MCP
+	Brands     MonitoredBrandStore
+	MCP        mcp.Client
+	RateLimits *middleware.TenantLimitCache
+	JobLimiter *middleware.JobLimiter
+	Statuses   *middleware.TenantStatusCache
+	// WebhookGuard rejeita webhook_url/slack_webhook que resolvem para endereços internos
+	WebhookGuard *ssrf.Guard
+	// HardDelete apaga os dados do tenant na exclusão; false apenas o inativa
+	HardDelete bool
+}
+
+// NewTenantHandler cria um novo handler de tenants
+func NewTenantHandler(tenantService TenantAdminStore, config TenantHandlerConfig) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+		brands:        config.Brands,
+		mcpClient:     config.MCP,
+		tenantLimits:  config.RateLimits,
+		jobLimiter:    config.JobLimiter,
+		tenantStatus:  config.Statuses,
+		webhookGuard:  config.WebhookGuard,
+		hardDelete:    config.HardDelete,
+	}
+}
+
+// DeleteTenantRequest request de exclusão de tenant
+type DeleteTenantRequest struct {
+	// Confirm precisa repetir o slug do tenant (ou o ID, se ele não tiver slug)
+	Confirm string `json:"confirm"`
+}
+
+// stopMonitorJobs para no MCP os jobs de monitoramento das marcas. Jobs que o MCP
+// não conhece mais são ignorados; outras falhas interrompem a exclusão, já que os
+// jobs ficariam rodando sem dono.
+func (h *TenantHandler) stopMonitorJobs(c *fiber.Ctx, claims *auth.Claims, tenantID uuid.UUID, brands []*models.Brand) error {
+	for _, brand := range brands {
+		mcpReq := &mcp.MCPRequest{
+			RequestID: response.RequestID(c),
+			TenantID:  tenantID,
+			ClientID:  &brand.ClientID,
+			UserID:    claims.UserID,
+			Scopes:    scopesToStrings(claims.Scopes),
+		}
+		if err := h.mcpClient.StopMonitorJob(c.Context(), mcpReq, *brand.MonitoringJobID); err != nil && !errors.Is(err, mcp.ErrMCPNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTenantRequest request de alteração do tenant; campos ausentes não são alterados
+type UpdateTenantRequest struct {
+	Name   *string        `json:"name"`
+	Email  *string        `json:"email"`
+	Status *models.Status `json:"status"`
+}
+
+// UpdatePlanRequest request de troca de plano do tenant
+type UpdatePlanRequest struct {
+	Plan string `json:"plan"`
+}
+
+// Status que um admin pode atribuir a um tenant
+var tenantStatuses = map[models.Status]bool{
+	models.StatusActive:    true,
+	models.StatusInactive:  true,
+	models.StatusSuspended: true,
+}
+
+// GetTenant retorna o tenant com plano, configurações e quotas
+func (h *TenantHandler) GetTenant(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	return h.respondTenant(c, tenantID)
+}
+
+// UpdateTenant altera nome, email e status do tenant. Suspender (ou inativar) o
+// tenant bloqueia o acesso de todos os seus usuários.
+func (h *TenantHandler) UpdateTenant(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateTenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	tenant, err := h.tenantService.GetByID(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to load tenant")
+	}
+
+	if req.Name != nil {
+		tenant.Name = strings.TrimSpace(*req.Name)
+		if tenant.Name == "" {
+			return response.UnprocessableEntity(c, "name cannot be empty")
+		}
+	}
+	if req.Email != nil {
+		tenant.Email = models.NormalizeEmail(*req.Email)
+		if tenant.Email != "" {
+			if _, err := mail.ParseAddress(tenant.Email); err != nil {
+				return response.UnprocessableEntity(c, "Invalid email")
+			}
+		}
+	}
+	if req.Status != nil {
+		if !tenantStatuses[*req.Status] {
+			return response.UnprocessableEntity(c, "status must be active, inactive or suspended")
+		}
+		tenant.Status = *req.Status
+	}
+
+	if err := h.tenantService.Update(c.Context(), tenant); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to update tenant")
+	}
+
+	if h.tenantStatus != nil {
+		h.tenantStatus.Invalidate(tenantID)
+	}
+
+	return response.Success(c, tenant)
+}
+
+// DeleteTenant exclui o tenant. Conforme AUTH_DELETION_MODE ele é inativado (dados
+// mantidos, acesso bloqueado) ou apagado com todos os seus dados.
+func (h *TenantHandler) DeleteTenant(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+	if tenantID == claims.TenantID {
+		return response.Conflict(c, "Cannot delete your own tenant")
+	}
+
+	var req DeleteTenantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	tenant, err := h.tenantService.GetByID(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to load tenant")
+	}
+
+	expected := tenant.Slug
+	if expected == "" {
+		expected = tenant.ID.String()
+	}
+	if req.Confirm != expected {
+		return response.UnprocessableEntity(c, "confirm must match the tenant slug")
+	}
+
+	// Os jobs de monitoramento rodam no MCP e não seriam alcançados depois da exclusão
+	if h.brands != nil && h.mcpClient != nil {
+		brands, err := h.brands.ListMonitored(c.Context(), &tenantID)
+		if err != nil {
+			return handleStoreError(c, err, "Failed to list monitored brands")
+		}
+		if err := h.stopMonitorJobs(c, claims, tenantID, brands); err != nil {
+			return handleMCPError(c, err)
+		}
+	}
+
+	if err := h.tenantService.Delete(c.Context(), tenantID, h.hardDelete); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to delete tenant")
+	}
+
+	if h.tenantStatus != nil {
+		h.tenantStatus.Invalidate(tenantID)
+	}
+	if h.tenantLimits != nil {
+		h.tenantLimits.Invalidate(tenantID)
+	}
+	if h.jobLimiter != nil {
+		h.jobLimiter.Invalidate(tenantID)
+	}
+
+	return response.NoContent(c)
+}
+
+// UpdatePlan troca o plano do tenant
+func (h *TenantHandler) UpdatePlan(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdatePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	plan := strings.ToLower(strings.TrimSpace(req.Plan))
+	if _, ok := middleware.DefaultPlanLimits()[plan]; !ok {
+		return response.UnprocessableEntity(c, "plan must be free, starter, pro or enterprise")
+	}
+
+	if err := h.tenantService.UpdatePlan(c.Context(), tenantID, plan); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to update plan")
+	}
+
+	return h.respondTenant(c, tenantID)
+}
+
+// UpdateQuotas substitui as quotas do tenant
+func (h *TenantHandler) UpdateQuotas(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	var quotas models.TenantQuotas
+	if err := c.BodyParser(&quotas); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if quotas.MaxClients < 0 || quotas.MaxBrands < 0 || quotas.MaxScansPerDay < 0 ||
+		quotas.MaxAlertsPerDay < 0 || quotas.MaxUsersPerTenant < 0 || quotas.StorageLimitMB < 0 {
+		return response.UnprocessableEntity(c, "Quotas must be zero or positive")
+	}
+
+	if err := h.tenantService.UpdateQuotas(c.Context(), tenantID, quotas); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to update quotas")
+	}
+
+	return h.respondTenant(c, tenantID)
+}
+
+// UpdateSettings substitui as configurações (scopes, ferramentas, webhooks) do tenant
+func (h *TenantHandler) UpdateSettings(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	var settings models.TenantSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if err := validateTenantSettings(settings); err != nil {
+		return response.UnprocessableEntity(c, err.Error())
+	}
+	if err := h.checkWebhookTargets(c.Context(), settings); err != nil {
+		if errors.Is(err, ssrf.ErrInvalidTarget) {
+			return response.UnprocessableEntity(c, err.Error())
+		}
+		return response.Fail(c, response.CodeTargetBlocked, "Webhook URL not allowed: "+err.Error())
+	}
+
+	// Entregas de webhook são sempre assinadas: sem segredo informado, mantém o atual
+	// ou gera um novo (retornado na resposta)
+	if settings.WebhookURL != "" && settings.WebhookSecret == "" {
+		tenant, err := h.tenantService.GetByID(c.Context(), tenantID)
+		if err != nil {
+			if errors.Is(err, services.ErrNotFound) {
+				return response.NotFound(c, "Tenant not found")
+			}
+			return handleStoreError(c, err, "Failed to load tenant")
+		}
+		settings.WebhookSecret = tenant.Settings.WebhookSecret
+		if settings.WebhookSecret == "" {
+			if settings.WebhookSecret, err = newWebhookSecret(); err != nil {
+				return response.InternalServerError(c, "Failed to generate webhook secret")
+			}
+		}
+	}
+
+	if err := h.tenantService.UpdateSettings(c.Context(), tenantID, settings); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to update settings")
+	}
+
+	if h.jobLimiter != nil {
+		h.jobLimiter.Invalidate(tenantID)
+	}
+
+	return h.respondTenant(c, tenantID)
+}
+
+// respondTenant carrega e retorna o tenant
+func (h *TenantHandler) respondTenant(c *fiber.Ctx, tenantID uuid.UUID) error {
+	tenant, err := h.tenantService.GetByID(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to load tenant")
+	}
+
+	return response.Success(c, tenant)
+}
+
+// validateTenantSettings valida scopes, limite de jobs e URLs de webhook
+func validateTenantSettings(settings models.TenantSettings) error {
+	for _, scope := range settings.AllowedScopes {
+		if !middleware.IsKnownScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if settings.MaxConcurrentJobs < 0 {
+		return errors.New("max_concurrent_jobs must be zero or positive")
+	}
+	for field, value := range map[string]string{"webhook_url": settings.WebhookURL, "slack_webhook": settings.SlackWebhook} {
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%s must be an http(s) URL", field)
+		}
+	}
+	return nil
+}
+
+// checkWebhookTargets valida no ssrf.Guard os destinos de webhook do tenant, que são
+// chamados pelo próprio gateway
+func (h *TenantHandler) checkWebhookTargets(ctx context.Context, settings models.TenantSettings) error {
+	for field, value := range map[string]string{"webhook_url": settings.WebhookURL, "slack_webhook": settings.SlackWebhook} {
+		if value == "" {
+			continue
+		}
+		if err := h.webhookGuard.CheckTarget(ctx, value); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// newWebhookSecret gera um segredo aleatório de 256 bits para assinar os webhooks
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// RateLimitRequest request de alteração do limite customizado do tenant
+type RateLimitRequest struct {
+	// Requests por minuto; 0 remove o limite customizado (volta ao limite do plano)
+	RequestsPerMinute *int `json:"requests_per_minute"`
+}
+
+// RateLimitResponse limite customizado do tenant
+type RateLimitResponse struct {
+	TenantID          uuid.UUID `json:"tenant_id"`
+	RequestsPerMinute int       `json:"requests_per_minute"`
+	Custom            bool      `json:"custom"`
+}
+
+// GetRateLimit retorna o limite customizado do tenant
+func (h *TenantHandler) GetRateLimit(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	limit, err := h.tenantService.GetRateLimit(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to load rate limit")
+	}
+
+	return response.Success(c, RateLimitResponse{
+		TenantID:          tenantID,
+		RequestsPerMinute: limit,
+		Custom:            limit > 0,
+	})
+}
+
+// SetRateLimit altera o limite customizado do tenant, com efeito imediato
+func (h *TenantHandler) SetRateLimit(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	var req RateLimitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	if req.RequestsPerMinute == nil {
+		return response.UnprocessableEntity(c, "requests_per_minute is required")
+	}
+	if *req.RequestsPerMinute < 0 {
+		return response.UnprocessableEntity(c, "requests_per_minute must be zero or positive")
+	}
+
+	if err := h.tenantService.SetRateLimit(c.Context(), tenantID, *req.RequestsPerMinute); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to update rate limit")
+	}
+
+	if h.tenantLimits != nil {
+		h.tenantLimits.Invalidate(tenantID)
+	}
+
+	return response.Success(c, RateLimitResponse{
+		TenantID:          tenantID,
+		RequestsPerMinute: *req.RequestsPerMinute,
+		Custom:            *req.RequestsPerMinute > 0,
+	})
+}
+
+// GetPolicy retorna a política do tenant (scopes, ferramentas e quotas) para o MCP
+func (h *TenantHandler) GetPolicy(c *fiber.Ctx) error {
+	tenantID, err := response.ParseUUIDParam(c, "tenant_id")
+	if err != nil {
+		return err
+	}
+
+	policy, err := h.tenantService.GetPolicy(c.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "Tenant not found")
+		}
+		return handleStoreError(c, err, "Failed to load tenant policy")
+	}
+
+	return response.Success(c, policy)
+}
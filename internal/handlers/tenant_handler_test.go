@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/arcaintelligence/arca-gateway/internal/handlers"
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/arcaintelligence/arca-gateway/internal/testutil"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// newTenantSettingsApp monta PUT /tenants/:tenant_id/settings sem autenticação
+func newTenantSettingsApp(t *testing.T, mem *testutil.Memory) *fiber.App {
+	t.Helper()
+	guard, err := ssrf.NewGuard(ssrf.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handlers.NewTenantHandler(mem.Tenants(), handlers.TenantHandlerConfig{WebhookGuard: guard})
+	app := testutil.NewApp()
+	app.Put("/tenants/:tenant_id/settings", h.UpdateSettings)
+	return app
+}
+
+func putTenantSettings(t *testing.T, app *fiber.App, tenantID uuid.UUID, settings map[string]interface{}) (int, *response.Response, *models.Tenant) {
+	t.Helper()
+	resp, err := app.Test(testutil.NewRequest(t, fiber.MethodPut, "/tenants/"+tenantID.String()+"/settings", settings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tenant models.Tenant
+	envelope := testutil.Decode(t, resp, &tenant)
+	return resp.StatusCode, envelope, &tenant
+}
+
+func TestUpdateSettingsRejectsInternalWebhookTargets(t *testing.T) {
+	mem := testutil.NewMemory()
+	tenant := mem.AddTenant(&models.Tenant{Plan: "pro"})
+	app := newTenantSettingsApp(t, mem)
+
+	for _, settings := range []map[string]interface{}{
+		{"webhook_url": "http://127.0.0.1:8080/hook", "webhook_secret": "s3cret"},
+		{"webhook_url": "http://169.254.169.254/latest/meta-data"},
+		{"slack_webhook": "https://10.0.0.5/services/x"},
+	} {
+		status, envelope, _ := putTenantSettings(t, app, tenant.ID, settings)
+		if status != fiber.StatusUnprocessableEntity || envelope.Error == nil || envelope.Error.Code != response.CodeTargetBlocked {
+			t.Errorf("settings %v: status = %d, envelope = %+v", settings, status, envelope.Error)
+		}
+	}
+}
+
+func TestUpdateSettingsGeneratesWebhookSecret(t *testing.T) {
+	mem := testutil.NewMemory()
+	tenant := mem.AddTenant(&models.Tenant{Plan: "pro"})
+	app := newTenantSettingsApp(t, mem)
+	settings := map[string]interface{}{"webhook_url": "https://93.184.216.34/hook"}
+
+	status, _, updated := putTenantSettings(t, app, tenant.ID, settings)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+	secret := updated.Settings.WebhookSecret
+	if len(secret) != 64 {
+		t.Fatalf("webhook_secret = %q, want a generated 64-char secret", secret)
+	}
+
+	// Salvar de novo sem segredo mantém o atual
+	if _, _, updated = putTenantSettings(t, app, tenant.ID, settings); updated.Settings.WebhookSecret != secret {
+		t.Errorf("webhook_secret rotated to %q, want %q", updated.Settings.WebhookSecret, secret)
+	}
+}
+
+func TestDeleteTenantStopsMonitorJobsFirst(t *testing.T) {
+	mem := testutil.NewMemory()
+	jwtManager := testutil.NewJWTManager()
+	admin := testutil.NewUser(models.RoleAdmin)
+	mem.AddTenant(&models.Tenant{ID: admin.TenantID, Plan: "enterprise"})
+	mem.AddUser(admin)
+
+	tenant := mem.AddTenant(&models.Tenant{Slug: "acme", Plan: "pro"})
+	client := mem.AddClient(&models.Client{TenantID: tenant.ID, Name: "Acme", Slug: "acme"})
+	var jobIDs []uuid.UUID
+	for _, domain := range []string{"acme.com", "acme.com.br"} {
+		jobID := uuid.New()
+		jobIDs = append(jobIDs, jobID)
+		mem.AddBrand(&models.Brand{TenantID: tenant.ID, ClientID: client.ID, Name: domain, PrimaryDomain: domain, MonitoringJobID: &jobID})
+	}
+	mem.AddBrand(&models.Brand{TenantID: tenant.ID, ClientID: client.ID, Name: "idle", PrimaryDomain: "idle.com"})
+
+	fake := mcp.NewFakeMCPClient(nil)
+	var stopped []uuid.UUID
+	fake.StopMonitorJobFunc = func(ctx context.Context, req *mcp.MCPRequest, jobID uuid.UUID) error {
+		// O tenant ainda existe quando os jobs são parados
+		if stored, err := mem.Tenants().GetByID(ctx, tenant.ID); err != nil || stored.Status != models.StatusActive {
+			t.Errorf("job %s stopped after the tenant was deleted", jobID)
+		}
+		if req.TenantID != tenant.ID {
+			t.Errorf("StopMonitorJob tenant = %s, want %s", req.TenantID, tenant.ID)
+		}
+		stopped = append(stopped, jobID)
+		return mcp.ErrMCPNotFound
+	}
+
+	h := handlers.NewTenantHandler(mem.Tenants(), handlers.TenantHandlerConfig{Brands: mem.Brands(), MCP: fake})
+	app := testutil.NewApp()
+	app.Delete("/tenants/:tenant_id", middleware.NewAuthMiddleware(jwtManager).Authenticate(), h.DeleteTenant)
+
+	resp, err := app.Test(testutil.AuthRequest(t, jwtManager, admin, fiber.MethodDelete, "/tenants/"+tenant.ID.String(), map[string]string{"confirm": "acme"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusOK && resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	sortIDs := func(ids []uuid.UUID) {
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	}
+	sortIDs(jobIDs)
+	sortIDs(stopped)
+	if len(stopped) != len(jobIDs) || stopped[0] != jobIDs[0] || stopped[1] != jobIDs[1] {
+		t.Errorf("stopped jobs = %v, want %v", stopped, jobIDs)
+	}
+	if stored, _ := mem.Tenants().GetByID(context.Background(), tenant.ID); stored == nil || stored.Status != models.StatusInactive {
+		t.Errorf("tenant was not deleted: %+v", stored)
+	}
+}
+
+// A política do tenant só é lida com o token de serviço; tokens de tenant (inclusive
+// admin e role api) são recusados
+func TestGetPolicyRequiresServiceToken(t *testing.T) {
+	const serviceToken = "mcp-service-token"
+	mem := testutil.NewMemory()
+	jwtManager := testutil.NewJWTManager()
+	tenant := mem.AddTenant(&models.Tenant{
+		Plan:     "pro",
+		Settings: models.TenantSettings{AllowedScopes: []models.Scope{models.ScopeClientsRead}, AllowedTools: []string{"site_scan"}},
+		Quotas:   models.TenantQuotas{MaxBrands: 20},
+	})
+
+	h := handlers.NewTenantHandler(mem.Tenants(), handlers.TenantHandlerConfig{})
+	app := testutil.NewApp()
+	internal := app.Group("/v1/internal", middleware.NewAuthMiddleware(jwtManager).AuthenticateService(serviceToken))
+	internal.Get("/tenants/:tenant_id/policy", middleware.RequireServiceToken(), h.GetPolicy)
+	target := "/v1/internal/tenants/" + tenant.ID.String() + "/policy"
+
+	get := func(token string, dest interface{}) (int, *response.Response) {
+		t.Helper()
+		req := testutil.NewRequest(t, fiber.MethodGet, target, nil)
+		if token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, testutil.Decode(t, resp, dest)
+	}
+
+	var policy models.TenantPolicy
+	if status, _ := get(serviceToken, &policy); status != fiber.StatusOK {
+		t.Fatalf("service token: status = %d, want 200", status)
+	}
+	if policy.TenantID != tenant.ID || len(policy.AllowedTools) != 1 || policy.Quotas.MaxBrands != 20 {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	admin := testutil.NewUser(models.RoleAdmin)
+	admin.TenantID = tenant.ID
+	apiUser := testutil.NewUser(models.RoleAPI)
+	apiUser.TenantID = tenant.ID
+	for _, tc := range []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"tenant admin token", testutil.Token(t, jwtManager, admin), fiber.StatusForbidden},
+		{"tenant api token", testutil.Token(t, jwtManager, apiUser), fiber.StatusForbidden},
+		{"wrong service token", "not-the-service-token", fiber.StatusUnauthorized},
+		{"no token", "", fiber.StatusUnauthorized},
+	} {
+		if status, envelope := get(tc.token, nil); status != tc.status || envelope.Error == nil {
+			t.Errorf("%s: status = %d, want %d", tc.name, status, tc.status)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UserHandler handlers de gerenciamento dos usuários do tenant
+type UserHandler struct {
+	userService   UserStore
+	tenantService TenantStore
+	jwtManager    *auth.JWTManager
+}
+
+// NewUserHandler cria um novo handler de usuários
+func NewUserHandler(userService UserStore, tenantService TenantStore, jwtManager *auth.JWTManager) *UserHandler {
+	return &UserHandler{
+		userService:   userService,
+		tenantService: tenantService,
+		jwtManager:    jwtManager,
+	}
+}
+
+// UpdateRoleRequest request de troca de role
+type UpdateRoleRequest struct {
+	Role models.Role `json:"role"`
+}
+
+// UpdateScopesRequest request de alteração dos scopes explícitos do usuário
+type UpdateScopesRequest struct {
+	// Scopes concedidos; vazio volta aos scopes padrão do role
+	Scopes []models.Scope `json:"scopes"`
+	// RevokeTokens encerra as sessões do usuário para que os novos scopes valham
+	// imediatamente (sem isso valem a partir do próximo login)
+	RevokeTokens bool `json:"revoke_tokens"`
+}
+
+// Roles que podem ser atribuídos a um usuário
+var assignableRoles = map[models.Role]bool{
+	models.RoleAdmin:   true,
+	models.RoleManager: true,
+	models.RoleAnalyst: true,
+	models.RoleViewer:  true,
+	models.RoleAPI:     true,
+}
+
+// UpdateRole troca o role do usuário. Os scopes explícitos são descartados e o
+// usuário passa a receber os padrão do novo role (limitados pelo teto do tenant).
+// As sessões do usuário são encerradas, já que o role antigo está nos tokens emitidos.
+func (h *UserHandler) UpdateRole(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	userID, err := response.ParseUUIDParam(c, "user_id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+	if !assignableRoles[req.Role] {
+		return response.UnprocessableEntity(c, "role must be admin, manager, analyst, viewer or api")
+	}
+
+	user, err := h.loadUser(c, userID, claims.TenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to load user")
+	}
+	if user.ID == claims.UserID {
+		return response.Forbidden(c, "Cannot change your own role")
+	}
+	// Apenas admins concedem ou retiram o role admin
+	if !claims.IsAdmin() && (req.Role == models.RoleAdmin || user.Role == models.RoleAdmin) {
+		return response.Fail(c, response.CodeRoleRequired, "Only admins can manage admin users")
+	}
+
+	user.Role = req.Role
+	user.Scopes = nil
+	if err := h.userService.Update(c.Context(), user); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to update user")
+	}
+
+	if err := h.revokeSessions(c, user.ID); err != nil {
+		return handleStoreError(c, err, "Failed to revoke user sessions")
+	}
+
+	return h.respondUser(c, user)
+}
+
+// UpdateScopes define os scopes explícitos do usuário, que precisam estar dentro do
+// teto do tenant (TenantSettings.AllowedScopes). Quem não é admin só concede scopes
+// que também possui.
+func (h *UserHandler) UpdateScopes(c *fiber.Ctx) error {
+	claims := getClaims(c)
+	if claims == nil {
+		return response.Unauthorized(c, "Authentication required")
+	}
+
+	userID, err := response.ParseUUIDParam(c, "user_id")
+	if err != nil {
+		return err
+	}
+
+	var req UpdateScopesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body")
+	}
+
+	user, err := h.loadUser(c, userID, claims.TenantID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to load user")
+	}
+	if !claims.IsAdmin() && user.Role == models.RoleAdmin {
+		return response.Fail(c, response.CodeRoleRequired, "Only admins can manage admin users")
+	}
+
+	settings, err := h.tenantService.GetSettings(c.Context(), user.TenantID)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to resolve tenant scopes")
+	}
+	ceiling := make(map[models.Scope]bool, len(settings.AllowedScopes))
+	for _, scope := range settings.AllowedScopes {
+		ceiling[scope] = true
+	}
+
+	scopes := make([]models.Scope, 0, len(req.Scopes))
+	seen := make(map[models.Scope]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+
+		switch {
+		case !middleware.IsKnownScope(scope):
+			return response.UnprocessableEntity(c, "Unknown scope: "+string(scope))
+		case len(ceiling) > 0 && !ceiling[scope]:
+			return response.UnprocessableEntity(c, "Scope not allowed for this tenant: "+string(scope))
+		case !claims.IsAdmin() && !claims.HasScope(scope):
+			return response.FailWithDetails(c, response.CodeScopeMissing, "Cannot grant a scope you do not have: "+string(scope), map[string]string{
+				"required_scopes": string(scope),
+			})
+		}
+		scopes = append(scopes, scope)
+	}
+
+	user.Scopes = scopes
+	if err := h.userService.Update(c.Context(), user); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return response.NotFound(c, "User not found")
+		}
+		return handleStoreError(c, err, "Failed to update user")
+	}
+
+	if req.RevokeTokens {
+		if err := h.revokeSessions(c, user.ID); err != nil {
+			return handleStoreError(c, err, "Failed to revoke user sessions")
+		}
+	}
+
+	return h.respondUser(c, user)
+}
+
+// loadUser carrega o usuário do tenant; usuários de outros tenants retornam ErrNotFound
+func (h *UserHandler) loadUser(c *fiber.Ctx, userID, tenantID uuid.UUID) (*models.User, error) {
+	user, err := h.userService.GetByID(c.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantID {
+		return nil, services.ErrNotFound
+	}
+	return user, nil
+}
+
+// revokeSessions invalida os tokens já emitidos do usuário (mesmo mecanismo do logout-all)
+func (h *UserHandler) revokeSessions(c *fiber.Ctx, userID uuid.UUID) error {
+	if _, err := h.userService.BumpTokenVersion(c.Context(), userID); err != nil {
+		return err
+	}
+	h.jwtManager.InvalidateTokenVersion(userID)
+	return nil
+}
+
+// respondUser retorna o usuário com os scopes efetivos (os que irão nos próximos tokens)
+func (h *UserHandler) respondUser(c *fiber.Ctx, user *models.User) error {
+	settings, err := h.tenantService.GetSettings(c.Context(), user.TenantID)
+	if err != nil {
+		return handleStoreError(c, err, "Failed to resolve tenant scopes")
+	}
+
+	return response.Success(c, UserResponse{
+		ID:          user.ID,
+		TenantID:    user.TenantID,
+		Email:       user.Email,
+		Name:        user.Name,
+		Role:        user.Role,
+		Scopes:      models.EffectiveScopes(user.Role, user.Scopes, settings.AllowedScopes),
+		LastLoginAt: user.LastLoginAt,
+	})
+}
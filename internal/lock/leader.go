@@ -0,0 +1,240 @@
+// Package lock elege uma instância líder entre as réplicas do gateway para as
+// tarefas que não podem rodar em paralelo (ex.: tarefas singleton do scheduler)
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultKey chave do lock de liderança no Redis
+const DefaultKey = "arca:leader"
+
+// Leader liderança desta instância
+type Leader interface {
+	// Leadership retorna um contexto cancelado quando a liderança for perdida e
+	// true se esta instância é a líder agora
+	Leadership() (context.Context, bool)
+	// Stop abandona a candidatura e libera a liderança, se detida
+	Stop()
+}
+
+// =============================================================================
+// SINGLE NODE
+// =============================================================================
+
+// SingleNode liderança de uma instância única (sem Redis): sempre líder até Stop
+type SingleNode struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewSingleNode cria a liderança de instância única
+func NewSingleNode() *SingleNode {
+	ctx, cancel := context.WithCancel(context.Background())
+	middleware.SetLeader(true)
+	return &SingleNode{ctx: ctx, cancel: cancel}
+}
+
+// Leadership retorna a liderança, válida até Stop
+func (n *SingleNode) Leadership() (context.Context, bool) {
+	return n.ctx, n.ctx.Err() == nil
+}
+
+// Stop encerra a liderança
+func (n *SingleNode) Stop() {
+	n.cancel()
+}
+
+// =============================================================================
+// REDIS
+// =============================================================================
+
+// Renova o lock apenas se ele ainda pertence a esta instância
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Libera o lock apenas se ele ainda pertence a esta instância
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisConfig configuração do RedisLeader
+type RedisConfig struct {
+	// Chave do lock (padrão DefaultKey)
+	Key string
+	// Validade do lock sem renovação (padrão 30s). Se a líder parar sem liberar o
+	// lock, outra instância assume depois de TTL.
+	TTL time.Duration
+	// Intervalo entre tentativas de obter ou renovar o lock (padrão TTL/3)
+	RenewInterval time.Duration
+}
+
+// RedisLeader eleição de líder com um lock no Redis (SET NX com TTL). A líder
+// renova o lock a cada RenewInterval; as demais tentam obtê-lo no mesmo intervalo.
+// Qualquer falha na renovação (erro do Redis ou lock de outra instância) encerra a
+// liderança imediatamente, já que ela não pode mais ser garantida.
+type RedisLeader struct {
+	client        *redis.Client
+	key           string
+	owner         string
+	ttl           time.Duration
+	renewInterval time.Duration
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRedisLeader cria a candidatura e inicia a disputa pela liderança
+func NewRedisLeader(client *redis.Client, config RedisConfig) *RedisLeader {
+	if config.Key == "" {
+		config.Key = DefaultKey
+	}
+	if config.TTL <= 0 {
+		config.TTL = 30 * time.Second
+	}
+	if config.RenewInterval <= 0 || config.RenewInterval >= config.TTL {
+		config.RenewInterval = config.TTL / 3
+	}
+
+	l := &RedisLeader{
+		client:        client,
+		key:           config.Key,
+		owner:         uuid.NewString(),
+		ttl:           config.TTL,
+		renewInterval: config.RenewInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go l.run()
+
+	return l
+}
+
+// Leadership retorna o contexto da liderança atual
+func (l *RedisLeader) Leadership() (context.Context, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ctx == nil {
+		return nil, false
+	}
+	return l.ctx, true
+}
+
+// Stop encerra a candidatura e libera o lock, permitindo que outra instância
+// assuma sem esperar o TTL
+func (l *RedisLeader) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+}
+
+// run disputa e renova a liderança até Stop
+func (l *RedisLeader) run() {
+	defer close(l.done)
+
+	l.tick()
+
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.tick()
+		case <-l.stop:
+			l.release()
+			return
+		}
+	}
+}
+
+// tick tenta obter o lock (seguidora) ou renová-lo (líder)
+func (l *RedisLeader) tick() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval)
+	defer cancel()
+
+	if _, leading := l.Leadership(); leading {
+		renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.owner, l.ttl.Milliseconds()).Int()
+		if err != nil || renewed == 0 {
+			l.stepDown(err)
+		}
+		return
+	}
+
+	acquired, err := l.client.SetNX(ctx, l.key, l.owner, l.ttl).Result()
+	if err != nil {
+		logger.WithError(err).Debug("failed to acquire leader lock")
+		return
+	}
+	if acquired {
+		l.mu.Lock()
+		l.ctx, l.cancel = context.WithCancel(context.Background())
+		l.mu.Unlock()
+
+		middleware.SetLeader(true)
+		logger.WithField("lock_key", l.key).Info("became leader")
+	}
+}
+
+// stepDown encerra a liderança após uma falha na renovação
+func (l *RedisLeader) stepDown(err error) {
+	if !l.resign() {
+		return
+	}
+	log := logger.WithField("lock_key", l.key)
+	if err != nil {
+		log = log.WithError(err)
+	}
+	log.Warn("lost leadership")
+}
+
+// release encerra a liderança e apaga o lock se ele ainda for desta instância
+func (l *RedisLeader) release() {
+	if !l.resign() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval)
+	defer cancel()
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.owner).Err(); err != nil {
+		logger.WithError(err).Warn("failed to release leader lock")
+	}
+}
+
+// resign cancela o contexto entregue por Leadership; false se esta instância não
+// era a líder
+func (l *RedisLeader) resign() bool {
+	l.mu.Lock()
+	cancel := l.cancel
+	l.ctx, l.cancel = nil, nil
+	l.mu.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	middleware.SetLeader(false)
+	return true
+}
@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	testTTL   = time.Second
+	testRenew = 10 * time.Millisecond
+)
+
+// newTestRedis Redis em memória (miniredis) e um client, fechados no fim do teste
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func newTestLeader(t *testing.T, client *redis.Client) *RedisLeader {
+	t.Helper()
+	l := NewRedisLeader(client, RedisConfig{TTL: testTTL, RenewInterval: testRenew})
+	t.Cleanup(l.Stop)
+	return l
+}
+
+func leading(l Leader) bool {
+	_, ok := l.Leadership()
+	return ok
+}
+
+// waitFor espera cond ficar verdadeira por até um segundo
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(testRenew)
+	}
+}
+
+// Com duas candidatas só uma lidera; ao parar, ela libera o lock e a outra assume
+// sem esperar o TTL
+func TestRedisLeaderContention(t *testing.T) {
+	_, client := newTestRedis(t)
+	a := newTestLeader(t, client)
+	b := newTestLeader(t, client)
+
+	waitFor(t, "a leader", func() bool { return leading(a) || leading(b) })
+	leader, follower := a, b
+	if leading(b) {
+		leader, follower = b, a
+	}
+
+	// Várias rodadas de renovação sem troca de líder
+	for i := 0; i < 10; i++ {
+		time.Sleep(testRenew)
+		if !leading(leader) || leading(follower) {
+			t.Fatalf("round %d: leader = %v, follower = %v", i, leading(leader), leading(follower))
+		}
+	}
+
+	ctx, _ := leader.Leadership()
+	leader.Stop()
+	if ctx.Err() == nil {
+		t.Error("leadership context not cancelled on Stop")
+	}
+	waitFor(t, "the follower to take over", func() bool { return leading(follower) })
+	if leading(leader) {
+		t.Error("stopped leader still reports leadership")
+	}
+}
+
+// O lock de uma líder que parou sem liberá-lo só é assumido depois do TTL
+func TestRedisLeaderTakesOverAfterExpiry(t *testing.T) {
+	server, client := newTestRedis(t)
+	if err := client.Set(context.Background(), DefaultKey, "crashed-replica", testTTL).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newTestLeader(t, client)
+	time.Sleep(10 * testRenew)
+	if leading(l) {
+		t.Fatal("acquired a lock that has not expired")
+	}
+
+	server.FastForward(testTTL)
+	waitFor(t, "leadership after the lock expired", func() bool { return leading(l) })
+}
+
+// Se o lock expira e outra instância o obtém, a antiga líder perde a liderança na
+// próxima renovação e seu contexto é cancelado
+func TestRedisLeaderStepsDownWhenLockIsLost(t *testing.T) {
+	server, client := newTestRedis(t)
+	l := newTestLeader(t, client)
+	waitFor(t, "leadership", func() bool { return leading(l) })
+	ctx, _ := l.Leadership()
+
+	server.Set(DefaultKey, "other-replica")
+
+	waitFor(t, "step down", func() bool { return !leading(l) })
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("leadership context not cancelled")
+	}
+	// O lock da outra instância não é apagado
+	if owner, _ := server.Get(DefaultKey); owner != "other-replica" {
+		t.Errorf("lock owner = %q, want other-replica", owner)
+	}
+}
+
+func TestSingleNodeLeadsUntilStop(t *testing.T) {
+	n := NewSingleNode()
+	ctx, ok := n.Leadership()
+	if !ok || ctx.Err() != nil {
+		t.Fatal("single node is not the leader")
+	}
+	n.Stop()
+	if leading(n) || ctx.Err() == nil {
+		t.Error("single node still leads after Stop")
+	}
+}
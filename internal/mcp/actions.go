@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Operações do gateway encaminhadas ao MCP
+const (
+	OpHunt             = "hunt"
+	OpScanURL          = "scan_url"
+	OpCreateMonitorJob = "create_monitor_job"
+	OpPlanMonitorJob   = "plan_monitor_job"
+	OpStopMonitorJob   = "stop_monitor_job"
+	OpGetMonitorJob    = "get_monitor_job"
+	OpAnalyzeURL       = "analyze_url"
+	OpSearchLeaks      = "search_leaks"
+)
+
+// ActionMapping ferramenta e ação do MCP executadas por uma operação do gateway
+type ActionMapping struct {
+	Tool   string
+	Action string
+}
+
+// ActionMap mapeia operações do gateway para ferramentas/ações do MCP
+type ActionMap map[string]ActionMapping
+
+// DefaultActionMap retorna o mapeamento padrão das operações
+func DefaultActionMap() ActionMap {
+	return ActionMap{
+		OpHunt:             {Tool: "hunting", Action: "hunt"},
+		OpScanURL:          {Tool: "scanner", Action: "site_scan"},
+		OpCreateMonitorJob: {Tool: "monitor", Action: "create_job"},
+		OpPlanMonitorJob:   {Tool: "monitor", Action: "plan_job"},
+		OpStopMonitorJob:   {Tool: "monitor", Action: "stop_job"},
+		OpGetMonitorJob:    {Tool: "monitor", Action: "get_job"},
+		OpAnalyzeURL:       {Tool: "analyzer", Action: "analyze_url"},
+		OpSearchLeaks:      {Tool: "leaks", Action: "leak_search"},
+	}
+}
+
+// ParseActionMap aplica overrides no formato operação -> "tool:action" (ou apenas "tool",
+// mantendo a ação padrão) sobre o mapeamento padrão e valida o resultado.
+func ParseActionMap(overrides map[string]string) (ActionMap, error) {
+	actions := DefaultActionMap()
+
+	for op, value := range overrides {
+		current, ok := actions[op]
+		if !ok {
+			return nil, fmt.Errorf("unknown MCP operation %q", op)
+		}
+
+		tool, action, hasAction := strings.Cut(strings.TrimSpace(value), ":")
+		current.Tool = strings.TrimSpace(tool)
+		if hasAction {
+			current.Action = strings.TrimSpace(action)
+		}
+		actions[op] = current
+	}
+
+	if err := actions.Validate(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// Validate verifica se todas as operações estão mapeadas para uma ferramenta e ação
+func (m ActionMap) Validate() error {
+	defaults := DefaultActionMap()
+
+	ops := make([]string, 0, len(defaults))
+	for op := range defaults {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	for _, op := range ops {
+		mapping, ok := m[op]
+		if !ok {
+			return fmt.Errorf("MCP operation %q is not mapped", op)
+		}
+		if mapping.Tool == "" {
+			return fmt.Errorf("MCP operation %q has an empty tool", op)
+		}
+		if mapping.Action == "" {
+			return fmt.Errorf("MCP operation %q has an empty action", op)
+		}
+	}
+
+	for op := range m {
+		if _, ok := defaults[op]; !ok {
+			return fmt.Errorf("unknown MCP operation %q", op)
+		}
+	}
+
+	return nil
+}
+
+// resolve retorna o mapeamento de uma operação, caindo no padrão se ausente
+func (m ActionMap) resolve(op string) ActionMapping {
+	if mapping, ok := m[op]; ok {
+		return mapping
+	}
+	return DefaultActionMap()[op]
+}
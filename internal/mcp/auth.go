@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// LoadClientTLS monta a configuração TLS do cliente MCP: certificado do gateway para
+// mTLS (certFile/keyFile, PEM) e, se caFile for informado, a CA usada para validar
+// o certificado do MCP no lugar das raízes do sistema. Retorna nil se nada for informado.
+func LoadClientTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load MCP client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MCP CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("MCP CA file contains no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// authorize adiciona as credenciais do gateway à request (o certificado de mTLS,
+// quando configurado, é apresentado pelo transporte)
+func (c *MCPClient) authorize(req *http.Request) {
+	if c.serviceToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.serviceToken)
+	}
+}
@@ -0,0 +1,79 @@
+package mcp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Resolver escolhe a URL base do MCP para uma request a partir do tenant e da
+// ferramenta (ex.: shards por tenant ou um cluster dedicado ao scanner). Vazio
+// usa o roteamento por ferramenta e, depois, a URL base padrão.
+type Resolver func(tenantID uuid.UUID, tool string) string
+
+// backends URLs base do MCP e roteamento das requests entre elas
+type backends struct {
+	defaultURL string
+	toolURLs   map[string]string
+	resolver   Resolver
+	// Todas as URLs distintas, a padrão primeiro; verificadas pelo Health
+	all []string
+}
+
+// newBackends monta o roteamento a partir da URL padrão, das URLs por ferramenta,
+// do resolver opcional e das URLs adicionais que ele pode retornar
+func newBackends(defaultURL string, toolURLs map[string]string, resolver Resolver, extra []string) *backends {
+	b := &backends{
+		defaultURL: trimBaseURL(defaultURL),
+		toolURLs:   make(map[string]string, len(toolURLs)),
+		resolver:   resolver,
+	}
+
+	seen := map[string]bool{b.defaultURL: true}
+	b.all = []string{b.defaultURL}
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			seen[url] = true
+			b.all = append(b.all, url)
+		}
+	}
+
+	// Ordenadas para que o Health consulte sempre na mesma ordem
+	tools := make([]string, 0, len(toolURLs))
+	for tool := range toolURLs {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		url := trimBaseURL(toolURLs[tool])
+		if url == "" {
+			continue
+		}
+		b.toolURLs[tool] = url
+		add(url)
+	}
+	for _, url := range extra {
+		add(trimBaseURL(url))
+	}
+
+	return b
+}
+
+// resolve retorna a URL base de uma request: resolver, ferramenta ou padrão
+func (b *backends) resolve(req *MCPRequest) string {
+	if b.resolver != nil {
+		if url := trimBaseURL(b.resolver(req.TenantID, req.Tool)); url != "" {
+			return url
+		}
+	}
+	if url, ok := b.toolURLs[req.Tool]; ok {
+		return url
+	}
+	return b.defaultURL
+}
+
+// trimBaseURL remove a barra final, já que os endpoints começam com "/"
+func trimBaseURL(url string) string {
+	return strings.TrimRight(strings.TrimSpace(url), "/")
+}
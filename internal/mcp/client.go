@@ -3,13 +3,17 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
 	"github.com/google/uuid"
 )
 
@@ -20,22 +24,69 @@ var (
 	ErrMCPForbidden    = errors.New("MCP forbidden - tool not allowed")
 	ErrMCPNotFound     = errors.New("MCP resource not found")
 	ErrMCPRateLimit    = errors.New("MCP rate limit exceeded")
+	// ErrMCPInvalidResponse resposta do MCP fora do contrato (ex.: job_id que não é UUID)
+	ErrMCPInvalidResponse = errors.New("MCP returned an invalid response")
 )
 
+// Client operações do MCP usadas pelo gateway. MCPClient fala HTTP com o MCP;
+// FakeMCPClient responde em memória (desenvolvimento local, CI e testes).
+type Client interface {
+	Hunt(ctx context.Context, req *MCPRequest, huntReq *HuntRequest) (*HuntResponse, error)
+	ScanURL(ctx context.Context, req *MCPRequest, scanReq *ScanRequest) (*ScanResponse, error)
+	AnalyzeURL(ctx context.Context, req *MCPRequest, analyzeReq *AnalyzeRequest) (*AnalyzeResponse, error)
+	SearchLeaks(ctx context.Context, req *MCPRequest, searchReq *LeakSearchRequest) (*LeakSearchResponse, error)
+	CreateMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorJobResponse, error)
+	PlanMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorPlan, error)
+	StopMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) error
+	GetMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) (*MonitorJobState, error)
+	ProxyRequest(ctx context.Context, method, endpoint string, req *MCPRequest) (*MCPResponse, error)
+	Health(ctx context.Context) (*Health, error)
+}
+
+var _ Client = (*MCPClient)(nil)
+
 // MCPClient cliente para comunicação com AGNO Control Plane
 type MCPClient struct {
-	baseURL    string
+	backends   *backends
 	httpClient *http.Client
 	maxRetries int
 	retryDelay time.Duration
+	actions    ActionMap
+	// Token enviado como Authorization: Bearer (vazio não envia)
+	serviceToken string
 }
 
 // MCPConfig configuração do cliente MCP
 type MCPConfig struct {
+	// URL base padrão, usada pelas ferramentas sem URL própria
 	BaseURL    string
+	// URLs base por ferramenta (ex.: "scanner" em um cluster dedicado)
+	ToolURLs map[string]string
+	// Resolver opcional que escolhe a URL por tenant/ferramenta; tem precedência
+	// sobre ToolURLs. As URLs que ele pode retornar além das acima vão em
+	// Backends, para que o Health as verifique.
+	Resolver Resolver
+	Backends []string
 	Timeout    time.Duration
 	MaxRetries int
 	RetryDelay time.Duration
+	// Mapeamento operação -> ferramenta/ação (default: DefaultActionMap())
+	Actions ActionMap
+
+	// Pool de conexões. O padrão do Go mantém só 2 conexões ociosas por host, e como
+	// as requests se concentram em poucos hosts (os backends), o resto é aberto e
+	// fechado a cada request. Zero usa os padrões abaixo.
+	MaxIdleConns        int           // total de conexões ociosas (padrão 100)
+	MaxIdleConnsPerHost int           // conexões ociosas por host (padrão 100)
+	IdleConnTimeout     time.Duration // tempo até fechar uma conexão ociosa (padrão 90s)
+	DialTimeout         time.Duration // timeout só da conexão TCP, dentro de Timeout (padrão 5s)
+	// HTTP2 habilita HTTP/2 em URLs https (negociado via TLS)
+	HTTP2 bool
+
+	// Autenticação do gateway no MCP: token estático (Authorization: Bearer) e/ou
+	// certificado de cliente para mTLS (ver LoadClientTLS)
+	ServiceToken string
+	TLSConfig    *tls.Config
 }
 
 // NewMCPClient cria um novo cliente MCP
@@ -49,14 +100,47 @@ func NewMCPClient(config MCPConfig) *MCPClient {
 	if config.RetryDelay == 0 {
 		config.RetryDelay = 1 * time.Second
 	}
+	if config.Actions == nil {
+		config.Actions = DefaultActionMap()
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 100
+	}
+	if config.IdleConnTimeout == 0 {
+		config.IdleConnTimeout = 90 * time.Second
+	}
+	if config.DialTimeout == 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   config.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     config.HTTP2,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       config.TLSConfig,
+	}
 
 	return &MCPClient{
-		baseURL: config.BaseURL,
+		backends: newBackends(config.BaseURL, config.ToolURLs, config.Resolver, config.Backends),
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: transport,
 		},
-		maxRetries: config.MaxRetries,
-		retryDelay: config.RetryDelay,
+		maxRetries:   config.MaxRetries,
+		retryDelay:   config.RetryDelay,
+		actions:      config.Actions,
+		serviceToken: config.ServiceToken,
 	}
 }
 
@@ -118,15 +202,18 @@ type HuntResponse struct {
 	TenantID  uuid.UUID              `json:"tenant_id"`
 	ClientID  *uuid.UUID             `json:"client_id,omitempty"`
 	Target    string                 `json:"target"`
+	// completed, partial (alguns checks falharam; ver Warnings) ou processing
 	Status    string                 `json:"status"`
 	Results   map[string]interface{} `json:"results,omitempty"`
+	Warnings  []string               `json:"warnings,omitempty"`
 	Timestamp string                 `json:"timestamp"`
 }
 
 // Hunt executa uma operação de hunting
 func (c *MCPClient) Hunt(ctx context.Context, req *MCPRequest, huntReq *HuntRequest) (*HuntResponse, error) {
-	req.Tool = "hunting"
-	req.Action = "hunt"
+	mapping := c.actions.resolve(OpHunt)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"target":        huntReq.Target,
 		"include_leaks": huntReq.IncludeLeaks,
@@ -144,14 +231,22 @@ func (c *MCPClient) Hunt(ctx context.Context, req *MCPRequest, huntReq *HuntRequ
 		TenantID:  req.TenantID,
 		ClientID:  req.ClientID,
 		Target:    huntReq.Target,
-		Status:    "completed",
+		Status:    StatusCompleted,
 		Results:   resp.Data,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
+	if partial := parsePartial(resp.Data); partial != nil {
+		huntResp.Status = StatusPartial
+		huntResp.Warnings = partial.Warnings
+	}
 
 	if resp.JobID != "" {
-		huntResp.HuntID = uuid.MustParse(resp.JobID)
-		huntResp.Status = "processing"
+		jobID, err := parseJobID(resp.JobID)
+		if err != nil {
+			return nil, err
+		}
+		huntResp.HuntID = jobID
+		huntResp.Status = StatusProcessing
 	}
 
 	return huntResp, nil
@@ -174,16 +269,19 @@ type ScanResponse struct {
 	TenantID   uuid.UUID              `json:"tenant_id"`
 	ClientID   *uuid.UUID             `json:"client_id,omitempty"`
 	URL        string                 `json:"url"`
+	// completed, partial (alguns checks falharam; ver Warnings) ou processing
 	Status     string                 `json:"status"`
 	Results    map[string]interface{} `json:"results,omitempty"`
+	Warnings   []string               `json:"warnings,omitempty"`
 	Artifacts  []string               `json:"artifacts,omitempty"`
 	Timestamp  string                 `json:"timestamp"`
 }
 
 // ScanURL executa um scan de URL
 func (c *MCPClient) ScanURL(ctx context.Context, req *MCPRequest, scanReq *ScanRequest) (*ScanResponse, error) {
-	req.Tool = "scanner"
-	req.Action = "site_scan"
+	mapping := c.actions.resolve(OpScanURL)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"url":              scanReq.URL,
 		"capture_types":    scanReq.CaptureTypes,
@@ -200,18 +298,38 @@ func (c *MCPClient) ScanURL(ctx context.Context, req *MCPRequest, scanReq *ScanR
 		TenantID:  req.TenantID,
 		ClientID:  req.ClientID,
 		URL:       scanReq.URL,
-		Status:    "completed",
+		Status:    StatusCompleted,
 		Results:   resp.Data,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	}
+	if partial := parsePartial(resp.Data); partial != nil {
+		scanResp.Status = StatusPartial
+		scanResp.Warnings = partial.Warnings
+	}
 
 	if resp.JobID != "" {
-		scanResp.ScanID = uuid.MustParse(resp.JobID)
+		jobID, err := parseJobID(resp.JobID)
+		if err != nil {
+			return nil, err
+		}
+		scanResp.ScanID = jobID
+		scanResp.Status = StatusProcessing
 	}
 
 	return scanResp, nil
 }
 
+// parseJobID valida o job_id de uma operação assíncrona. Ele identifica o job na
+// conclusão (POST /v1/internal/jobs/:job_id/complete), então um id inválido não pode
+// ser trocado por outro gerado aqui.
+func parseJobID(jobID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: job_id %q is not a UUID", ErrMCPInvalidResponse, jobID)
+	}
+	return id, nil
+}
+
 // =============================================================================
 // MONITOR OPERATIONS
 // =============================================================================
@@ -237,8 +355,9 @@ type MonitorJobResponse struct {
 
 // CreateMonitorJob cria um job de monitoramento
 func (c *MCPClient) CreateMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorJobResponse, error) {
-	req.Tool = "monitor"
-	req.Action = "create_job"
+	mapping := c.actions.resolve(OpCreateMonitorJob)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"brand_id":       monitorReq.BrandID.String(),
 		"target":         monitorReq.Target,
@@ -271,8 +390,9 @@ func (c *MCPClient) CreateMonitorJob(ctx context.Context, req *MCPRequest, monit
 
 // StopMonitorJob para um job de monitoramento
 func (c *MCPClient) StopMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) error {
-	req.Tool = "monitor"
-	req.Action = "stop_job"
+	mapping := c.actions.resolve(OpStopMonitorJob)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"job_id": jobID.String(),
 	}
@@ -281,6 +401,109 @@ func (c *MCPClient) StopMonitorJob(ctx context.Context, req *MCPRequest, jobID u
 	return err
 }
 
+// MonitorJobState estado de um job de monitoramento segundo o MCP
+type MonitorJobState struct {
+	JobID     uuid.UUID  `json:"job_id"`
+	Status    string     `json:"status"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	// Total de ameaças encontradas pelo job; nil quando o MCP não informa
+	ThreatsFound *int `json:"threats_found,omitempty"`
+}
+
+// GetMonitorJob consulta o estado atual de um job de monitoramento. Um job que o MCP
+// não conhece (ex.: removido ou expirado) retorna ErrMCPNotFound.
+func (c *MCPClient) GetMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) (*MonitorJobState, error) {
+	mapping := c.actions.resolve(OpGetMonitorJob)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
+	req.Params = map[string]interface{}{
+		"job_id": jobID.String(),
+	}
+
+	resp, err := c.execute(ctx, http.MethodGet, fmt.Sprintf("/v1/monitor/jobs/%s", jobID), req)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &MonitorJobState{
+		JobID:  jobID,
+		Status: strings.ToLower(firstString(resp.Data, "status", "state")),
+	}
+	if state.Status == "" {
+		return nil, fmt.Errorf("%w: monitor job %s has no status", ErrMCPInvalidResponse, jobID)
+	}
+	if lastRun := firstString(resp.Data, "last_run_at", "last_scan_at"); lastRun != "" {
+		if t, err := time.Parse(time.RFC3339, lastRun); err == nil {
+			t = t.UTC()
+			state.LastRunAt = &t
+		}
+	}
+	if threats, ok := intValue(resp.Data, "threats_found"); ok {
+		state.ThreatsFound = &threats
+	}
+
+	return state, nil
+}
+
+// MonitorPlan prévia de um job de monitoramento: o que o MCP executaria, sem criar o job
+type MonitorPlan struct {
+	BrandID       uuid.UUID `json:"brand_id"`
+	Target        string    `json:"target"`
+	IntervalMins  int       `json:"interval_mins"`
+	PlannedChecks []string  `json:"planned_checks"`
+	// Avisos sobre o alvo ou a configuração (ex.: domínio inacessível)
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// PlanMonitorJob pede ao MCP o plano de um job de monitoramento sem criá-lo. Sem
+// planned_checks na resposta, o plano considera os checks pedidos.
+func (c *MCPClient) PlanMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorPlan, error) {
+	mapping := c.actions.resolve(OpPlanMonitorJob)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
+	req.Params = map[string]interface{}{
+		"brand_id":       monitorReq.BrandID.String(),
+		"target":         monitorReq.Target,
+		"interval_mins":  monitorReq.IntervalMins,
+		"enabled_checks": monitorReq.EnabledChecks,
+		"dry_run":        true,
+	}
+
+	resp, err := c.execute(ctx, http.MethodPost, "/v1/monitor/jobs/plan", req)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MonitorPlan{
+		BrandID:       monitorReq.BrandID,
+		Target:        monitorReq.Target,
+		IntervalMins:  monitorReq.IntervalMins,
+		PlannedChecks: monitorReq.EnabledChecks,
+	}
+	if list, ok := resp.Data["planned_checks"].([]interface{}); ok {
+		plan.PlannedChecks = make([]string, 0, len(list))
+		for _, item := range list {
+			// Nome do check ou objeto com o nome em "check"/"name"
+			check, _ := item.(string)
+			if fields, ok := item.(map[string]interface{}); ok {
+				check = firstString(fields, "check", "name")
+			}
+			if check != "" {
+				plan.PlannedChecks = append(plan.PlannedChecks, check)
+			}
+		}
+	}
+	if list, ok := resp.Data["warnings"].([]interface{}); ok {
+		for _, item := range list {
+			if warning := describeWarning(item); warning != "" {
+				plan.Warnings = append(plan.Warnings, warning)
+			}
+		}
+	}
+
+	return plan, nil
+}
+
 // =============================================================================
 // ANALYZE OPERATIONS
 // =============================================================================
@@ -306,8 +529,9 @@ type AnalyzeResponse struct {
 
 // AnalyzeURL executa análise de URL
 func (c *MCPClient) AnalyzeURL(ctx context.Context, req *MCPRequest, analyzeReq *AnalyzeRequest) (*AnalyzeResponse, error) {
-	req.Tool = "analyzer"
-	req.Action = "analyze_url"
+	mapping := c.actions.resolve(OpAnalyzeURL)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"url":           analyzeReq.URL,
 		"include_leaks": analyzeReq.IncludeLeaks,
@@ -362,8 +586,9 @@ type LeakSearchResponse struct {
 
 // SearchLeaks busca vazamentos
 func (c *MCPClient) SearchLeaks(ctx context.Context, req *MCPRequest, searchReq *LeakSearchRequest) (*LeakSearchResponse, error) {
-	req.Tool = "leaks"
-	req.Action = "leak_search"
+	mapping := c.actions.resolve(OpSearchLeaks)
+	req.Tool = mapping.Tool
+	req.Action = mapping.Action
 	req.Params = map[string]interface{}{
 		"query":       searchReq.Query,
 		"type":        searchReq.Type,
@@ -410,20 +635,46 @@ func (c *MCPClient) ProxyRequest(ctx context.Context, method, endpoint string, r
 
 // execute executa uma request para o MCP com retry
 func (c *MCPClient) execute(ctx context.Context, method, endpoint string, req *MCPRequest) (*MCPResponse, error) {
+	// Garantir um request id para correlação: chamadas internas podem não ter X-Request-ID.
+	// O id gerado fica em req.RequestID, visível para o chamador.
+	if req.RequestID == "" {
+		req.RequestID = requestIDFromContext(ctx)
+	}
+
+	// A URL é resolvida uma vez: os retries vão para o mesmo backend
+	baseURL := c.backends.resolve(req)
+
 	var lastErr error
 
 	for attempt := 0; attempt <= c.maxRetries; attempt++ {
 		if attempt > 0 {
-			time.Sleep(c.retryDelay * time.Duration(attempt))
+			// O prazo do contexto vale para todas as tentativas, incluindo as esperas
+			select {
+			case <-ctx.Done():
+				return nil, contextError(ctx, lastErr)
+			case <-time.After(c.retryDelay * time.Duration(attempt)):
+			}
 		}
 
-		resp, err := c.doRequest(ctx, method, endpoint, req)
+		resp, err := c.doRequest(ctx, method, baseURL+endpoint, req)
 		if err != nil {
 			lastErr = err
+			logger.WithContext(ctx).WithFields(map[string]interface{}{
+				"mcp_request_id": req.RequestID,
+				"tool":           req.Tool,
+				"action":         req.Action,
+				"endpoint":       endpoint,
+				"backend":        baseURL,
+				"attempt":        attempt + 1,
+			}).Warn("MCP request failed: %v", err)
 			// Não fazer retry para erros de autorização/forbidden
 			if errors.Is(err, ErrMCPUnauthorized) || errors.Is(err, ErrMCPForbidden) {
 				return nil, err
 			}
+			// Nem com o contexto encerrado (prazo esgotado ou request cancelada)
+			if ctx.Err() != nil {
+				return nil, contextError(ctx, err)
+			}
 			continue
 		}
 
@@ -433,8 +684,44 @@ func (c *MCPClient) execute(ctx context.Context, method, endpoint string, req *M
 	return nil, fmt.Errorf("MCP request failed after %d attempts: %w", c.maxRetries+1, lastErr)
 }
 
+// contextError retorna o erro de uma request interrompida pelo contexto:
+// ErrMCPTimeout se o prazo esgotou, senão o último erro da request
+func contextError(ctx context.Context, lastErr error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if errors.Is(lastErr, ErrMCPTimeout) {
+			return lastErr
+		}
+		return ErrMCPTimeout
+	}
+	if lastErr == nil {
+		return fmt.Errorf("%w: %v", ErrMCPUnavailable, ctx.Err())
+	}
+	return lastErr
+}
+
+// isTimeout verifica se a request expirou: prazo do contexto, Timeout do cliente
+// HTTP ou timeout de rede (conexão, TLS, leitura)
+func isTimeout(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// requestIDFromContext retorna o request id gerado pelo middleware (disponível no
+// context do Fiber) ou, na ausência dele, um novo id
+func requestIDFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if requestID, ok := ctx.Value("requestid").(string); ok && requestID != "" {
+			return requestID
+		}
+	}
+	return uuid.New().String()
+}
+
 // doRequest executa uma request HTTP para o MCP
-func (c *MCPClient) doRequest(ctx context.Context, method, endpoint string, req *MCPRequest) (*MCPResponse, error) {
+func (c *MCPClient) doRequest(ctx context.Context, method, url string, req *MCPRequest) (*MCPResponse, error) {
 	// Serializar request
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -442,7 +729,7 @@ func (c *MCPClient) doRequest(ctx context.Context, method, endpoint string, req
 	}
 
 	// Criar HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -450,14 +737,19 @@ func (c *MCPClient) doRequest(ctx context.Context, method, endpoint string, req
 	// Headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-Request-ID", req.RequestID)
+	c.authorize(httpReq)
 	httpReq.Header.Set("X-Tenant-ID", req.TenantID.String())
 	if req.ClientID != nil {
 		httpReq.Header.Set("X-Client-ID", req.ClientID.String())
 	}
 
-	// Executar request
+	// Executar request. O prazo do contexto pode ser menor que o Timeout do cliente
+	// (ex.: requests interativas); esgotado, a request falha com ErrMCPTimeout.
 	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
+		if isTimeout(ctx, err) {
+			return nil, fmt.Errorf("%w: %v", ErrMCPTimeout, err)
+		}
 		return nil, fmt.Errorf("%w: %v", ErrMCPUnavailable, err)
 	}
 	defer httpResp.Body.Close()
@@ -465,6 +757,9 @@ func (c *MCPClient) doRequest(ctx context.Context, method, endpoint string, req
 	// Ler response
 	respBody, err := io.ReadAll(httpResp.Body)
 	if err != nil {
+		if isTimeout(ctx, err) {
+			return nil, fmt.Errorf("%w: %v", ErrMCPTimeout, err)
+		}
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
@@ -496,23 +791,3 @@ func (c *MCPClient) doRequest(ctx context.Context, method, endpoint string, req
 
 	return &mcpResp, nil
 }
-
-// HealthCheck verifica se o MCP está disponível
-func (c *MCPClient) HealthCheck(ctx context.Context) error {
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
-	if err != nil {
-		return err
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
-	if err != nil {
-		return ErrMCPUnavailable
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return ErrMCPUnavailable
-	}
-
-	return nil
-}
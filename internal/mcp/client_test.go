@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordingServer MCP de teste que guarda o corpo de cada request recebida
+func recordingServer(t *testing.T) (*httptest.Server, func() []MCPRequest) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		received []MCPRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req MCPRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode MCP request: %v", err)
+		}
+		mu.Lock()
+		received = append(received, req)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []MCPRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]MCPRequest(nil), received...)
+	}
+}
+
+func TestRemappedActionIsSentToMCP(t *testing.T) {
+	server, received := recordingServer(t)
+	actions, err := ParseActionMap(map[string]string{
+		OpAnalyzeURL: "analyzer-v2:deep_analyze",
+		OpHunt:       "hunting-v2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewMCPClient(MCPConfig{BaseURL: server.URL, Timeout: time.Second, MaxRetries: 1, Actions: actions})
+	ctx := context.Background()
+
+	if _, err := client.AnalyzeURL(ctx, &MCPRequest{TenantID: uuid.New()}, &AnalyzeRequest{URL: "https://acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Hunt(ctx, &MCPRequest{TenantID: uuid.New()}, &HuntRequest{Target: "acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SearchLeaks(ctx, &MCPRequest{TenantID: uuid.New()}, &LeakSearchRequest{Query: "acme.com", Type: "domain"}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := received()
+	if len(requests) != 3 {
+		t.Fatalf("MCP received %d requests, want 3", len(requests))
+	}
+	for i, want := range []ActionMapping{
+		{Tool: "analyzer-v2", Action: "deep_analyze"},
+		// Só a ferramenta remapeada: a ação padrão é mantida
+		{Tool: "hunting-v2", Action: DefaultActionMap()[OpHunt].Action},
+		// Operação sem override usa o mapeamento padrão
+		DefaultActionMap()[OpSearchLeaks],
+	} {
+		if got := requests[i]; got.Tool != want.Tool || got.Action != want.Action {
+			t.Errorf("request %d: tool/action = %s/%s, want %s/%s", i, got.Tool, got.Action, want.Tool, want.Action)
+		}
+	}
+}
+
+func TestParseActionMapRejectsInvalidOverrides(t *testing.T) {
+	for _, overrides := range []map[string]string{
+		{"unknown_op": "tool:action"},
+		{OpHunt: ""},
+		{OpHunt: "hunting:"},
+	} {
+		if _, err := ParseActionMap(overrides); err == nil {
+			t.Errorf("ParseActionMap(%v) accepted an invalid mapping", overrides)
+		}
+	}
+}
+
+// Sem request id o cliente gera um (no header e em req.RequestID, para correlação nos
+// logs); com request id, o mesmo valor é repassado ao MCP
+func TestRequestIDIsGeneratedOrEchoed(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		headers []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Get("X-Request-ID"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer server.Close()
+	client := NewMCPClient(MCPConfig{BaseURL: server.URL, Timeout: time.Second, MaxRetries: 1})
+	ctx := context.Background()
+
+	generated := &MCPRequest{TenantID: uuid.New()}
+	if _, err := client.Hunt(ctx, generated, &HuntRequest{Target: "acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uuid.Parse(generated.RequestID); err != nil {
+		t.Errorf("generated request id = %q, want a UUID", generated.RequestID)
+	}
+
+	echoed := &MCPRequest{TenantID: uuid.New(), RequestID: "req-123"}
+	if _, err := client.Hunt(ctx, echoed, &HuntRequest{Target: "acme.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if echoed.RequestID != "req-123" {
+		t.Errorf("request id = %q, want req-123", echoed.RequestID)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{generated.RequestID, "req-123"}; len(headers) != 2 || headers[0] != want[0] || headers[1] != want[1] {
+		t.Errorf("X-Request-ID headers = %q, want %q", headers, want)
+	}
+}
+
+// Um MCP lento resulta em ErrMCPTimeout, tanto pelo Timeout do cliente quanto pelo
+// prazo do contexto; um MCP fora do ar continua ErrMCPUnavailable
+func TestSlowMCPReturnsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	for _, tc := range []struct {
+		name    string
+		baseURL string
+		timeout time.Duration
+		ctxWait time.Duration
+		want    error
+	}{
+		{"client timeout", slow.URL, 50 * time.Millisecond, 0, ErrMCPTimeout},
+		{"context deadline", slow.URL, 5 * time.Second, 50 * time.Millisecond, ErrMCPTimeout},
+		{"service down", downURL, time.Second, 0, ErrMCPUnavailable},
+	} {
+		client := NewMCPClient(MCPConfig{BaseURL: tc.baseURL, Timeout: tc.timeout, MaxRetries: 1, RetryDelay: time.Millisecond})
+		ctx := context.Background()
+		if tc.ctxWait > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tc.ctxWait)
+			defer cancel()
+		}
+
+		start := time.Now()
+		_, err := client.Hunt(ctx, &MCPRequest{TenantID: uuid.New()}, &HuntRequest{Target: "acme.com"})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+		if tc.want == ErrMCPTimeout && errors.Is(err, ErrMCPUnavailable) {
+			t.Errorf("%s: timeout also reported as unavailable: %v", tc.name, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: took %v, the slow server was not cut off", tc.name, elapsed)
+		}
+	}
+}
+
+// Um job_id que não é UUID na resposta do MCP vira ErrMCPInvalidResponse, sem panic
+func TestMalformedMCPJobIDIsInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"job_id":"job-42","data":{}}`))
+	}))
+	defer server.Close()
+	client := NewMCPClient(MCPConfig{BaseURL: server.URL, Timeout: time.Second, MaxRetries: 1})
+	ctx := context.Background()
+
+	if _, err := client.Hunt(ctx, &MCPRequest{TenantID: uuid.New()}, &HuntRequest{Target: "acme.com"}); !errors.Is(err, ErrMCPInvalidResponse) {
+		t.Errorf("Hunt: err = %v, want ErrMCPInvalidResponse", err)
+	}
+	if _, err := client.ScanURL(ctx, &MCPRequest{TenantID: uuid.New()}, &ScanRequest{URL: "https://acme.com"}); !errors.Is(err, ErrMCPInvalidResponse) {
+		t.Errorf("ScanURL: err = %v, want ErrMCPInvalidResponse", err)
+	}
+}
@@ -0,0 +1,109 @@
+package mcp
+
+// Payload conteúdo de uma resposta do MCP sem o envelope dele, pronto para ser
+// devolvido no envelope padrão do gateway
+type Payload struct {
+	Data  interface{}
+	JobID string
+	// Paginação informada pelo MCP; Paginated é false em respostas que não são listagens
+	Paginated bool
+	Page      int
+	PerPage   int
+	Total     int64
+}
+
+// Payload extrai os dados da resposta. Algumas rotas do MCP devolvem o próprio
+// envelope dentro de data ({"success": true, "data": {...}, "meta": {...}}) e as
+// listagens trazem a paginação junto dos itens, em "meta"/"pagination" ou no mesmo
+// nível ({"items": [...], "page": 1, "per_page": 20, "total": 42}). Os dois casos
+// são desembrulhados; o resto é repassado como está.
+func (r *MCPResponse) Payload() Payload {
+	payload := Payload{JobID: r.JobID}
+	if r.Data == nil {
+		return payload
+	}
+
+	data := r.Data
+	var meta map[string]interface{}
+
+	// Envelope do MCP dentro de data
+	if inner, ok := data["data"]; ok && hasAnyKey(data, "success", "meta", "pagination") {
+		if payload.JobID == "" {
+			payload.JobID, _ = data["job_id"].(string)
+		}
+		meta = metaObject(data)
+		innerMap, isMap := inner.(map[string]interface{})
+		if !isMap {
+			payload.Data = inner
+			payload.setPagination(meta)
+			return payload
+		}
+		data = innerMap
+	}
+
+	// Listagem paginada
+	if items, ok := data["items"]; ok {
+		if meta == nil {
+			meta = metaObject(data)
+		}
+		if meta == nil {
+			meta = data
+		}
+		if payload.setPagination(meta) {
+			payload.Data = items
+			return payload
+		}
+	}
+
+	payload.Data = data
+	return payload
+}
+
+// setPagination lê page/per_page/total (e variações) de meta; retorna false se
+// não houver paginação
+func (p *Payload) setPagination(meta map[string]interface{}) bool {
+	if meta == nil {
+		return false
+	}
+	page, hasPage := intValue(meta, "page", "current_page")
+	perPage, hasPerPage := intValue(meta, "per_page", "page_size", "limit")
+	total, hasTotal := intValue(meta, "total", "total_count", "total_items")
+	if !hasPage && !hasPerPage && !hasTotal {
+		return false
+	}
+
+	p.Paginated = true
+	p.Page, p.PerPage, p.Total = page, perPage, int64(total)
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	return true
+}
+
+func metaObject(data map[string]interface{}) map[string]interface{} {
+	for _, key := range []string{"meta", "pagination"} {
+		if meta, ok := data[key].(map[string]interface{}); ok {
+			return meta
+		}
+	}
+	return nil
+}
+
+func hasAnyKey(m map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := m[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// intValue lê o primeiro número presente entre as chaves (JSON decodifica números como float64)
+func intValue(m map[string]interface{}, keys ...string) (int, bool) {
+	for _, key := range keys {
+		if value, ok := m[key].(float64); ok {
+			return int(value), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpProxy operação registrada em FakeCall para ProxyRequest
+const OpProxy = "proxy"
+
+// FakeMCPClient MCP em memória para desenvolvimento local, CI e testes de handlers.
+// Sem configuração, cada operação responde com sucesso e dados fixos, e os jobs de
+// monitoramento ficam em memória (criar, consultar e parar são consistentes entre
+// si). Para simular outras respostas ou erros, defina a função da operação antes
+// de usar o cliente:
+//
+//	fake := mcp.NewFakeMCPClient(nil)
+//	fake.HuntFunc = func(ctx context.Context, req *mcp.MCPRequest, huntReq *mcp.HuntRequest) (*mcp.HuntResponse, error) {
+//		return nil, mcp.ErrMCPUnavailable
+//	}
+type FakeMCPClient struct {
+	HuntFunc             func(ctx context.Context, req *MCPRequest, huntReq *HuntRequest) (*HuntResponse, error)
+	ScanURLFunc          func(ctx context.Context, req *MCPRequest, scanReq *ScanRequest) (*ScanResponse, error)
+	AnalyzeURLFunc       func(ctx context.Context, req *MCPRequest, analyzeReq *AnalyzeRequest) (*AnalyzeResponse, error)
+	SearchLeaksFunc      func(ctx context.Context, req *MCPRequest, searchReq *LeakSearchRequest) (*LeakSearchResponse, error)
+	CreateMonitorJobFunc func(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorJobResponse, error)
+	PlanMonitorJobFunc   func(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorPlan, error)
+	StopMonitorJobFunc   func(ctx context.Context, req *MCPRequest, jobID uuid.UUID) error
+	GetMonitorJobFunc    func(ctx context.Context, req *MCPRequest, jobID uuid.UUID) (*MonitorJobState, error)
+	ProxyRequestFunc     func(ctx context.Context, method, endpoint string, req *MCPRequest) (*MCPResponse, error)
+	HealthFunc           func(ctx context.Context) (*Health, error)
+
+	actions ActionMap
+
+	mu    sync.Mutex
+	jobs  map[uuid.UUID]*MonitorJobState
+	calls []FakeCall
+}
+
+var _ Client = (*FakeMCPClient)(nil)
+
+// FakeCall chamada recebida pelo FakeMCPClient
+type FakeCall struct {
+	// Operação (OpHunt, OpScanURL, ... ou OpProxy)
+	Operation string
+	// Método e endpoint, apenas em OpProxy
+	Method   string
+	Endpoint string
+	// Cópia da request, com ferramenta e ação já resolvidas
+	Request MCPRequest
+}
+
+// NewFakeMCPClient cria um MCP em memória. actions é o mapeamento das operações
+// (nil usa DefaultActionMap()), aplicado nas requests como no MCPClient.
+func NewFakeMCPClient(actions ActionMap) *FakeMCPClient {
+	if actions == nil {
+		actions = DefaultActionMap()
+	}
+	return &FakeMCPClient{
+		actions: actions,
+		jobs:    make(map[uuid.UUID]*MonitorJobState),
+	}
+}
+
+// Calls retorna as chamadas recebidas, na ordem
+func (f *FakeMCPClient) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeCall(nil), f.calls...)
+}
+
+// Hunt responde a um hunting concluído sem ameaças
+func (f *FakeMCPClient) Hunt(ctx context.Context, req *MCPRequest, huntReq *HuntRequest) (*HuntResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpHunt}, req)
+	if f.HuntFunc != nil {
+		return f.HuntFunc(ctx, req, huntReq)
+	}
+	return &HuntResponse{
+		HuntID:    uuid.New(),
+		TenantID:  req.TenantID,
+		ClientID:  req.ClientID,
+		Target:    huntReq.Target,
+		Status:    StatusCompleted,
+		Results:   map[string]interface{}{"threats": []interface{}{}},
+		Timestamp: fakeTimestamp(),
+	}, nil
+}
+
+// ScanURL responde a um scan concluído sem ameaças
+func (f *FakeMCPClient) ScanURL(ctx context.Context, req *MCPRequest, scanReq *ScanRequest) (*ScanResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpScanURL}, req)
+	if f.ScanURLFunc != nil {
+		return f.ScanURLFunc(ctx, req, scanReq)
+	}
+	return &ScanResponse{
+		ScanID:    uuid.New(),
+		TenantID:  req.TenantID,
+		ClientID:  req.ClientID,
+		URL:       scanReq.URL,
+		Status:    StatusCompleted,
+		Results:   map[string]interface{}{"threats": []interface{}{}},
+		Timestamp: fakeTimestamp(),
+	}, nil
+}
+
+// AnalyzeURL responde a uma análise concluída de uma URL sem risco
+func (f *FakeMCPClient) AnalyzeURL(ctx context.Context, req *MCPRequest, analyzeReq *AnalyzeRequest) (*AnalyzeResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpAnalyzeURL}, req)
+	if f.AnalyzeURLFunc != nil {
+		return f.AnalyzeURLFunc(ctx, req, analyzeReq)
+	}
+	return &AnalyzeResponse{
+		AnalysisID: uuid.New(),
+		TenantID:   req.TenantID,
+		ClientID:   req.ClientID,
+		URL:        analyzeReq.URL,
+		Status:     StatusCompleted,
+		Analysis:   map[string]interface{}{"risk_score": 0, "malicious": false},
+		Timestamp:  fakeTimestamp(),
+	}, nil
+}
+
+// SearchLeaks responde a uma busca sem vazamentos
+func (f *FakeMCPClient) SearchLeaks(ctx context.Context, req *MCPRequest, searchReq *LeakSearchRequest) (*LeakSearchResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpSearchLeaks}, req)
+	if f.SearchLeaksFunc != nil {
+		return f.SearchLeaksFunc(ctx, req, searchReq)
+	}
+	return &LeakSearchResponse{
+		SearchID:  uuid.New(),
+		TenantID:  req.TenantID,
+		ClientID:  req.ClientID,
+		Query:     searchReq.Query,
+		Results:   []map[string]interface{}{},
+		Timestamp: fakeTimestamp(),
+	}, nil
+}
+
+// CreateMonitorJob cria um job em memória com status "running"
+func (f *FakeMCPClient) CreateMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorJobResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpCreateMonitorJob}, req)
+	if f.CreateMonitorJobFunc != nil {
+		return f.CreateMonitorJobFunc(ctx, req, monitorReq)
+	}
+
+	jobID := uuid.New()
+	f.mu.Lock()
+	f.jobs[jobID] = &MonitorJobState{JobID: jobID, Status: "running"}
+	f.mu.Unlock()
+
+	return &MonitorJobResponse{
+		JobID:     jobID,
+		TenantID:  req.TenantID,
+		ClientID:  req.ClientID,
+		BrandID:   monitorReq.BrandID,
+		Status:    "running",
+		Timestamp: fakeTimestamp(),
+	}, nil
+}
+
+// PlanMonitorJob responde com os checks pedidos, sem avisos
+func (f *FakeMCPClient) PlanMonitorJob(ctx context.Context, req *MCPRequest, monitorReq *MonitorJobRequest) (*MonitorPlan, error) {
+	f.record(ctx, FakeCall{Operation: OpPlanMonitorJob}, req)
+	if f.PlanMonitorJobFunc != nil {
+		return f.PlanMonitorJobFunc(ctx, req, monitorReq)
+	}
+	return &MonitorPlan{
+		BrandID:       monitorReq.BrandID,
+		Target:        monitorReq.Target,
+		IntervalMins:  monitorReq.IntervalMins,
+		PlannedChecks: monitorReq.EnabledChecks,
+	}, nil
+}
+
+// StopMonitorJob marca o job como "stopped"; jobs desconhecidos retornam ErrMCPNotFound
+func (f *FakeMCPClient) StopMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) error {
+	f.record(ctx, FakeCall{Operation: OpStopMonitorJob}, req)
+	if f.StopMonitorJobFunc != nil {
+		return f.StopMonitorJobFunc(ctx, req, jobID)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return ErrMCPNotFound
+	}
+	job.Status = "stopped"
+	return nil
+}
+
+// GetMonitorJob retorna o job em memória; jobs desconhecidos retornam ErrMCPNotFound
+func (f *FakeMCPClient) GetMonitorJob(ctx context.Context, req *MCPRequest, jobID uuid.UUID) (*MonitorJobState, error) {
+	f.record(ctx, FakeCall{Operation: OpGetMonitorJob}, req)
+	if f.GetMonitorJobFunc != nil {
+		return f.GetMonitorJobFunc(ctx, req, jobID)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[jobID]
+	if !ok {
+		return nil, ErrMCPNotFound
+	}
+	state := *job
+	return &state, nil
+}
+
+// ProxyRequest responde com sucesso, devolvendo os parâmetros da request em data
+func (f *FakeMCPClient) ProxyRequest(ctx context.Context, method, endpoint string, req *MCPRequest) (*MCPResponse, error) {
+	f.record(ctx, FakeCall{Operation: OpProxy, Method: method, Endpoint: endpoint}, req)
+	if f.ProxyRequestFunc != nil {
+		return f.ProxyRequestFunc(ctx, method, endpoint, req)
+	}
+
+	data := make(map[string]interface{}, len(req.Params))
+	for key, value := range req.Params {
+		data[key] = value
+	}
+	return &MCPResponse{
+		Success:   true,
+		RequestID: req.RequestID,
+		Data:      data,
+		Timestamp: fakeTimestamp(),
+	}, nil
+}
+
+// Health responde como um MCP saudável
+func (f *FakeMCPClient) Health(ctx context.Context) (*Health, error) {
+	if f.HealthFunc != nil {
+		return f.HealthFunc(ctx)
+	}
+	return &Health{Status: "healthy", Version: "fake"}, nil
+}
+
+// record completa a request como o MCPClient (ferramenta e ação da operação,
+// request id) e registra a chamada
+func (f *FakeMCPClient) record(ctx context.Context, call FakeCall, req *MCPRequest) {
+	if call.Operation != OpProxy {
+		mapping := f.actions.resolve(call.Operation)
+		req.Tool = mapping.Tool
+		req.Action = mapping.Action
+	}
+	if req.RequestID == "" {
+		req.RequestID = requestIDFromContext(ctx)
+	}
+	call.Request = *req
+
+	f.mu.Lock()
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+}
+
+// fakeTimestamp timestamp das respostas
+func fakeTimestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
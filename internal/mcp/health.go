@@ -0,0 +1,215 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timeout padrão do health check quando o contexto não tem prazo menor
+const defaultHealthTimeout = 5 * time.Second
+
+// Status de ferramenta considerados saudáveis no health do MCP
+var healthyToolStatuses = map[string]bool{
+	"ok": true, "up": true, "healthy": true, "pass": true, "available": true,
+}
+
+// Health estado detalhado do MCP retornado por GET /health
+type Health struct {
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"`
+	// Status por ferramenta (ex.: "scanner": "healthy", "leaks": "degraded")
+	Tools map[string]string `json:"tools,omitempty"`
+	// Jobs aguardando execução, quando o MCP informa
+	QueueDepth *int `json:"queue_depth,omitempty"`
+}
+
+// DegradedTools retorna, em ordem alfabética, as ferramentas com status diferente de saudável
+func (h *Health) DegradedTools() []string {
+	var degraded []string
+	for tool, status := range h.Tools {
+		if !healthyToolStatuses[strings.ToLower(status)] {
+			degraded = append(degraded, tool)
+		}
+	}
+	sort.Strings(degraded)
+	return degraded
+}
+
+// Health consulta GET /health de cada backend do MCP em paralelo e combina as
+// respostas: versão do primeiro backend que responder (o padrão primeiro), status
+// por ferramenta de todos ("tools" ou "components", como mapa ou lista; se dois
+// backends informarem a mesma ferramenta prevalece o status não saudável) e a
+// soma das filas. Campos ausentes
+// ficam vazios. Backends indisponíveis ou com resposta diferente de 200 retornam
+// ErrMCPUnavailable junto com o que foi possível ler dos demais. A consulta é
+// limitada a 5s mesmo sem prazo no contexto, para que um MCP travado não segure o
+// /health do gateway.
+func (c *MCPClient) Health(ctx context.Context) (*Health, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultHealthTimeout)
+	defer cancel()
+
+	urls := c.backends.all
+	healths := make([]*Health, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, baseURL := range urls {
+		wg.Add(1)
+		go func(i int, baseURL string) {
+			defer wg.Done()
+			healths[i], errs[i] = c.backendHealth(ctx, baseURL)
+			if errs[i] != nil && len(urls) > 1 {
+				errs[i] = fmt.Errorf("backend %s: %w", baseURL, errs[i])
+			}
+		}(i, baseURL)
+	}
+	wg.Wait()
+
+	health := mergeHealth(healths)
+	if err := errors.Join(errs...); err != nil {
+		return health, err
+	}
+	if health == nil {
+		health = &Health{}
+	}
+	if health.Status == "" {
+		health.Status = "healthy"
+	}
+	return health, nil
+}
+
+// HealthCheck verifica se todos os backends do MCP estão disponíveis (variante
+// simples de Health)
+func (c *MCPClient) HealthCheck(ctx context.Context) error {
+	_, err := c.Health(ctx)
+	return err
+}
+
+// backendHealth consulta GET /health de um backend
+func (c *MCPClient) backendHealth(ctx context.Context, baseURL string) (*Health, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if isTimeout(ctx, err) {
+			return nil, fmt.Errorf("%w: %v", ErrMCPTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrMCPUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	health := parseHealth(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return health, fmt.Errorf("%w: health returned status %d", ErrMCPUnavailable, resp.StatusCode)
+	}
+	return health, nil
+}
+
+// mergeHealth combina o health dos backends (o padrão primeiro); nil se nenhum
+// backend respondeu
+func mergeHealth(healths []*Health) *Health {
+	var merged *Health
+	for _, health := range healths {
+		if health == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &Health{Status: health.Status, Version: health.Version}
+		}
+		if merged.Status == "" {
+			merged.Status = health.Status
+		}
+		for tool, status := range health.Tools {
+			if merged.Tools == nil {
+				merged.Tools = make(map[string]string, len(health.Tools))
+			}
+			if current, ok := merged.Tools[tool]; !ok || healthyToolStatuses[strings.ToLower(current)] {
+				merged.Tools[tool] = status
+			}
+		}
+		if health.QueueDepth != nil {
+			depth := *health.QueueDepth
+			if merged.QueueDepth != nil {
+				depth += *merged.QueueDepth
+			}
+			merged.QueueDepth = &depth
+		}
+	}
+	return merged
+}
+
+// parseHealth lê o corpo do health do MCP; corpos inválidos resultam em Health vazio
+func parseHealth(body io.Reader) *Health {
+	var raw map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&raw); err != nil {
+		return &Health{}
+	}
+	// Alguns serviços respondem no envelope {"success": true, "data": {...}}
+	if data, ok := raw["data"].(map[string]interface{}); ok {
+		raw = data
+	}
+
+	health := &Health{}
+	health.Status, _ = raw["status"].(string)
+	health.Version, _ = raw["version"].(string)
+	if depth, ok := intValue(raw, "queue_depth", "queue_size", "pending_jobs"); ok {
+		health.QueueDepth = &depth
+	}
+
+	for _, key := range []string{"tools", "components"} {
+		switch tools := raw[key].(type) {
+		case map[string]interface{}:
+			health.Tools = make(map[string]string, len(tools))
+			for name, value := range tools {
+				health.Tools[name] = toolStatus(value)
+			}
+		case []interface{}:
+			health.Tools = make(map[string]string, len(tools))
+			for _, item := range tools {
+				if entry, ok := item.(map[string]interface{}); ok {
+					if name, _ := entry["name"].(string); name != "" {
+						health.Tools[name] = toolStatus(entry)
+					}
+				}
+			}
+		}
+		if health.Tools != nil {
+			break
+		}
+	}
+
+	return health
+}
+
+// toolStatus aceita o status como string, booleano ou objeto com "status"/"healthy"
+func toolStatus(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		if v {
+			return "healthy"
+		}
+		return "unhealthy"
+	case map[string]interface{}:
+		if status, ok := v["status"].(string); ok && status != "" {
+			return status
+		}
+		if healthy, ok := v["healthy"].(bool); ok {
+			return toolStatus(healthy)
+		}
+	}
+	return "unknown"
+}
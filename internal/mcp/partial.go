@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status das operações síncronas
+const (
+	StatusCompleted  = "completed"
+	StatusPartial    = "partial"
+	StatusProcessing = "processing"
+)
+
+// PartialResult indica que o MCP retornou dados incompletos (algumas fontes ou
+// checks falharam ou expiraram). Warnings descreve o que ficou de fora.
+type PartialResult struct {
+	Warnings []string
+}
+
+// parsePartial procura indicadores de resultado parcial em resp.Data. O MCP não
+// é consistente, então aceita qualquer um de:
+//
+//	"partial": true | "degraded": true | "status": "partial" | "degraded"
+//	"warnings": ["...", {"check": "whois", "message": "timeout"}]
+//	"failed_checks" / "failed_sources": ["whois", "dns"]
+//
+// Listas de falhas também marcam o resultado como parcial. Sem nenhum indicador
+// retorna nil (resultado completo).
+func parsePartial(data map[string]interface{}) *PartialResult {
+	if data == nil {
+		return nil
+	}
+
+	partial := false
+	for _, key := range []string{"partial", "degraded"} {
+		if flag, ok := data[key].(bool); ok && flag {
+			partial = true
+		}
+	}
+	if status, ok := data["status"].(string); ok {
+		switch strings.ToLower(status) {
+		case "partial", "degraded":
+			partial = true
+		}
+	}
+
+	var warnings []string
+	if list, ok := data["warnings"].([]interface{}); ok {
+		for _, item := range list {
+			if warning := describeWarning(item); warning != "" {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	for _, key := range []string{"failed_checks", "failed_sources"} {
+		list, ok := data[key].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			if name := describeWarning(item); name != "" {
+				warnings = append(warnings, name+": failed")
+				partial = true
+			}
+		}
+	}
+
+	if !partial {
+		return nil
+	}
+	return &PartialResult{Warnings: warnings}
+}
+
+// describeWarning formata um item de warning: string ou objeto com o nome do
+// check/fonte e a mensagem
+func describeWarning(item interface{}) string {
+	switch v := item.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		name := firstString(v, "check", "source", "name")
+		message := firstString(v, "message", "error", "reason")
+		switch {
+		case name != "" && message != "":
+			return name + ": " + message
+		case name != "":
+			return name
+		default:
+			return message
+		}
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,323 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Tamanho padrão da fila de escrita de auditoria
+const defaultAuditBufferSize = 1000
+
+// AuditOverflowPolicy comportamento do writer quando a fila de auditoria está cheia
+type AuditOverflowPolicy string
+
+const (
+	// AuditOverflowBlock bloqueia a request até haver espaço na fila
+	AuditOverflowBlock AuditOverflowPolicy = "block"
+	// AuditOverflowDropOldest descarta o registro mais antigo da fila para enfileirar o novo
+	AuditOverflowDropOldest AuditOverflowPolicy = "drop-oldest"
+	// AuditOverflowDropNewest descarta o registro novo
+	AuditOverflowDropNewest AuditOverflowPolicy = "drop-newest"
+)
+
+// ParseAuditOverflowPolicy valida a política de overflow (vazio = drop-newest)
+func ParseAuditOverflowPolicy(value string) (AuditOverflowPolicy, error) {
+	switch policy := AuditOverflowPolicy(strings.ToLower(strings.TrimSpace(value))); policy {
+	case "":
+		return AuditOverflowDropNewest, nil
+	case AuditOverflowBlock, AuditOverflowDropOldest, AuditOverflowDropNewest:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid audit overflow policy %q (expected block, drop-oldest or drop-newest)", value)
+	}
+}
+
+// Ações registradas na auditoria, derivadas do método HTTP
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionRead   = "read"
+)
+
+// AuditStore persiste registros de auditoria
+type AuditStore interface {
+	Log(ctx context.Context, entry *models.AuditLog) error
+}
+
+// =============================================================================
+// AUDIT WRITER
+// =============================================================================
+
+// AuditWriterConfig configuração do writer de auditoria
+type AuditWriterConfig struct {
+	// Tamanho da fila de registros pendentes
+	BufferSize int
+	// Comportamento quando a fila está cheia
+	OverflowPolicy AuditOverflowPolicy
+}
+
+// AuditWriter grava registros de auditoria de forma assíncrona, fora do caminho da request
+type AuditWriter struct {
+	store   AuditStore
+	entries chan *models.AuditLog
+	policy  AuditOverflowPolicy
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewAuditWriter cria um novo writer e inicia o worker de escrita
+func NewAuditWriter(store AuditStore, config AuditWriterConfig) *AuditWriter {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultAuditBufferSize
+	}
+	if config.OverflowPolicy == "" {
+		config.OverflowPolicy = AuditOverflowDropNewest
+	}
+
+	w := &AuditWriter{
+		store:   store,
+		entries: make(chan *models.AuditLog, config.BufferSize),
+		policy:  config.OverflowPolicy,
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w
+}
+
+// Write enfileira um registro. Com a fila cheia o comportamento segue a política
+// de overflow: bloquear, descartar o mais antigo ou descartar o novo registro.
+func (w *AuditWriter) Write(entry *models.AuditLog) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.stopped {
+		return
+	}
+
+	switch w.policy {
+	case AuditOverflowBlock:
+		w.entries <- entry
+
+	case AuditOverflowDropOldest:
+		for {
+			select {
+			case w.entries <- entry:
+				return
+			default:
+			}
+
+			// Fila cheia: abrir espaço descartando o registro mais antigo
+			select {
+			case <-w.entries:
+				w.dropped()
+			default:
+			}
+		}
+
+	default:
+		select {
+		case w.entries <- entry:
+		default:
+			w.dropped()
+		}
+	}
+}
+
+// dropped contabiliza um registro descartado
+func (w *AuditWriter) dropped() {
+	RecordAuditDropped(string(w.policy))
+	logger.WithField("policy", string(w.policy)).Debug("audit queue full, dropping entry")
+}
+
+// Stop para de aceitar registros e aguarda a fila ser drenada
+func (w *AuditWriter) Stop() {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	w.stopped = true
+	close(w.entries)
+	w.mu.Unlock()
+
+	w.wg.Wait()
+}
+
+// run persiste os registros da fila
+func (w *AuditWriter) run() {
+	defer w.wg.Done()
+
+	for entry := range w.entries {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := w.store.Log(ctx, entry); err != nil {
+			logger.WithFields(map[string]interface{}{
+				"tenant_id": entry.TenantID.String(),
+				"action":    entry.Action,
+				"resource":  entry.Resource,
+			}).Error("failed to persist audit log: %v", err)
+		}
+		cancel()
+	}
+}
+
+// =============================================================================
+// AUDIT MIDDLEWARE
+// =============================================================================
+
+// AuditConfig configuração do middleware de auditoria
+type AuditConfig struct {
+	// Writer que persiste os registros (nil desabilita a persistência)
+	Writer *AuditWriter
+	// Amostragem de leituras (GET): 0 não audita leituras, N audita 1 a cada N
+	ReadSampleRate int
+}
+
+// Marca a request para ser auditada sem referência ao usuário (ver AnonymizeAudit)
+const contextKeyAuditAnonymous = "audit_anonymous"
+
+// AnonymizeAudit registra a request na auditoria sem o user_id, para requests que
+// apagam o próprio usuário (o registro não pode voltar a referenciá-lo)
+func AnonymizeAudit(c *fiber.Ctx) {
+	c.Locals(contextKeyAuditAnonymous, true)
+}
+
+// AuditMiddleware registra ações para auditoria.
+// Requests mutantes (POST/PUT/PATCH/DELETE) autenticadas são sempre registradas;
+// leituras são amostradas conforme ReadSampleRate.
+func AuditMiddleware(config AuditConfig) fiber.Handler {
+	var reads uint64
+
+	return func(c *fiber.Ctx) error {
+		// Capturar informações antes da request
+		startTime := time.Now()
+		requestID := response.RequestID(c)
+
+		// Processar request
+		err := c.Next()
+
+		if config.Writer == nil {
+			return err
+		}
+
+		action := auditAction(c.Method())
+		if action == "" {
+			return err
+		}
+		if action == AuditActionRead {
+			if config.ReadSampleRate <= 0 || atomic.AddUint64(&reads, 1)%uint64(config.ReadSampleRate) != 0 {
+				return err
+			}
+		}
+
+		// Auditoria é por tenant: requests anônimas não são registradas
+		claims := GetClaims(c)
+		if claims == nil {
+			return err
+		}
+
+		// O error handler ainda não rodou, então o status vem do erro retornado
+		statusCode := c.Response().StatusCode()
+		if err != nil {
+			statusCode = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				statusCode = fiberErr.Code
+			}
+		}
+
+		route := c.Path()
+		if r := c.Route(); r != nil && r.Path != "" {
+			route = r.Path
+		}
+		resource, resourceID := auditResource(c, route)
+
+		entry := &models.AuditLog{
+			ID:         uuid.New(),
+			TenantID:   claims.TenantID,
+			Action:     action,
+			Resource:   resource,
+			ResourceID: resourceID,
+			Details: map[string]interface{}{
+				"request_id":  requestID,
+				"method":      c.Method(),
+				"path":        c.Path(),
+				"route":       route,
+				"status_code": statusCode,
+				"duration_ms": time.Since(startTime).Milliseconds(),
+				"role":        string(claims.Role),
+			},
+			IP:        ClientIP(c),
+			UserAgent: c.Get("User-Agent"),
+			CreatedAt: startTime.UTC(),
+		}
+		if anonymous, _ := c.Locals(contextKeyAuditAnonymous).(bool); claims.UserID != uuid.Nil && !anonymous {
+			userID := claims.UserID
+			entry.UserID = &userID
+		}
+
+		config.Writer.Write(entry)
+
+		return err
+	}
+}
+
+// auditAction mapeia o método HTTP para a ação auditada
+func auditAction(method string) string {
+	switch method {
+	case fiber.MethodPost:
+		return AuditActionCreate
+	case fiber.MethodPut, fiber.MethodPatch:
+		return AuditActionUpdate
+	case fiber.MethodDelete:
+		return AuditActionDelete
+	case fiber.MethodGet:
+		return AuditActionRead
+	default:
+		return ""
+	}
+}
+
+// auditResource extrai o recurso e o id do template da rota: o recurso é o último
+// segmento estático seguido de um parâmetro (ex: /v1/clients/:client_id/brands/:brand_id
+// -> brands, brand_id) ou, na ausência dele, o último segmento estático.
+func auditResource(c *fiber.Ctx, route string) (string, *uuid.UUID) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+
+	var resource, param, lastStatic string
+	for i, segment := range segments {
+		if segment == "" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			continue
+		}
+		lastStatic = segment
+		if i+1 < len(segments) && strings.HasPrefix(segments[i+1], ":") {
+			resource = segment
+			param = strings.TrimSuffix(strings.TrimPrefix(segments[i+1], ":"), "?")
+		}
+	}
+
+	if resource == "" {
+		return lastStatic, nil
+	}
+
+	id, err := uuid.Parse(c.Params(param))
+	if err != nil {
+		return resource, nil
+	}
+	return resource, &id
+}
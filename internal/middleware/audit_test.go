@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// gatedAuditStore grava os registros em ordem; cada Log espera o gate ser aberto,
+// o que mantém o worker do writer ocupado e a fila cheia durante o teste
+type gatedAuditStore struct {
+	gate    chan struct{}
+	started chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	entries []string
+}
+
+func newGatedAuditStore() *gatedAuditStore {
+	return &gatedAuditStore{gate: make(chan struct{}), started: make(chan struct{})}
+}
+
+func (s *gatedAuditStore) Log(ctx context.Context, entry *models.AuditLog) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.gate
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry.Resource)
+	return nil
+}
+
+func (s *gatedAuditStore) written() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.entries...)
+}
+
+// floodAuditWriter ocupa o worker com o registro "0" e então escreve os registros "1".."n"
+// em uma fila de bufferSize posições; retorna o store e o writer, ainda bloqueados
+func floodAuditWriter(t *testing.T, policy AuditOverflowPolicy, bufferSize, n int) (*gatedAuditStore, *AuditWriter) {
+	t.Helper()
+	store := newGatedAuditStore()
+	w := NewAuditWriter(store, AuditWriterConfig{BufferSize: bufferSize, OverflowPolicy: policy})
+
+	w.Write(&models.AuditLog{Resource: "0"})
+	select {
+	case <-store.started:
+	case <-time.After(time.Second):
+		t.Fatal("audit worker did not pick up the first entry")
+	}
+	for i := 1; i <= n; i++ {
+		w.Write(&models.AuditLog{Resource: strconv.Itoa(i)})
+	}
+	return store, w
+}
+
+func TestAuditWriterDropNewestUnderFlood(t *testing.T) {
+	dropped := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowDropNewest)))
+	store, w := floodAuditWriter(t, AuditOverflowDropNewest, 3, 6)
+	close(store.gate)
+	w.Stop()
+
+	// A fila guarda os 3 primeiros; os 3 seguintes são descartados
+	if got, want := store.written(), []string{"0", "1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	if got := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowDropNewest))) - dropped; got != 3 {
+		t.Errorf("dropped events = %v, want 3", got)
+	}
+}
+
+func TestAuditWriterDropOldestUnderFlood(t *testing.T) {
+	dropped := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowDropOldest)))
+	store, w := floodAuditWriter(t, AuditOverflowDropOldest, 3, 6)
+	close(store.gate)
+	w.Stop()
+
+	// Os mais antigos da fila dão lugar aos novos: sobram os 3 últimos
+	if got, want := store.written(), []string{"0", "4", "5", "6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	if got := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowDropOldest))) - dropped; got != 3 {
+		t.Errorf("dropped events = %v, want 3", got)
+	}
+}
+
+func TestAuditWriterBlockUnderFlood(t *testing.T) {
+	dropped := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowBlock)))
+	store, w := floodAuditWriter(t, AuditOverflowBlock, 3, 3)
+
+	// Com a fila cheia, o próximo Write espera uma vaga em vez de descartar
+	done := make(chan struct{})
+	go func() {
+		w.Write(&models.AuditLog{Resource: "4"})
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Write returned while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(store.gate)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Write still blocked after the queue drained")
+	}
+	w.Stop()
+
+	if got, want := store.written(), []string{"0", "1", "2", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	if got := promtest.ToFloat64(auditEventsDropped.WithLabelValues(string(AuditOverflowBlock))) - dropped; got != 0 {
+		t.Errorf("dropped events = %v, want 0", got)
+	}
+}
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
 	"strings"
 
 	"github.com/arcaintelligence/arca-gateway/internal/auth"
 	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -18,6 +21,7 @@ const (
 	ContextKeyClientID = "client_id"
 	ContextKeyRole     = "role"
 	ContextKeyScopes   = "scopes"
+	ContextKeyService  = "service"
 )
 
 // AuthMiddleware middleware de autenticação JWT
@@ -39,25 +43,27 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 		authHeader := c.Get("Authorization")
 		tokenString, err := auth.ExtractTokenFromHeader(authHeader)
 		if err != nil {
-			return response.Unauthorized(c, "Missing or invalid authorization token")
+			return response.Fail(c, response.CodeAuthTokenMissing, "Missing or invalid authorization token")
 		}
 
 		// Validar token
-		claims, err := m.jwtManager.ValidateToken(tokenString)
+		claims, err := m.jwtManager.ValidateTokenContext(c.Context(), tokenString)
 		if err != nil {
 			switch err {
 			case auth.ErrExpiredToken:
-				return response.Unauthorized(c, "Token has expired")
+				return response.Fail(c, response.CodeAuthTokenExpired, "Token has expired")
+			case auth.ErrRevokedToken:
+				return response.Fail(c, response.CodeAuthTokenRevoked, "Token has been revoked")
 			case auth.ErrInvalidToken, auth.ErrInvalidClaims:
-				return response.Unauthorized(c, "Invalid token")
+				return response.Fail(c, response.CodeAuthTokenInvalid, "Invalid token")
 			default:
-				return response.Unauthorized(c, "Authentication failed")
+				return response.Fail(c, response.CodeAuthTokenInvalid, "Authentication failed")
 			}
 		}
 
 		// Verificar se é token de acesso
 		if claims.TokenType != auth.TokenTypeAccess && claims.TokenType != auth.TokenTypeAPI {
-			return response.Unauthorized(c, "Invalid token type")
+			return response.Fail(c, response.CodeAuthTokenInvalid, "Invalid token type")
 		}
 
 		// Armazenar claims no contexto
@@ -67,18 +73,83 @@ func (m *AuthMiddleware) Authenticate() fiber.Handler {
 		c.Locals(ContextKeyRole, claims.Role)
 		c.Locals(ContextKeyScopes, claims.Scopes)
 
-		// Extrair client_id do JWT (formato cli_xxx) ou do header
+		// client_id do JWT (formato cli_xxx); X-Client-ID só vale depois da
+		// validação de RequireClientAccess
 		if claims.ClientID != "" {
 			c.Locals(ContextKeyClientID, claims.ClientID)
-		} else if clientID := c.Get("X-Client-ID"); clientID != "" {
-			c.Locals(ContextKeyClientID, clientID)
 		}
 
 		return c.Next()
 	}
 }
 
-// OptionalAuth middleware que tenta autenticar mas não falha se não houver token
+// QueryTokenAuth aceita o token em ?access_token= quando não há header Authorization,
+// para clientes que não enviam headers (EventSource). Deve preceder Authenticate e ser
+// usado só em rotas de streaming: tokens em URL podem aparecer em logs de proxies.
+func QueryTokenAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// AuthenticateService middleware para rotas internas chamadas pelo Core/MCP.
+// Aceita o token de serviço estático (se configurado) ou um JWT de acesso/API com role api.
+func (m *AuthMiddleware) AuthenticateService(serviceToken string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString, err := auth.ExtractTokenFromHeader(c.Get("Authorization"))
+		if err != nil {
+			return response.Fail(c, response.CodeAuthTokenMissing, "Missing or invalid authorization token")
+		}
+
+		// Token de serviço (comparação em tempo constante)
+		if serviceToken != "" && subtle.ConstantTimeCompare([]byte(tokenString), []byte(serviceToken)) == 1 {
+			c.Locals(ContextKeyService, true)
+			return c.Next()
+		}
+
+		claims, err := m.jwtManager.ValidateTokenContext(c.Context(), tokenString)
+		if err != nil {
+			return response.Fail(c, response.CodeAuthTokenInvalid, "Invalid token")
+		}
+
+		if claims.TokenType != auth.TokenTypeAccess && claims.TokenType != auth.TokenTypeAPI {
+			return response.Fail(c, response.CodeAuthTokenInvalid, "Invalid token type")
+		}
+
+		if claims.Role != models.RoleAPI {
+			return response.Fail(c, response.CodeServiceAuthRequired, "Service credentials required")
+		}
+
+		c.Locals(ContextKeyClaims, claims)
+		c.Locals(ContextKeyUserID, claims.UserID)
+		c.Locals(ContextKeyTenantID, claims.TenantID)
+		c.Locals(ContextKeyRole, claims.Role)
+		c.Locals(ContextKeyScopes, claims.Scopes)
+
+		return c.Next()
+	}
+}
+
+// RequireServiceToken middleware que aceita apenas o token de serviço estático.
+// Deve ser usado após AuthenticateService em rotas que nunca podem ser acessadas
+// por tokens de tenant, nem mesmo com role api.
+func RequireServiceToken() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !IsServiceRequest(c) {
+			return response.Fail(c, response.CodeServiceAuthRequired, "Service credentials required")
+		}
+		return c.Next()
+	}
+}
+
+// OptionalAuth middleware que tenta autenticar mas não falha se não houver token.
+// Tokens presentes mas inutilizáveis seguem como anônimos e contam em arca_auth_failures_total;
+// a ausência de token não é uma falha.
 func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
@@ -88,11 +159,18 @@ func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 
 		tokenString, err := auth.ExtractTokenFromHeader(authHeader)
 		if err != nil {
+			RecordAuthFailure("malformed")
 			return c.Next()
 		}
 
-		claims, err := m.jwtManager.ValidateToken(tokenString)
+		claims, err := m.jwtManager.ValidateTokenContext(c.Context(), tokenString)
 		if err != nil {
+			RecordAuthFailure(authFailureReason(err))
+			return c.Next()
+		}
+
+		if claims.TokenType != auth.TokenTypeAccess && claims.TokenType != auth.TokenTypeAPI {
+			RecordAuthFailure("invalid_token_type")
 			return c.Next()
 		}
 
@@ -106,6 +184,18 @@ func (m *AuthMiddleware) OptionalAuth() fiber.Handler {
 	}
 }
 
+// authFailureReason rótulo da métrica de falha de autenticação para o erro de validação
+func authFailureReason(err error) string {
+	switch err {
+	case auth.ErrExpiredToken:
+		return "expired"
+	case auth.ErrRevokedToken:
+		return "revoked"
+	default:
+		return "malformed"
+	}
+}
+
 // RequireRole middleware que requer um role específico
 func (m *AuthMiddleware) RequireRole(roles ...models.Role) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -120,7 +210,7 @@ func (m *AuthMiddleware) RequireRole(roles ...models.Role) fiber.Handler {
 			}
 		}
 
-		return response.Forbidden(c, "Insufficient permissions")
+		return response.Fail(c, response.CodeRoleRequired, "Insufficient permissions")
 	}
 }
 
@@ -137,8 +227,12 @@ func RequireScope(scopes ...models.Scope) fiber.Handler {
 			return c.Next()
 		}
 
+		if len(claims.Scopes) == 0 {
+			return denyNoScopes(c, scopes)
+		}
+
 		if !claims.HasAnyScope(scopes...) {
-			return response.Forbidden(c, "Missing required scope: "+scopesToString(scopes))
+			return denyMissingScopes(c, "Missing required scope: "+scopesToString(scopes), scopes)
 		}
 
 		return c.Next()
@@ -158,14 +252,33 @@ func RequireAllScopes(scopes ...models.Scope) fiber.Handler {
 			return c.Next()
 		}
 
+		if len(claims.Scopes) == 0 {
+			return denyNoScopes(c, scopes)
+		}
+
 		if !claims.HasAllScopes(scopes...) {
-			return response.Forbidden(c, "Missing required scopes")
+			return denyMissingScopes(c, "Missing required scopes", scopes)
 		}
 
 		return c.Next()
 	}
 }
 
+// denyNoScopes nega o acesso de um token sem nenhum scope. É tratado à parte de
+// "scope ausente" porque indica falha na carga/persistência dos scopes do usuário.
+func denyNoScopes(c *fiber.Ctx, required []models.Scope) error {
+	logger.FromContext(c).WithField("required_scopes", scopesToString(required)).
+		Warn("access denied: token has no scopes")
+	return response.Fail(c, response.CodeNoScopes, "Token has no scopes assigned")
+}
+
+// denyMissingScopes nega o acesso por falta de scope, com os scopes exigidos em details
+func denyMissingScopes(c *fiber.Ctx, message string, required []models.Scope) error {
+	return response.FailWithDetails(c, response.CodeScopeMissing, message, map[string]string{
+		"required_scopes": scopesToString(required),
+	})
+}
+
 // RequireTenantAccess middleware que verifica acesso ao tenant
 func RequireTenantAccess() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -177,7 +290,7 @@ func RequireTenantAccess() fiber.Handler {
 		// Extrair tenant_id do path ou header
 		tenantIDStr := c.Params("tenant_id")
 		if tenantIDStr == "" {
-			tenantIDStr = c.Get("X-Tenant-ID")
+			tenantIDStr = c.Get(HeaderTenantID)
 		}
 
 		if tenantIDStr == "" {
@@ -198,15 +311,22 @@ func RequireTenantAccess() fiber.Handler {
 
 		// Usuário só pode acessar seu próprio tenant
 		if claims.TenantID != tenantID {
-			return response.Forbidden(c, "Access denied to this tenant")
+			return response.Fail(c, response.CodeTenantAccessDenied, "Access denied to this tenant")
 		}
 
 		return c.Next()
 	}
 }
 
-// RequireClientAccess middleware que verifica acesso ao cliente
-func RequireClientAccess() fiber.Handler {
+// ClientOwnershipStore verifica a qual tenant um cliente pertence
+type ClientOwnershipStore interface {
+	BelongsToTenant(ctx context.Context, clientID, tenantID uuid.UUID) (bool, error)
+}
+
+// RequireClientAccess middleware que verifica acesso ao cliente do path ou do
+// header X-Client-ID. O cliente precisa pertencer ao tenant da request; só então
+// vai para o contexto (GetClientID).
+func RequireClientAccess(store ClientOwnershipStore) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		claims := GetClaims(c)
 		if claims == nil {
@@ -215,7 +335,7 @@ func RequireClientAccess() fiber.Handler {
 
 		clientIDStr := c.Params("client_id")
 		if clientIDStr == "" {
-			clientIDStr = c.Get("X-Client-ID")
+			clientIDStr = c.Get(HeaderClientID)
 		}
 
 		if clientIDStr == "" {
@@ -227,11 +347,17 @@ func RequireClientAccess() fiber.Handler {
 			return response.BadRequest(c, "Invalid client ID")
 		}
 
-		c.Locals(ContextKeyClientID, clientID)
-
-		// TODO: Verificar se o cliente pertence ao tenant do usuário
-		// Isso requer acesso ao repositório de clientes
+		owned, err := store.BelongsToTenant(c.Context(), clientID, GetTenantID(c))
+		if err != nil {
+			logger.FromContext(c).WithError(err).Error("failed to verify client ownership")
+			return response.InternalServerError(c, "Failed to verify client access")
+		}
+		if !owned {
+			logger.FromContext(c).WithField("client_id", clientID.String()).Warn("access denied: client belongs to another tenant")
+			return response.Fail(c, response.CodeClientAccessDenied, "Access denied to this client")
+		}
 
+		c.Locals(ContextKeyClientID, clientID)
 		return c.Next()
 	}
 }
@@ -249,6 +375,12 @@ func GetClaims(c *fiber.Ctx) *auth.Claims {
 	return claims
 }
 
+// IsServiceRequest indica se a request foi autenticada com o token de serviço
+func IsServiceRequest(c *fiber.Ctx) bool {
+	service, ok := c.Locals(ContextKeyService).(bool)
+	return ok && service
+}
+
 // GetUserID retorna o user_id do contexto
 func GetUserID(c *fiber.Ctx) uuid.UUID {
 	userID, ok := c.Locals(ContextKeyUserID).(uuid.UUID)
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// withScopes autentica a request de teste com o role e os scopes informados
+func withScopes(role models.Role, scopes ...models.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tenantID := uuid.New()
+		c.Locals(ContextKeyClaims, &auth.Claims{UserID: uuid.New(), TenantID: tenantID, Role: role, Scopes: scopes})
+		c.Locals(ContextKeyTenantID, tenantID)
+		return c.Next()
+	}
+}
+
+// testStatus executa a request e retorna o status e o código de erro do envelope
+func testStatus(t *testing.T, app *fiber.App, method, target string) (int, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(method, target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == fiber.StatusOK {
+		return resp.StatusCode, ""
+	}
+	var envelope response.Response
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if envelope.Error == nil {
+		return resp.StatusCode, ""
+	}
+	return resp.StatusCode, envelope.Error.Code
+}
+
+// Um token sem nenhum scope recebe NO_SCOPES (e um aviso no log), distinto de
+// SCOPE_MISSING, que indica a falta de um scope específico
+func TestRequireScopeDeniesEmptyScopesWithNoScopes(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.Config{Level: logger.InfoLevel, Output: &buf})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		logger.ToContext(c, log)
+		return c.Next()
+	})
+	app.Get("/empty/any", withScopes(models.RoleAnalyst), RequireScope(ScopeClientsRead), ok)
+	app.Get("/empty/all", withScopes(models.RoleAnalyst), RequireAllScopes(ScopeClientsRead, ScopeBrandsRead), ok)
+	app.Get("/viewer/any", withScopes(models.RoleViewer), RequireScope(ScopeClientsRead), ok)
+	app.Get("/missing", withScopes(models.RoleViewer, ScopeAlertsRead), RequireScope(ScopeClientsWrite), ok)
+	app.Get("/granted", withScopes(models.RoleViewer, ScopeClientsRead), RequireScope(ScopeClientsRead), ok)
+	app.Get("/admin", withScopes(models.RoleAdmin), RequireScope(ScopeClientsWrite), ok)
+
+	for _, tc := range []struct {
+		target string
+		status int
+		code   string
+	}{
+		{"/empty/any", fiber.StatusForbidden, response.CodeNoScopes},
+		{"/empty/all", fiber.StatusForbidden, response.CodeNoScopes},
+		{"/viewer/any", fiber.StatusForbidden, response.CodeNoScopes},
+		{"/missing", fiber.StatusForbidden, response.CodeScopeMissing},
+		{"/granted", fiber.StatusOK, ""},
+		{"/admin", fiber.StatusOK, ""},
+	} {
+		if status, code := testStatus(t, app, fiber.MethodGet, tc.target); status != tc.status || code != tc.code {
+			t.Errorf("%s: status = %d, code = %q, want %d %q", tc.target, status, code, tc.status, tc.code)
+		}
+	}
+
+	var warnings int
+	for _, entry := range logEntries(t, &buf) {
+		if entry.Message == "access denied: token has no scopes" {
+			warnings++
+		}
+	}
+	if warnings != 3 {
+		t.Errorf("NO_SCOPES warnings logged = %d, want 3", warnings)
+	}
+}
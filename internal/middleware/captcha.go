@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoints de verificação (siteverify) dos provedores suportados
+const (
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+)
+
+// Onde o token de CAPTCHA é procurado na request
+const (
+	CaptchaHeader    = "X-Captcha-Token"
+	captchaBodyField = "captcha_token"
+)
+
+var (
+	// ErrCaptchaInvalid token ausente, expirado ou rejeitado pelo provedor
+	ErrCaptchaInvalid = errors.New("captcha verification failed")
+	// ErrCaptchaUnavailable provedor de CAPTCHA inacessível ou com resposta inválida
+	ErrCaptchaUnavailable = errors.New("captcha provider unavailable")
+)
+
+// CaptchaVerifier valida um token de CAPTCHA junto ao provedor
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// =============================================================================
+// SITEVERIFY VERIFIER
+// =============================================================================
+
+// SiteVerifier implementa CaptchaVerifier para provedores com API siteverify
+// (Cloudflare Turnstile, hCaptcha e reCAPTCHA compartilham o mesmo contrato)
+type SiteVerifier struct {
+	verifyURL  string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewCaptchaVerifier cria o verifier do provedor (turnstile ou hcaptcha).
+// verifyURL sobrescreve o endpoint padrão do provedor quando informado.
+func NewCaptchaVerifier(provider, secret, verifyURL string, timeout time.Duration) (*SiteVerifier, error) {
+	if secret == "" {
+		return nil, errors.New("captcha secret is required")
+	}
+
+	if verifyURL == "" {
+		switch strings.ToLower(provider) {
+		case "", "turnstile":
+			verifyURL = TurnstileVerifyURL
+		case "hcaptcha":
+			verifyURL = HCaptchaVerifyURL
+		default:
+			return nil, fmt.Errorf("invalid captcha provider %q (expected turnstile or hcaptcha)", provider)
+		}
+	}
+
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &SiteVerifier{
+		verifyURL:  verifyURL,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// siteVerifyResponse resposta da API siteverify
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify envia o token ao provedor e retorna ErrCaptchaInvalid se ele for rejeitado
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCaptchaUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", ErrCaptchaUnavailable, resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrCaptchaUnavailable, err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrCaptchaInvalid, strings.Join(result.ErrorCodes, ","))
+	}
+	return nil
+}
+
+// =============================================================================
+// CAPTCHA MIDDLEWARE
+// =============================================================================
+
+// CaptchaMiddleware exige um token de CAPTCHA válido, lido do header X-Captcha-Token
+// ou do campo "captcha_token" do corpo JSON. Com verifier nil (desabilitado) é um no-op.
+func CaptchaMiddleware(verifier CaptchaVerifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if verifier == nil {
+			return c.Next()
+		}
+
+		token := captchaToken(c)
+		if token == "" {
+			return response.Fail(c, response.CodeCaptchaRequired, "Captcha token is required")
+		}
+
+		if err := verifier.Verify(c.UserContext(), token, ClientIP(c)); err != nil {
+			if errors.Is(err, ErrCaptchaInvalid) {
+				return response.Fail(c, response.CodeCaptchaFailed, "Captcha verification failed")
+			}
+			logger.FromContext(c).WithError(err).Error("captcha verification unavailable")
+			return response.ServiceUnavailable(c, "Captcha verification unavailable")
+		}
+
+		return c.Next()
+	}
+}
+
+// captchaToken extrai o token do header ou, na ausência dele, do corpo JSON
+func captchaToken(c *fiber.Ctx) string {
+	if token := c.Get(CaptchaHeader); token != "" {
+		return token
+	}
+
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return ""
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return ""
+	}
+
+	var token string
+	if err := json.Unmarshal(body[captchaBodyField], &token); err != nil {
+		return ""
+	}
+	return token
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// fakeCaptchaVerifier aceita apenas o token "valid", devolve err quando definido e
+// guarda uma cópia dos tokens recebidos (o header do Fiber aponta para um buffer reutilizado)
+type fakeCaptchaVerifier struct {
+	err    error
+	tokens []string
+}
+
+func (v *fakeCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	v.tokens = append(v.tokens, strings.Clone(token))
+	if v.err != nil {
+		return v.err
+	}
+	if token != "valid" {
+		return fmt.Errorf("%w: invalid-input-response", ErrCaptchaInvalid)
+	}
+	return nil
+}
+
+// captchaRequest executa POST /register com o header e o corpo JSON informados
+func captchaRequest(t *testing.T, app *fiber.App, header, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, "/register", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if header != "" {
+		req.Header.Set(CaptchaHeader, header)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == fiber.StatusCreated {
+		return resp.StatusCode, ""
+	}
+	var envelope response.Response
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error == nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	return resp.StatusCode, envelope.Error.Code
+}
+
+func newCaptchaApp(verifier CaptchaVerifier) *fiber.App {
+	app := fiber.New()
+	app.Post("/register", CaptchaMiddleware(verifier), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	return app
+}
+
+func TestCaptchaMiddlewareWithFakeVerifier(t *testing.T) {
+	verifier := &fakeCaptchaVerifier{}
+	app := newCaptchaApp(verifier)
+
+	for _, tc := range []struct {
+		name   string
+		header string
+		body   string
+		status int
+		code   string
+	}{
+		{"header token", "valid", `{}`, fiber.StatusCreated, ""},
+		{"body token", "", `{"captcha_token":"valid"}`, fiber.StatusCreated, ""},
+		{"header wins over body", "valid", `{"captcha_token":"other"}`, fiber.StatusCreated, ""},
+		{"rejected token", "expired", `{}`, fiber.StatusBadRequest, response.CodeCaptchaFailed},
+		{"missing token", "", `{"email":"ana@acme.com"}`, fiber.StatusBadRequest, response.CodeCaptchaRequired},
+		{"non-string body token", "", `{"captcha_token":123}`, fiber.StatusBadRequest, response.CodeCaptchaRequired},
+	} {
+		if status, code := captchaRequest(t, app, tc.header, tc.body); status != tc.status || code != tc.code {
+			t.Errorf("%s: status = %d, code = %q, want %d %q", tc.name, status, code, tc.status, tc.code)
+		}
+	}
+
+	// Sem token o provedor não é consultado
+	if want := []string{"valid", "valid", "valid", "expired"}; !reflect.DeepEqual(verifier.tokens, want) {
+		t.Errorf("verified tokens = %v, want %v", verifier.tokens, want)
+	}
+}
+
+func TestCaptchaMiddlewareProviderUnavailable(t *testing.T) {
+	app := newCaptchaApp(&fakeCaptchaVerifier{err: fmt.Errorf("%w: status 502", ErrCaptchaUnavailable)})
+
+	if status, code := captchaRequest(t, app, "valid", `{}`); status != fiber.StatusServiceUnavailable || code != response.CodeServiceUnavailable {
+		t.Errorf("status = %d, code = %q, want 503 %s", status, code, response.CodeServiceUnavailable)
+	}
+}
+
+// Desabilitado (verifier nil) o middleware não exige token
+func TestCaptchaMiddlewareDisabled(t *testing.T) {
+	if status, code := captchaRequest(t, newCaptchaApp(nil), "", `{}`); status != fiber.StatusCreated {
+		t.Errorf("status = %d, code = %q, want 201", status, code)
+	}
+}
+
+func TestSiteVerifierSendsSecretAndToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		if r.PostForm.Get("secret") != "s3cret" || r.PostForm.Get("remoteip") != "203.0.113.7" {
+			t.Errorf("siteverify form = %v", r.PostForm)
+		}
+		success := r.PostForm.Get("response") == "valid"
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": success, "error-codes": []string{}})
+	}))
+	defer server.Close()
+
+	verifier, err := NewCaptchaVerifier("turnstile", "s3cret", server.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := verifier.Verify(ctx, "valid", "203.0.113.7"); err != nil {
+		t.Errorf("valid token: %v", err)
+	}
+	if err := verifier.Verify(ctx, "forged", "203.0.113.7"); !errors.Is(err, ErrCaptchaInvalid) {
+		t.Errorf("forged token: err = %v, want ErrCaptchaInvalid", err)
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/netip"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContextKeyClientIP chave do IP do cliente resolvido por ClientIPMiddleware
+const ContextKeyClientIP = "client_ip"
+
+// ClientIPConfig configuração da resolução do IP do cliente atrás de proxies
+type ClientIPConfig struct {
+	// Header com a cadeia de IPs adicionada pelos proxies (ex.: X-Forwarded-For).
+	// Vazio usa sempre o IP da conexão.
+	ProxyHeader string
+	// IPs ou CIDRs dos proxies confiáveis (ex.: 10.0.0.0/8). Entradas inválidas são
+	// ignoradas; a configuração é validada no startup.
+	TrustedProxies []string
+}
+
+// ClientIPMiddleware resolve o IP real do cliente e o guarda no contexto (ver ClientIP).
+// O header só é considerado quando a conexão vem de um proxy confiável; a cadeia é
+// percorrida da direita para a esquerda, pulando proxies confiáveis, e o primeiro IP
+// não confiável é o cliente. Entradas à esquerda dele podem ter sido forjadas.
+func ClientIPMiddleware(config ClientIPConfig) fiber.Handler {
+	trusted := parseTrustedProxies(config.TrustedProxies)
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *fiber.Ctx) error {
+		peer, ok := netip.AddrFromSlice(c.Context().RemoteIP())
+		if !ok {
+			return c.Next()
+		}
+		client := peer.Unmap()
+
+		if config.ProxyHeader != "" && isTrusted(client) {
+			hops := strings.Split(c.Get(config.ProxyHeader), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop, ok := parseHop(hops[i])
+				if !ok {
+					break
+				}
+				client = hop
+				if !isTrusted(hop) {
+					break
+				}
+			}
+		}
+
+		c.Locals(ContextKeyClientIP, client.String())
+		return c.Next()
+	}
+}
+
+// ClientIP retorna o IP do cliente resolvido por ClientIPMiddleware, ou o IP da
+// conexão se o middleware não rodou
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(ContextKeyClientIP).(string); ok {
+		return ip
+	}
+	return c.IP()
+}
+
+// parseTrustedProxies converte IPs e CIDRs em prefixos; IPs viram prefixos de host
+func parseTrustedProxies(entries []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return prefixes
+}
+
+// parseHop lê uma entrada do header, aceitando IP puro ou IP:porta
+func parseHop(hop string) (netip.Addr, bool) {
+	hop = strings.TrimSpace(hop)
+	if addr, err := netip.ParseAddr(hop); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	return netip.Addr{}, false
+}
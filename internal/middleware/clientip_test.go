@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// resolvedClientIP executa a request com o X-Forwarded-For informado e retorna o IP resolvido
+func resolvedClientIP(t *testing.T, app *fiber.App, forwardedFor string) string {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if forwardedFor != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+// A conexão de teste do Fiber vem de 0.0.0.0, confiável aqui como o load balancer; as
+// demais entradas da cadeia só são puladas quando estão nas faixas confiáveis
+func TestClientIPSkipsTrustedProxyRanges(t *testing.T) {
+	app := fiber.New()
+	app.Use(ClientIPMiddleware(ClientIPConfig{
+		ProxyHeader:    fiber.HeaderXForwardedFor,
+		TrustedProxies: []string{"0.0.0.0", "10.0.0.0/8", "fd00::/8"},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{"", "0.0.0.0"},
+		{"203.0.113.9", "203.0.113.9"},
+		{"203.0.113.9, 10.1.2.3", "203.0.113.9"},
+		{"2001:db8::9, fd00::1, 10.0.0.2", "2001:db8::9"},
+		{"[2001:db8::9]:443, fd12:3456::1", "2001:db8::9"},
+		{"::ffff:203.0.113.9, 10.0.0.2", "203.0.113.9"},
+		// A entrada forjada à esquerda do primeiro IP não confiável é ignorada
+		{"198.51.100.1, 2001:db8::9, fd00::1", "2001:db8::9"},
+		// IPv6 fora da faixa confiável encerra a busca
+		{"203.0.113.9, fe80::1", "fe80::1"},
+		// Entrada inválida interrompe a busca no último IP válido lido
+		{"garbage, 10.0.0.2", "10.0.0.2"},
+	} {
+		if got := resolvedClientIP(t, app, tc.header); got != tc.want {
+			t.Errorf("X-Forwarded-For %q: client IP = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+// Sem a conexão vir de um proxy confiável o header é ignorado
+func TestClientIPIgnoresHeaderFromUntrustedPeer(t *testing.T) {
+	app := fiber.New()
+	app.Use(ClientIPMiddleware(ClientIPConfig{
+		ProxyHeader:    fiber.HeaderXForwardedFor,
+		TrustedProxies: []string{"10.0.0.0/8", "2001:db8::/32"},
+	}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+
+	if got := resolvedClientIP(t, app, "203.0.113.9"); got != "0.0.0.0" {
+		t.Errorf("client IP = %q, want the connection IP 0.0.0.0", got)
+	}
+}
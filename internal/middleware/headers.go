@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/netip"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Headers de contexto que clientes podem tentar forjar
+const (
+	HeaderTenantID  = "X-Tenant-ID"
+	HeaderClientID  = "X-Client-ID"
+	HeaderRequestID = "X-Request-ID"
+)
+
+// maxRequestIDLength tamanho máximo de um X-Request-ID aceito do cliente (um
+// UUID tem 36 caracteres)
+const maxRequestIDLength = 64
+
+// TrustedHeadersConfig configuração do TrustedHeadersMiddleware
+type TrustedHeadersConfig struct {
+	// IPs ou CIDRs (ex.: serviços internos) cujas requests podem escolher o
+	// tenant via X-Tenant-ID. Comparados com o IP do cliente resolvido por
+	// ClientIPMiddleware. Entradas inválidas são ignoradas; a configuração é
+	// validada no startup.
+	TrustedSources []string
+}
+
+// TrustedHeadersMiddleware remove headers de contexto que o cliente não pode
+// definir. X-Tenant-ID só é mantido para fontes confiáveis: para os demais o
+// tenant vem do token. X-Request-ID do cliente é mantido se for curto e só tiver
+// caracteres seguros; caso contrário é descartado e o gateway gera outro.
+// X-Client-ID passa, mas só é usado depois da validação de RequireClientAccess.
+// Deve rodar depois de ClientIPMiddleware e antes do middleware de request id.
+func TrustedHeadersMiddleware(config TrustedHeadersConfig) fiber.Handler {
+	trusted := parseTrustedProxies(config.TrustedSources)
+	isTrusted := func(ip string) bool {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range trusted {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *fiber.Ctx) error {
+		header := &c.Request().Header
+
+		if len(header.Peek(HeaderTenantID)) > 0 && !isTrusted(ClientIP(c)) {
+			header.Del(HeaderTenantID)
+		}
+
+		if requestID := header.Peek(HeaderRequestID); len(requestID) > 0 && !isValidRequestID(requestID) {
+			header.Del(HeaderRequestID)
+		}
+
+		return c.Next()
+	}
+}
+
+// isValidRequestID aceita UUIDs e ids opacos de até maxRequestIDLength caracteres
+// alfanuméricos, '-', '_', '.' ou ':', que podem ir para logs e headers sem escape
+func isValidRequestID(id []byte) bool {
+	if len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, b := range id {
+		switch {
+		case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z', b >= '0' && b <= '9':
+		case b == '-', b == '_', b == '.', b == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newIPFilterApp monta o filtro com o IP do cliente vindo do header X-Test-IP
+func newIPFilterApp(filter *IPFilter) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(ContextKeyClientIP, c.Get("X-Test-IP"))
+		return c.Next()
+	})
+	app.Use(filter.Handler())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	return app
+}
+
+func ipFilterStatus(t *testing.T, app *fiber.App, ip string) int {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("X-Test-IP", ip)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestIPFilterMatchesCIDRRanges(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterRules{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32", "198.51.100.7"},
+		Deny:  []string{"10.1.0.0/16", "2001:db8:bad::/48", "::ffff:192.0.2.1"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newIPFilterApp(filter)
+
+	for _, tc := range []struct {
+		ip     string
+		status int
+	}{
+		{"10.2.3.4", fiber.StatusOK},
+		{"10.1.2.3", fiber.StatusForbidden},
+		{"198.51.100.7", fiber.StatusOK},
+		{"198.51.100.8", fiber.StatusForbidden},
+		{"2001:db8:1::1", fiber.StatusOK},
+		{"2001:db8:bad::1", fiber.StatusForbidden},
+		{"2001:db9::1", fiber.StatusForbidden},
+		// IPv4 mapeado em IPv6 casa com as regras IPv4
+		{"::ffff:10.2.3.4", fiber.StatusOK},
+		{"192.0.2.1", fiber.StatusForbidden},
+		{"not-an-ip", fiber.StatusForbidden},
+	} {
+		if status := ipFilterStatus(t, app, tc.ip); status != tc.status {
+			t.Errorf("%s: status = %d, want %d", tc.ip, status, tc.status)
+		}
+	}
+}
+
+func TestIPFilterUpdateReplacesRules(t *testing.T) {
+	filter, err := NewIPFilter(IPFilterRules{Deny: []string{"2001:db8::/32"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newIPFilterApp(filter)
+
+	if status := ipFilterStatus(t, app, "2001:db8::1"); status != fiber.StatusForbidden {
+		t.Errorf("before update: status = %d, want 403", status)
+	}
+
+	// Regras inválidas são rejeitadas e as atuais mantidas
+	if err := filter.Update(IPFilterRules{Deny: []string{"2001:db8::/129"}}); err == nil {
+		t.Error("Update accepted an invalid CIDR")
+	}
+	if status := ipFilterStatus(t, app, "2001:db8::1"); status != fiber.StatusForbidden {
+		t.Errorf("after invalid update: status = %d, want 403", status)
+	}
+
+	if err := filter.Update(IPFilterRules{Deny: []string{"203.0.113.0/24"}}); err != nil {
+		t.Fatal(err)
+	}
+	if status := ipFilterStatus(t, app, "2001:db8::1"); status != fiber.StatusOK {
+		t.Errorf("after update: status = %d, want 200", status)
+	}
+	if status := ipFilterStatus(t, app, "203.0.113.50"); status != fiber.StatusForbidden {
+		t.Errorf("newly denied range: status = %d, want 403", status)
+	}
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrJobLimitReached o tenant já tem o máximo de jobs simultâneos em execução
+var ErrJobLimitReached = errors.New("concurrent job limit reached")
+
+// JobSlotStore contador de jobs em execução por tenant. A implementação em memória
+// vale para uma instância; com múltiplas réplicas o store precisa ser compartilhado.
+type JobSlotStore interface {
+	// Acquire ocupa um slot se o tenant tiver menos de limit jobs em execução
+	Acquire(ctx context.Context, tenantID uuid.UUID, limit int) (bool, error)
+	// Release libera um slot ocupado por Acquire
+	Release(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// JobLimitStore fonte do limite de jobs simultâneos do tenant (0 = usar o padrão)
+type JobLimitStore interface {
+	GetMaxConcurrentJobs(ctx context.Context, tenantID uuid.UUID) (int, error)
+}
+
+// =============================================================================
+// JOB LIMITER
+// =============================================================================
+
+// JobLimiter semáforo por tenant para operações longas no MCP (hunt, scan, analyze)
+type JobLimiter struct {
+	slots  JobSlotStore
+	limits JobLimitStore
+	ttl    time.Duration
+
+	mu           sync.RWMutex
+	defaultLimit int
+	entries      map[uuid.UUID]cachedTenantLimit
+}
+
+// NewJobLimiter cria um novo limitador de jobs simultâneos. O limite de cada tenant
+// vem de TenantSettings.MaxConcurrentJobs (cacheado por ttl), com defaultLimit
+// quando o tenant não define um valor.
+func NewJobLimiter(slots JobSlotStore, limits JobLimitStore, defaultLimit int, ttl time.Duration) *JobLimiter {
+	if defaultLimit <= 0 {
+		defaultLimit = 5
+	}
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+
+	return &JobLimiter{
+		slots:        slots,
+		limits:       limits,
+		ttl:          ttl,
+		defaultLimit: defaultLimit,
+		entries:      make(map[uuid.UUID]cachedTenantLimit),
+	}
+}
+
+// Acquire ocupa um slot de job do tenant. Retorna a função que libera o slot
+// (deve ser chamada ao fim do job) ou ErrJobLimitReached se o tenant estiver no limite.
+// Falhas do store não bloqueiam o job. Um limiter nil não limita.
+func (l *JobLimiter) Acquire(ctx context.Context, tenantID uuid.UUID) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	limit := l.Limit(ctx, tenantID)
+	ok, err := l.slots.Acquire(ctx, tenantID, limit)
+	if err != nil {
+		logger.WithField("tenant_id", tenantID.String()).Warn("failed to acquire job slot: %v", err)
+		return func() {}, nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w (%d)", ErrJobLimitReached, limit)
+	}
+
+	label, _ := TenantLabel(tenantID.String())
+	inFlight := jobsInFlight.WithLabelValues(label)
+	inFlight.Inc()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			inFlight.Dec()
+			// O contexto da request pode já estar cancelado; a liberação não deve falhar por isso
+			if err := l.slots.Release(context.Background(), tenantID); err != nil {
+				logger.WithField("tenant_id", tenantID.String()).Warn("failed to release job slot: %v", err)
+			}
+		})
+	}, nil
+}
+
+// Limit retorna o limite de jobs simultâneos do tenant.
+// Em caso de erro no banco mantém o último valor conhecido.
+func (l *JobLimiter) Limit(ctx context.Context, tenantID uuid.UUID) int {
+	now := time.Now()
+
+	l.mu.RLock()
+	entry, ok := l.entries[tenantID]
+	l.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.limit
+	}
+
+	limit, err := l.limits.GetMaxConcurrentJobs(ctx, tenantID)
+	if err != nil {
+		logger.WithField("tenant_id", tenantID.String()).Warn("failed to load tenant job limit: %v", err)
+		limit = entry.limit
+	}
+	l.mu.Lock()
+	if limit <= 0 {
+		limit = l.defaultLimit
+	}
+	l.entries[tenantID] = cachedTenantLimit{limit: limit, expiresAt: now.Add(l.ttl)}
+	l.mu.Unlock()
+
+	return limit
+}
+
+// SetDefaultLimit altera o limite padrão em tempo de execução (ex: reload via SIGHUP).
+// O cache é limpo para que tenants sem limite próprio passem a usar o novo valor.
+func (l *JobLimiter) SetDefaultLimit(limit int) {
+	if limit <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.defaultLimit = limit
+	l.entries = make(map[uuid.UUID]cachedTenantLimit)
+	l.mu.Unlock()
+}
+
+// Invalidate remove o limite do tenant do cache, forçando nova leitura do banco
+func (l *JobLimiter) Invalidate(tenantID uuid.UUID) {
+	l.mu.Lock()
+	delete(l.entries, tenantID)
+	l.mu.Unlock()
+}
+
+// =============================================================================
+// MEMORY JOB SLOT STORE
+// =============================================================================
+
+// MemoryJobSlotStore implementação em memória do JobSlotStore (instância única)
+type MemoryJobSlotStore struct {
+	mu      sync.Mutex
+	running map[uuid.UUID]int
+}
+
+// NewMemoryJobSlotStore cria um novo store de slots em memória
+func NewMemoryJobSlotStore() *MemoryJobSlotStore {
+	return &MemoryJobSlotStore{
+		running: make(map[uuid.UUID]int),
+	}
+}
+
+// Acquire ocupa um slot se houver capacidade
+func (s *MemoryJobSlotStore) Acquire(ctx context.Context, tenantID uuid.UUID, limit int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[tenantID] >= limit {
+		return false, nil
+	}
+	s.running[tenantID]++
+	return true, nil
+}
+
+// Release libera um slot do tenant
+func (s *MemoryJobSlotStore) Release(ctx context.Context, tenantID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[tenantID] <= 1 {
+		delete(s.running, tenantID)
+		return nil
+	}
+	s.running[tenantID]--
+	return nil
+}
@@ -1,12 +1,20 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/arcaintelligence/arca-gateway/pkg/buildinfo"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -18,7 +26,7 @@ var (
 			Name: "arca_http_requests_total",
 			Help: "Total number of HTTP requests",
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "path", "status", "status_class"},
 	)
 
 	httpRequestDuration = promauto.NewHistogramVec(
@@ -72,6 +80,14 @@ var (
 		[]string{"tenant_id"},
 	)
 
+	jobsInFlight = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "arca_jobs_in_flight",
+			Help: "Number of hunting/scan jobs currently running against MCP",
+		},
+		[]string{"tenant_id"},
+	)
+
 	threatsDetected = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "arca_threats_detected_total",
@@ -112,47 +128,242 @@ var (
 		},
 		[]string{"reason"},
 	)
+
+	auditEventsDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "arca_audit_events_dropped_total",
+			Help: "Total number of audit events dropped because the audit queue was full",
+		},
+		[]string{"policy"},
+	)
+
+	panicsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "arca_panics_total",
+			Help: "Total number of panics recovered from request handlers",
+		},
+		[]string{"path"},
+	)
+
+	monitoringDrift = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "arca_monitoring_drift_total",
+			Help: "Total number of brand monitoring fields corrected to match the MCP job state",
+		},
+		[]string{"tenant_id", "field"},
+	)
+
+	schedulerRuns = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "arca_scheduler_runs_total",
+			Help: "Total number of background job runs by result (success, error, panic, skipped)",
+		},
+		[]string{"job", "status"},
+	)
+
+	schedulerRunDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "arca_scheduler_run_duration_seconds",
+			Help:    "Background job run duration in seconds",
+			Buckets: []float64{.01, .1, .5, 1, 5, 15, 30, 60, 300},
+		},
+		[]string{"job"},
+	)
+
+	leader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "arca_leader",
+			Help: "Whether this instance holds the leader lock and runs singleton background jobs (1) or not (0)",
+		},
+	)
+
+	entityCacheRequests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "arca_entity_cache_requests_total",
+			Help: "Total number of user/tenant lookups by ID served from the entity cache (hit) or the database (miss)",
+		},
+		[]string{"cache", "result"},
+	)
 )
 
-// MetricsMiddleware middleware para coletar métricas
-func MetricsMiddleware() fiber.Handler {
+// Label path de requests que não casaram com nenhuma rota
+const unmatchedRoute = "unmatched"
+
+// DefaultSlowRoutes rotas que chamam o MCP e podem levar de segundos a minutos
+func DefaultSlowRoutes() []string {
+	return []string{"/v1/hunting", "/v1/monitor", "/v1/onboarding", "/v1/brands", "/v1/threats"}
+}
+
+// DefaultSlowBuckets buckets (segundos) do histograma das rotas lentas
+func DefaultSlowBuckets() []float64 {
+	return []float64{.25, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+}
+
+// MetricsConfig configuração do MetricsMiddleware
+type MetricsConfig struct {
+	// SlowRoutes prefixos de rotas (templates) observados também em
+	// arca_http_slow_request_duration_seconds; nil usa DefaultSlowRoutes
+	SlowRoutes []string
+	// SlowBuckets buckets do histograma das rotas lentas; nil usa DefaultSlowBuckets
+	SlowBuckets []float64
+}
+
+// ParseBuckets converte buckets de histograma (segundos), que precisam ser
+// positivos e crescentes
+func ParseBuckets(values []string) ([]float64, error) {
+	buckets := make([]float64, 0, len(values))
+	for _, value := range values {
+		bucket, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || bucket <= 0 {
+			return nil, fmt.Errorf("invalid histogram bucket %q: must be a positive number of seconds", value)
+		}
+		if len(buckets) > 0 && bucket <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("histogram buckets must be increasing, got %q after %g", value, buckets[len(buckets)-1])
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// MetricsMiddleware middleware para coletar métricas HTTP. O label path é o template
+// da rota ("/v1/clients/:client_id"), lido depois do roteamento, para que IDs na URL
+// não criem séries novas; requests sem rota usam "unmatched". As rotas lentas (que
+// esperam o MCP) passam dos buckets de arca_http_request_duration_seconds, então
+// também são observadas em um histograma com buckets de até minutos.
+func MetricsMiddleware(config MetricsConfig) fiber.Handler {
+	if config.SlowRoutes == nil {
+		config.SlowRoutes = DefaultSlowRoutes()
+	}
+	if len(config.SlowBuckets) == 0 {
+		config.SlowBuckets = DefaultSlowBuckets()
+	}
+	slowDuration := slowRequestDuration(config.SlowBuckets)
+
+	slowPrefixes := make([]string, 0, len(config.SlowRoutes))
+	for _, route := range config.SlowRoutes {
+		if route = strings.TrimRight(strings.TrimSpace(route), "/"); route != "" {
+			slowPrefixes = append(slowPrefixes, route)
+		}
+	}
+	isSlow := func(path string) bool {
+		for _, prefix := range slowPrefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
-		path := c.Route().Path
 		method := c.Method()
-
-		// Request size
 		reqSize := float64(len(c.Body()))
-		httpRequestSize.WithLabelValues(method, path).Observe(reqSize)
 
 		// Process request
 		err := c.Next()
 
-		// Response metrics
+		// Antes do Next a rota é a deste middleware ("/"); depois é a do handler
+		path := unmatchedRoute
+		if route := c.Route(); route != nil && route.Path != "" && route.Path != "/" {
+			path = route.Path
+		} else if c.Path() == "/" {
+			path = "/"
+		}
+
+		// Response metrics. O error handler global ainda não rodou: derivar o status do erro
 		duration := time.Since(start).Seconds()
-		status := strconv.Itoa(c.Response().StatusCode())
+		statusCode := c.Response().StatusCode()
+		if err != nil {
+			statusCode = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				statusCode = fiberErr.Code
+			}
+		}
+		status := strconv.Itoa(statusCode)
+		statusClass := strconv.Itoa(statusCode/100) + "xx"
 		respSize := float64(len(c.Response().Body()))
 
-		httpRequestsTotal.WithLabelValues(method, path, status).Inc()
+		httpRequestsTotal.WithLabelValues(method, path, status, statusClass).Inc()
 		httpRequestDuration.WithLabelValues(method, path).Observe(duration)
+		httpRequestSize.WithLabelValues(method, path).Observe(reqSize)
 		httpResponseSize.WithLabelValues(method, path).Observe(respSize)
+		if path != unmatchedRoute && isSlow(path) {
+			slowDuration.WithLabelValues(method, path).Observe(duration)
+		}
 
 		return err
 	}
 }
 
+// slowRequestDuration registra o histograma das rotas lentas. Se já houver um
+// registrado (middleware criado mais de uma vez), reaproveita o existente.
+func slowRequestDuration(buckets []float64) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "arca_http_slow_request_duration_seconds",
+			Help:    "HTTP request duration in seconds of MCP-backed routes",
+			Buckets: buckets,
+		},
+		[]string{"method", "path"},
+	)
+	if err := prometheus.Register(histogram); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(*prometheus.HistogramVec); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return histogram
+}
+
 // MetricsHandler retorna o handler do Prometheus
 func MetricsHandler() fiber.Handler {
 	return adaptor.HTTPHandler(promhttp.Handler())
 }
 
+// RegisterProcessMetrics registra arca_build_info (sempre 1, com versão e commit nos
+// labels, para dashboards e anotações de deploy) e os collectors de runtime do Go
+// (go_goroutines, go_gc_*, go_memstats_*) e do processo (process_resident_memory_bytes,
+// process_cpu_seconds_total, ...). O registry padrão do client_golang já costuma
+// incluir os dois collectors; nesse caso o registro é ignorado.
+func RegisterProcessMetrics(info buildinfo.Info) error {
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "arca_build_info",
+		Help: "Build information of the running gateway; always 1",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"go_version": info.GoVersion,
+		},
+	})
+	buildInfo.Set(1)
+
+	for _, collector := range []prometheus.Collector{
+		buildInfo,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := prometheus.Register(collector); err != nil {
+			var registered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &registered) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // =============================================================================
 // BUSINESS METRICS HELPERS
 // =============================================================================
 
 // RecordHuntingOperation registra uma operação de hunting
 func RecordHuntingOperation(tenantID, operation, status string) {
-	huntingOperations.WithLabelValues(tenantID, operation, status).Inc()
+	label, _ := TenantLabel(tenantID)
+	huntingOperations.WithLabelValues(label, operation, status).Inc()
 }
 
 // RecordMCPRequest registra uma requisição MCP
@@ -163,12 +374,14 @@ func RecordMCPRequest(tool, action, status string, duration time.Duration) {
 
 // RecordThreatDetected registra uma ameaça detectada
 func RecordThreatDetected(tenantID, severity, threatType string) {
-	threatsDetected.WithLabelValues(tenantID, severity, threatType).Inc()
+	label, _ := TenantLabel(tenantID)
+	threatsDetected.WithLabelValues(label, severity, threatType).Inc()
 }
 
 // RecordRateLimitHit registra um hit de rate limit
 func RecordRateLimitHit(tenantID, path string) {
-	rateLimitHits.WithLabelValues(tenantID, path).Inc()
+	label, _ := TenantLabel(tenantID)
+	rateLimitHits.WithLabelValues(label, path).Inc()
 }
 
 // RecordAuthFailure registra uma falha de autenticação
@@ -176,12 +389,169 @@ func RecordAuthFailure(reason string) {
 	authFailures.WithLabelValues(reason).Inc()
 }
 
+// RecordAuditDropped registra um evento de auditoria descartado por fila cheia
+func RecordAuditDropped(policy string) {
+	auditEventsDropped.WithLabelValues(policy).Inc()
+}
+
+// RecordMonitoringDrift registra um campo de monitoramento da marca que divergia do MCP
+func RecordMonitoringDrift(tenantID, field string) {
+	label, _ := TenantLabel(tenantID)
+	monitoringDrift.WithLabelValues(label, field).Inc()
+}
+
+// RecordSchedulerRun registra uma execução de tarefa em background. Execuções
+// puladas (lock com outra réplica) não têm duração.
+func RecordSchedulerRun(job, status string, duration time.Duration) {
+	schedulerRuns.WithLabelValues(job, status).Inc()
+	if status != "skipped" {
+		schedulerRunDuration.WithLabelValues(job).Observe(duration.Seconds())
+	}
+}
+
+// RecordEntityCache registra uma leitura do cache de usuários/tenants
+func RecordEntityCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	entityCacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// SetLeader indica se esta instância é a líder das tarefas singleton
+func SetLeader(isLeader bool) {
+	if isLeader {
+		leader.Set(1)
+		return
+	}
+	leader.Set(0)
+}
+
 // SetActiveUsers define o número de usuários ativos
 func SetActiveUsers(count float64) {
 	activeUsers.Set(count)
 }
 
-// SetActiveMonitoringJobs define o número de jobs de monitoramento ativos
+// SetActiveMonitoringJobs define o número de jobs de monitoramento ativos do tenant.
+// Só é registrado quando o tenant tem label próprio: com tenants agrupados (off, hash
+// ou "other") o valor de um sobrescreveria o dos outros do mesmo grupo.
 func SetActiveMonitoringJobs(tenantID string, count float64) {
-	monitoringJobs.WithLabelValues(tenantID).Set(count)
+	if label, individual := TenantLabel(tenantID); individual {
+		monitoringJobs.WithLabelValues(label).Set(count)
+	}
+}
+
+// =============================================================================
+// TENANT LABELS
+// =============================================================================
+
+// TenantLabelMode define como o tenant_id aparece nas métricas de negócio. Cada
+// valor distinto de tenant_id cria novas séries em cada métrica, então rotular
+// milhares de tenants pelo UUID pode esgotar a memória do Prometheus.
+type TenantLabelMode string
+
+const (
+	// TenantLabelsOff deixa tenant_id vazio: só os totais agregados (padrão)
+	TenantLabelsOff TenantLabelMode = "off"
+	// TenantLabelsAll usa o UUID do tenant; só para poucas dezenas de tenants
+	TenantLabelsAll TenantLabelMode = "all"
+	// TenantLabelsHash agrupa os tenants em um número fixo de buckets ("bucket-N")
+	TenantLabelsHash TenantLabelMode = "hash"
+	// TenantLabelsAllowlist usa o UUID dos tenants listados e "other" para os demais
+	TenantLabelsAllowlist TenantLabelMode = "allowlist"
+)
+
+// Valor de tenant_id dos tenants fora da allowlist
+const TenantLabelOther = "other"
+
+// Número padrão de buckets do modo hash
+const defaultTenantLabelBuckets = 32
+
+// TenantLabelConfig configuração do label tenant_id
+type TenantLabelConfig struct {
+	Mode TenantLabelMode
+	// Buckets número de buckets do modo hash (padrão 32)
+	Buckets int
+	// Allowlist tenants rotulados individualmente no modo allowlist
+	Allowlist []string
+}
+
+// tenantLabeler configuração ativa; nil equivale a TenantLabelsOff
+type tenantLabeler struct {
+	mode      TenantLabelMode
+	buckets   uint32
+	allowlist map[string]bool
+}
+
+var tenantLabels atomic.Pointer[tenantLabeler]
+
+// ParseTenantLabelMode valida o modo do label tenant_id (vazio = off)
+func ParseTenantLabelMode(value string) (TenantLabelMode, error) {
+	switch mode := TenantLabelMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return TenantLabelsOff, nil
+	case TenantLabelsOff, TenantLabelsAll, TenantLabelsHash, TenantLabelsAllowlist:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid tenant label mode %q (expected off, all, hash or allowlist)", value)
+	}
+}
+
+// ConfigureTenantLabels define como o tenant_id é rotulado nas métricas. Deve ser
+// chamado no startup, antes de registrar métricas: trocar o modo depois deixaria
+// gauges incrementados com um label e decrementados com outro.
+func ConfigureTenantLabels(config TenantLabelConfig) error {
+	labeler := &tenantLabeler{mode: config.Mode}
+	switch config.Mode {
+	case "", TenantLabelsOff:
+		labeler.mode = TenantLabelsOff
+	case TenantLabelsAll:
+	case TenantLabelsHash:
+		if config.Buckets < 0 {
+			return fmt.Errorf("tenant label buckets must be positive, got %d", config.Buckets)
+		}
+		labeler.buckets = uint32(config.Buckets)
+		if labeler.buckets == 0 {
+			labeler.buckets = defaultTenantLabelBuckets
+		}
+	case TenantLabelsAllowlist:
+		labeler.allowlist = make(map[string]bool, len(config.Allowlist))
+		for _, entry := range config.Allowlist {
+			id, err := uuid.Parse(strings.TrimSpace(entry))
+			if err != nil {
+				return fmt.Errorf("invalid tenant id %q in tenant label allowlist", entry)
+			}
+			labeler.allowlist[id.String()] = true
+		}
+	default:
+		return fmt.Errorf("invalid tenant label mode %q", config.Mode)
+	}
+
+	tenantLabels.Store(labeler)
+	return nil
+}
+
+// TenantLabel retorna o valor de tenant_id para o tenant conforme o modo configurado;
+// individual indica que o valor identifica só esse tenant (modo all ou allowlist)
+func TenantLabel(tenantID string) (label string, individual bool) {
+	labeler := tenantLabels.Load()
+	if labeler == nil {
+		return "", false
+	}
+
+	switch labeler.mode {
+	case TenantLabelsAll:
+		return tenantID, true
+	case TenantLabelsHash:
+		h := fnv.New32a()
+		h.Write([]byte(tenantID))
+		return "bucket-" + strconv.FormatUint(uint64(h.Sum32()%labeler.buckets), 10), false
+	case TenantLabelsAllowlist:
+		if labeler.allowlist[tenantID] {
+			return tenantID, true
+		}
+		return TenantLabelOther, false
+	default:
+		return "", false
+	}
 }
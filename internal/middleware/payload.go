@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultRedactFields campos JSON/form e headers mascarados por padrão nos logs de
+// payload. Entradas com "*" casam por padrão: "*token*" mascara qualquer chave que
+// contenha "token" (captcha_token, X-Captcha-Token...), "*_key" qualquer uma que
+// termine em "_key".
+var DefaultRedactFields = []string{
+	"*password*", "*secret*", "*token*", "api_key",
+	"authorization", "cookie", "set-cookie",
+}
+
+// Valor que substitui os campos mascarados
+const redactedValue = "[REDACTED]"
+
+// Tamanho máximo padrão de cada corpo logado (após a redação)
+const defaultPayloadLogMaxBytes = 4096
+
+// PayloadLoggerConfig configuração do log de payloads
+type PayloadLoggerConfig struct {
+	// Logger cujo nível decide se o payload é logado (apenas em debug)
+	Logger *logger.Logger
+	// Campos e headers mascarados além de DefaultRedactFields (sem diferenciar
+	// maiúsculas; aceita os mesmos padrões com "*")
+	RedactFields []string
+	// Corpos maiores são truncados (padrão 4096 bytes)
+	MaxBodyBytes int
+}
+
+// PayloadLogger loga headers e corpos de request e response para depuração, com os
+// campos sensíveis mascarados em qualquer nível do JSON. Só age quando o nível do
+// logger é debug (verificado a cada request, então acompanha o reload do LOG_LEVEL).
+// O corpo da request é lido do buffer do fasthttp e continua disponível para os
+// handlers; respostas em stream (SSE, exports) não são lidas.
+func PayloadLogger(config PayloadLoggerConfig) fiber.Handler {
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultPayloadLogMaxBytes
+	}
+	redact := newRedactor(append(append([]string{}, DefaultRedactFields...), config.RedactFields...))
+
+	return func(c *fiber.Ctx) error {
+		if config.Logger == nil || config.Logger.GetLevel() > logger.DebugLevel {
+			return c.Next()
+		}
+
+		requestHeaders := make(map[string]string)
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			requestHeaders[string(key)] = redactHeader(redact, string(key), string(value))
+		})
+		requestBody := redactPayload(redact, c.Get(fiber.HeaderContentType), c.Request().Body(), config.MaxBodyBytes)
+
+		err := c.Next()
+
+		responseHeaders := make(map[string]string)
+		c.Response().Header.VisitAll(func(key, value []byte) {
+			responseHeaders[string(key)] = redactHeader(redact, string(key), string(value))
+		})
+		responseBody := "[stream]"
+		if !c.Response().IsBodyStream() {
+			responseBody = redactPayload(redact, string(c.Response().Header.ContentType()), c.Response().Body(), config.MaxBodyBytes)
+		}
+
+		logger.FromContext(c).WithFields(map[string]interface{}{
+			"method":           c.Method(),
+			"path":             c.Path(),
+			"status":           c.Response().StatusCode(),
+			"request_headers":  requestHeaders,
+			"request_body":     requestBody,
+			"response_headers": responseHeaders,
+			"response_body":    responseBody,
+		}).Debug("payload %s %s", c.Method(), c.Path())
+
+		return err
+	}
+}
+
+// redactor decide quais chaves são mascaradas: nomes exatos ou padrões com "*"
+type redactor struct {
+	exact    map[string]bool
+	contains []string
+	prefix   []string
+	suffix   []string
+}
+
+func newRedactor(fields []string) *redactor {
+	r := &redactor{exact: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		leading, trailing := strings.HasPrefix(field, "*"), strings.HasSuffix(field, "*")
+		pattern := strings.Trim(field, "*")
+		switch {
+		case pattern == "":
+			continue
+		case leading && trailing:
+			r.contains = append(r.contains, pattern)
+		case leading:
+			r.suffix = append(r.suffix, pattern)
+		case trailing:
+			r.prefix = append(r.prefix, pattern)
+		default:
+			r.exact[pattern] = true
+		}
+	}
+	return r
+}
+
+// matches verifica se a chave (campo ou header) deve ser mascarada
+func (r *redactor) matches(key string) bool {
+	key = strings.ToLower(key)
+	if r.exact[key] {
+		return true
+	}
+	for _, pattern := range r.contains {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range r.prefix {
+		if strings.HasPrefix(key, pattern) {
+			return true
+		}
+	}
+	for _, pattern := range r.suffix {
+		if strings.HasSuffix(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeader(redact *redactor, key, value string) string {
+	if redact.matches(key) {
+		return redactedValue
+	}
+	return value
+}
+
+// redactPayload retorna o corpo com os campos sensíveis mascarados. JSON e
+// formulários são redigidos campo a campo; outros formatos (ou JSON inválido)
+// não são logados, já que não dá para garantir que não contenham segredos.
+func redactPayload(redact *redactor, contentType string, body []byte, maxBytes int) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var out string
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch mediaType = strings.TrimSpace(mediaType); {
+	case mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return fmt.Sprintf("[%d bytes, invalid JSON omitted]", len(body))
+		}
+		data, err := json.Marshal(redactValue(redact, doc))
+		if err != nil {
+			return fmt.Sprintf("[%d bytes omitted]", len(body))
+		}
+		out = string(data)
+	case mediaType == fiber.MIMEApplicationForm:
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return fmt.Sprintf("[%d bytes, invalid form omitted]", len(body))
+		}
+		for key := range values {
+			if redact.matches(key) {
+				values[key] = []string{redactedValue}
+			}
+		}
+		out = values.Encode()
+	default:
+		return fmt.Sprintf("[%d bytes of %q omitted]", len(body), mediaType)
+	}
+
+	if len(out) > maxBytes {
+		out = out[:maxBytes] + fmt.Sprintf("...[truncated, %d bytes]", len(out))
+	}
+	return out
+}
+
+// redactValue mascara recursivamente as chaves sensíveis de objetos e listas JSON
+func redactValue(redact *redactor, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if redact.matches(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(redact, inner)
+			}
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(redact, inner)
+		}
+	}
+	return value
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactorMatchesDefaultFields(t *testing.T) {
+	redact := newRedactor(append(append([]string{}, DefaultRedactFields...), "*_pin", "card*"))
+
+	for _, key := range []string{
+		"password", "current_password", "new_password", "password_hash",
+		"token", "access_token", "refresh_token", "captcha_token", "X-Captcha-Token",
+		"webhook_secret", "client_secret", "api_key", "Authorization", "Set-Cookie",
+		"atm_pin", "card_number",
+	} {
+		if !redact.matches(key) {
+			t.Errorf("%s is not redacted", key)
+		}
+	}
+	for _, key := range []string{"email", "name", "pinned", "discard", "X-Request-ID", "Content-Type"} {
+		if redact.matches(key) {
+			t.Errorf("%s is redacted", key)
+		}
+	}
+}
+
+func TestRedactPayloadMasksNestedFields(t *testing.T) {
+	redact := newRedactor(DefaultRedactFields)
+	body := `{"email":"a@acme.com","current_password":"old","new_password":"new","settings":{"webhook_secret":"s3cret"},"items":[{"captcha_token":"x"}]}`
+
+	out := redactPayload(redact, "application/json", []byte(body), defaultPayloadLogMaxBytes)
+	for _, secret := range []string{`"old"`, `"new"`, "s3cret", `"x"`} {
+		if strings.Contains(out, secret) {
+			t.Errorf("payload leaks %s: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "a@acme.com") {
+		t.Errorf("payload lost a non-sensitive field: %s", out)
+	}
+}
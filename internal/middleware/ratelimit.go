@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"context"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
 	"github.com/arcaintelligence/arca-gateway/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -13,18 +17,49 @@ import (
 type RateLimiter struct {
 	mu              sync.RWMutex
 	requests        map[string]*slidingWindow
-	limit           int
+	limit           atomic.Int64
+	anonymousLimit  atomic.Int64
 	windowSize      time.Duration
 	cleanupInterval time.Duration
 	stopCleanup     chan struct{}
+	cleanupDone     chan struct{}
+	stopOnce        sync.Once
+	store           RateLimitStore
+	storeWarnedAt   atomic.Int64
 }
 
-// slidingWindow representa uma janela deslizante para rate limiting
+// RateLimitStore janelas de rate limiting compartilhadas entre instâncias (ex: Redis).
+// Allow registra a request se a chave tiver menos de limit requests na janela e
+// retorna se foi permitida, quantas restam e o tempo até liberar a próxima.
+// Inspect retorna quantas requests estão na janela e o tempo até liberar a mais
+// antiga, sem registrar nada; Reset apaga a janela da chave.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error)
+	Inspect(ctx context.Context, key string, window time.Duration) (int, time.Duration, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// slidingWindow representa uma janela deslizante para rate limiting. Com um store
+// compartilhado a janela local só guarda o último limite aplicado à chave.
 type slidingWindow struct {
 	timestamps []time.Time
+	limit      int
+	lastSeen   time.Time
 	mu         sync.Mutex
 }
 
+// RateLimitState estado da janela de uma chave
+type RateLimitState struct {
+	Key string `json:"key"`
+	// Requests registradas na janela atual
+	Count int `json:"count"`
+	// Último limite aplicado à chave nesta instância (ou o limite padrão)
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+	// Quando a request mais antiga sai da janela, liberando uma vaga; nil com a janela vazia
+	ResetAt *time.Time `json:"reset_at,omitempty"`
+}
+
 // RateLimitConfig configuração do rate limiter
 type RateLimitConfig struct {
 	// Limite de requests por janela
@@ -37,6 +72,24 @@ type RateLimitConfig struct {
 	KeyExtractor func(*fiber.Ctx) string
 	// Limites customizados por tenant/plano
 	CustomLimits map[string]int
+	// Limites padrão por plano (plan do token -> req/janela)
+	PlanLimits map[string]int
+	// Limites customizados por tenant persistidos no banco (têm precedência sobre os demais)
+	TenantLimits *TenantLimitCache
+	// Limite para requests anônimas (sem claims); 0 usa Limit
+	AnonymousLimit int
+	// Store compartilhado das janelas; nil mantém as janelas em memória (instância única)
+	Store RateLimitStore
+}
+
+// DefaultPlanLimits retorna os limites padrão por plano (req/min)
+func DefaultPlanLimits() map[string]int {
+	return map[string]int{
+		"free":       100,   // 100 req/min
+		"starter":    500,   // 500 req/min
+		"pro":        2000,  // 2000 req/min
+		"enterprise": 10000, // 10000 req/min
+	}
 }
 
 // NewRateLimiter cria um novo rate limiter
@@ -53,11 +106,14 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 
 	rl := &RateLimiter{
 		requests:        make(map[string]*slidingWindow),
-		limit:           config.Limit,
 		windowSize:      config.WindowSize,
 		cleanupInterval: config.CleanupInterval,
 		stopCleanup:     make(chan struct{}),
+		cleanupDone:     make(chan struct{}),
+		store:           config.Store,
 	}
+	rl.limit.Store(int64(config.Limit))
+	rl.anonymousLimit.Store(int64(config.AnonymousLimit))
 
 	// Iniciar goroutine de limpeza
 	go rl.cleanup()
@@ -67,6 +123,8 @@ func NewRateLimiter(config RateLimitConfig) *RateLimiter {
 
 // cleanup remove entradas antigas periodicamente
 func (rl *RateLimiter) cleanup() {
+	defer close(rl.cleanupDone)
+
 	ticker := time.NewTicker(rl.cleanupInterval)
 	defer ticker.Stop()
 
@@ -87,8 +145,8 @@ func (rl *RateLimiter) cleanup() {
 				}
 				window.timestamps = newTimestamps
 				
-				// Remover janela vazia
-				if len(window.timestamps) == 0 {
+				// Remover janela vazia (com store, a que não recebe requests há uma janela)
+				if len(window.timestamps) == 0 && window.lastSeen.Before(cutoff) {
 					delete(rl.requests, key)
 				}
 				window.mu.Unlock()
@@ -100,9 +158,38 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
-// Stop para o rate limiter
+// Stop para o rate limiter e aguarda o término da goroutine de limpeza.
+// Pode ser chamado mais de uma vez.
 func (rl *RateLimiter) Stop() {
-	close(rl.stopCleanup)
+	rl.stopOnce.Do(func() {
+		close(rl.stopCleanup)
+	})
+	<-rl.cleanupDone
+}
+
+// Limit retorna o limite padrão de requests por janela
+func (rl *RateLimiter) Limit() int {
+	return int(rl.limit.Load())
+}
+
+// SetLimit altera o limite padrão em tempo de execução (ex: reload via SIGHUP).
+// As janelas em andamento são mantidas.
+func (rl *RateLimiter) SetLimit(limit int) {
+	if limit > 0 {
+		rl.limit.Store(int64(limit))
+	}
+}
+
+// AnonymousLimit retorna o limite para requests anônimas (0 = usar Limit)
+func (rl *RateLimiter) AnonymousLimit() int {
+	return int(rl.anonymousLimit.Load())
+}
+
+// SetAnonymousLimit altera o limite para requests anônimas em tempo de execução
+func (rl *RateLimiter) SetAnonymousLimit(limit int) {
+	if limit >= 0 {
+		rl.anonymousLimit.Store(int64(limit))
+	}
 }
 
 // Allow verifica se uma request é permitida
@@ -110,20 +197,27 @@ func (rl *RateLimiter) Allow(key string, customLimit int) (bool, int, time.Durat
 	now := time.Now()
 	cutoff := now.Add(-rl.windowSize)
 	
-	limit := rl.limit
+	limit := rl.Limit()
 	if customLimit > 0 {
 		limit = customLimit
 	}
 
-	rl.mu.Lock()
-	window, exists := rl.requests[key]
-	if !exists {
-		window = &slidingWindow{
-			timestamps: make([]time.Time, 0),
+	window := rl.window(key)
+	window.mu.Lock()
+	window.limit, window.lastSeen = limit, now
+	window.mu.Unlock()
+
+	if rl.store != nil {
+		allowed, remaining, resetIn, err := rl.store.Allow(context.Background(), key, limit, rl.windowSize)
+		if err == nil {
+			return allowed, remaining, resetIn
+		}
+		// Store indisponível: a janela local da instância assume até ele voltar
+		// (aviso no máximo uma vez por janela para não inundar o log)
+		if last := rl.storeWarnedAt.Load(); now.UnixNano()-last >= int64(rl.windowSize) && rl.storeWarnedAt.CompareAndSwap(last, now.UnixNano()) {
+			logger.Warn("rate limit store unavailable, using local windows: %v", err)
 		}
-		rl.requests[key] = window
 	}
-	rl.mu.Unlock()
 
 	window.mu.Lock()
 	defer window.mu.Unlock()
@@ -155,10 +249,95 @@ func (rl *RateLimiter) Allow(key string, customLimit int) (bool, int, time.Durat
 	return true, remaining - 1, 0
 }
 
-// RateLimitMiddleware cria um middleware de rate limiting
-func RateLimitMiddleware(config RateLimitConfig) fiber.Handler {
+// window retorna a janela local da chave, criando-a se necessário
+func (rl *RateLimiter) window(key string) *slidingWindow {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	window, exists := rl.requests[key]
+	if !exists {
+		window = &slidingWindow{
+			timestamps: make([]time.Time, 0),
+		}
+		rl.requests[key] = window
+	}
+	return window
+}
+
+// Inspect retorna o estado da janela da chave sem registrar uma request. Com store
+// a contagem vem dele (todas as instâncias); o limite é o último aplicado à chave
+// nesta instância, já que ele é resolvido por request (plano, tenant), ou o padrão.
+func (rl *RateLimiter) Inspect(ctx context.Context, key string) (RateLimitState, error) {
+	now := time.Now()
+	state := RateLimitState{Key: key, Limit: rl.Limit()}
+
+	rl.mu.RLock()
+	window, exists := rl.requests[key]
+	rl.mu.RUnlock()
+
+	var resetIn time.Duration
+	if exists {
+		window.mu.Lock()
+		if window.limit > 0 {
+			state.Limit = window.limit
+		}
+		if rl.store == nil {
+			cutoff := now.Add(-rl.windowSize)
+			for _, ts := range window.timestamps {
+				if ts.After(cutoff) {
+					if state.Count == 0 {
+						resetIn = ts.Add(rl.windowSize).Sub(now)
+					}
+					state.Count++
+				}
+			}
+		}
+		window.mu.Unlock()
+	}
+
+	if rl.store != nil {
+		count, storeResetIn, err := rl.store.Inspect(ctx, key, rl.windowSize)
+		if err != nil {
+			return RateLimitState{}, err
+		}
+		state.Count, resetIn = count, storeResetIn
+	}
+
+	state.Remaining = max(state.Limit-state.Count, 0)
+	if state.Count > 0 {
+		resetAt := now.Add(resetIn)
+		state.ResetAt = &resetAt
+	}
+	return state, nil
+}
+
+// Reset apaga a janela da chave, liberando imediatamente o limite
+func (rl *RateLimiter) Reset(ctx context.Context, key string) error {
+	rl.mu.Lock()
+	delete(rl.requests, key)
+	rl.mu.Unlock()
+
+	if rl.store != nil {
+		return rl.store.Reset(ctx, key)
+	}
+	return nil
+}
+
+// RateLimitMiddleware cria um middleware de rate limiting e o limiter que ele usa.
+// O limiter deve ser parado com Stop no shutdown do servidor.
+func RateLimitMiddleware(config RateLimitConfig) (fiber.Handler, *RateLimiter) {
+	if config.Limit == 0 {
+		config.Limit = 1000
+	}
 	limiter := NewRateLimiter(config)
 
+	return RateLimitHandler(limiter, config), limiter
+}
+
+// RateLimitHandler cria um middleware de rate limiting a partir de um limiter existente.
+// Os limites padrão e anônimo são lidos do limiter a cada request (SetLimit e
+// SetAnonymousLimit); config.Limit e config.AnonymousLimit são ignorados aqui.
+func RateLimitHandler(limiter *RateLimiter, config RateLimitConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Extrair chave de identificação
 		var key string
@@ -170,29 +349,41 @@ func RateLimitMiddleware(config RateLimitConfig) fiber.Handler {
 			if tenantID != uuid.Nil {
 				key = "tenant:" + tenantID.String()
 			} else {
-				key = "ip:" + c.IP()
+				key = "ip:" + ClientIP(c)
 			}
 		}
 
-		// Verificar limite customizado
-		customLimit := 0
-		if config.CustomLimits != nil {
-			if limit, ok := config.CustomLimits[key]; ok {
-				customLimit = limit
+		// Resolver limite efetivo: banco (tenant) > customizado (chave) > plano > default (ou anônimo)
+		limit := limiter.Limit()
+		if claims := GetClaims(c); claims != nil {
+			if planLimit, ok := config.PlanLimits[claims.Plan]; ok {
+				limit = planLimit
+			}
+		} else if anonymousLimit := limiter.AnonymousLimit(); anonymousLimit > 0 {
+			limit = anonymousLimit
+		}
+		if customLimit, ok := config.CustomLimits[key]; ok {
+			limit = customLimit
+		}
+		if config.TenantLimits != nil {
+			if tenantID := GetTenantID(c); tenantID != uuid.Nil {
+				if tenantLimit := config.TenantLimits.Limit(c.Context(), tenantID); tenantLimit > 0 {
+					limit = tenantLimit
+				}
 			}
 		}
 
 		// Verificar rate limit
-		allowed, remaining, resetIn := limiter.Allow(key, customLimit)
+		allowed, remaining, resetIn := limiter.Allow(key, limit)
 
 		// Adicionar headers de rate limit
-		c.Set("X-RateLimit-Limit", string(rune(config.Limit)))
-		c.Set("X-RateLimit-Remaining", string(rune(remaining)))
-		
+		c.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
 		if !allowed {
 			c.Set("X-RateLimit-Reset", resetIn.String())
-			c.Set("Retry-After", string(rune(int(resetIn.Seconds()))))
-			return response.TooManyRequests(c, "Rate limit exceeded. Please try again later.")
+			c.Set("Retry-After", strconv.Itoa(int(resetIn.Seconds())+1))
+			return response.Fail(c, response.CodeRateLimitExceeded, "Rate limit exceeded. Please try again later.")
 		}
 
 		return c.Next()
@@ -200,14 +391,10 @@ func RateLimitMiddleware(config RateLimitConfig) fiber.Handler {
 }
 
 // TenantRateLimitMiddleware rate limiting por tenant com limites baseados no plano
-func TenantRateLimitMiddleware(baseLimits map[string]int) fiber.Handler {
+// e, se tenantLimits for informado, nos limites customizados persistidos por tenant
+func TenantRateLimitMiddleware(baseLimits map[string]int, tenantLimits *TenantLimitCache) (fiber.Handler, *RateLimiter) {
 	// Limites por plano
-	planLimits := map[string]int{
-		"free":       100,   // 100 req/min
-		"starter":    500,   // 500 req/min
-		"pro":        2000,  // 2000 req/min
-		"enterprise": 10000, // 10000 req/min
-	}
+	planLimits := DefaultPlanLimits()
 
 	// Merge com limites customizados
 	for k, v := range baseLimits {
@@ -215,22 +402,40 @@ func TenantRateLimitMiddleware(baseLimits map[string]int) fiber.Handler {
 	}
 
 	config := RateLimitConfig{
-		Limit:      1000,
-		WindowSize: time.Minute,
+		Limit:        1000,
+		WindowSize:   time.Minute,
+		PlanLimits:   planLimits,
+		TenantLimits: tenantLimits,
 		KeyExtractor: func(c *fiber.Ctx) string {
 			tenantID := GetTenantID(c)
 			if tenantID != uuid.Nil {
 				return "tenant:" + tenantID.String()
 			}
-			return "ip:" + c.IP()
+			return "ip:" + ClientIP(c)
 		},
 	}
 
 	return RateLimitMiddleware(config)
 }
 
+// ClaimsKeyExtractor chave de rate limit para rotas com OptionalAuth: requests com
+// token válido usam o tenant; anônimas usam o IP, em um espaço de chaves separado
+func ClaimsKeyExtractor(c *fiber.Ctx) string {
+	if claims := GetClaims(c); claims != nil && claims.TenantID != uuid.Nil {
+		return "tenant:" + claims.TenantID.String()
+	}
+	return AnonymousKeyExtractor(c)
+}
+
+// AnonymousKeyExtractor chave de rate limit por IP, no mesmo espaço das requests
+// anônimas de ClaimsKeyExtractor. Usada no login, onde um token válido não pode
+// trocar o limite por IP pelo limite (maior) do tenant.
+func AnonymousKeyExtractor(c *fiber.Ctx) string {
+	return "anon:" + ClientIP(c)
+}
+
 // EndpointRateLimitMiddleware rate limiting específico por endpoint
-func EndpointRateLimitMiddleware(limit int, window time.Duration) fiber.Handler {
+func EndpointRateLimitMiddleware(limit int, window time.Duration) (fiber.Handler, *RateLimiter) {
 	config := RateLimitConfig{
 		Limit:      limit,
 		WindowSize: window,
@@ -240,7 +445,7 @@ func EndpointRateLimitMiddleware(limit int, window time.Duration) fiber.Handler
 			if tenantID != uuid.Nil {
 				return "endpoint:" + tenantID.String() + ":" + endpoint
 			}
-			return "endpoint:" + c.IP() + ":" + endpoint
+			return "endpoint:" + ClientIP(c) + ":" + endpoint
 		},
 	}
 
@@ -263,3 +468,74 @@ func NewBurstRateLimiter(limit, burstSize int, window time.Duration) *BurstRateL
 		burstSize: burstSize,
 	}
 }
+
+// =============================================================================
+// TENANT CUSTOM LIMITS
+// =============================================================================
+
+// TenantLimitStore fonte dos limites customizados por tenant (0 = sem limite customizado)
+type TenantLimitStore interface {
+	GetRateLimit(ctx context.Context, tenantID uuid.UUID) (int, error)
+}
+
+// TenantLimitCache cache com TTL dos limites customizados por tenant, evitando
+// uma consulta ao banco por request. Alterações feitas por admin são aplicadas
+// imediatamente via Invalidate ou, em outras instâncias, após o TTL.
+type TenantLimitCache struct {
+	store TenantLimitStore
+	ttl   time.Duration
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]cachedTenantLimit
+}
+
+// cachedTenantLimit limite em cache e sua expiração
+type cachedTenantLimit struct {
+	limit     int
+	expiresAt time.Time
+}
+
+// NewTenantLimitCache cria um novo cache de limites por tenant
+func NewTenantLimitCache(store TenantLimitStore, ttl time.Duration) *TenantLimitCache {
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+
+	return &TenantLimitCache{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]cachedTenantLimit),
+	}
+}
+
+// Limit retorna o limite customizado do tenant (0 se não houver).
+// Em caso de erro no banco mantém o último valor conhecido.
+func (tc *TenantLimitCache) Limit(ctx context.Context, tenantID uuid.UUID) int {
+	now := time.Now()
+
+	tc.mu.RLock()
+	entry, ok := tc.entries[tenantID]
+	tc.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.limit
+	}
+
+	limit, err := tc.store.GetRateLimit(ctx, tenantID)
+	if err != nil {
+		logger.WithField("tenant_id", tenantID.String()).Warn("failed to load tenant rate limit: %v", err)
+		limit = entry.limit
+	}
+
+	tc.mu.Lock()
+	tc.entries[tenantID] = cachedTenantLimit{limit: limit, expiresAt: now.Add(tc.ttl)}
+	tc.mu.Unlock()
+
+	return limit
+}
+
+// Invalidate remove o limite do tenant do cache, forçando nova leitura do banco
+func (tc *TenantLimitCache) Invalidate(tenantID uuid.UUID) {
+	tc.mu.Lock()
+	delete(tc.entries, tenantID)
+	tc.mu.Unlock()
+}
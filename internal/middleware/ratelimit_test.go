@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// fakeTenantLimits limites customizados por tenant, como tenants.rate_limit_rpm
+type fakeTenantLimits map[uuid.UUID]int
+
+func (f fakeTenantLimits) GetRateLimit(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	return f[tenantID], nil
+}
+
+// withClaims autentica a request de teste com um tenant do plano informado
+func withClaims(tenantID uuid.UUID, plan string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(ContextKeyClaims, &auth.Claims{TenantID: tenantID, Plan: plan})
+		c.Locals(ContextKeyTenantID, tenantID)
+		return c.Next()
+	}
+}
+
+func TestTenantRateLimitDBOverridesPlanLimit(t *testing.T) {
+	custom := uuid.New()
+	planOnly := uuid.New()
+	limits := NewTenantLimitCache(fakeTenantLimits{custom: 5000}, time.Minute)
+
+	handler, limiter := TenantRateLimitMiddleware(nil, limits)
+	defer limiter.Stop()
+
+	for _, tc := range []struct {
+		name     string
+		tenantID uuid.UUID
+		want     int
+	}{
+		{"custom limit in the database", custom, 5000},
+		{"plan default", planOnly, DefaultPlanLimits()["free"]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", withClaims(tc.tenantID, "free"), handler, func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if got := resp.Header.Get("X-RateLimit-Limit"); got != strconv.Itoa(tc.want) {
+				t.Errorf("X-RateLimit-Limit = %s, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+// Nas rotas públicas (OptionalAuth + ClaimsKeyExtractor) requests anônimas têm o
+// limite por IP e as autenticadas o do tenant; o login fica sempre no limite por IP
+func TestPublicRateLimitAnonymousVsAuthenticated(t *testing.T) {
+	jwtManager := auth.NewJWTManager("ratelimit-test-secret-with-32-bytes!", time.Minute, time.Hour, "arca-gateway", "arca-gateway")
+	token, err := jwtManager.GenerateAccessToken(&models.User{ID: uuid.New(), TenantID: uuid.New(), Role: models.RoleViewer})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := RateLimitConfig{Limit: 5, AnonymousLimit: 2}
+	limiter := NewRateLimiter(config)
+	defer limiter.Stop()
+	public, login := config, config
+	public.KeyExtractor = ClaimsKeyExtractor
+	login.KeyExtractor = AnonymousKeyExtractor
+
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app := fiber.New()
+	app.Post("/register", NewAuthMiddleware(jwtManager).OptionalAuth(), RateLimitHandler(limiter, public), ok)
+	app.Post("/login", RateLimitHandler(limiter, login), ok)
+
+	// allowed conta quantas de n requests passam
+	allowed := func(target, token string, n int) int {
+		t.Helper()
+		passed := 0
+		for i := 0; i < n; i++ {
+			req := httptest.NewRequest(fiber.MethodPost, target, nil)
+			if token != "" {
+				req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode == fiber.StatusOK {
+				passed++
+			}
+		}
+		return passed
+	}
+
+	if got := allowed("/register", "", 4); got != 2 {
+		t.Errorf("anonymous requests allowed = %d, want 2", got)
+	}
+	// O mesmo IP autenticado usa o limite do tenant, não o anônimo já esgotado
+	if got := allowed("/register", token, 6); got != 5 {
+		t.Errorf("authenticated requests allowed = %d, want 5", got)
+	}
+	// Um token válido não libera o login, que compartilha a janela anônima do IP
+	if got := allowed("/login", token, 1); got != 0 {
+		t.Errorf("login with a token allowed = %d, want 0", got)
+	}
+}
+
+func TestRateLimiterStopTerminatesCleanup(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{Limit: 10, CleanupInterval: time.Millisecond})
+	limiter.Allow("tenant:a", 10)
+
+	stopped := make(chan struct{})
+	go func() {
+		limiter.Stop()
+		// Uma segunda chamada (ex.: shutdown após um erro) não bloqueia nem causa panic
+		limiter.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	// A goroutine de limpeza terminou: cleanupDone está fechado
+	select {
+	case <-limiter.cleanupDone:
+	default:
+		t.Error("cleanup goroutine still running after Stop")
+	}
+}
@@ -21,3 +21,13 @@ const (
 	ScopeAdminRead    = models.ScopeAdminRead
 	ScopeAdminWrite   = models.ScopeAdminWrite
 )
+
+// IsKnownScope verifica se o scope é um dos definidos em models
+func IsKnownScope(scope models.Scope) bool {
+	for _, known := range models.GetDefaultScopesForRole(models.RoleAdmin) {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
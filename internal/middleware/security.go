@@ -1,12 +1,20 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/google/uuid"
 )
@@ -19,18 +27,30 @@ type SecurityConfig struct {
 	AllowCredentials bool
 	MaxAge           int
 	Environment      string
+	// Resolução do IP do cliente atrás de proxies
+	ClientIP ClientIPConfig
+	// Amostragem de logs por rota (template da rota -> loga 1 a cada N)
+	LogSampleRates map[string]int
+	// Logger estruturado usado no log de requests
+	Logger *logger.Logger
+	// Fontes que podem enviar headers de contexto (X-Tenant-ID)
+	TrustedHeaders TrustedHeadersConfig
 }
 
 // SetupSecurityMiddlewares configura todos os middlewares de segurança
 func SetupSecurityMiddlewares(app *fiber.App, config SecurityConfig) {
-	// Recover - recupera de panics
-	app.Use(recover.New(recover.Config{
-		EnableStackTrace: config.Environment != "production",
-	}))
+	// Recover - recupera de panics, loga com stack via logger estruturado e responde 500
+	app.Use(RecoverMiddleware())
+
+	// IP do cliente - resolvido antes do logging e do rate limiting
+	app.Use(ClientIPMiddleware(config.ClientIP))
+
+	// Headers de contexto forjados - removidos antes de qualquer leitura
+	app.Use(TrustedHeadersMiddleware(config.TrustedHeaders))
 
 	// Request ID - gera ID único para cada request
 	app.Use(requestid.New(requestid.Config{
-		Header: "X-Request-ID",
+		Header: HeaderRequestID,
 		Generator: func() string {
 			return uuid.New().String()
 		},
@@ -49,19 +69,16 @@ func SetupSecurityMiddlewares(app *fiber.App, config SecurityConfig) {
 	}))
 
 	// CORS
-	app.Use(cors.New(cors.Config{
-		AllowOrigins:     joinStrings(config.AllowOrigins),
-		AllowMethods:     joinStrings(config.AllowMethods),
-		AllowHeaders:     joinStrings(config.AllowHeaders),
-		AllowCredentials: config.AllowCredentials,
-		MaxAge:           config.MaxAge,
-	}))
+	app.Use(cors.New(corsConfig(config)))
 
 	// Custom security headers
 	app.Use(CustomSecurityHeaders())
 
 	// Request logging
-	app.Use(RequestLogger())
+	app.Use(RequestLogger(RequestLoggerConfig{
+		Logger:      config.Logger,
+		SampleRates: config.LogSampleRates,
+	}))
 
 	// Timeout
 	app.Use(TimeoutMiddleware(30 * time.Second))
@@ -88,20 +105,132 @@ func CustomSecurityHeaders() fiber.Handler {
 	}
 }
 
+// RecoverMiddleware recupera panics dos handlers seguintes. O panic é logado com o
+// stack trace completo (sempre, em qualquer ambiente) junto com request_id, tenant e
+// rota, conta em arca_panics_total e vira o envelope 500 padrão, sem nada do panic
+// na resposta.
+func RecoverMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				route := unmatchedRoute
+				if current := c.Route(); current != nil && current.Path != "" {
+					route = current.Path
+				}
+				panicsTotal.WithLabelValues(route).Inc()
+				logPanic(c, r)
+				err = response.InternalServerError(c, "Internal server error")
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// logPanic loga um panic recuperado com o stack trace completo do ponto do panic
+func logPanic(c *fiber.Ctx, e interface{}) {
+	logger.FromContext(c).
+		WithError(fmt.Errorf("panic: %v", e)).
+		WithFields(map[string]interface{}{
+			"method": c.Method(),
+			"path":   c.Path(),
+			"stack":  string(debug.Stack()),
+		}).
+		Error("panic recovered")
+}
+
+// RequestLoggerConfig configuração do logging de requests
+type RequestLoggerConfig struct {
+	// Logger estruturado (default: logger.Default())
+	Logger *logger.Logger
+	// Rotas de alto volume (template da rota, ex: "/health") e taxa de amostragem:
+	// com N=100 apenas 1 a cada 100 requests bem-sucedidas é logada.
+	// Erros (status >= 400) são sempre logados.
+	SampleRates map[string]int
+}
+
+// logSampler decide se uma request deve ser logada de acordo com a taxa da rota
+type logSampler struct {
+	rates    map[string]int
+	counters sync.Map // rota -> *uint64
+}
+
+// newLogSampler cria um novo sampler
+func newLogSampler(rates map[string]int) *logSampler {
+	return &logSampler{rates: rates}
+}
+
+// shouldLog retorna true para a primeira request de cada janela de N requests da rota
+func (s *logSampler) shouldLog(route string, status int) bool {
+	if status >= fiber.StatusBadRequest {
+		return true
+	}
+
+	rate, ok := s.rates[route]
+	if !ok || rate <= 1 {
+		return true
+	}
+
+	counter, _ := s.counters.LoadOrStore(route, new(uint64))
+	n := atomic.AddUint64(counter.(*uint64), 1)
+	return (n-1)%uint64(rate) == 0
+}
+
 // RequestLogger middleware de logging de requests
-func RequestLogger() fiber.Handler {
+func RequestLogger(config RequestLoggerConfig) fiber.Handler {
+	if config.Logger == nil {
+		config.Logger = logger.Default()
+	}
+	sampler := newLogSampler(config.SampleRates)
+
 	return func(c *fiber.Ctx) error {
 		start := time.Now()
+
+		// Disponibilizar o logger para logger.FromContext nos handlers
+		logger.ToContext(c, config.Logger)
 		
 		// Processar request
 		err := c.Next()
 		
 		// Calcular duração
 		duration := time.Since(start)
-		
-		// Log estruturado (pode ser enviado para observabilidade)
-		// Em produção, usar logger estruturado (zerolog, zap)
 		c.Set("X-Response-Time", duration.String())
+
+		// O error handler global ainda não rodou: derivar o status do erro
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		route := c.Path()
+		if r := c.Route(); r != nil && r.Path != "" {
+			route = r.Path
+		}
+
+		if !sampler.shouldLog(route, status) {
+			return err
+		}
+
+		// request_id, tenant_id, user_id e route vêm do contexto da request
+		entry := logger.FromContext(c).WithFields(map[string]interface{}{
+			"method":      c.Method(),
+			"path":        c.Path(),
+			"route":       route,
+			"status":      status,
+			"duration_ms": duration.Milliseconds(),
+			"ip":          ClientIP(c),
+		})
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			entry.Error("%s %s %d", c.Method(), c.Path(), status)
+		case status >= fiber.StatusBadRequest:
+			entry.Warn("%s %s %d", c.Method(), c.Path(), status)
+		default:
+			entry.Info("%s %s %d", c.Method(), c.Path(), status)
+		}
 		
 		return err
 	}
@@ -116,42 +245,14 @@ func TimeoutMiddleware(timeout time.Duration) fiber.Handler {
 	}
 }
 
-// IPWhitelistMiddleware permite apenas IPs específicos
-func IPWhitelistMiddleware(allowedIPs []string) fiber.Handler {
-	ipSet := make(map[string]bool)
-	for _, ip := range allowedIPs {
-		ipSet[ip] = true
-	}
-
+// BodyLimit rejeita com 413 requests cujo corpo exceda maxBytes. Restringe rotas
+// específicas abaixo do limite global (fiber.Config.BodyLimit), que o fasthttp
+// aplica antes de qualquer handler.
+func BodyLimit(maxBytes int) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		clientIP := c.IP()
-		
-		if !ipSet[clientIP] {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "IP not allowed",
-			})
+		if c.Request().Header.ContentLength() > maxBytes || len(c.Body()) > maxBytes {
+			return response.PayloadTooLarge(c, fmt.Sprintf("Request body exceeds %d bytes", maxBytes))
 		}
-		
-		return c.Next()
-	}
-}
-
-// IPBlacklistMiddleware bloqueia IPs específicos
-func IPBlacklistMiddleware(blockedIPs []string) fiber.Handler {
-	ipSet := make(map[string]bool)
-	for _, ip := range blockedIPs {
-		ipSet[ip] = true
-	}
-
-	return func(c *fiber.Ctx) error {
-		clientIP := c.IP()
-		
-		if ipSet[clientIP] {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error": "IP blocked",
-			})
-		}
-		
 		return c.Next()
 	}
 }
@@ -186,55 +287,79 @@ func TenantIsolationMiddleware() fiber.Handler {
 	}
 }
 
-// AuditMiddleware registra ações para auditoria
-func AuditMiddleware() fiber.Handler {
+// SanitizeInputMiddleware sanitiza inputs para prevenir injection
+func SanitizeInputMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// Capturar informações antes da request
-		startTime := time.Now()
-		requestID := c.Get("X-Request-ID")
-		
-		// Processar request
-		err := c.Next()
-		
-		// Capturar informações após a request
-		duration := time.Since(startTime)
-		statusCode := c.Response().StatusCode()
-		
-		// Criar log de auditoria
-		auditLog := map[string]interface{}{
-			"request_id":  requestID,
-			"timestamp":   startTime.UTC().Format(time.RFC3339),
-			"method":      c.Method(),
-			"path":        c.Path(),
-			"status_code": statusCode,
-			"duration_ms": duration.Milliseconds(),
-			"ip":          c.IP(),
-			"user_agent":  c.Get("User-Agent"),
-		}
+		// Sanitização básica - em produção usar biblioteca especializada
+		// Os handlers devem fazer validação adicional
+		return c.Next()
+	}
+}
 
-		// Adicionar informações do usuário se autenticado
-		claims := GetClaims(c)
-		if claims != nil {
-			auditLog["user_id"] = claims.UserID.String()
-			auditLog["tenant_id"] = claims.TenantID.String()
-			auditLog["role"] = string(claims.Role)
+// Prefixo de origem CORS tratada como expressão regular (ex: "regex:^https://pr-[0-9]+\.arca\.intelligence$")
+const corsOriginRegexPrefix = "regex:"
+
+// corsConfig monta a configuração do CORS. Origens exatas e curingas de subdomínio
+// ("https://*.preview.arca.intelligence") usam o matching nativo; com origens regex
+// toda a verificação passa para AllowOriginsFunc.
+func corsConfig(config SecurityConfig) cors.Config {
+	cfg := cors.Config{
+		AllowMethods:     joinStrings(config.AllowMethods),
+		AllowHeaders:     joinStrings(config.AllowHeaders),
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
+	}
+
+	var origins []string
+	var patterns []*regexp.Regexp
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			// Fora de produção (Config.Validate barra em produção) o curinga desliga credenciais
+			if config.AllowCredentials {
+				logger.Warn("CORS wildcard origin configured; disabling credentials")
+				cfg.AllowCredentials = false
+			}
+			cfg.AllowOrigins = "*"
+			return cfg
+		}
+		if pattern, ok := strings.CutPrefix(origin, corsOriginRegexPrefix); ok {
+			// Padrões já validados na inicialização
+			patterns = append(patterns, regexp.MustCompile(pattern))
+			continue
 		}
+		origins = append(origins, strings.ToLower(strings.TrimSuffix(origin, "/")))
+	}
 
-		// Em produção, enviar para sistema de auditoria
-		// logger.Info().Fields(auditLog).Msg("audit")
-		_ = auditLog
+	if len(patterns) == 0 {
+		cfg.AllowOrigins = joinStrings(origins)
+		return cfg
+	}
 
-		return err
+	cfg.AllowOriginsFunc = func(origin string) bool {
+		origin = strings.ToLower(origin)
+		for _, allowed := range origins {
+			if matchCORSOrigin(allowed, origin) {
+				return true
+			}
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+		return false
 	}
+	return cfg
 }
 
-// SanitizeInputMiddleware sanitiza inputs para prevenir injection
-func SanitizeInputMiddleware() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Sanitização básica - em produção usar biblioteca especializada
-		// Os handlers devem fazer validação adicional
-		return c.Next()
+// matchCORSOrigin compara a origem com uma origem exata ou curinga de subdomínio
+func matchCORSOrigin(allowed, origin string) bool {
+	scheme, suffix, ok := strings.Cut(allowed, "://*.")
+	if !ok {
+		return allowed == origin
 	}
+	host, ok := strings.CutPrefix(origin, scheme+"://")
+	return ok && strings.HasSuffix(host, "."+suffix) && len(host) > len(suffix)+1
 }
 
 // Helper functions
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// logEntries decodifica as entradas JSON escritas pelo logger
+func logEntries(t *testing.T, buf *bytes.Buffer) []logger.Entry {
+	t.Helper()
+	var entries []logger.Entry
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry logger.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestRequestLoggerSamplesSuccessOnHighVolumeRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	app := fiber.New()
+	app.Use(RequestLogger(RequestLoggerConfig{
+		Logger:      logger.New(logger.Config{Level: logger.DebugLevel, Output: &buf}),
+		SampleRates: map[string]int{"/health": 10},
+	}))
+	app.Get("/health", func(c *fiber.Ctx) error {
+		if c.Query("fail") != "" {
+			return c.SendStatus(fiber.StatusServiceUnavailable)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/v1/clients", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	request := func(target string, n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	request("/health", 25)
+	request("/health?fail=1", 3)
+	request("/v1/clients", 4)
+
+	counts := make(map[string]int)
+	for _, entry := range logEntries(t, &buf) {
+		status, _ := entry.Fields["status"].(float64)
+		route, _ := entry.Fields["route"].(string)
+		switch {
+		case route == "/health" && status == fiber.StatusOK:
+			counts["health ok"]++
+		case route == "/health" && status == fiber.StatusServiceUnavailable:
+			counts["health failed"]++
+			if entry.Level != logger.ErrorLevel.String() {
+				t.Errorf("503 logged at level %s, want error", entry.Level)
+			}
+		case route == "/v1/clients":
+			counts["clients"]++
+		}
+	}
+
+	// 25 requests com taxa 10: a 1ª, a 11ª e a 21ª
+	if counts["health ok"] != 3 {
+		t.Errorf("sampled /health 200 entries = %d, want 3", counts["health ok"])
+	}
+	if counts["health failed"] != 3 {
+		t.Errorf("/health 503 entries = %d, want all 3", counts["health failed"])
+	}
+	if counts["clients"] != 4 {
+		t.Errorf("unsampled route entries = %d, want 4", counts["clients"])
+	}
+}
+
+// BodyLimit por rota responde 413 no envelope padrão, abaixo do limite global
+func TestBodyLimitRejectsOversizedBody(t *testing.T) {
+	app := fiber.New()
+	app.Post("/auth/login", BodyLimit(64), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	post := func(body string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, "/auth/login", strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var envelope response.Response
+		json.NewDecoder(resp.Body).Decode(&envelope)
+		if envelope.Error == nil {
+			return resp.StatusCode, ""
+		}
+		return resp.StatusCode, envelope.Error.Code
+	}
+
+	if status, code := post(`{"email":"ana@acme.com"}`); status != fiber.StatusOK {
+		t.Errorf("small body: status = %d, code = %q, want 200", status, code)
+	}
+	if status, code := post(`{"email":"` + strings.Repeat("a", 100) + `@acme.com"}`); status != fiber.StatusRequestEntityTooLarge || code != response.CodePayloadTooLarge {
+		t.Errorf("oversized body: status = %d, code = %q, want 413 %s", status, code, response.CodePayloadTooLarge)
+	}
+}
+
+// Um panic conta em arca_panics_total pela rota e é logado com stack, request_id e
+// tenant; a resposta é o 500 padrão, sem o valor do panic nem o stack
+func TestRecoverMiddlewareCountsAndLogsPanics(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.Config{Level: logger.InfoLevel, Output: &buf})
+	tenantID := uuid.New()
+
+	app := fiber.New()
+	app.Use(RecoverMiddleware())
+	app.Use(func(c *fiber.Ctx) error {
+		logger.ToContext(c, log)
+		c.Locals(ContextKeyTenantID, tenantID)
+		return c.Next()
+	})
+	app.Get("/clients/:client_id", func(c *fiber.Ctx) error { panic("nil client settings") })
+	app.Get("/brands", func(c *fiber.Ctx) error { panic("nil brand config") })
+
+	clientPanics := promtest.ToFloat64(panicsTotal.WithLabelValues("/clients/:client_id"))
+	brandPanics := promtest.ToFloat64(panicsTotal.WithLabelValues("/brands"))
+
+	for _, target := range []string{"/clients/1", "/clients/2", "/brands"} {
+		req := httptest.NewRequest(fiber.MethodGet, target, nil)
+		req.Header.Set(HeaderRequestID, "req-"+target)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var envelope response.Response
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusInternalServerError || envelope.Error == nil || envelope.Error.Code != response.CodeInternalServerError {
+			t.Errorf("%s: status = %d, error = %+v", target, resp.StatusCode, envelope.Error)
+		} else if strings.Contains(envelope.Error.Message, "nil") || len(envelope.Error.Details) > 0 {
+			t.Errorf("%s: response leaks the panic: %+v", target, envelope.Error)
+		}
+	}
+
+	if got := promtest.ToFloat64(panicsTotal.WithLabelValues("/clients/:client_id")) - clientPanics; got != 2 {
+		t.Errorf("arca_panics_total{path=/clients/:client_id} increased by %v, want 2", got)
+	}
+	if got := promtest.ToFloat64(panicsTotal.WithLabelValues("/brands")) - brandPanics; got != 1 {
+		t.Errorf("arca_panics_total{path=/brands} increased by %v, want 1", got)
+	}
+
+	var logged int
+	for _, entry := range logEntries(t, &buf) {
+		if entry.Message != "panic recovered" {
+			continue
+		}
+		logged++
+		stack, _ := entry.Fields["stack"].(string)
+		if entry.Level != logger.ErrorLevel.String() || !strings.Contains(stack, "TestRecoverMiddlewareCountsAndLogsPanics") {
+			t.Errorf("panic entry level = %s, stack = %q", entry.Level, stack)
+		}
+		if entry.Fields["tenant_id"] != tenantID.String() || entry.Fields["request_id"] == nil || entry.Fields["route"] == nil {
+			t.Errorf("panic entry fields = %v", entry.Fields)
+		}
+		if errMsg, _ := entry.Fields["error"].(string); !strings.Contains(errMsg, "panic: nil") {
+			t.Errorf("panic entry error = %q", errMsg)
+		}
+	}
+	if logged != 3 {
+		t.Errorf("panic entries logged = %d, want 3", logged)
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// TENANT STATUS
+// =============================================================================
+
+// TenantStatusStore fonte do status dos tenants
+type TenantStatusStore interface {
+	GetStatus(ctx context.Context, tenantID uuid.UUID) (models.Status, error)
+}
+
+// TenantStatusCache cache com TTL do status dos tenants, evitando uma consulta ao
+// banco por request. Uma suspensão vale imediatamente na instância que a aplicou
+// (via Invalidate) e, nas demais, após o TTL.
+type TenantStatusCache struct {
+	store TenantStatusStore
+	ttl   time.Duration
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]cachedTenantStatus
+}
+
+// cachedTenantStatus status em cache e sua expiração
+type cachedTenantStatus struct {
+	status    models.Status
+	expiresAt time.Time
+}
+
+// NewTenantStatusCache cria um novo cache de status por tenant
+func NewTenantStatusCache(store TenantStatusStore, ttl time.Duration) *TenantStatusCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+
+	return &TenantStatusCache{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]cachedTenantStatus),
+	}
+}
+
+// Status retorna o status do tenant. Se o store falhar, usa o último valor
+// conhecido (mesmo expirado); sem valor conhecido, retorna o erro.
+func (tc *TenantStatusCache) Status(ctx context.Context, tenantID uuid.UUID) (models.Status, error) {
+	now := time.Now()
+
+	tc.mu.RLock()
+	entry, ok := tc.entries[tenantID]
+	tc.mu.RUnlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.status, nil
+	}
+
+	status, err := tc.store.GetStatus(ctx, tenantID)
+	if err != nil {
+		if ok {
+			return entry.status, nil
+		}
+		return "", err
+	}
+
+	tc.mu.Lock()
+	tc.entries[tenantID] = cachedTenantStatus{status: status, expiresAt: now.Add(tc.ttl)}
+	tc.mu.Unlock()
+
+	return status, nil
+}
+
+// Invalidate remove o status do tenant do cache, forçando nova leitura do banco
+func (tc *TenantStatusCache) Invalidate(tenantID uuid.UUID) {
+	tc.mu.Lock()
+	delete(tc.entries, tenantID)
+	tc.mu.Unlock()
+}
+
+// RequireActiveTenant middleware (após Authenticate) que rejeita requests de tenants
+// suspensos ou inativos: os tokens já emitidos continuam válidos até expirar, então
+// o status do tenant é verificado a cada request. Admins do tenant da plataforma
+// (platformTenantID; uuid.Nil desabilita) não são bloqueados, para poderem reativar
+// tenants mesmo que o próprio tenant seja suspenso por engano.
+func RequireActiveTenant(cache *TenantStatusCache, platformTenantID uuid.UUID) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := GetClaims(c)
+		if claims == nil {
+			return response.Unauthorized(c, "Authentication required")
+		}
+
+		if IsPlatformAdmin(claims.Role, claims.TenantID, platformTenantID) {
+			return c.Next()
+		}
+
+		status, err := cache.Status(c.Context(), claims.TenantID)
+		if err != nil {
+			logger.FromContext(c).WithField("tenant_id", claims.TenantID.String()).
+				Warn("failed to load tenant status: %v", err)
+			return response.ServiceUnavailable(c, "Failed to verify tenant status")
+		}
+		if status != models.StatusActive {
+			return response.Fail(c, response.CodeTenantSuspended, "Tenant suspended")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequirePlatformAdmin middleware que restringe a rota aos admins do tenant da
+// plataforma. O role admin sozinho não basta: todo tenant registrado tem um admin,
+// que não pode alterar plano, quotas ou status de outros tenants (nem do próprio).
+// Sem platformTenantID configurado a rota fica indisponível.
+func RequirePlatformAdmin(platformTenantID uuid.UUID) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := GetClaims(c)
+		if claims == nil {
+			return response.Unauthorized(c, "Authentication required")
+		}
+
+		if !IsPlatformAdmin(claims.Role, claims.TenantID, platformTenantID) {
+			return response.Fail(c, response.CodePlatformAdminRequired, "Platform administrator required")
+		}
+
+		return c.Next()
+	}
+}
+
+// IsPlatformAdmin verifica se o usuário é admin do tenant da plataforma
+func IsPlatformAdmin(role models.Role, tenantID, platformTenantID uuid.UUID) bool {
+	return platformTenantID != uuid.Nil && role == models.RoleAdmin && tenantID == platformTenantID
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ToolScopes scopes exigidos para chamar cada ferramenta do MCP. Actions (chave
+// "tool:action") tem precedência sobre Tools, permitindo exigir scopes diferentes
+// para ações de leitura e escrita da mesma ferramenta.
+type ToolScopes struct {
+	Tools   map[string]models.Scope
+	Actions map[string]models.Scope
+}
+
+// ToolResolver extrai da request a ferramenta e a ação do MCP que serão chamadas
+type ToolResolver func(c *fiber.Ctx) (tool, action string)
+
+// DefaultToolScopes retorna o mapeamento padrão de ferramentas e ações para scopes
+func DefaultToolScopes() ToolScopes {
+	return ToolScopes{
+		Tools: map[string]models.Scope{
+			"hunting":  models.ScopeHuntingWrite,
+			"scanner":  models.ScopeHuntingWrite,
+			"leaks":    models.ScopeHuntingRead,
+			"analyzer": models.ScopeAnalyzeWrite,
+			"monitor":  models.ScopeMonitorWrite,
+			"threats":  models.ScopeAlertsRead,
+		},
+		Actions: map[string]models.Scope{
+			"onboarding:create_brand":          models.ScopeBrandsWrite,
+			"onboarding:list_brands":           models.ScopeBrandsRead,
+			"onboarding:get_monitoring_status": models.ScopeBrandsRead,
+			"monitoring:start":                 models.ScopeMonitorWrite,
+			"monitoring:stop":                  models.ScopeMonitorWrite,
+			"monitoring:status":                models.ScopeMonitorRead,
+		},
+	}
+}
+
+// ParseToolScopes aplica overrides no formato "tool" ou "tool:action" -> scope sobre
+// o mapeamento padrão. Scopes desconhecidos são rejeitados.
+func ParseToolScopes(overrides map[string]string) (ToolScopes, error) {
+	scopes := DefaultToolScopes()
+
+	for key, value := range overrides {
+		scope := models.Scope(strings.TrimSpace(value))
+		if !IsKnownScope(scope) {
+			return ToolScopes{}, fmt.Errorf("unknown scope %q for MCP tool %q", value, key)
+		}
+
+		tool, action, hasAction := strings.Cut(strings.TrimSpace(key), ":")
+		tool, action = strings.TrimSpace(tool), strings.TrimSpace(action)
+		switch {
+		case tool == "" || (hasAction && action == ""):
+			return ToolScopes{}, fmt.Errorf("invalid MCP tool %q", key)
+		case hasAction:
+			scopes.Actions[tool+":"+action] = scope
+		default:
+			scopes.Tools[tool] = scope
+		}
+	}
+
+	return scopes, nil
+}
+
+// Required retorna o scope exigido para a ação da ferramenta; ok é false quando a
+// ferramenta não está mapeada
+func (s ToolScopes) Required(tool, action string) (scope models.Scope, ok bool) {
+	if scope, ok = s.Actions[tool+":"+action]; ok {
+		return scope, true
+	}
+	scope, ok = s.Tools[tool]
+	return scope, ok
+}
+
+// Require middleware que exige o scope de uma ferramenta/ação fixa (rotas tipadas)
+func (s ToolScopes) Require(tool, action string) fiber.Handler {
+	return s.RequireFor(func(*fiber.Ctx) (string, string) {
+		return tool, action
+	})
+}
+
+// RequireFor middleware que exige o scope da ferramenta/ação resolvida a partir da
+// request (proxy genérico). Ferramentas fora do mapeamento são negadas, inclusive
+// para admins, já que não há como saber o que elas fazem.
+func (s ToolScopes) RequireFor(resolve ToolResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims := GetClaims(c)
+		if claims == nil {
+			return response.Unauthorized(c, "Authentication required")
+		}
+
+		tool, action := resolve(c)
+		scope, ok := s.Required(tool, action)
+		if !ok {
+			logger.FromContext(c).WithFields(map[string]interface{}{
+				"tool":   tool,
+				"action": action,
+			}).Warn("access denied: MCP tool has no scope mapping")
+			return response.FailWithDetails(c, response.CodeMCPToolForbidden, "Tool not allowed: "+tool, map[string]string{
+				"tool": tool,
+			})
+		}
+
+		// Admin tem acesso a tudo
+		if claims.IsAdmin() {
+			return c.Next()
+		}
+
+		required := []models.Scope{scope}
+		if len(claims.Scopes) == 0 {
+			return denyNoScopes(c, required)
+		}
+		if !claims.HasAnyScope(required...) {
+			return denyMissingScopes(c, "Missing required scope: "+string(scope), required)
+		}
+
+		return c.Next()
+	}
+}
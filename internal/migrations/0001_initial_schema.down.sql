@@ -0,0 +1,10 @@
+-- Remove o schema inicial (ordem inversa das dependências)
+
+DROP TABLE IF EXISTS login_events;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS brands;
+DROP TABLE IF EXISTS clients;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,11 @@
+ALTER TABLE brands DROP COLUMN IF EXISTS last_scan_at;
+ALTER TABLE brands DROP COLUMN IF EXISTS monitoring_job_id;
+ALTER TABLE brands DROP COLUMN IF EXISTS config;
+ALTER TABLE brands DROP COLUMN IF EXISTS status;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS settings;
+ALTER TABLE clients DROP COLUMN IF EXISTS description;
+
+ALTER TABLE users DROP COLUMN IF EXISTS scopes;
+
+ALTER TABLE tenants DROP COLUMN IF EXISTS email;
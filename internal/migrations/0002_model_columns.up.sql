@@ -0,0 +1,13 @@
+-- Colunas presentes nos models que ainda não existiam no schema
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS description TEXT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS status VARCHAR(50) NOT NULL DEFAULT 'active';
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS config JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS monitoring_job_id UUID;
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS last_scan_at TIMESTAMP WITH TIME ZONE;
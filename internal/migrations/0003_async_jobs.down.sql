@@ -0,0 +1,5 @@
+DELETE FROM webhook_deliveries WHERE alert_id IS NULL;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS job_id;
+ALTER TABLE webhook_deliveries ALTER COLUMN alert_id SET NOT NULL;
+
+DROP TABLE IF EXISTS async_jobs;
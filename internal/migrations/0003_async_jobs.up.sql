@@ -0,0 +1,22 @@
+-- Jobs assíncronos do MCP (hunt/scan com async=true) e callbacks de conclusão
+
+CREATE TABLE IF NOT EXISTS async_jobs (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    operation VARCHAR(50) NOT NULL,
+    target TEXT NOT NULL,
+    priority VARCHAR(20),
+    callback_url TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    result JSONB,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_async_jobs_tenant ON async_jobs(tenant_id, created_at DESC);
+
+-- Entregas de callbacks de jobs ficam junto das entregas de alertas
+ALTER TABLE webhook_deliveries ALTER COLUMN alert_id DROP NOT NULL;
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS job_id UUID;
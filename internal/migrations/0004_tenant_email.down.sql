@@ -0,0 +1,3 @@
+-- Migração só de dados: o email preenchido é mantido, já que não há como distinguir
+-- os backfills dos emails gravados pelo registro
+SELECT 1;
@@ -0,0 +1,14 @@
+-- Tenants registrados antes do registro gravar o email do tenant recebem o email
+-- do primeiro admin. Settings e quotas vazios ('{}') são mantidos: para eles
+-- "sem limite" é o comportamento atual, e aplicar os padrões do registro agora
+-- restringiria tenants existentes sem aviso (use PUT /v1/admin/tenants/{id}/quotas).
+
+UPDATE tenants t
+SET email = u.email
+FROM (
+    SELECT DISTINCT ON (tenant_id) tenant_id, email
+    FROM users
+    WHERE role = 'admin'
+    ORDER BY tenant_id, created_at
+) u
+WHERE u.tenant_id = t.id AND (t.email IS NULL OR t.email = '');
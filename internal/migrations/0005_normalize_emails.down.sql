@@ -0,0 +1,2 @@
+-- A forma original dos emails não é restaurada
+DROP INDEX IF EXISTS idx_users_tenant_email_normalized;
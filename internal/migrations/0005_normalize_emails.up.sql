@@ -0,0 +1,11 @@
+-- Emails passam a ser gravados e comparados normalizados (sem espaços nas pontas e em
+-- minúsculas). Se o mesmo tenant tiver contas que só diferem na caixa/espaços, a
+-- migração falha na unicidade; unifique-as antes de aplicar:
+--   SELECT tenant_id, lower(btrim(email)), count(*) FROM users
+--   GROUP BY 1, 2 HAVING count(*) > 1;
+
+UPDATE users SET email = lower(btrim(email)) WHERE email <> lower(btrim(email));
+UPDATE tenants SET email = lower(btrim(email)) WHERE email <> lower(btrim(email));
+
+-- Garante a unicidade na forma normalizada mesmo para escritas fora do gateway
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email_normalized ON users (tenant_id, lower(email));
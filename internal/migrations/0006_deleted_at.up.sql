@@ -0,0 +1,9 @@
+-- Instante da exclusão lógica (AUTH_DELETION_MODE=soft) de usuários e tenants, usado
+-- para apagar os dados após AUTH_DELETION_RETENTION. Linhas inativadas antes desta
+-- migração ficam com NULL e nunca são apagadas automaticamente.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_brands_client_created;
+DROP INDEX IF EXISTS idx_clients_tenant_created;
@@ -0,0 +1,6 @@
+-- Índices da paginação por cursor (?cursor=) de clientes e marcas: a ordem
+-- created_at DESC, id DESC da listagem sai do índice, sem ordenar o tenant/cliente
+-- inteiro a cada página.
+
+CREATE INDEX IF NOT EXISTS idx_clients_tenant_created ON clients(tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_brands_client_created ON brands(client_id, created_at DESC, id DESC);
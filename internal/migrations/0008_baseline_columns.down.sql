@@ -0,0 +1,4 @@
+-- As colunas e restrições adicionadas aqui também fazem parte do 0001 (que as remove
+-- no down dele), e o email único global não pode voltar depois que o mesmo email
+-- existir em tenants diferentes
+SELECT 1;
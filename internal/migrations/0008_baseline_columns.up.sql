@@ -0,0 +1,31 @@
+-- Bancos criados pelo schema.sql anterior às migrações já tinham tenants, users,
+-- clients e brands, então o CREATE TABLE IF NOT EXISTS do 0001 não criou nelas as
+-- colunas e restrições novas. Aqui elas são adicionadas; em bancos criados pelo 0001
+-- tudo já existe e nada muda.
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS quotas JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS rate_limit_rpm INTEGER;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants(slug);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_version INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_tenant_slug ON clients(tenant_id, slug);
+
+ALTER TABLE brands ADD COLUMN IF NOT EXISTS threats_found INTEGER NOT NULL DEFAULT 0;
+
+-- O email era único globalmente; passa a ser único por tenant, como no 0001
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conrelid = 'users'::regclass AND contype = 'u'
+          AND conname = 'users_tenant_id_email_key'
+    ) THEN
+        ALTER TABLE users ADD CONSTRAINT users_tenant_id_email_key UNIQUE (tenant_id, email);
+    END IF;
+END
+$$;
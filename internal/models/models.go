@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -91,11 +92,22 @@ type TenantSettings struct {
 	AllowedScopes    []Scope  `json:"allowed_scopes"`
 	AllowedTools     []string `json:"allowed_tools"`
 	WebhookURL       string   `json:"webhook_url,omitempty"`
+	WebhookSecret    string   `json:"webhook_secret,omitempty"`
 	SlackWebhook     string   `json:"slack_webhook,omitempty"`
 	EmailNotify      bool     `json:"email_notify"`
 	MaxConcurrentJobs int     `json:"max_concurrent_jobs"`
 }
 
+// TenantPolicy política do tenant consumida pelo MCP (scopes, ferramentas e quotas)
+type TenantPolicy struct {
+	TenantID      uuid.UUID    `json:"tenant_id"`
+	Plan          string       `json:"plan"`
+	Status        Status       `json:"status"`
+	AllowedScopes []Scope      `json:"allowed_scopes"`
+	AllowedTools  []string     `json:"allowed_tools"`
+	Quotas        TenantQuotas `json:"quotas"`
+}
+
 // TenantQuotas quotas de uso do tenant
 type TenantQuotas struct {
 	MaxClients       int `json:"max_clients"`
@@ -117,6 +129,7 @@ type User struct {
 	Scopes       []Scope   `json:"scopes" db:"scopes"`
 	Status       Status    `json:"status" db:"status"`
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	TokenVersion int       `json:"-" db:"token_version"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -220,6 +233,30 @@ type MonitoringStats struct {
 	ErrorCount      int       `json:"error_count"`
 }
 
+// Status de um job assíncrono do MCP
+const (
+	AsyncJobPending   = "pending"
+	AsyncJobCompleted = "completed"
+	AsyncJobFailed    = "failed"
+)
+
+// AsyncJob hunt/scan executado pelo MCP em segundo plano (async=true). Quando o MCP
+// avisa a conclusão, o resultado é gravado e encaminhado para CallbackURL, se houver.
+type AsyncJob struct {
+	ID          uuid.UUID              `json:"job_id" db:"id"`
+	TenantID    uuid.UUID              `json:"tenant_id" db:"tenant_id"`
+	UserID      uuid.UUID              `json:"user_id" db:"user_id"`
+	Operation   string                 `json:"operation" db:"operation"` // hunt, scan_url
+	Target      string                 `json:"target" db:"target"`
+	Priority    string                 `json:"priority,omitempty" db:"priority"`
+	CallbackURL string                 `json:"callback_url,omitempty" db:"callback_url"`
+	Status      string                 `json:"status" db:"status"` // pending, completed, failed
+	Result      map[string]interface{} `json:"result,omitempty" db:"result"`
+	Error       string                 `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
+}
+
 // Alert representa um alerta gerado
 type Alert struct {
 	ID          uuid.UUID    `json:"id" db:"id"`
@@ -232,6 +269,7 @@ type Alert struct {
 	Description string       `json:"description" db:"description"`
 	Details     AlertDetails `json:"details" db:"details"`
 	Status      string       `json:"status" db:"status"`       // new, acknowledged, resolved, false_positive
+	DedupKey    string       `json:"dedup_key,omitempty" db:"dedup_key"`
 	ResolvedAt  *time.Time   `json:"resolved_at,omitempty" db:"resolved_at"`
 	ResolvedBy  *uuid.UUID   `json:"resolved_by,omitempty" db:"resolved_by"`
 	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
@@ -250,6 +288,78 @@ type AlertDetails struct {
 	ScreenshotURL string   `json:"screenshot_url,omitempty"`
 }
 
+// =============================================================================
+// MODELOS DE NOTIFICAÇÃO
+// =============================================================================
+
+// Status de entrega de webhook
+const (
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookDelivery registro de uma entrega de webhook
+type WebhookDelivery struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	TenantID     uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	AlertID      *uuid.UUID `json:"alert_id,omitempty" db:"alert_id"`
+	JobID        *uuid.UUID `json:"job_id,omitempty" db:"job_id"` // callbacks de jobs assíncronos
+	Channel      string     `json:"channel" db:"channel"`         // webhook, slack
+	URL          string     `json:"url" db:"url"`
+	Status       string     `json:"status" db:"status"` // delivered, failed
+	Attempts     int        `json:"attempts" db:"attempts"`
+	ResponseCode int        `json:"response_code,omitempty" db:"response_code"`
+	Error        string     `json:"error,omitempty" db:"error"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// =============================================================================
+// MODELOS DE RELATÓRIO
+// =============================================================================
+
+// ReportSummary resumo de monitoramento e alertas do tenant (ou de um cliente) no período
+type ReportSummary struct {
+	TenantID    uuid.UUID         `json:"tenant_id"`
+	ClientID    *uuid.UUID        `json:"client_id,omitempty"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Alerts      ReportAlertTotals `json:"alerts"`
+	Monitoring  ReportMonitoring  `json:"monitoring"`
+	Trend       []ReportTrendDay  `json:"trend"`
+	TopBrands   []ReportBrand     `json:"top_brands"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// ReportAlertTotals totais de alertas do período
+type ReportAlertTotals struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByType     map[string]int `json:"by_type"`
+}
+
+// ReportMonitoring situação atual do monitoramento das marcas
+type ReportMonitoring struct {
+	Brands          int `json:"brands"`
+	MonitoredBrands int `json:"monitored_brands"`
+	ThreatsFound    int `json:"threats_found"`
+}
+
+// ReportTrendDay alertas de um dia (UTC) do período
+type ReportTrendDay struct {
+	Date       string         `json:"date"` // YYYY-MM-DD
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// ReportBrand marca com mais alertas no período
+type ReportBrand struct {
+	BrandID  uuid.UUID `json:"brand_id"`
+	Name     string    `json:"name"`
+	Domain   string    `json:"domain"`
+	Alerts   int       `json:"alerts"`
+	Critical int       `json:"critical"` // alertas high ou critical
+}
+
 // =============================================================================
 // MODELOS DE AUDITORIA
 // =============================================================================
@@ -268,10 +378,29 @@ type AuditLog struct {
 	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
 }
 
+// LoginEvent tentativa de login (bem-sucedida ou não) de um usuário
+type LoginEvent struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	TenantID      uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Success       bool      `json:"success" db:"success"`
+	FailureReason string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	IP            string    `json:"ip" db:"ip"`
+	UserAgent     string    `json:"user_agent" db:"user_agent"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
 // =============================================================================
 // HELPERS
 // =============================================================================
 
+// NormalizeEmail retorna a forma canônica de um email (sem espaços nas pontas e em
+// minúsculas), usada para gravar e comparar emails: " User@Example.com " e
+// "user@example.com" são a mesma conta
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // GetDefaultScopesForRole retorna os scopes padrão para um role
 func GetDefaultScopesForRole(role Role) []Scope {
 	switch role {
@@ -327,6 +456,33 @@ func GetDefaultScopesForRole(role Role) []Scope {
 	}
 }
 
+// EffectiveScopes retorna os scopes concedidos a um usuário: os scopes explícitos do
+// usuário (ou os padrão do role, se não houver) limitados pelo teto do tenant
+// (TenantSettings.AllowedScopes). Um grant explícito pode ir além dos padrão do role,
+// mas nunca além do teto. Teto vazio não limita; admins não são limitados, já que o
+// role admin ignora a verificação de scopes.
+func EffectiveScopes(role Role, userScopes []Scope, allowed []Scope) []Scope {
+	scopes := userScopes
+	if len(scopes) == 0 {
+		scopes = GetDefaultScopesForRole(role)
+	}
+	if role == RoleAdmin || len(allowed) == 0 {
+		return scopes
+	}
+
+	ceiling := make(map[Scope]bool, len(allowed))
+	for _, scope := range allowed {
+		ceiling[scope] = true
+	}
+	effective := make([]Scope, 0, len(scopes))
+	for _, scope := range scopes {
+		if ceiling[scope] {
+			effective = append(effective, scope)
+		}
+	}
+	return effective
+}
+
 // HasScope verifica se o usuário tem um scope específico
 func (u *User) HasScope(scope Scope) bool {
 	for _, s := range u.Scopes {
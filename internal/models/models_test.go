@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	for _, email := range []string{
+		"user@example.com",
+		"User@Example.com",
+		"USER@EXAMPLE.COM",
+		" user@example.com",
+		"user@example.com ",
+		"  User@Example.COM  ",
+		"\tuser@example.com\n",
+	} {
+		if got := NormalizeEmail(email); got != "user@example.com" {
+			t.Errorf("NormalizeEmail(%q) = %q, want user@example.com", email, got)
+		}
+	}
+
+	if got := NormalizeEmail("   "); got != "" {
+		t.Errorf("NormalizeEmail(blank) = %q, want empty", got)
+	}
+	// Espaços internos não são removidos: o email continua inválido na validação
+	if got := NormalizeEmail(" Us er@Example.com "); got != "us er@example.com" {
+		t.Errorf("NormalizeEmail with inner space = %q", got)
+	}
+}
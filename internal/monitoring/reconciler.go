@@ -0,0 +1,254 @@
+// Package monitoring mantém o estado de monitoramento das marcas (status, job, último
+// scan e ameaças encontradas) alinhado com os jobs de monitoramento do MCP
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/mcp"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// ErrStateNotSaved o estado do MCP foi obtido, mas não pôde ser gravado na marca
+var ErrStateNotSaved = errors.New("failed to persist monitoring state")
+
+// JobMissing status de um job que o MCP não conhece (ex.: removido ou expirado)
+const JobMissing = "missing"
+
+// Campos da marca corrigidos pela reconciliação
+const (
+	DriftStatus       = "status"
+	DriftJob          = "monitoring_job_id"
+	DriftLastScanAt   = "last_scan_at"
+	DriftThreatsFound = "threats_found"
+)
+
+// Config configuração do Reconciler
+type Config struct {
+	// Prazo de cada consulta ao MCP
+	Timeout time.Duration
+}
+
+// BrandStore marcas monitoradas (implementado por services.BrandService)
+type BrandStore interface {
+	ListMonitored(ctx context.Context, tenantID *uuid.UUID) ([]*models.Brand, error)
+	UpdateMonitoring(ctx context.Context, brand *models.Brand) error
+}
+
+var _ BrandStore = (*services.BrandService)(nil)
+
+// Reconciler compara as marcas com job de monitoramento com o estado do job no MCP
+// e corrige o que divergir. A execução periódica fica a cargo do scheduler.
+type Reconciler struct {
+	mcpClient mcp.Client
+	brands    BrandStore
+	timeout   time.Duration
+}
+
+// Result estado de monitoramento de uma marca após a reconciliação
+type Result struct {
+	BrandID         uuid.UUID     `json:"brand_id"`
+	Status          models.Status `json:"status"`
+	MonitoringJobID *uuid.UUID    `json:"monitoring_job_id,omitempty"`
+	// Status do job no MCP (JobMissing se o MCP não conhece o job); vazio sem job
+	JobStatus    string     `json:"job_status,omitempty"`
+	LastScanAt   *time.Time `json:"last_scan_at,omitempty"`
+	ThreatsFound int        `json:"threats_found"`
+	// Campos que divergiam do MCP e foram corrigidos
+	Drift     []string  `json:"drift,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Summary resultado de ReconcileAll. Results traz apenas as marcas corrigidas.
+type Summary struct {
+	Checked int      `json:"checked"`
+	Drifted int      `json:"drifted"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+// NewReconciler cria um novo reconciliador
+func NewReconciler(mcpClient mcp.Client, brands BrandStore, config Config) *Reconciler {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Reconciler{
+		mcpClient: mcpClient,
+		brands:    brands,
+		timeout:   config.Timeout,
+	}
+}
+
+// Reconcile consulta o job de monitoramento da marca no MCP e persiste o estado real
+// quando ele diverge do local. Marcas sem job são retornadas como estão.
+func (r *Reconciler) Reconcile(ctx context.Context, brand *models.Brand) (*Result, error) {
+	if brand.MonitoringJobID == nil {
+		return newResult(brand, "", nil), nil
+	}
+	jobID := *brand.MonitoringJobID
+
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	state, err := r.mcpClient.GetMonitorJob(callCtx, newRequest(brand), jobID)
+	cancel()
+	switch {
+	case errors.Is(err, mcp.ErrMCPNotFound):
+		state = &mcp.MonitorJobState{JobID: jobID, Status: JobMissing}
+	case err != nil:
+		return nil, err
+	}
+
+	drift := applyJobState(brand, state)
+	if len(drift) > 0 {
+		if err := r.brands.UpdateMonitoring(ctx, brand); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrStateNotSaved, err)
+		}
+		recordDrift(ctx, brand, jobID, state.Status, drift)
+	} else if _, _, known := jobStatus(state.Status); !known {
+		logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"brand_id":   brand.ID.String(),
+			"job_id":     jobID.String(),
+			"job_status": state.Status,
+		}).Warn("unknown monitor job status, brand status left unchanged")
+	}
+
+	return newResult(brand, state.Status, drift), nil
+}
+
+// ReconcileAll reconcilia as marcas com job de monitoramento de um tenant ou de todos
+// (tenantID nil). Falhas de uma marca são contadas e logadas sem interromper as demais.
+func (r *Reconciler) ReconcileAll(ctx context.Context, tenantID *uuid.UUID) (*Summary, error) {
+	brands, err := r.brands.ListMonitored(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{Results: []Result{}}
+	for _, brand := range brands {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		result, err := r.Reconcile(ctx, brand)
+		summary.Checked++
+		if err != nil {
+			summary.Failed++
+			logger.WithContext(ctx).WithError(err).WithFields(map[string]interface{}{
+				"tenant_id": brand.TenantID.String(),
+				"brand_id":  brand.ID.String(),
+			}).Warn("failed to reconcile brand monitoring")
+			continue
+		}
+		if len(result.Drift) > 0 {
+			summary.Drifted++
+			summary.Results = append(summary.Results, *result)
+		}
+	}
+	return summary, nil
+}
+
+// Sync reconcilia as marcas de todos os tenants; é a tarefa periódica do scheduler
+func (r *Reconciler) Sync(ctx context.Context) error {
+	summary, err := r.ReconcileAll(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if summary.Drifted > 0 || summary.Failed > 0 {
+		logger.WithFields(map[string]interface{}{
+			"checked": summary.Checked,
+			"drifted": summary.Drifted,
+			"failed":  summary.Failed,
+		}).Info("monitoring sync finished")
+	}
+	return nil
+}
+
+// applyJobState aplica o estado do job na marca e retorna os campos alterados.
+// last_scan_at só avança e threats_found só aumenta: o MCP informa os números do job
+// atual, enquanto a marca acumula os de jobs anteriores.
+func applyJobState(brand *models.Brand, state *mcp.MonitorJobState) []string {
+	var drift []string
+
+	if status, running, known := jobStatus(state.Status); known {
+		if brand.Status != status {
+			brand.Status = status
+			drift = append(drift, DriftStatus)
+		}
+		if !running {
+			brand.MonitoringJobID = nil
+			drift = append(drift, DriftJob)
+		}
+	}
+	if state.LastRunAt != nil && (brand.LastScanAt == nil || state.LastRunAt.After(*brand.LastScanAt)) {
+		lastRun := *state.LastRunAt
+		brand.LastScanAt = &lastRun
+		drift = append(drift, DriftLastScanAt)
+	}
+	if state.ThreatsFound != nil && *state.ThreatsFound > brand.ThreatsFound {
+		brand.ThreatsFound = *state.ThreatsFound
+		drift = append(drift, DriftThreatsFound)
+	}
+
+	return drift
+}
+
+// jobStatus converte o status de um job do MCP no status da marca. running indica se
+// o job ainda existe no MCP (senão a marca perde o monitoring_job_id); known é false
+// para status não reconhecidos, que não alteram a marca.
+func jobStatus(status string) (brandStatus models.Status, running, known bool) {
+	switch status {
+	case "running", "active", "scheduled", "pending", "queued":
+		return models.StatusActive, true, true
+	case "paused", "suspended":
+		return models.StatusSuspended, true, true
+	case "stopped", "completed", "cancelled", "canceled", "failed", "error", JobMissing:
+		return models.StatusInactive, false, true
+	default:
+		return "", false, false
+	}
+}
+
+// recordDrift loga e contabiliza os campos corrigidos de uma marca
+func recordDrift(ctx context.Context, brand *models.Brand, jobID uuid.UUID, jobStatus string, drift []string) {
+	for _, field := range drift {
+		middleware.RecordMonitoringDrift(brand.TenantID.String(), field)
+	}
+	logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"tenant_id":  brand.TenantID.String(),
+		"brand_id":   brand.ID.String(),
+		"job_id":     jobID.String(),
+		"job_status": jobStatus,
+		"drift":      drift,
+	}).Warn("brand monitoring state drifted from MCP")
+}
+
+// newRequest monta a request ao MCP em nome do tenant e do cliente da marca
+func newRequest(brand *models.Brand) *mcp.MCPRequest {
+	clientID := brand.ClientID
+	return &mcp.MCPRequest{
+		RequestID: uuid.New().String(),
+		TenantID:  brand.TenantID,
+		ClientID:  &clientID,
+		Scopes:    []string{string(models.ScopeMonitorRead)},
+	}
+}
+
+func newResult(brand *models.Brand, jobStatus string, drift []string) *Result {
+	return &Result{
+		BrandID:         brand.ID,
+		Status:          brand.Status,
+		MonitoringJobID: brand.MonitoringJobID,
+		JobStatus:       jobStatus,
+		LastScanAt:      brand.LastScanAt,
+		ThreatsFound:    brand.ThreatsFound,
+		Drift:           drift,
+		CheckedAt:       time.Now().UTC(),
+	}
+}
@@ -0,0 +1,103 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+)
+
+// Email mensagem de email em texto puro
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia emails transacionais (verificação de conta, avisos)
+type Mailer interface {
+	Send(ctx context.Context, email *Email) error
+}
+
+// SMTPConfig configuração do servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// NewMailer cria o mailer SMTP ou, sem SMTP configurado (dev local), um mailer que apenas loga
+func NewMailer(config SMTPConfig) Mailer {
+	if config.Host == "" {
+		return LogMailer{}
+	}
+	return &SMTPMailer{config: config}
+}
+
+// =============================================================================
+// SMTP MAILER
+// =============================================================================
+
+// SMTPMailer envia emails via SMTP (STARTTLS quando suportado pelo servidor)
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// Send envia o email; o contexto é respeitado até a abertura da conexão
+func (m *SMTPMailer) Send(ctx context.Context, email *Email) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{email.To}, m.message(email)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message monta a mensagem RFC 5322
+func (m *SMTPMailer) message(email *Email) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(m.config.From) + "\r\n")
+	b.WriteString("To: " + headerValue(email.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue(email.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().UTC().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue remove quebras de linha para impedir injeção de headers
+func headerValue(v string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(v)
+}
+
+// =============================================================================
+// LOG MAILER
+// =============================================================================
+
+// LogMailer escreve o email no log em vez de enviá-lo (desenvolvimento local)
+type LogMailer struct{}
+
+// Send loga o destinatário, assunto e corpo do email
+func (LogMailer) Send(ctx context.Context, email *Email) error {
+	logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"to":      email.To,
+		"subject": email.Subject,
+	}).Info("email not sent (SMTP not configured):\n%s", email.Body)
+	return nil
+}
@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Alertas pendentes por assinante; um assinante lento perde alertas em vez de travar a publicação
+const subscriberBuffer = 64
+
+var (
+	ErrTooManyStreams = errors.New("too many concurrent streams for tenant")
+	ErrBrokerClosed   = errors.New("alert broker closed")
+)
+
+// AlertBroker distribui alertas recém-ingeridos para os streams abertos do tenant
+type AlertBroker interface {
+	Publish(ctx context.Context, alert *models.Alert) error
+	Subscribe(tenantID uuid.UUID) (*Subscription, error)
+	// Close encerra todas as assinaturas (shutdown)
+	Close()
+}
+
+// Subscription assinatura dos alertas de um tenant. C é fechado quando a assinatura
+// termina (Close ou encerramento do broker).
+type Subscription struct {
+	C <-chan *models.Alert
+
+	ch       chan *models.Alert
+	tenantID uuid.UUID
+	once     sync.Once
+	close    func(*Subscription)
+}
+
+// Close cancela a assinatura; pode ser chamado mais de uma vez
+func (s *Subscription) Close() {
+	s.once.Do(func() { s.close(s) })
+}
+
+// =============================================================================
+// MEMORY ALERT BROKER
+// =============================================================================
+
+// MemoryAlertBroker implementação em memória do AlertBroker. Só entrega alertas
+// ingeridos na mesma instância; com múltiplas réplicas o broker precisa ser compartilhado.
+type MemoryAlertBroker struct {
+	maxPerTenant int
+
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewMemoryAlertBroker cria um novo broker em memória com no máximo maxPerTenant
+// streams simultâneos por tenant (0 = sem limite)
+func NewMemoryAlertBroker(maxPerTenant int) *MemoryAlertBroker {
+	return &MemoryAlertBroker{
+		maxPerTenant: maxPerTenant,
+		subs:         make(map[uuid.UUID]map[*Subscription]struct{}),
+	}
+}
+
+// Publish entrega o alerta aos assinantes do tenant sem bloquear
+func (b *MemoryAlertBroker) Publish(ctx context.Context, alert *models.Alert) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	for sub := range b.subs[alert.TenantID] {
+		select {
+		case sub.ch <- alert:
+		default:
+			logger.WithField("tenant_id", alert.TenantID.String()).Warn("alert stream subscriber is lagging; dropping alert %s", alert.ID)
+		}
+	}
+	return nil
+}
+
+// Subscribe abre uma assinatura dos alertas do tenant
+func (b *MemoryAlertBroker) Subscribe(tenantID uuid.UUID) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrokerClosed
+	}
+	if b.maxPerTenant > 0 && len(b.subs[tenantID]) >= b.maxPerTenant {
+		return nil, ErrTooManyStreams
+	}
+
+	ch := make(chan *models.Alert, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, tenantID: tenantID, close: b.unsubscribe}
+	if b.subs[tenantID] == nil {
+		b.subs[tenantID] = make(map[*Subscription]struct{})
+	}
+	b.subs[tenantID][sub] = struct{}{}
+	return sub, nil
+}
+
+// Close encerra todas as assinaturas (shutdown)
+func (b *MemoryAlertBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for tenantID, subs := range b.subs {
+		for sub := range subs {
+			close(sub.ch)
+		}
+		delete(b.subs, tenantID)
+	}
+}
+
+// unsubscribe remove a assinatura e fecha seu canal
+func (b *MemoryAlertBroker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs, ok := b.subs[sub.tenantID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.tenantID)
+	}
+	close(sub.ch)
+}
@@ -0,0 +1,431 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Headers enviados em cada entrega de webhook
+const (
+	HeaderSignature  = "X-ARCA-Signature"
+	HeaderEvent      = "X-ARCA-Event"
+	HeaderDeliveryID = "X-ARCA-Delivery"
+	HeaderTimestamp  = "X-ARCA-Timestamp"
+)
+
+// Canais de entrega
+const (
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+)
+
+// EventAlertCreated evento disparado quando um alerta é criado/ingerido
+const EventAlertCreated = "alert.created"
+
+// Eventos de conclusão de jobs assíncronos, entregues no callback_url do job
+const (
+	EventJobCompleted = "job.completed"
+	EventJobFailed    = "job.failed"
+)
+
+var (
+	ErrQueueFull         = errors.New("webhook delivery queue is full")
+	ErrDispatcherStopped = errors.New("webhook dispatcher stopped")
+	ErrMissingSecret     = errors.New("webhook secret not configured")
+)
+
+// DeliveryStore persiste o resultado das entregas de webhook
+type DeliveryStore interface {
+	Create(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// DispatcherConfig configuração do dispatcher de webhooks
+type DispatcherConfig struct {
+	// Número de workers que entregam em paralelo
+	Workers int
+	// Tamanho máximo da fila de entregas pendentes
+	QueueSize int
+	// Número máximo de retentativas em respostas 5xx/erros de rede
+	MaxRetries int
+	// Delay base do backoff exponencial
+	RetryDelay time.Duration
+	// Timeout de cada tentativa HTTP
+	Timeout time.Duration
+	// Guard valida o IP de cada conexão de entrega; nil conecta em qualquer endereço
+	Guard *ssrf.Guard
+}
+
+// WebhookDispatcher entrega alertas para os webhooks configurados pelo tenant e
+// resultados de jobs assíncronos para o callback informado na request
+type WebhookDispatcher struct {
+	httpClient *http.Client
+	store      DeliveryStore
+	jobs       chan *delivery
+	maxRetries int
+	retryDelay time.Duration
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// delivery representa uma entrega pendente; alert ou asyncJob é preenchido
+type delivery struct {
+	id       uuid.UUID
+	channel  string
+	event    string
+	url      string
+	secret   string
+	tenantID uuid.UUID
+	alert    *models.Alert
+	asyncJob *models.AsyncJob
+}
+
+// AlertPayload payload enviado para webhooks genéricos
+type AlertPayload struct {
+	Event      string        `json:"event"`
+	DeliveryID uuid.UUID     `json:"delivery_id"`
+	Timestamp  string        `json:"timestamp"`
+	Alert      *models.Alert `json:"alert"`
+}
+
+// JobPayload payload enviado para o callback de um job assíncrono
+type JobPayload struct {
+	Event      string           `json:"event"`
+	DeliveryID uuid.UUID        `json:"delivery_id"`
+	Timestamp  string           `json:"timestamp"`
+	Job        *models.AsyncJob `json:"job"`
+}
+
+// NewWebhookDispatcher cria um novo dispatcher e inicia o pool de workers
+func NewWebhookDispatcher(config DispatcherConfig, store DeliveryStore) *WebhookDispatcher {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1000
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 1 * time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	d := &WebhookDispatcher{
+		httpClient: config.Guard.HTTPClient(config.Timeout),
+		store:      store,
+		jobs:       make(chan *delivery, config.QueueSize),
+		maxRetries: config.MaxRetries,
+		retryDelay: config.RetryDelay,
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+
+	return d
+}
+
+// Dispatch enfileira a entrega de um alerta para os canais configurados no tenant.
+// Não bloqueia: se a fila estiver cheia a entrega é registrada como falha.
+func (d *WebhookDispatcher) Dispatch(settings models.TenantSettings, alert *models.Alert) {
+	if settings.WebhookURL != "" {
+		d.enqueue(&delivery{
+			id:       uuid.New(),
+			channel:  ChannelWebhook,
+			event:    EventAlertCreated,
+			url:      settings.WebhookURL,
+			secret:   settings.WebhookSecret,
+			tenantID: alert.TenantID,
+			alert:    alert,
+		})
+	}
+
+	if settings.SlackWebhook != "" {
+		d.enqueue(&delivery{
+			id:       uuid.New(),
+			channel:  ChannelSlack,
+			url:      settings.SlackWebhook,
+			tenantID: alert.TenantID,
+			alert:    alert,
+		})
+	}
+}
+
+// DispatchJob enfileira a entrega do resultado de um job para o callback_url dele,
+// assinada com o segredo de webhook do tenant (sem segredo a entrega é recusada)
+func (d *WebhookDispatcher) DispatchJob(job *models.AsyncJob, secret string) {
+	event := EventJobCompleted
+	if job.Status == models.AsyncJobFailed {
+		event = EventJobFailed
+	}
+
+	d.enqueue(&delivery{
+		id:       uuid.New(),
+		channel:  ChannelWebhook,
+		event:    event,
+		url:      job.CallbackURL,
+		secret:   secret,
+		tenantID: job.TenantID,
+		asyncJob: job,
+	})
+}
+
+// Stop para de aceitar novas entregas e aguarda a fila ser drenada
+func (d *WebhookDispatcher) Stop() {
+	d.mu.Lock()
+	if d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	d.stopped = true
+	close(d.jobs)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+// enqueue adiciona uma entrega na fila sem bloquear
+func (d *WebhookDispatcher) enqueue(job *delivery) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.stopped {
+		d.record(job, 0, 0, ErrDispatcherStopped)
+		return
+	}
+
+	select {
+	case d.jobs <- job:
+	default:
+		d.record(job, 0, 0, ErrQueueFull)
+	}
+}
+
+// worker processa entregas da fila
+func (d *WebhookDispatcher) worker() {
+	defer d.wg.Done()
+
+	for job := range d.jobs {
+		d.deliver(job)
+	}
+}
+
+// deliver executa a entrega com retry e backoff exponencial em erros 5xx/rede
+func (d *WebhookDispatcher) deliver(job *delivery) {
+	// Entregas genéricas sempre são assinadas: sem segredo o receptor não teria
+	// como distinguir o gateway de qualquer um que conheça a URL
+	if job.channel == ChannelWebhook && job.secret == "" {
+		d.record(job, 0, 0, ErrMissingSecret)
+		return
+	}
+
+	body, err := d.buildPayload(job)
+	if err != nil {
+		d.record(job, 0, 0, err)
+		return
+	}
+
+	var (
+		lastErr    error
+		statusCode int
+		attempts   int
+	)
+
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(d.retryDelay * time.Duration(1<<(attempt-1)))
+		}
+		attempts++
+
+		statusCode, lastErr = d.send(job, body)
+		if lastErr == nil {
+			break
+		}
+
+		// Erros 4xx não são retentados: o destino rejeitou o payload
+		if statusCode >= 400 && statusCode < 500 {
+			break
+		}
+	}
+
+	d.record(job, attempts, statusCode, lastErr)
+}
+
+// send executa uma tentativa de entrega
+func (d *WebhookDispatcher) send(job *delivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, job.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if job.channel == ChannelWebhook {
+		timestamp := time.Now().UTC().Format(time.RFC3339)
+		req.Header.Set(HeaderEvent, job.event)
+		req.Header.Set(HeaderDeliveryID, job.id.String())
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign(job.secret, timestamp, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+// buildPayload monta o corpo da entrega de acordo com o canal
+func (d *WebhookDispatcher) buildPayload(job *delivery) ([]byte, error) {
+	if job.channel == ChannelSlack {
+		return json.Marshal(slackMessage(job.alert))
+	}
+	if job.asyncJob != nil {
+		return json.Marshal(JobPayload{
+			Event:      job.event,
+			DeliveryID: job.id,
+			Timestamp:  time.Now().UTC().Format(time.RFC3339),
+			Job:        job.asyncJob,
+		})
+	}
+
+	return json.Marshal(AlertPayload{
+		Event:      job.event,
+		DeliveryID: job.id,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Alert:      job.alert,
+	})
+}
+
+// record persiste o resultado da entrega
+func (d *WebhookDispatcher) record(job *delivery, attempts, statusCode int, deliveryErr error) {
+	record := &models.WebhookDelivery{
+		ID:           job.id,
+		TenantID:     job.tenantID,
+		Channel:      job.channel,
+		URL:          job.url,
+		Status:       models.DeliveryStatusDelivered,
+		Attempts:     attempts,
+		ResponseCode: statusCode,
+		CreatedAt:    time.Now(),
+	}
+
+	fields := map[string]interface{}{
+		"delivery_id": job.id.String(),
+		"tenant_id":   job.tenantID.String(),
+		"channel":     job.channel,
+		"attempts":    attempts,
+	}
+	if job.alert != nil {
+		record.AlertID = &job.alert.ID
+		fields["alert_id"] = job.alert.ID.String()
+	}
+	if job.asyncJob != nil {
+		record.JobID = &job.asyncJob.ID
+		fields["job_id"] = job.asyncJob.ID.String()
+	}
+	log := logger.WithFields(fields)
+
+	if deliveryErr != nil {
+		record.Status = models.DeliveryStatusFailed
+		record.Error = deliveryErr.Error()
+		log.Warn("webhook delivery failed: %v", deliveryErr)
+	} else {
+		log.Debug("webhook delivered")
+	}
+
+	if d.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.store.Create(ctx, record); err != nil {
+		log.Error("failed to record webhook delivery: %v", err)
+	}
+}
+
+// Sign calcula a assinatura HMAC-SHA256 de timestamp + "." + corpo no formato
+// "sha256=<hex>". Os receptores devem recalcular o HMAC com o X-ARCA-Timestamp e o
+// corpo bruto e rejeitar timestamps antigos: como o timestamp é assinado, uma
+// entrega capturada não pode ser reenviada com um timestamp novo.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// =============================================================================
+// SLACK
+// =============================================================================
+
+// slackSeverityColors cores dos attachments por severidade
+var slackSeverityColors = map[string]string{
+	"critical": "#8B0000",
+	"high":     "#E01E5A",
+	"medium":   "#ECB22E",
+	"low":      "#36C5F0",
+	"info":     "#CCCCCC",
+}
+
+// slackMessage monta a mensagem no formato de Incoming Webhooks do Slack
+func slackMessage(alert *models.Alert) map[string]interface{} {
+	fields := []map[string]interface{}{
+		{"title": "Severity", "value": strings.ToUpper(alert.Severity), "short": true},
+		{"title": "Type", "value": alert.Type, "short": true},
+	}
+	if alert.Details.Domain != "" {
+		fields = append(fields, map[string]interface{}{"title": "Domain", "value": alert.Details.Domain, "short": true})
+	}
+	if alert.Details.URL != "" {
+		fields = append(fields, map[string]interface{}{"title": "URL", "value": alert.Details.URL, "short": false})
+	}
+
+	color, ok := slackSeverityColors[alert.Severity]
+	if !ok {
+		color = slackSeverityColors["info"]
+	}
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf(":rotating_light: *[%s]* %s", strings.ToUpper(alert.Severity), alert.Title),
+		"attachments": []map[string]interface{}{
+			{
+				"color":  color,
+				"text":   alert.Description,
+				"fields": fields,
+				"footer": "ARCA Intelligence",
+				"ts":     alert.CreatedAt.Unix(),
+			},
+		},
+	}
+}
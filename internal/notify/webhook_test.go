@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/ssrf"
+	"github.com/google/uuid"
+)
+
+// memoryDeliveries registra as entregas em memória
+type memoryDeliveries struct {
+	mu         sync.Mutex
+	deliveries []*models.WebhookDelivery
+}
+
+func (m *memoryDeliveries) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+// only retorna a única entrega registrada
+func (m *memoryDeliveries) only(t *testing.T) *models.WebhookDelivery {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(m.deliveries))
+	}
+	return m.deliveries[0]
+}
+
+func newTestJob(callbackURL string) *models.AsyncJob {
+	return &models.AsyncJob{
+		ID:          uuid.New(),
+		TenantID:    uuid.New(),
+		Operation:   "hunt",
+		CallbackURL: callbackURL,
+		Status:      models.AsyncJobCompleted,
+	}
+}
+
+func TestSignCoversTimestampAndBody(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		timestamp string
+		signature string
+		body      []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		timestamp = r.Header.Get(HeaderTimestamp)
+		signature = r.Header.Get(HeaderSignature)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	store := &memoryDeliveries{}
+	d := NewWebhookDispatcher(DispatcherConfig{Workers: 1}, store)
+	d.DispatchJob(newTestJob(server.URL), "s3cret")
+	d.Stop()
+
+	if delivery := store.only(t); delivery.Status != models.DeliveryStatusDelivered {
+		t.Fatalf("delivery = %+v", delivery)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if signature != Sign("s3cret", timestamp, body) {
+		t.Errorf("signature %q does not match timestamp %q and body", signature, timestamp)
+	}
+	// Reenviar o mesmo corpo com outro timestamp invalida a assinatura
+	replayed := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	if signature == Sign("s3cret", replayed, body) {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestDispatchRefusesUnsignedWebhook(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	store := &memoryDeliveries{}
+	d := NewWebhookDispatcher(DispatcherConfig{Workers: 1}, store)
+	d.DispatchJob(newTestJob(server.URL), "")
+	d.Stop()
+
+	delivery := store.only(t)
+	if delivery.Status != models.DeliveryStatusFailed || delivery.Error != ErrMissingSecret.Error() {
+		t.Errorf("delivery = %+v, want failure %q", delivery, ErrMissingSecret)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("unsigned webhook was delivered")
+	}
+}
+
+func TestDispatchGuardBlocksInternalAddress(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	guard, err := ssrf.NewGuard(ssrf.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &memoryDeliveries{}
+	d := NewWebhookDispatcher(DispatcherConfig{Workers: 1, Guard: guard}, store)
+	d.DispatchJob(newTestJob(server.URL), "s3cret")
+	d.Stop()
+
+	delivery := store.only(t)
+	if delivery.Status != models.DeliveryStatusFailed || !strings.Contains(delivery.Error, ssrf.ErrBlockedAddress.Error()) {
+		t.Errorf("delivery = %+v, want blocked address", delivery)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("delivery reached a loopback address")
+	}
+}
@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+)
+
+// Document documento OpenAPI 3.0
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info metadados da API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag agrupamento de operações
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem operações de um path
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+}
+
+// Operation uma operação (método + path)
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Parameter parâmetro de path, query ou header
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody corpo da requisição
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response resposta de uma operação
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType schema de um content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components schemas e esquemas de segurança reutilizáveis
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme esquema de autenticação
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// SecurityRequirement esquemas exigidos por uma operação (nome -> scopes)
+type SecurityRequirement map[string][]string
+
+// Nomes dos esquemas de segurança registrados por New
+const (
+	SecurityBearer = "bearerAuth"
+	SecurityAPIKey = "apiKey"
+)
+
+// =============================================================================
+// BUILDER
+// =============================================================================
+
+// Route descreve uma rota para o documento. Request e Response são valores de
+// exemplo (ex.: handlers.LoginRequest{}) cujos tipos viram schemas; Response é
+// embrulhado no envelope padrão de pkg/response.
+type Route struct {
+	Method      string
+	Path        string // no formato do Fiber (/v1/clients/:client_id)
+	Tag         string
+	Summary     string
+	Description string
+	Request     interface{}
+	Response    interface{}
+	// Status de sucesso (padrão 200; 204 não tem corpo)
+	Status int
+	// Response é uma lista paginada de itens do tipo de Response
+	Paginated bool
+	// Parâmetros de query e header; os de path são extraídos de Path
+	Parameters []Parameter
+	// Scopes exigidos; rotas com Public não exigem autenticação
+	Scopes []string
+	Public bool
+}
+
+// Builder monta um Document a partir de rotas, gerando os schemas dos tipos por reflection
+type Builder struct {
+	doc     *Document
+	schemas *schemaRegistry
+}
+
+// New cria um novo builder com os esquemas Bearer (JWT) e API key
+func New(title, version, description string) *Builder {
+	b := &Builder{
+		doc: &Document{
+			OpenAPI: "3.0.3",
+			Info:    Info{Title: title, Version: version, Description: description},
+			Paths:   make(map[string]*PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					SecurityBearer: {
+						Type:         "http",
+						Scheme:       "bearer",
+						BearerFormat: "JWT",
+						Description:  "Access token obtido em /v1/auth/login",
+					},
+					SecurityAPIKey: {
+						Type:        "apiKey",
+						In:          "header",
+						Name:        "Authorization",
+						Description: "API key gerada em /v1/auth/api-key, enviada como \"Bearer <api_key>\"",
+					},
+				},
+			},
+		},
+	}
+	b.schemas = newSchemaRegistry(b.doc.Components.Schemas)
+	b.doc.Components.Schemas["ErrorResponse"] = b.envelope(false, nil, b.schemas.schemaFor(reflect.TypeOf(response.ErrorInfo{})))
+	return b
+}
+
+// Tag registra a descrição de uma tag
+func (b *Builder) Tag(name, description string) *Builder {
+	b.doc.Tags = append(b.doc.Tags, Tag{Name: name, Description: description})
+	return b
+}
+
+// Add adiciona as rotas ao documento
+func (b *Builder) Add(routes ...Route) *Builder {
+	for _, route := range routes {
+		b.add(route)
+	}
+	return b
+}
+
+// Document retorna o documento montado
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+func (b *Builder) add(route Route) {
+	path := pathParam.ReplaceAllString(strings.TrimSuffix(route.Path, "/"), "{$1}")
+	if path == "" {
+		path = "/"
+	}
+
+	op := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		OperationID: operationID(route.Method, path),
+		Responses:   make(map[string]*Response),
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		param := Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		if strings.HasSuffix(m[1], "_id") {
+			param.Schema.Format = "uuid"
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	op.Parameters = append(op.Parameters, route.Parameters...)
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schemas.schemaFor(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	if status != http.StatusNoContent {
+		var data *Schema
+		if route.Response != nil {
+			data = b.schemas.schemaFor(reflect.TypeOf(route.Response))
+		}
+		success.Content = map[string]MediaType{"application/json": {Schema: b.envelope(true, data, nil)}}
+		if route.Paginated {
+			success.Content["application/json"] = MediaType{Schema: b.envelope(true, paginated(data, b.schemas.schemaFor(reflect.TypeOf(response.Meta{}))), nil)}
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	errorRef := &Response{
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{Ref: "#/components/schemas/ErrorResponse"}}},
+	}
+	addError := func(status int) {
+		resp := *errorRef
+		resp.Description = http.StatusText(status)
+		op.Responses[strconv.Itoa(status)] = &resp
+	}
+	if route.Request != nil || len(op.Parameters) > 0 {
+		addError(http.StatusBadRequest)
+	}
+	if !route.Public {
+		addError(http.StatusUnauthorized)
+		op.Security = []SecurityRequirement{
+			{SecurityBearer: route.Scopes},
+			{SecurityAPIKey: route.Scopes},
+		}
+		if len(route.Scopes) > 0 {
+			addError(http.StatusForbidden)
+			op.Description = strings.TrimSpace(op.Description + "\n\nScopes: `" + strings.Join(route.Scopes, "`, `") + "`")
+		}
+	}
+	addError(http.StatusTooManyRequests)
+
+	item, ok := b.doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[path] = item
+	}
+	switch route.Method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodDelete:
+		item.Delete = op
+	}
+}
+
+// envelope schema de response.Response com data ou error
+func (b *Builder) envelope(success bool, data, errInfo *Schema) *Schema {
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success":    {Type: "boolean", Enum: []interface{}{success}},
+			"request_id": {Type: "string"},
+			"timestamp":  {Type: "string", Format: "date-time"},
+		},
+		Required: []string{"success", "timestamp"},
+	}
+	if data != nil {
+		s.Properties["data"] = data
+	}
+	if errInfo != nil {
+		s.Properties["error"] = errInfo
+		s.Required = append(s.Required, "error")
+	}
+	return s
+}
+
+// paginated schema de response.PaginatedData com itens do schema informado
+func paginated(item, meta *Schema) *Schema {
+	if item == nil {
+		item = &Schema{}
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"items": {Type: "array", Items: item},
+			"meta":  meta,
+		},
+		Required: []string{"items", "meta"},
+	}
+}
+
+// operationID gera um id estável a partir do método e do path (ex.: get_v1_clients_client_id)
+func operationID(method, path string) string {
+	id := strings.ToLower(method) + strings.NewReplacer("/", "_", "{", "", "}", "", "-", "_").Replace(path)
+	return strings.TrimSuffix(id, "_")
+}
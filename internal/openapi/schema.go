@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema JSON Schema (subconjunto do OpenAPI 3.0)
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaRegistry gera schemas a partir de tipos Go. Structs nomeadas viram
+// components (referenciadas por $ref); em caso de nomes repetidos entre pacotes
+// o segundo tipo recebe o nome do pacote como prefixo (ex.: mcp.HuntRequest).
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry(components map[string]*Schema) *schemaRegistry {
+	return &schemaRegistry{components: components, names: make(map[reflect.Type]string)}
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: nullable}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid", Nullable: nullable}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: r.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + r.register(t)}
+	}
+	// interface{} e demais tipos: qualquer valor
+	return &Schema{}
+}
+
+// register adiciona a struct nomeada aos components e retorna o nome usado
+func (r *schemaRegistry) register(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := r.components[name]; taken {
+		name = path.Base(t.PkgPath()) + "." + t.Name()
+	}
+	r.names[t] = name
+	// Reserva o nome antes de gerar as propriedades para suportar tipos recursivos
+	r.components[name] = &Schema{}
+	*r.components[name] = *r.structSchema(t)
+	return name
+}
+
+// structSchema gera o schema das propriedades de uma struct seguindo as tags json;
+// campos embutidos sem nome json são achatados como no encoding/json
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := r.structSchema(embedded)
+				for prop, schema := range inner.Properties {
+					s.Properties[prop] = schema
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := r.schemaFor(field.Type)
+		if applyValidateTag(prop, field.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// applyValidateTag traduz regras de validação (tag validate) para o schema e
+// indica se o campo é obrigatório
+func applyValidateTag(s *Schema, tag string) bool {
+	if tag == "" || s.Ref != "" {
+		return strings.Contains(tag, "required")
+	}
+
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		n, numErr := strconv.Atoi(value)
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				s.Enum = append(s.Enum, option)
+			}
+		case "min", "max", "len":
+			if s.Type != "string" || numErr != nil {
+				continue
+			}
+			if key != "max" {
+				s.MinLength = &n
+			}
+			if key != "min" {
+				s.MaxLength = &n
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,182 @@
+// Package scheduler executa tarefas periódicas em background (limpezas,
+// reconciliações) com cancelamento no shutdown e isolamento de panics por tarefa
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/lock"
+	"github.com/arcaintelligence/arca-gateway/internal/middleware"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+)
+
+// Resultados de uma execução registrados em arca_scheduler_runs_total
+const (
+	runSucceeded = "success"
+	runFailed    = "error"
+	runPanicked  = "panic"
+	runSkipped   = "skipped"
+)
+
+// ErrStarted indica um Register depois de Start
+var ErrStarted = errors.New("scheduler already started")
+
+// Job tarefa periódica
+type Job struct {
+	// Nome único, usado em logs e métricas
+	Name string
+	// Intervalo entre execuções; a primeira acontece um intervalo após Start
+	Interval time.Duration
+	// Prazo de cada execução (0 = Interval). O contexto também é cancelado no Stop
+	// e, nas tarefas Singleton, quando a instância perde a liderança.
+	Timeout time.Duration
+	// Singleton tarefas que só a réplica líder executa (ex.: escrita no banco
+	// compartilhado); tarefas sobre estado local rodam em todas
+	Singleton bool
+	Run       func(ctx context.Context) error
+}
+
+// Config configuração do Scheduler
+type Config struct {
+	// Liderança que habilita as tarefas Singleton; nil executa todas as tarefas
+	// (instância única)
+	Leader lock.Leader
+}
+
+// Scheduler executa as tarefas registradas, cada uma em sua goroutine. Execuções
+// da mesma tarefa nunca se sobrepõem.
+type Scheduler struct {
+	leader lock.Leader
+
+	mu      sync.Mutex
+	jobs    []Job
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New cria um novo scheduler
+func New(config Config) *Scheduler {
+	return &Scheduler{leader: config.Leader}
+}
+
+// Register adiciona uma tarefa. Deve ser chamado antes de Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("scheduler job requires a name and a run function")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("scheduler job %q: interval must be positive", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrStarted
+	}
+	for _, registered := range s.jobs {
+		if registered.Name == job.Name {
+			return fmt.Errorf("scheduler job %q already registered", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start inicia as tarefas registradas
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancela as execuções em andamento e aguarda as tarefas terminarem
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// loop executa a tarefa a cada intervalo até o contexto ser cancelado
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runOnce executa a tarefa uma vez, registrando o resultado. Um panic é logado e
+// contabilizado sem derrubar o processo nem as próximas execuções.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	log := logger.WithField("job", job.Name)
+
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = job.Interval
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if job.Singleton && s.leader != nil {
+		leadership, leading := s.leader.Leadership()
+		if !leading {
+			middleware.RecordSchedulerRun(job.Name, runSkipped, 0)
+			return
+		}
+		// Perder a liderança cancela a execução: outra réplica pode assumir a tarefa
+		stop := context.AfterFunc(leadership, cancel)
+		defer stop()
+	}
+
+	start := time.Now()
+	status := runSucceeded
+	defer func() {
+		if r := recover(); r != nil {
+			status = runPanicked
+			log.WithError(fmt.Errorf("panic: %v", r)).
+				WithField("stack", string(debug.Stack())).
+				Error("scheduler job panicked")
+		}
+		middleware.RecordSchedulerRun(job.Name, status, time.Since(start))
+	}()
+
+	if err := job.Run(runCtx); err != nil {
+		status = runFailed
+		if runCtx.Err() != context.Canceled {
+			log.WithError(err).Error("scheduler job failed")
+		}
+	}
+}
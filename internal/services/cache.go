@@ -0,0 +1,342 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// ENTITY CACHE
+// =============================================================================
+
+// EntityCache cache das leituras por ID de usuários e tenants. Cada entrada
+// pertence a um tenant (o próprio tenant ou o tenant do usuário), para que uma
+// alteração no tenant descarte de uma vez tudo o que é dele. MemoryEntityCache
+// atende uma instância; internal/cache tem a implementação no Redis, compartilhada
+// entre instâncias.
+type EntityCache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, tenantID uuid.UUID, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+	DeleteTenant(ctx context.Context, tenantID uuid.UUID) error
+}
+
+// MemoryEntityCache EntityCache em memória com TTL e limite de entradas; acima do
+// limite as entradas usadas há mais tempo são descartadas (LRU)
+type MemoryEntityCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // mais recentes na frente
+	entries map[string]*list.Element
+	tenants map[uuid.UUID]map[string]struct{}
+}
+
+// memoryEntity entrada do MemoryEntityCache
+type memoryEntity struct {
+	key       string
+	tenantID  uuid.UUID
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryEntityCache cria um cache em memória com até size entradas
+func NewMemoryEntityCache(size int, ttl time.Duration) *MemoryEntityCache {
+	if size <= 0 {
+		size = 10000
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &MemoryEntityCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		tenants: make(map[uuid.UUID]map[string]struct{}),
+	}
+}
+
+// Get retorna o valor da chave se ele ainda não expirou
+func (c *MemoryEntityCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*memoryEntity)
+	if !time.Now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set grava o valor da chave, descartando as entradas menos usadas acima do limite
+func (c *MemoryEntityCache) Set(ctx context.Context, tenantID uuid.UUID, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	entry := &memoryEntity{key: key, tenantID: tenantID, value: value, expiresAt: time.Now().Add(c.ttl)}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.tenants[tenantID] == nil {
+		c.tenants[tenantID] = make(map[string]struct{})
+	}
+	c.tenants[tenantID][key] = struct{}{}
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete remove a chave
+func (c *MemoryEntityCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+// DeleteTenant remove todas as entradas do tenant
+func (c *MemoryEntityCache) DeleteTenant(ctx context.Context, tenantID uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.tenants[tenantID] {
+		c.remove(c.entries[key])
+	}
+	return nil
+}
+
+// remove tira a entrada da lista, do mapa e do índice do tenant (com o lock)
+func (c *MemoryEntityCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*memoryEntity)
+	delete(c.entries, entry.key)
+	if keys := c.tenants[entry.tenantID]; keys != nil {
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(c.tenants, entry.tenantID)
+		}
+	}
+}
+
+// Nomes dos caches no label cache de arca_entity_cache_requests_total
+const (
+	entityCacheUser   = "user"
+	entityCacheTenant = "tenant"
+)
+
+func userCacheKey(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+func tenantCacheKey(id uuid.UUID) string {
+	return "tenant:" + id.String()
+}
+
+// EntityCacheObserver recebe o resultado (hit ou miss) de cada leitura do cache, para
+// métricas; name é entityCacheUser ou entityCacheTenant
+type EntityCacheObserver func(name string, hit bool)
+
+// cacheGet lê a entrada (JSON) e decodifica em dest. Falhas do cache contam como
+// miss: a leitura segue para o banco. Os helpers de cache não fazem nada com cache nil.
+func cacheGet(ctx context.Context, cache EntityCache, observe EntityCacheObserver, name, key string, dest interface{}) bool {
+	if cache == nil {
+		return false
+	}
+	value, ok, err := cache.Get(ctx, key)
+	if err == nil && ok {
+		err = json.Unmarshal(value, dest)
+	}
+	if err != nil {
+		logger.WithContext(ctx).WithError(err).WithField("key", key).Warn("entity cache read failed")
+		ok = false
+	}
+	if observe != nil {
+		observe(name, ok)
+	}
+	return ok
+}
+
+// cacheSet grava o valor codificado; uma falha só deixa a próxima leitura ir ao banco
+func cacheSet(ctx context.Context, cache EntityCache, tenantID uuid.UUID, key string, value interface{}) {
+	if cache == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = cache.Set(ctx, tenantID, key, data)
+	}
+	if err != nil {
+		logger.WithContext(ctx).WithError(err).WithField("key", key).Warn("entity cache write failed")
+	}
+}
+
+// cacheInvalidate remove a chave; se falhar, a entrada antiga vale até o TTL
+func cacheInvalidate(ctx context.Context, cache EntityCache, key string) {
+	if cache == nil {
+		return
+	}
+	if err := cache.Delete(ctx, key); err != nil {
+		logger.WithContext(ctx).WithError(err).WithField("key", key).Warn("entity cache invalidation failed")
+	}
+}
+
+// cacheInvalidateTenant remove as entradas do tenant; se falhar, elas valem até o TTL
+func cacheInvalidateTenant(ctx context.Context, cache EntityCache, tenantID uuid.UUID) {
+	if cache == nil {
+		return
+	}
+	if err := cache.DeleteTenant(ctx, tenantID); err != nil {
+		logger.WithContext(ctx).WithError(err).WithField("tenant_id", tenantID.String()).Warn("entity cache invalidation failed")
+	}
+}
+
+// =============================================================================
+// CACHED USER SERVICE
+// =============================================================================
+
+// CachedUserService UserService com GetByID servido pelo EntityCache. As
+// alterações do usuário feitas por ele (role, scopes, senha, exclusão, ...) removem
+// o usuário do cache; as feitas por outra instância valem após o TTL, ou
+// imediatamente com o cache no Redis.
+//
+// O cache guarda o JSON de models.User, sem o hash da senha nem a versão dos tokens:
+// os usuários lidos dele vêm sem esses campos. Quem verifica a senha ou emite tokens
+// usa GetWithCredentials, que sempre lê do banco.
+type CachedUserService struct {
+	*UserService
+	cache   EntityCache
+	observe EntityCacheObserver
+}
+
+// NewCachedUserService cria o UserService com cache (cache nil desabilita o cache);
+// observe recebe o resultado de cada leitura do cache e pode ser nil
+func NewCachedUserService(users *UserService, cache EntityCache, observe EntityCacheObserver) *CachedUserService {
+	return &CachedUserService{UserService: users, cache: cache, observe: observe}
+}
+
+func (s *CachedUserService) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	if cacheGet(ctx, s.cache, s.observe, entityCacheUser, userCacheKey(id), &user) {
+		return &user, nil
+	}
+
+	loaded, err := s.UserService.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cacheSet(ctx, s.cache, loaded.TenantID, userCacheKey(id), loaded)
+	return loaded, nil
+}
+
+func (s *CachedUserService) Update(ctx context.Context, user *models.User) error {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(user.ID))
+	return s.UserService.Update(ctx, user)
+}
+
+func (s *CachedUserService) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(id))
+	return s.UserService.UpdatePassword(ctx, id, passwordHash)
+}
+
+func (s *CachedUserService) TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(id))
+	return s.UserService.TouchLastLogin(ctx, id, at)
+}
+
+func (s *CachedUserService) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(id))
+	return s.UserService.VerifyEmail(ctx, id, email)
+}
+
+func (s *CachedUserService) BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(id))
+	return s.UserService.BumpTokenVersion(ctx, id)
+}
+
+func (s *CachedUserService) DeleteAccount(ctx context.Context, user *models.User, hard bool) error {
+	defer cacheInvalidate(ctx, s.cache, userCacheKey(user.ID))
+	return s.UserService.DeleteAccount(ctx, user, hard)
+}
+
+// =============================================================================
+// CACHED TENANT SERVICE
+// =============================================================================
+
+// CachedTenantService TenantService com GetByID servido pelo EntityCache. Qualquer
+// alteração do tenant feita por ele (status/suspensão, plano, quotas, settings,
+// exclusão) remove do cache o tenant e os usuários dele.
+type CachedTenantService struct {
+	*TenantService
+	cache   EntityCache
+	observe EntityCacheObserver
+}
+
+// NewCachedTenantService cria o TenantService com cache (cache nil desabilita o cache);
+// observe recebe o resultado de cada leitura do cache e pode ser nil
+func NewCachedTenantService(tenants *TenantService, cache EntityCache, observe EntityCacheObserver) *CachedTenantService {
+	return &CachedTenantService{TenantService: tenants, cache: cache, observe: observe}
+}
+
+func (s *CachedTenantService) GetByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if cacheGet(ctx, s.cache, s.observe, entityCacheTenant, tenantCacheKey(id), &tenant) {
+		return &tenant, nil
+	}
+
+	loaded, err := s.TenantService.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cacheSet(ctx, s.cache, id, tenantCacheKey(id), loaded)
+	return loaded, nil
+}
+
+func (s *CachedTenantService) Update(ctx context.Context, tenant *models.Tenant) error {
+	defer cacheInvalidateTenant(ctx, s.cache, tenant.ID)
+	return s.TenantService.Update(ctx, tenant)
+}
+
+func (s *CachedTenantService) UpdatePlan(ctx context.Context, id uuid.UUID, plan string) error {
+	defer cacheInvalidateTenant(ctx, s.cache, id)
+	return s.TenantService.UpdatePlan(ctx, id, plan)
+}
+
+func (s *CachedTenantService) UpdateQuotas(ctx context.Context, id uuid.UUID, quotas models.TenantQuotas) error {
+	defer cacheInvalidateTenant(ctx, s.cache, id)
+	return s.TenantService.UpdateQuotas(ctx, id, quotas)
+}
+
+func (s *CachedTenantService) UpdateSettings(ctx context.Context, id uuid.UUID, settings models.TenantSettings) error {
+	defer cacheInvalidateTenant(ctx, s.cache, id)
+	return s.TenantService.UpdateSettings(ctx, id, settings)
+}
+
+func (s *CachedTenantService) SetRateLimit(ctx context.Context, id uuid.UUID, limit int) error {
+	defer cacheInvalidateTenant(ctx, s.cache, id)
+	return s.TenantService.SetRateLimit(ctx, id, limit)
+}
+
+func (s *CachedTenantService) Delete(ctx context.Context, id uuid.UUID, hard bool) error {
+	defer cacheInvalidateTenant(ctx, s.cache, id)
+	return s.TenantService.Delete(ctx, id, hard)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// O cache de usuários não guarda o hash da senha nem a versão dos tokens; eles só
+// saem do banco, por GetWithCredentials
+func TestCachedUserServiceKeepsCredentialsOutOfTheCache(t *testing.T) {
+	id, tenantID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	db, backend := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		return resultRows([]driver.Value{
+			id.String(), tenantID.String(), "ana@acme.com", "$2a$10$hash", "Ana", "admin", "active",
+			[]byte("{}"), nil, int64(3), now, now,
+		}), nil
+	})
+
+	cache := NewMemoryEntityCache(10, time.Minute)
+	var lookups []bool
+	users := NewCachedUserService(NewUserService(db), cache, func(name string, hit bool) {
+		if name == entityCacheUser {
+			lookups = append(lookups, hit)
+		}
+	})
+	ctx := context.Background()
+
+	if _, err := users.GetByID(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := users.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lookups) != 2 || lookups[0] || !lookups[1] {
+		t.Fatalf("cache lookups = %v, want [miss hit]", lookups)
+	}
+	if cached.Email != "ana@acme.com" || cached.PasswordHash != "" || cached.TokenVersion != 0 {
+		t.Errorf("cached user = %+v, want no password hash or token version", cached)
+	}
+
+	raw, ok, _ := cache.Get(ctx, userCacheKey(id))
+	if !ok || strings.Contains(string(raw), "$2a$10$hash") {
+		t.Errorf("cache entry = %s", raw)
+	}
+
+	queries := len(backend.Calls())
+	user, err := users.GetWithCredentials(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.PasswordHash != "$2a$10$hash" || user.TokenVersion != 3 {
+		t.Errorf("user = %+v, want password hash and token version", user)
+	}
+	if len(backend.Calls()) != queries+1 {
+		t.Error("GetWithCredentials did not read from the database")
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/logger"
+	"github.com/lib/pq"
+)
+
+// =============================================================================
+// DB (PostgreSQL)
+// =============================================================================
+
+// DB pool do PostgreSQL usado pelos services. Cada consulta recebe um contexto filho
+// do contexto da chamada com o prazo de queryTimeout, para que uma consulta lenta
+// (ex.: esperando um lock) não segure a request e a conexão do pool até o timeout
+// de escrita do servidor. Consultas que excedem o prazo retornam ErrQueryTimeout.
+//
+// Com prepared statements habilitados (DB_PREPARE_STATEMENTS), as consultas
+// frequentes registradas pelos services na construção são preparadas uma vez e
+// reutilizadas; as demais seguem sem preparo.
+type DB struct {
+	pool              *sql.DB
+	queryTimeout      time.Duration
+	prepareStatements bool
+
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt
+}
+
+// NewDB cria o DB dos services sobre o pool; queryTimeout 0 desabilita o prazo por
+// consulta (vale apenas o contexto da chamada) e prepareStatements habilita os
+// prepared statements das consultas frequentes
+func NewDB(pool *sql.DB, queryTimeout time.Duration, prepareStatements bool) *DB {
+	return &DB{
+		pool:              pool,
+		queryTimeout:      queryTimeout,
+		prepareStatements: prepareStatements,
+		stmts:             make(map[string]*sql.Stmt),
+	}
+}
+
+// ExecContext executa um comando com o prazo de consulta
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if stmt := db.stmt(query); stmt != nil {
+		res, err := execContext(ctx, stmtConn{stmt}, db.queryTimeout, query, args...)
+		if !isStmtRejected(err) {
+			return res, err
+		}
+		db.dropStmt(query, stmt, err)
+	}
+	return execContext(ctx, db.pool, db.queryTimeout, query, args...)
+}
+
+// QueryContext executa uma consulta; o prazo termina em Rows.Close
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	if stmt := db.stmt(query); stmt != nil {
+		rows, err := queryContext(ctx, stmtConn{stmt}, db.queryTimeout, query, args...)
+		if !isStmtRejected(err) {
+			return rows, err
+		}
+		db.dropStmt(query, stmt, err)
+	}
+	return queryContext(ctx, db.pool, db.queryTimeout, query, args...)
+}
+
+// QueryRowContext executa uma consulta de uma linha; o prazo termina em Row.Scan
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	if stmt := db.stmt(query); stmt != nil {
+		row := queryRowContext(ctx, stmtConn{stmt}, db.queryTimeout, query, args...)
+		// O erro do statement só aparece no Scan
+		row.fallback = func(err error) *Row {
+			db.dropStmt(query, stmt, err)
+			return queryRowContext(ctx, db.pool, db.queryTimeout, query, args...)
+		}
+		return row
+	}
+	return queryRowContext(ctx, db.pool, db.queryTimeout, query, args...)
+}
+
+// prepare prepara as consultas frequentes de um service quando os prepared
+// statements estão habilitados. Uma consulta que o banco não aceita preparar (ex.:
+// PgBouncer em modo transaction) segue sem preparo.
+func (db *DB) prepare(queries ...string) {
+	if !db.prepareStatements {
+		return
+	}
+	for _, query := range queries {
+		if db.stmt(query) != nil {
+			continue
+		}
+		ctx, cancel := withQueryTimeout(context.Background(), db.queryTimeout)
+		stmt, err := db.pool.PrepareContext(ctx, query)
+		cancel()
+		if err != nil {
+			logger.WithError(err).WithField("query", query).Warn("failed to prepare statement; running it unprepared")
+			continue
+		}
+		db.mu.Lock()
+		db.stmts[query] = stmt
+		db.mu.Unlock()
+	}
+}
+
+// stmt retorna o prepared statement da consulta (nil se ela não foi preparada)
+func (db *DB) stmt(query string) *sql.Stmt {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.stmts[query]
+}
+
+// dropStmt descarta um prepared statement recusado pelo banco; a consulta passa a
+// ser executada sem preparo
+func (db *DB) dropStmt(query string, stmt *sql.Stmt, err error) {
+	db.mu.Lock()
+	if db.stmts[query] != stmt {
+		db.mu.Unlock()
+		return
+	}
+	delete(db.stmts, query)
+	db.mu.Unlock()
+
+	stmt.Close()
+	logger.WithError(err).WithField("query", query).Warn("prepared statement rejected by the database; running it unprepared")
+}
+
+// CloseStatements fecha os prepared statements (no shutdown, antes de fechar o pool).
+// Consultas feitas depois disso seguem sem preparo.
+func (db *DB) CloseStatements() error {
+	db.mu.Lock()
+	stmts := db.stmts
+	db.stmts = make(map[string]*sql.Stmt)
+	db.mu.Unlock()
+
+	var errs []error
+	for _, stmt := range stmts {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BeginTx inicia uma transação. A espera por uma conexão do pool tem o prazo de
+// consulta; a transação vive enquanto ctx e cada comando dela tem o próprio prazo.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	acquireCtx, cancel := withQueryTimeout(ctx, db.queryTimeout)
+	conn, err := db.pool.Conn(acquireCtx)
+	err = queryError(ctx, acquireCtx, err)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Tx{tx: tx, conn: conn, queryTimeout: db.queryTimeout}, nil
+}
+
+// Tx transação com o prazo de consulta em cada comando
+type Tx struct {
+	tx           *sql.Tx
+	conn         *sql.Conn
+	queryTimeout time.Duration
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, tx.tx, tx.queryTimeout, query, args...)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryContext(ctx, tx.tx, tx.queryTimeout, query, args...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return queryRowContext(ctx, tx.tx, tx.queryTimeout, query, args...)
+}
+
+// Commit confirma a transação e devolve a conexão ao pool
+func (tx *Tx) Commit() error {
+	defer tx.conn.Close()
+	return tx.tx.Commit()
+}
+
+// Rollback desfaz a transação (sql.ErrTxDone se já terminou) e devolve a conexão ao pool
+func (tx *Tx) Rollback() error {
+	defer tx.conn.Close()
+	return tx.tx.Rollback()
+}
+
+// Row resultado de QueryRowContext
+type Row struct {
+	row    *sql.Row
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	// fallback refaz a consulta sem preparo quando o prepared statement é recusado
+	fallback func(err error) *Row
+}
+
+// Scan copia as colunas da linha (sql.ErrNoRows se não houver linha) e encerra o prazo
+func (r *Row) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	if r.fallback != nil && isStmtRejected(err) {
+		r.cancel()
+		return r.fallback(err).Scan(dest...)
+	}
+	defer r.cancel()
+	return queryError(r.parent, r.ctx, err)
+}
+
+// Rows resultado de QueryContext; Close precisa ser chamado para encerrar o prazo
+type Rows struct {
+	*sql.Rows
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *Rows) Scan(dest ...interface{}) error {
+	return queryError(r.parent, r.ctx, r.Rows.Scan(dest...))
+}
+
+func (r *Rows) Err() error {
+	return queryError(r.parent, r.ctx, r.Rows.Err())
+}
+
+// Close fecha as linhas e encerra o prazo
+func (r *Rows) Close() error {
+	defer r.cancel()
+	return r.Rows.Close()
+}
+
+// sqlConn operações comuns a *sql.DB e *sql.Tx
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// stmtConn executa um prepared statement pela interface sqlConn; a consulta já
+// está no statement
+type stmtConn struct {
+	stmt *sql.Stmt
+}
+
+func (c stmtConn) ExecContext(ctx context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	return c.stmt.ExecContext(ctx, args...)
+}
+
+func (c stmtConn) QueryContext(ctx context.Context, _ string, args ...interface{}) (*sql.Rows, error) {
+	return c.stmt.QueryContext(ctx, args...)
+}
+
+func (c stmtConn) QueryRowContext(ctx context.Context, _ string, args ...interface{}) *sql.Row {
+	return c.stmt.QueryRowContext(ctx, args...)
+}
+
+func execContext(ctx context.Context, conn sqlConn, timeout time.Duration, query string, args ...interface{}) (sql.Result, error) {
+	queryCtx, cancel := withQueryTimeout(ctx, timeout)
+	defer cancel()
+	res, err := conn.ExecContext(queryCtx, query, args...)
+	return res, queryError(ctx, queryCtx, err)
+}
+
+func queryContext(ctx context.Context, conn sqlConn, timeout time.Duration, query string, args ...interface{}) (*Rows, error) {
+	queryCtx, cancel := withQueryTimeout(ctx, timeout)
+	rows, err := conn.QueryContext(queryCtx, query, args...)
+	if err != nil {
+		err = queryError(ctx, queryCtx, err)
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, parent: ctx, ctx: queryCtx, cancel: cancel}, nil
+}
+
+func queryRowContext(ctx context.Context, conn sqlConn, timeout time.Duration, query string, args ...interface{}) *Row {
+	queryCtx, cancel := withQueryTimeout(ctx, timeout)
+	return &Row{row: conn.QueryRowContext(queryCtx, query, args...), parent: ctx, ctx: queryCtx, cancel: cancel}
+}
+
+// withQueryTimeout deriva o contexto de uma consulta; timeout 0 só permite cancelá-lo
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// queryError converte o erro de uma consulta que excedeu o prazo (contexto da
+// consulta ou statement_timeout do PostgreSQL) em ErrQueryTimeout. Se o contexto
+// da chamada já terminou (ex.: cliente desconectou), o erro é mantido.
+func queryError(parent, queryCtx context.Context, err error) error {
+	if err == nil || parent.Err() != nil {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || queryCtx.Err() == context.DeadlineExceeded || isQueryCanceled(err) {
+		return fmt.Errorf("%w: %w", ErrQueryTimeout, err)
+	}
+	return err
+}
+
+// isQueryCanceled identifica o cancelamento do comando pelo servidor (query_canceled,
+// ex.: statement_timeout)
+func isQueryCanceled(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
+
+// isStmtRejected identifica um prepared statement que o banco não executa mais:
+// statement inexistente na conexão (invalid_sql_statement_name, ex.: atrás de um
+// pooler) ou plano em cache invalidado por mudança de schema (feature_not_supported)
+func isStmtRejected(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && (pqErr.Code == "26000" || pqErr.Code == "0A000")
+}
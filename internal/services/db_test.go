@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userRow responde às consultas de usuário por email com uma linha
+func userRow() fakeRespond {
+	id, tenantID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	return func(query string, args []driver.Value) (fakeRows, error) {
+		if !strings.Contains(query, "FROM users") {
+			return fakeRows{affected: 1}, nil
+		}
+		return resultRows([]driver.Value{
+			id.String(), tenantID.String(), "ana@acme.com", "hash", "Ana", "analyst", "active", "{}", nil, int64(1), now, now,
+		}), nil
+	}
+}
+
+// Com prepared statements, GetByEmail reutiliza o statement preparado na construção
+// do service; sem eles, nada é preparado. Depois de CloseStatements a consulta segue
+// sem preparo.
+func TestPreparedStatementsAreReused(t *testing.T) {
+	ctx := context.Background()
+	for _, prepare := range []bool{false, true} {
+		db, backend := newFakeDB(t, prepare, userRow())
+		users := NewUserService(db)
+		prepared := backend.Prepares()
+
+		for i := 0; i < 5; i++ {
+			if _, err := users.GetByEmail(ctx, "Ana@Acme.com", uuid.Nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		calls := backend.CallsMatching("FROM users WHERE lower(email)")
+		if len(calls) != 5 {
+			t.Fatalf("prepare=%v: got %d queries, want 5", prepare, len(calls))
+		}
+		for i, call := range calls {
+			if call.prepared != prepare {
+				t.Errorf("prepare=%v: query %d prepared = %v", prepare, i, call.prepared)
+			}
+		}
+		if prepare && (prepared == 0 || backend.Prepares() != prepared) {
+			t.Errorf("prepares = %d after construction, %d after queries; want the same nonzero count", prepared, backend.Prepares())
+		}
+		if !prepare && backend.Prepares() != 0 {
+			t.Errorf("prepares = %d, want 0 without prepared statements", backend.Prepares())
+		}
+
+		if err := db.CloseStatements(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := users.GetByEmail(ctx, "ana@acme.com", uuid.Nil); err != nil {
+			t.Fatal(err)
+		}
+		if calls := backend.CallsMatching("FROM users WHERE lower(email)"); calls[len(calls)-1].prepared {
+			t.Errorf("prepare=%v: query after CloseStatements still prepared", prepare)
+		}
+	}
+}
+
+// Compara GetByEmail com e sem prepared statements:
+// go test -run - -bench GetByEmail -benchmem ./internal/services
+func BenchmarkGetByEmail(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		prepare bool
+	}{
+		{"unprepared", false},
+		{"prepared", true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			db, _ := newFakeDB(b, bc.prepare, userRow())
+			users := NewUserService(db)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := users.GetByEmail(ctx, "ana@acme.com", uuid.Nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
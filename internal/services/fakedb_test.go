@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// FAKE DATABASE
+// =============================================================================
+
+// Driver database/sql em memória para testar os services sem PostgreSQL: cada
+// consulta é registrada e respondida pela função do teste.
+
+func init() {
+	sql.Register("services-fake", fakeDriver{})
+}
+
+// fakeBackends backend de cada DSN aberto por newFakeDB
+var fakeBackends sync.Map
+
+// fakeRows resultado de uma consulta: colunas, linhas e linhas afetadas (Exec)
+type fakeRows struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+}
+
+// fakeCall consulta recebida pelo driver
+type fakeCall struct {
+	query    string
+	args     []driver.Value
+	prepared bool
+}
+
+// fakeRespond responde uma consulta; o erro retornado chega ao service
+type fakeRespond func(query string, args []driver.Value) (fakeRows, error)
+
+type fakeBackend struct {
+	respond fakeRespond
+
+	mu       sync.Mutex
+	calls    []fakeCall
+	prepares int
+}
+
+// newFakeDB abre um DB dos services sobre o driver fake. respond nil responde toda
+// consulta sem linhas e com uma linha afetada.
+func newFakeDB(t testing.TB, prepareStatements bool, respond fakeRespond) (*DB, *fakeBackend) {
+	t.Helper()
+	if respond == nil {
+		respond = func(string, []driver.Value) (fakeRows, error) { return fakeRows{affected: 1}, nil }
+	}
+	backend := &fakeBackend{respond: respond}
+	dsn := uuid.NewString()
+	fakeBackends.Store(dsn, backend)
+
+	pool, err := sql.Open("services-fake", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pool.Close()
+		fakeBackends.Delete(dsn)
+	})
+	return NewDB(pool, 0, prepareStatements), backend
+}
+
+// Calls consultas recebidas (exceto BEGIN/COMMIT/ROLLBACK)
+func (b *fakeBackend) Calls() []fakeCall {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]fakeCall(nil), b.calls...)
+}
+
+// CallsMatching consultas recebidas que contêm fragment
+func (b *fakeBackend) CallsMatching(fragment string) []fakeCall {
+	var calls []fakeCall
+	for _, call := range b.Calls() {
+		if strings.Contains(call.query, fragment) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Prepares statements preparados no banco
+func (b *fakeBackend) Prepares() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.prepares
+}
+
+func (b *fakeBackend) run(query string, args []driver.NamedValue, prepared bool) (fakeRows, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	b.mu.Lock()
+	b.calls = append(b.calls, fakeCall{query: query, args: values, prepared: prepared})
+	b.mu.Unlock()
+	return b.respond(query, values)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	backend, ok := fakeBackends.Load(dsn)
+	if !ok {
+		return nil, errors.New("fake: unknown dsn")
+	}
+	return &fakeConn{backend: backend.(*fakeBackend)}, nil
+}
+
+type fakeConn struct {
+	backend *fakeBackend
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.backend.mu.Lock()
+	c.backend.prepares++
+	c.backend.mu.Unlock()
+	return &fakeStmt{backend: c.backend, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := c.backend.run(query, args, false)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(rows.affected), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.backend.run(query, args, false)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeCursor{rows: rows}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	backend *fakeBackend
+	query   string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("fake: use ExecContext")
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("fake: use QueryContext")
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	rows, err := s.backend.run(s.query, args, true)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(rows.affected), nil
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.backend.run(s.query, args, true)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeCursor{rows: rows}, nil
+}
+
+type fakeCursor struct {
+	rows fakeRows
+	next int
+}
+
+func (r *fakeCursor) Columns() []string { return r.rows.columns }
+func (r *fakeCursor) Close() error      { return nil }
+
+func (r *fakeCursor) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows.rows[r.next])
+	r.next++
+	return nil
+}
+
+// resultRows monta o resultado de uma consulta com as linhas informadas
+func resultRows(rows ...[]driver.Value) fakeRows {
+	result := fakeRows{rows: rows}
+	if len(rows) > 0 {
+		result.columns = make([]string, len(rows[0]))
+		for i := range result.columns {
+			result.columns[i] = "col" + strconv.Itoa(i)
+		}
+	}
+	return result
+}
@@ -3,39 +3,130 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/arcaintelligence/arca-gateway/internal/models"
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 var (
 	ErrNotFound      = errors.New("resource not found")
 	ErrAlreadyExists = errors.New("resource already exists")
 	ErrForbidden     = errors.New("access forbidden")
+	// ErrAmbiguousEmail indica que um email sem tenant corresponde a usuários de mais de um tenant
+	ErrAmbiguousEmail = errors.New("email belongs to multiple tenants")
+	// ErrQuotaExceeded indica que a operação ultrapassaria uma quota do tenant
+	ErrQuotaExceeded = errors.New("tenant quota exceeded")
+	// ErrStaleVersion indica que o recurso foi alterado depois da versão informada na atualização
+	ErrStaleVersion = errors.New("resource was modified since the expected version")
+	// ErrJobFinished indica que o job assíncrono já foi concluído
+	ErrJobFinished = errors.New("job already finished")
+	// ErrLastAdmin indica que a operação deixaria o tenant sem nenhum admin ativo
+	ErrLastAdmin = errors.New("user is the last admin of the tenant")
+	// ErrQueryTimeout indica que a consulta excedeu o prazo (DB_QUERY_TIMEOUT)
+	ErrQueryTimeout = errors.New("database query timed out")
 )
 
+// pageOffset calcula o OFFSET da página (1-based): page ou perPage menores que 1
+// resultam em 0 e páginas enormes são limitadas em vez de estourar o int
+func pageOffset(page, perPage int) int {
+	if page < 1 || perPage < 1 {
+		return 0
+	}
+	if page-1 > math.MaxInt/perPage {
+		return math.MaxInt
+	}
+	return (page - 1) * perPage
+}
+
+// Keyset posição na paginação por cursor: created_at e id do último item já
+// entregue. O valor zero começa pela primeira página.
+type Keyset struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// IsZero indica a primeira página
+func (k Keyset) IsZero() bool {
+	return k.ID == uuid.Nil
+}
+
+// keysetQuery completa a consulta de uma página por cursor (created_at DESC, id DESC)
+// com a posição after; pede limit+1 linhas para saber se há uma próxima página
+func keysetQuery(query string, args []interface{}, after Keyset, limit int) (string, []interface{}) {
+	if !after.IsZero() {
+		args = append(args, after.CreatedAt, after.ID)
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+	return query, args
+}
+
+// dbNow retorna o horário atual na precisão do PostgreSQL (microssegundos), para que o
+// updated_at devolvido na resposta seja exatamente o gravado e possa voltar como versão
+func dbNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// scopesToDB converte scopes para a coluna TEXT[] NOT NULL (nil vira '{}')
+func scopesToDB(scopes []models.Scope) pq.StringArray {
+	values := make(pq.StringArray, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return values
+}
+
+// scopesFromDB converte a coluna TEXT[]; vazio (scopes padrão do role) vira nil
+func scopesFromDB(values pq.StringArray) []models.Scope {
+	if len(values) == 0 {
+		return nil
+	}
+	scopes := make([]models.Scope, len(values))
+	for i, value := range values {
+		scopes[i] = models.Scope(value)
+	}
+	return scopes
+}
+
 // =============================================================================
 // USER SERVICE (PostgreSQL)
 // =============================================================================
 
 type UserService struct {
-	db *sql.DB
+	db *DB
 }
 
-func NewUserService(db *sql.DB) *UserService {
+// Consultas frequentes do UserService, preparadas com DB_PREPARE_STATEMENTS. As de
+// GetByEmail param na segunda linha, que já basta para detectar ambiguidade; as de
+// ListByEmail trazem todas as contas, em ordem estável.
+const (
+	userByEmailQuery          = `SELECT id, tenant_id, email, password_hash, name, role, status, scopes, last_login_at, token_version, created_at, updated_at FROM users WHERE lower(email) = $1 LIMIT 2`
+	userByEmailInTenantQuery  = `SELECT id, tenant_id, email, password_hash, name, role, status, scopes, last_login_at, token_version, created_at, updated_at FROM users WHERE lower(email) = $1 AND tenant_id = $2 LIMIT 2`
+	usersByEmailQuery         = `SELECT id, tenant_id, email, password_hash, name, role, status, scopes, last_login_at, token_version, created_at, updated_at FROM users WHERE lower(email) = $1 ORDER BY created_at, id`
+	usersByEmailInTenantQuery = `SELECT id, tenant_id, email, password_hash, name, role, status, scopes, last_login_at, token_version, created_at, updated_at FROM users WHERE lower(email) = $1 AND tenant_id = $2 ORDER BY created_at, id`
+)
+
+func NewUserService(db *DB) *UserService {
+	db.prepare(userByEmailQuery, userByEmailInTenantQuery, usersByEmailQuery, usersByEmailInTenantQuery)
 	return &UserService{db: db}
 }
 
 func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := `SELECT id, tenant_id, email, password_hash, name, role, status, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT id, tenant_id, email, password_hash, name, role, status, scopes, last_login_at, token_version, created_at, updated_at FROM users WHERE id = $1`
 	
 	var user models.User
+	var scopes pq.StringArray
+	var lastLoginAt sql.NullTime
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID, &user.TenantID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.TenantID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Status, &scopes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -44,37 +135,100 @@ func (s *UserService) GetByID(ctx context.Context, id uuid.UUID) (*models.User,
 	if err != nil {
 		return nil, err
 	}
+	user.Scopes = scopesFromDB(scopes)
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
 	return &user, nil
 }
 
-func (s *UserService) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, tenant_id, email, password_hash, name, role, status, created_at, updated_at FROM users WHERE email = $1`
-	
-	var user models.User
-	err := s.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID, &user.TenantID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Status, &user.CreatedAt, &user.UpdatedAt,
-	)
-	
-	if err == sql.ErrNoRows {
+// GetWithCredentials retorna o usuário com o hash da senha e a versão dos tokens,
+// sempre lido do banco: CachedUserService serve GetByID do cache, sem esses campos
+func (s *UserService) GetWithCredentials(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return s.GetByID(ctx, id)
+}
+
+// GetByEmail busca o usuário pelo email (comparado na forma normalizada). Com tenantID informado a busca é restrita ao tenant;
+// com uuid.Nil a busca é global e retorna ErrAmbiguousEmail se o email existir em mais de um tenant.
+func (s *UserService) GetByEmail(ctx context.Context, email string, tenantID uuid.UUID) (*models.User, error) {
+	users, err := s.queryByEmail(ctx, userByEmailQuery, userByEmailInTenantQuery, email, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(users) {
+	case 0:
 		return nil, ErrNotFound
+	case 1:
+		return users[0], nil
+	default:
+		return nil, ErrAmbiguousEmail
+	}
+}
+
+// ListByEmail retorna os usuários com o email (comparado na forma normalizada), de todos os
+// tenants com uuid.Nil ou apenas do tenant informado, do mais antigo ao mais recente.
+// Vazio se não houver nenhum.
+func (s *UserService) ListByEmail(ctx context.Context, email string, tenantID uuid.UUID) ([]*models.User, error) {
+	return s.queryByEmail(ctx, usersByEmailQuery, usersByEmailInTenantQuery, email, tenantID)
+}
+
+// queryByEmail executa global ou inTenant (conforme tenantID) com o email normalizado
+func (s *UserService) queryByEmail(ctx context.Context, global, inTenant, email string, tenantID uuid.UUID) ([]*models.User, error) {
+	query := global
+	args := []interface{}{models.NormalizeEmail(email)}
+	if tenantID != uuid.Nil {
+		query = inTenant
+		args = append(args, tenantID)
 	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		var user models.User
+		var scopes pq.StringArray
+		var lastLoginAt sql.NullTime
+		if err := rows.Scan(
+			&user.ID, &user.TenantID, &user.Email, &user.PasswordHash, &user.Name, &user.Role, &user.Status, &scopes, &lastLoginAt, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		user.Scopes = scopesFromDB(scopes)
+		if lastLoginAt.Valid {
+			user.LastLoginAt = &lastLoginAt.Time
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
 }
 
+// Create persiste um usuário. Retorna ErrAlreadyExists se o email já existir no tenant.
 func (s *UserService) Create(ctx context.Context, user *models.User) error {
-	query := `INSERT INTO users (id, tenant_id, email, password_hash, name, role, status, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	query := `INSERT INTO users (id, tenant_id, email, password_hash, name, role, status, scopes, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			  ON CONFLICT (tenant_id, email) DO NOTHING`
 	
-	_, err := s.db.ExecContext(ctx, query,
-		user.ID, user.TenantID, user.Email, user.PasswordHash, user.Name, user.Role, user.Status, user.CreatedAt, user.UpdatedAt,
+	user.Email = models.NormalizeEmail(user.Email)
+	res, err := s.db.ExecContext(ctx, query,
+		user.ID, user.TenantID, user.Email, user.PasswordHash, user.Name, user.Role, user.Status, scopesToDB(user.Scopes), user.CreatedAt, user.UpdatedAt,
 	)
 	
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAlreadyExists
+	}
 	return nil
 }
 
@@ -85,18 +239,32 @@ func (s *UserService) CreateWithTenant(ctx context.Context, tenant *models.Tenan
 	}
 	defer tx.Rollback()
 
+	settings, err := json.Marshal(tenant.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant settings: %w", err)
+	}
+	quotas, err := json.Marshal(tenant.Quotas)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant quotas: %w", err)
+	}
+
+	tenant.Email = models.NormalizeEmail(tenant.Email)
+	user.Email = models.NormalizeEmail(user.Email)
+
 	// Create Tenant
-	queryTenant := `INSERT INTO tenants (id, name, plan, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err = tx.ExecContext(ctx, queryTenant, tenant.ID, tenant.Name, tenant.Plan, tenant.Status, tenant.CreatedAt, tenant.UpdatedAt)
+	queryTenant := `INSERT INTO tenants (id, name, slug, email, plan, status, settings, quotas, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err = tx.ExecContext(ctx, queryTenant,
+		tenant.ID, tenant.Name, tenant.Slug, tenant.Email, tenant.Plan, tenant.Status, settings, quotas, tenant.CreatedAt, tenant.UpdatedAt,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to create tenant: %w", err)
 	}
 
 	// Create User
-	queryUser := `INSERT INTO users (id, tenant_id, email, password_hash, name, role, status, created_at, updated_at) 
-				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	queryUser := `INSERT INTO users (id, tenant_id, email, password_hash, name, role, status, scopes, created_at, updated_at) 
+				  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 	_, err = tx.ExecContext(ctx, queryUser,
-		user.ID, user.TenantID, user.Email, user.PasswordHash, user.Name, user.Role, user.Status, user.CreatedAt, user.UpdatedAt,
+		user.ID, user.TenantID, user.Email, user.PasswordHash, user.Name, user.Role, user.Status, scopesToDB(user.Scopes), user.CreatedAt, user.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -105,10 +273,14 @@ func (s *UserService) CreateWithTenant(ctx context.Context, tenant *models.Tenan
 	return tx.Commit()
 }
 
+// Update altera nome, role, status e scopes explícitos do usuário
 func (s *UserService) Update(ctx context.Context, user *models.User) error {
-	query := `UPDATE users SET name = $1, role = $2, status = $3, updated_at = $4 WHERE id = $5`
+	// Reativar o usuário cancela a exclusão lógica (e a remoção após a retenção)
+	query := `UPDATE users SET name = $1, role = $2, status = $3, scopes = $4, updated_at = $5,
+			  deleted_at = CASE WHEN $3 = 'active' THEN NULL ELSE deleted_at END WHERE id = $6`
 	
-	res, err := s.db.ExecContext(ctx, query, user.Name, user.Role, user.Status, time.Now(), user.ID)
+	user.UpdatedAt = dbNow()
+	res, err := s.db.ExecContext(ctx, query, user.Name, user.Role, user.Status, scopesToDB(user.Scopes), user.UpdatedAt, user.ID)
 	if err != nil {
 		return err
 	}
@@ -123,87 +295,116 @@ func (s *UserService) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
-// =============================================================================
-// CLIENT SERVICE (PostgreSQL)
-// =============================================================================
+// TouchLastLogin registra o horário do último login bem-sucedido do usuário
+func (s *UserService) TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	query := `UPDATE users SET last_login_at = $1 WHERE id = $2`
 
-type ClientService struct {
-	db *sql.DB
+	res, err := s.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-func NewClientService(db *sql.DB) *ClientService {
-	return &ClientService{db: db}
+// VerifyEmail ativa um usuário pendente cujo email confere com o verificado.
+// Retorna ErrNotFound se não houver usuário pendente com esse id e email.
+func (s *UserService) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	query := `UPDATE users SET status = $1, updated_at = $2 WHERE id = $3 AND email = $4 AND status = $5`
+
+	res, err := s.db.ExecContext(ctx, query, models.StatusActive, time.Now(), id, email, models.StatusPending)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
-func (s *ClientService) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Client, error) {
-	query := `SELECT id, tenant_id, name, industry, status, created_at, updated_at FROM clients WHERE id = $1 AND tenant_id = $2`
-	
-	var client models.Client
-	err := s.db.QueryRowContext(ctx, query, id, tenantID).Scan(
-		&client.ID, &client.TenantID, &client.Name, &client.Industry, &client.Status, &client.CreatedAt, &client.UpdatedAt,
-	)
-	
+// TokenVersion retorna a versão atual dos tokens do usuário (auth.TokenVersionStore)
+func (s *UserService) TokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT token_version FROM users WHERE id = $1`, id).Scan(&version)
 	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
+		return 0, ErrNotFound
 	}
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	return &client, nil
+	return version, nil
 }
 
-func (s *ClientService) ListByTenant(ctx context.Context, tenantID uuid.UUID, page, perPage int) ([]*models.Client, int64, error) {
-	offset := (page - 1) * perPage
-	
-	// Count total
-	var total int64
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clients WHERE tenant_id = $1`, tenantID).Scan(&total)
+// BumpTokenVersion incrementa a versão dos tokens do usuário, invalidando todos os tokens já emitidos
+func (s *UserService) BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `UPDATE users SET token_version = token_version + 1, updated_at = $1 WHERE id = $2 RETURNING token_version`
+
+	var version int
+	err := s.db.QueryRowContext(ctx, query, time.Now(), id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
 	if err != nil {
-		return nil, 0, err
+		return 0, err
 	}
-	
-	// List items
-	query := `SELECT id, tenant_id, name, industry, status, created_at, updated_at 
-			  FROM clients WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
-	
-	rows, err := s.db.QueryContext(ctx, query, tenantID, perPage, offset)
+	return version, nil
+}
+
+// DeleteAccount remove a conta do usuário. Com hard=false o usuário é desativado e
+// seus tokens invalidados (token_version); com hard=true ele é apagado, junto com o
+// histórico de login, e os registros de auditoria perdem a referência a ele. Retorna
+// ErrLastAdmin se ele for o último admin ativo do tenant.
+func (s *UserService) DeleteAccount(ctx context.Context, user *models.User, hard bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
-	defer rows.Close()
-	
-	var clients []*models.Client
-	for rows.Next() {
-		var c models.Client
-		if err := rows.Scan(&c.ID, &c.TenantID, &c.Name, &c.Industry, &c.Status, &c.CreatedAt, &c.UpdatedAt); err != nil {
-			return nil, 0, err
-		}
-		clients = append(clients, &c)
+	defer tx.Rollback()
+
+	// O lock no tenant serializa remoções concorrentes de admins do mesmo tenant
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM tenants WHERE id = $1 FOR UPDATE`, user.TenantID); err != nil {
+		return err
 	}
-	
-	return clients, total, nil
-}
 
-func (s *ClientService) Create(ctx context.Context, client *models.Client) error {
-	query := `INSERT INTO clients (id, tenant_id, name, industry, status, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	
-	_, err := s.db.ExecContext(ctx, query,
-		client.ID, client.TenantID, client.Name, client.Industry, client.Status, client.CreatedAt, client.UpdatedAt,
-	)
-	return err
-}
+	if user.Role == models.RoleAdmin {
+		var others int
+		err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM users WHERE tenant_id = $1 AND id <> $2 AND role = $3 AND status = $4`,
+			user.TenantID, user.ID, models.RoleAdmin, models.StatusActive,
+		).Scan(&others)
+		if err != nil {
+			return err
+		}
+		if others == 0 {
+			return ErrLastAdmin
+		}
+	}
 
-func (s *ClientService) Update(ctx context.Context, client *models.Client) error {
-	query := `UPDATE clients SET name = $1, industry = $2, status = $3, updated_at = $4 WHERE id = $5 AND tenant_id = $6`
-	
-	res, err := s.db.ExecContext(ctx, query,
-		client.Name, client.Industry, client.Status, time.Now(), client.ID, client.TenantID,
-	)
+	var res sql.Result
+	if hard {
+		res, err = eraseUser(ctx, tx, user.ID)
+	} else {
+		res, err = tx.ExecContext(ctx,
+			`UPDATE users SET status = $1, token_version = token_version + 1, updated_at = $2, deleted_at = $2 WHERE id = $3`,
+			models.StatusInactive, time.Now(), user.ID,
+		)
+	}
 	if err != nil {
 		return err
 	}
-	
+
 	rows, err := res.RowsAffected()
 	if err != nil {
 		return err
@@ -211,17 +412,80 @@ func (s *ClientService) Update(ctx context.Context, client *models.Client) error
 	if rows == 0 {
 		return ErrNotFound
 	}
-	return nil
+	return tx.Commit()
 }
 
-func (s *ClientService) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
-	query := `DELETE FROM clients WHERE id = $1 AND tenant_id = $2`
-	
-	res, err := s.db.ExecContext(ctx, query, id, tenantID)
+// PurgeDeleted apaga os usuários excluídos logicamente antes de before (como na
+// exclusão com hard=true) e retorna quantos foram apagados
+func (s *UserService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM users WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return purged, err
+		}
+		if _, err := eraseUser(ctx, tx, id); err != nil {
+			tx.Rollback()
+			return purged, fmt.Errorf("failed to purge user %s: %w", id, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// eraseUser apaga o usuário e seu histórico de login e remove a referência a ele
+// dos registros de auditoria
+func eraseUser(ctx context.Context, tx *Tx, id uuid.UUID) (sql.Result, error) {
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_logs SET user_id = NULL WHERE user_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to anonymize audit logs: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM login_events WHERE user_id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to delete login events: %w", err)
+	}
+	return tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+}
+
+// rowScanner linha de Row ou Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanIDs lê uma coluna de UUIDs e fecha rows
+func scanIDs(rows *Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// UpdatePassword grava o novo hash de senha do usuário
+func (s *UserService) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $1, updated_at = $2 WHERE id = $3`
+
+	res, err := s.db.ExecContext(ctx, query, passwordHash, time.Now(), id)
 	if err != nil {
 		return err
 	}
-	
+
 	rows, err := res.RowsAffected()
 	if err != nil {
 		return err
@@ -233,89 +497,177 @@ func (s *ClientService) Delete(ctx context.Context, id, tenantID uuid.UUID) erro
 }
 
 // =============================================================================
-// BRAND SERVICE (PostgreSQL)
+// CLIENT SERVICE (PostgreSQL)
 // =============================================================================
 
-type BrandService struct {
-	db *sql.DB
+type ClientService struct {
+	db *DB
 }
 
-func NewBrandService(db *sql.DB) *BrandService {
-	return &BrandService{db: db}
+// clientColumns colunas lidas por scanClient
+const clientColumns = `id, tenant_id, name, COALESCE(slug, ''), COALESCE(description, ''), COALESCE(industry, ''), status, settings, created_at, updated_at`
+
+// Consultas frequentes do ClientService, preparadas com DB_PREPARE_STATEMENTS
+const (
+	clientCountByTenantQuery = `SELECT COUNT(*) FROM clients WHERE tenant_id = $1`
+	clientsByTenantQuery     = `SELECT ` + clientColumns + ` 
+			  FROM clients WHERE tenant_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+)
+
+func NewClientService(db *DB) *ClientService {
+	db.prepare(clientCountByTenantQuery, clientsByTenantQuery)
+	return &ClientService{db: db}
 }
 
-func (s *BrandService) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Brand, error) {
-	query := `SELECT id, tenant_id, client_id, name, domain, industry, monitoring_enabled, created_at, updated_at 
-			  FROM brands WHERE id = $1 AND tenant_id = $2`
-	
-	var brand models.Brand
-	err := s.db.QueryRowContext(ctx, query, id, tenantID).Scan(
-		&brand.ID, &brand.TenantID, &brand.ClientID, &brand.Name, &brand.PrimaryDomain, &brand.Industry, &brand.MonitoringEnabled, &brand.CreatedAt, &brand.UpdatedAt,
-	)
+func (s *ClientService) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Client, error) {
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE id = $1 AND tenant_id = $2`
 	
+	client, err := scanClient(s.db.QueryRowContext(ctx, query, id, tenantID))
 	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &brand, nil
+	return client, nil
 }
 
-func (s *BrandService) ListByClient(ctx context.Context, clientID, tenantID uuid.UUID, page, perPage int) ([]*models.Brand, int64, error) {
-	offset := (page - 1) * perPage
+// scanClient lê um cliente (clientColumns), decodificando settings
+func scanClient(row rowScanner) (*models.Client, error) {
+	var client models.Client
+	var rawSettings []byte
+	err := row.Scan(
+		&client.ID, &client.TenantID, &client.Name, &client.Slug, &client.Description, &client.Industry, &client.Status, &rawSettings, &client.CreatedAt, &client.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawSettings) > 0 {
+		if err := json.Unmarshal(rawSettings, &client.Settings); err != nil {
+			return nil, fmt.Errorf("failed to decode client settings: %w", err)
+		}
+	}
+	return &client, nil
+}
+
+// BelongsToTenant verifica se o cliente existe e pertence ao tenant
+func (s *ClientService) BelongsToTenant(ctx context.Context, id, tenantID uuid.UUID) (bool, error) {
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM clients WHERE id = $1 AND tenant_id = $2)`, id, tenantID).Scan(&exists)
+	return exists, err
+}
+
+func (s *ClientService) ListByTenant(ctx context.Context, tenantID uuid.UUID, page, perPage int) ([]*models.Client, int64, error) {
+	offset := pageOffset(page, perPage)
 	
 	// Count total
 	var total int64
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM brands WHERE client_id = $1 AND tenant_id = $2`, clientID, tenantID).Scan(&total)
+	err := s.db.QueryRowContext(ctx, clientCountByTenantQuery, tenantID).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 	
 	// List items
-	query := `SELECT id, tenant_id, client_id, name, domain, industry, monitoring_enabled, created_at, updated_at 
-			  FROM brands WHERE client_id = $1 AND tenant_id = $2 ORDER BY created_at DESC LIMIT $3 OFFSET $4`
-	
-	rows, err := s.db.QueryContext(ctx, query, clientID, tenantID, perPage, offset)
+	rows, err := s.db.QueryContext(ctx, clientsByTenantQuery, tenantID, perPage, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 	
-	var brands []*models.Brand
+	var clients []*models.Client
 	for rows.Next() {
-		var b models.Brand
-		if err := rows.Scan(&b.ID, &b.TenantID, &b.ClientID, &b.Name, &b.PrimaryDomain, &b.Industry, &b.MonitoringEnabled, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		client, err := scanClient(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		brands = append(brands, &b)
+		clients = append(clients, client)
 	}
 	
-	return brands, total, nil
+	return clients, total, nil
 }
 
-func (s *BrandService) CountByClient(ctx context.Context, clientID uuid.UUID) (int, error) {
-	var count int
-	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM brands WHERE client_id = $1`, clientID).Scan(&count)
-	return count, err
+// ListByTenantAfter lista uma página de clientes do tenant a partir da posição after
+// (mais recentes primeiro, id como desempate); more indica que há clientes depois da
+// página. Sem COUNT e OFFSET, o custo não cresce com a profundidade da listagem.
+func (s *ClientService) ListByTenantAfter(ctx context.Context, tenantID uuid.UUID, after Keyset, limit int) ([]*models.Client, bool, error) {
+	query, args := keysetQuery(`SELECT `+clientColumns+` FROM clients WHERE tenant_id = $1`, []interface{}{tenantID}, after, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var clients []*models.Client
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(clients) > limit {
+		return clients[:limit], true, nil
+	}
+	return clients, false, nil
 }
 
-func (s *BrandService) Create(ctx context.Context, brand *models.Brand) error {
-	query := `INSERT INTO brands (id, tenant_id, client_id, name, domain, industry, monitoring_enabled, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+// Each percorre todos os clientes do tenant (mais recentes primeiro) sem carregá-los em memória
+func (s *ClientService) Each(ctx context.Context, tenantID uuid.UUID, fn func(*models.Client) error) error {
+	query := `SELECT ` + clientColumns + ` FROM clients WHERE tenant_id = $1 ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		client, err := scanClient(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *ClientService) Create(ctx context.Context, client *models.Client) error {
+	settings, err := json.Marshal(client.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode client settings: %w", err)
+	}
+
+	query := `INSERT INTO clients (id, tenant_id, name, slug, description, industry, status, settings, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 	
-	_, err := s.db.ExecContext(ctx, query,
-		brand.ID, brand.TenantID, brand.ClientID, brand.Name, brand.PrimaryDomain, brand.Industry, brand.MonitoringEnabled, brand.CreatedAt, brand.UpdatedAt,
+	_, err = s.db.ExecContext(ctx, query,
+		client.ID, client.TenantID, client.Name, client.Slug, client.Description, client.Industry, client.Status, settings, client.CreatedAt, client.UpdatedAt,
 	)
 	return err
 }
 
-func (s *BrandService) Update(ctx context.Context, brand *models.Brand) error {
-	query := `UPDATE brands SET name = $1, domain = $2, industry = $3, monitoring_enabled = $4, updated_at = $5 
-			  WHERE id = $6 AND tenant_id = $7`
+// Update persiste o cliente. Com unmodifiedSince, a escrita só acontece se o updated_at
+// atual não for posterior a ele (concorrência otimista); caso contrário retorna ErrStaleVersion.
+// Em caso de sucesso client.UpdatedAt recebe o valor gravado.
+func (s *ClientService) Update(ctx context.Context, client *models.Client, unmodifiedSince *time.Time) error {
+	settings, err := json.Marshal(client.Settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode client settings: %w", err)
+	}
+
+	query := `UPDATE clients SET name = $1, slug = $2, description = $3, industry = $4, status = $5, settings = $6, updated_at = $7 
+			  WHERE id = $8 AND tenant_id = $9 AND ($10::timestamptz IS NULL OR updated_at <= $10)`
 	
+	now := dbNow()
 	res, err := s.db.ExecContext(ctx, query,
-		brand.Name, brand.PrimaryDomain, brand.Industry, brand.MonitoringEnabled, time.Now(), brand.ID, brand.TenantID,
+		client.Name, client.Slug, client.Description, client.Industry, client.Status, settings, now, client.ID, client.TenantID, unmodifiedSince,
 	)
 	if err != nil {
 		return err
@@ -326,11 +678,446 @@ func (s *BrandService) Update(ctx context.Context, brand *models.Brand) error {
 		return err
 	}
 	if rows == 0 {
-		return ErrNotFound
+		return s.updateMiss(ctx, client, unmodifiedSince)
 	}
+	client.UpdatedAt = now
 	return nil
 }
 
+// UpdateMonitoring persiste o estado de monitoramento da marca (status, job, último
+// scan e ameaças encontradas). Em caso de sucesso brand.UpdatedAt recebe o valor gravado.
+func (s *BrandService) UpdateMonitoring(ctx context.Context, brand *models.Brand) error {
+	query := `UPDATE brands SET status = $1, monitoring_job_id = $2, last_scan_at = $3, threats_found = $4, updated_at = $5 
+			  WHERE id = $6 AND tenant_id = $7`
+
+	now := dbNow()
+	res, err := s.db.ExecContext(ctx, query,
+		brand.Status, brand.MonitoringJobID, brand.LastScanAt, brand.ThreatsFound, now, brand.ID, brand.TenantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	brand.UpdatedAt = now
+	return nil
+}
+
+// updateMiss distingue cliente inexistente de versão desatualizada quando o UPDATE não afeta linhas
+func (s *ClientService) updateMiss(ctx context.Context, client *models.Client, unmodifiedSince *time.Time) error {
+	if unmodifiedSince == nil {
+		return ErrNotFound
+	}
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM clients WHERE id = $1 AND tenant_id = $2)`,
+		client.ID, client.TenantID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrStaleVersion
+	}
+	return ErrNotFound
+}
+
+// SlugExists verifica se o slug já é usado por outro cliente do tenant (excludeID ignora o próprio cliente na edição)
+func (s *ClientService) SlugExists(ctx context.Context, tenantID uuid.UUID, slug string, excludeID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM clients WHERE tenant_id = $1 AND slug = $2 AND id <> $3)`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, tenantID, slug, excludeID).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (s *ClientService) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	query := `DELETE FROM clients WHERE id = $1 AND tenant_id = $2`
+	
+	res, err := s.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		return err
+	}
+	
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// =============================================================================
+// BRAND SERVICE (PostgreSQL)
+// =============================================================================
+
+type BrandService struct {
+	db *DB
+}
+
+// Consultas frequentes do BrandService (contagem de marcas na listagem e na leitura
+// de clientes), preparadas com DB_PREPARE_STATEMENTS
+const (
+	brandCountByClientQuery  = `SELECT COUNT(*) FROM brands WHERE client_id = $1`
+	brandCountByClientsQuery = `SELECT client_id, COUNT(*) FROM brands WHERE client_id = ANY($1::uuid[]) GROUP BY client_id`
+)
+
+// brandColumns colunas lidas por scanBrand
+const brandColumns = `id, tenant_id, client_id, name, domain, COALESCE(industry, ''), monitoring_enabled, status, config, monitoring_job_id, last_scan_at, threats_found, created_at, updated_at`
+
+func NewBrandService(db *DB) *BrandService {
+	db.prepare(brandCountByClientQuery, brandCountByClientsQuery)
+	return &BrandService{db: db}
+}
+
+func (s *BrandService) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Brand, error) {
+	query := `SELECT ` + brandColumns + ` FROM brands WHERE id = $1 AND tenant_id = $2`
+	
+	brand, err := scanBrand(s.db.QueryRowContext(ctx, query, id, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+// scanBrand lê uma marca (brandColumns), decodificando config
+func scanBrand(row rowScanner) (*models.Brand, error) {
+	var brand models.Brand
+	var rawConfig []byte
+	err := row.Scan(
+		&brand.ID, &brand.TenantID, &brand.ClientID, &brand.Name, &brand.PrimaryDomain, &brand.Industry, &brand.MonitoringEnabled,
+		&brand.Status, &rawConfig, &brand.MonitoringJobID, &brand.LastScanAt, &brand.ThreatsFound, &brand.CreatedAt, &brand.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &brand.Config); err != nil {
+			return nil, fmt.Errorf("failed to decode brand config: %w", err)
+		}
+	}
+	return &brand, nil
+}
+
+func (s *BrandService) ListByClient(ctx context.Context, clientID, tenantID uuid.UUID, page, perPage int) ([]*models.Brand, int64, error) {
+	offset := pageOffset(page, perPage)
+	
+	// Count total
+	var total int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM brands WHERE client_id = $1 AND tenant_id = $2`, clientID, tenantID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	
+	// List items
+	query := `SELECT ` + brandColumns + ` 
+			  FROM brands WHERE client_id = $1 AND tenant_id = $2 ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+	
+	rows, err := s.db.QueryContext(ctx, query, clientID, tenantID, perPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	
+	var brands []*models.Brand
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		brands = append(brands, brand)
+	}
+	
+	return brands, total, nil
+}
+
+// ListByClientAfter lista uma página de marcas do cliente a partir da posição after
+// (mais recentes primeiro, id como desempate); more indica que há marcas depois da página
+func (s *BrandService) ListByClientAfter(ctx context.Context, clientID, tenantID uuid.UUID, after Keyset, limit int) ([]*models.Brand, bool, error) {
+	query, args := keysetQuery(`SELECT `+brandColumns+` FROM brands WHERE client_id = $1 AND tenant_id = $2`,
+		[]interface{}{clientID, tenantID}, after, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var brands []*models.Brand
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		brands = append(brands, brand)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(brands) > limit {
+		return brands[:limit], true, nil
+	}
+	return brands, false, nil
+}
+
+// EachByClient percorre todas as marcas do cliente (mais recentes primeiro) sem carregá-las em memória
+func (s *BrandService) EachByClient(ctx context.Context, clientID, tenantID uuid.UUID, fn func(*models.Brand) error) error {
+	query := `SELECT ` + brandColumns + ` FROM brands WHERE client_id = $1 AND tenant_id = $2 ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, clientID, tenantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(brand); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// ListMonitored retorna as marcas com job de monitoramento, de um tenant ou de todos
+// (tenantID nil), para reconciliação com o MCP
+func (s *BrandService) ListMonitored(ctx context.Context, tenantID *uuid.UUID) ([]*models.Brand, error) {
+	query := `SELECT id, tenant_id, client_id, name, domain, monitoring_enabled, status, monitoring_job_id, last_scan_at, threats_found, created_at, updated_at 
+			  FROM brands WHERE monitoring_job_id IS NOT NULL AND ($1::uuid IS NULL OR tenant_id = $1) ORDER BY tenant_id, id`
+
+	rows, err := s.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var brands []*models.Brand
+	for rows.Next() {
+		var b models.Brand
+		if err := rows.Scan(&b.ID, &b.TenantID, &b.ClientID, &b.Name, &b.PrimaryDomain, &b.MonitoringEnabled,
+			&b.Status, &b.MonitoringJobID, &b.LastScanAt, &b.ThreatsFound, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		brands = append(brands, &b)
+	}
+	return brands, rows.Err()
+}
+
+func (s *BrandService) CountByClient(ctx context.Context, clientID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, brandCountByClientQuery, clientID).Scan(&count)
+	return count, err
+}
+
+// CountByClients retorna o total de marcas de cada cliente em uma única consulta;
+// clientes sem marcas ficam com 0 e uma lista vazia não consulta o banco
+func (s *BrandService) CountByClients(ctx context.Context, clientIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(clientIDs))
+	if len(clientIDs) == 0 {
+		return counts, nil
+	}
+	for _, id := range clientIDs {
+		counts[id] = 0
+	}
+
+	rows, err := s.db.QueryContext(ctx, brandCountByClientsQuery, pq.Array(clientIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var clientID uuid.UUID
+		var count int
+		if err := rows.Scan(&clientID, &count); err != nil {
+			return nil, err
+		}
+		counts[clientID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CountByTenant retorna o total de marcas do tenant
+func (s *BrandService) CountByTenant(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM brands WHERE tenant_id = $1`, tenantID).Scan(&count)
+	return count, err
+}
+
+// IncrementThreatsFound incrementa atomicamente o contador de ameaças da marca
+func (s *BrandService) IncrementThreatsFound(ctx context.Context, id, tenantID uuid.UUID) error {
+	query := `UPDATE brands SET threats_found = threats_found + 1, updated_at = $1 WHERE id = $2 AND tenant_id = $3`
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// brandInsertQuery insere uma marca (Create e CreateMany)
+const brandInsertQuery = `INSERT INTO brands (id, tenant_id, client_id, name, domain, industry, monitoring_enabled, config, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+func (s *BrandService) Create(ctx context.Context, brand *models.Brand) error {
+	config, err := json.Marshal(brand.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode brand config: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, brandInsertQuery,
+		brand.ID, brand.TenantID, brand.ClientID, brand.Name, brand.PrimaryDomain, brand.Industry, brand.MonitoringEnabled, config, brand.CreatedAt, brand.UpdatedAt,
+	)
+	return err
+}
+
+// CreateMany persiste as marcas do tenant em uma única transação e retorna o erro de
+// cada item (nil = criado). maxBrands (0 = sem limite) é verificado contra o total do
+// tenant após a importação, com a linha do tenant bloqueada para serializar importações.
+//
+// Sem allowPartial a importação é tudo-ou-nada: quota insuficiente retorna
+// ErrQuotaExceeded e a primeira falha desfaz a transação (os itens seguintes não são
+// tentados). Com allowPartial cada item falha isoladamente (savepoint) e os itens
+// além da quota restante recebem ErrQuotaExceeded.
+func (s *BrandService) CreateMany(ctx context.Context, tenantID uuid.UUID, brands []*models.Brand, maxBrands int, allowPartial bool) ([]error, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM tenants WHERE id = $1 FOR UPDATE`, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to lock tenant: %w", err)
+	}
+
+	var existing int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM brands WHERE tenant_id = $1`, tenantID).Scan(&existing); err != nil {
+		return nil, err
+	}
+
+	remaining := len(brands)
+	if maxBrands > 0 {
+		remaining = maxBrands - existing
+		if remaining < len(brands) && !allowPartial {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	results := make([]error, len(brands))
+	created := 0
+	for i, brand := range brands {
+		if created >= remaining {
+			results[i] = ErrQuotaExceeded
+			continue
+		}
+
+		config, err := json.Marshal(brand.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode brand config: %w", err)
+		}
+
+		if allowPartial {
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT brand_item`); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, brandInsertQuery,
+			brand.ID, tenantID, brand.ClientID, brand.Name, brand.PrimaryDomain, brand.Industry, brand.MonitoringEnabled, config, brand.CreatedAt, brand.UpdatedAt,
+		)
+		if err != nil {
+			results[i] = fmt.Errorf("failed to create brand: %w", err)
+			if !allowPartial {
+				return results, results[i]
+			}
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT brand_item`); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		created++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Update persiste a marca. Com unmodifiedSince, a escrita só acontece se o updated_at
+// atual não for posterior a ele (concorrência otimista); caso contrário retorna ErrStaleVersion.
+// Em caso de sucesso brand.UpdatedAt recebe o valor gravado.
+func (s *BrandService) Update(ctx context.Context, brand *models.Brand, unmodifiedSince *time.Time) error {
+	config, err := json.Marshal(brand.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode brand config: %w", err)
+	}
+
+	query := `UPDATE brands SET name = $1, domain = $2, industry = $3, monitoring_enabled = $4, config = $5, updated_at = $6 
+			  WHERE id = $7 AND tenant_id = $8 AND ($9::timestamptz IS NULL OR updated_at <= $9)`
+	
+	now := dbNow()
+	res, err := s.db.ExecContext(ctx, query,
+		brand.Name, brand.PrimaryDomain, brand.Industry, brand.MonitoringEnabled, config, now, brand.ID, brand.TenantID, unmodifiedSince,
+	)
+	if err != nil {
+		return err
+	}
+	
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return s.updateMiss(ctx, brand, unmodifiedSince)
+	}
+	brand.UpdatedAt = now
+	return nil
+}
+
+// updateMiss distingue marca inexistente de versão desatualizada quando o UPDATE não afeta linhas
+func (s *BrandService) updateMiss(ctx context.Context, brand *models.Brand, unmodifiedSince *time.Time) error {
+	if unmodifiedSince == nil {
+		return ErrNotFound
+	}
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM brands WHERE id = $1 AND tenant_id = $2)`,
+		brand.ID, brand.TenantID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrStaleVersion
+	}
+	return ErrNotFound
+}
+
 func (s *BrandService) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
 	query := `DELETE FROM brands WHERE id = $1 AND tenant_id = $2`
 	
@@ -348,3 +1135,835 @@ func (s *BrandService) Delete(ctx context.Context, id, tenantID uuid.UUID) error
 	}
 	return nil
 }
+
+// =============================================================================
+// TENANT SERVICE (PostgreSQL)
+// =============================================================================
+
+type TenantService struct {
+	db *DB
+}
+
+func NewTenantService(db *DB) *TenantService {
+	return &TenantService{db: db}
+}
+
+// Colunas lidas por scanTenant
+const tenantColumns = `id, name, slug, email, plan, status, settings, quotas, created_at, updated_at`
+
+// GetByID retorna o tenant com suas configurações e quotas
+func (s *TenantService) GetByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1`
+	return scanTenant(s.db.QueryRowContext(ctx, query, id))
+}
+
+// Update altera o nome, o email e o status do tenant
+func (s *TenantService) Update(ctx context.Context, tenant *models.Tenant) error {
+	tenant.UpdatedAt = dbNow()
+	// Reativar o tenant cancela a exclusão lógica (e a remoção após a retenção)
+	return s.update(ctx, `UPDATE tenants SET name = $1, email = $2, status = $3, updated_at = $4,
+			  deleted_at = CASE WHEN $3 = 'active' THEN NULL ELSE deleted_at END WHERE id = $5`,
+		tenant.Name, tenant.Email, tenant.Status, tenant.UpdatedAt, tenant.ID)
+}
+
+// UpdatePlan altera o plano do tenant
+func (s *TenantService) UpdatePlan(ctx context.Context, id uuid.UUID, plan string) error {
+	return s.update(ctx, `UPDATE tenants SET plan = $1, updated_at = $2 WHERE id = $3`, plan, dbNow(), id)
+}
+
+// UpdateQuotas substitui as quotas do tenant
+func (s *TenantService) UpdateQuotas(ctx context.Context, id uuid.UUID, quotas models.TenantQuotas) error {
+	raw, err := json.Marshal(quotas)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant quotas: %w", err)
+	}
+	return s.update(ctx, `UPDATE tenants SET quotas = $1, updated_at = $2 WHERE id = $3`, raw, dbNow(), id)
+}
+
+// UpdateSettings substitui as configurações (webhooks, scopes, tools) do tenant
+func (s *TenantService) UpdateSettings(ctx context.Context, id uuid.UUID, settings models.TenantSettings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode tenant settings: %w", err)
+	}
+	return s.update(ctx, `UPDATE tenants SET settings = $1, updated_at = $2 WHERE id = $3`, raw, dbNow(), id)
+}
+
+// Delete remove o tenant. Com hard=false o tenant é apenas inativado (o acesso de
+// todos os usuários é bloqueado e os dados mantidos); com hard=true todos os dados
+// do tenant (usuários, clientes, marcas com seus monitoramentos, alertas, entregas
+// de webhook, jobs e auditoria) são apagados em uma transação.
+func (s *TenantService) Delete(ctx context.Context, id uuid.UUID, hard bool) error {
+	if !hard {
+		return s.update(ctx, `UPDATE tenants SET status = $1, updated_at = $2, deleted_at = $2 WHERE id = $3`, models.StatusInactive, dbNow(), id)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ordem respeita as foreign keys (dependentes primeiro)
+	for _, table := range []string{
+		"webhook_deliveries", "alerts", "brands", "clients",
+		"async_jobs", "audit_logs", "login_events", "users",
+	} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE tenant_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete tenant %s: %w", table, err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM tenants WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit()
+}
+
+// PurgeDeleted apaga os tenants excluídos logicamente antes de before, com todos os
+// seus dados (como em Delete com hard=true), e retorna quantos foram apagados
+func (s *TenantService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM tenants WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := scanIDs(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		if err := s.Delete(ctx, id, true); err != nil && !errors.Is(err, ErrNotFound) {
+			return purged, fmt.Errorf("failed to purge tenant %s: %w", id, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// update executa um UPDATE de um único tenant; retorna ErrNotFound se ele não existir
+func (s *TenantService) update(ctx context.Context, query string, args ...interface{}) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetBySlug retorna o tenant identificado pelo slug
+func (s *TenantService) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE slug = $1`
+	return scanTenant(s.db.QueryRowContext(ctx, query, slug))
+}
+
+// scanTenant lê um tenant (tenantColumns), decodificando settings e quotas
+func scanTenant(row *Row) (*models.Tenant, error) {
+	var tenant models.Tenant
+	var slug, email sql.NullString
+	var rawSettings, rawQuotas []byte
+	err := row.Scan(
+		&tenant.ID, &tenant.Name, &slug, &email, &tenant.Plan, &tenant.Status, &rawSettings, &rawQuotas, &tenant.CreatedAt, &tenant.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.Slug, tenant.Email = slug.String, email.String
+	if len(rawSettings) > 0 {
+		if err := json.Unmarshal(rawSettings, &tenant.Settings); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant settings: %w", err)
+		}
+	}
+	if len(rawQuotas) > 0 {
+		if err := json.Unmarshal(rawQuotas, &tenant.Quotas); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant quotas: %w", err)
+		}
+	}
+	return &tenant, nil
+}
+
+// SlugExists verifica se o slug já é usado por algum tenant
+func (s *TenantService) SlugExists(ctx context.Context, slug string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM tenants WHERE slug = $1)`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, slug).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetStatus retorna o status do tenant (middleware.TenantStatusStore)
+func (s *TenantService) GetStatus(ctx context.Context, id uuid.UUID) (models.Status, error) {
+	var status models.Status
+	err := s.db.QueryRowContext(ctx, `SELECT status FROM tenants WHERE id = $1`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// GetSettings retorna as configurações (webhooks, scopes, tools) do tenant
+func (s *TenantService) GetSettings(ctx context.Context, id uuid.UUID) (*models.TenantSettings, error) {
+	query := `SELECT settings FROM tenants WHERE id = $1`
+
+	var raw []byte
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings models.TenantSettings
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant settings: %w", err)
+		}
+	}
+	return &settings, nil
+}
+
+// GetMaxConcurrentJobs retorna o limite de jobs simultâneos do tenant; 0 indica que não há limite definido
+func (s *TenantService) GetMaxConcurrentJobs(ctx context.Context, id uuid.UUID) (int, error) {
+	settings, err := s.GetSettings(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return settings.MaxConcurrentJobs, nil
+}
+
+// GetPolicy retorna a política (scopes, ferramentas e quotas) do tenant
+func (s *TenantService) GetPolicy(ctx context.Context, id uuid.UUID) (*models.TenantPolicy, error) {
+	query := `SELECT id, plan, status, settings, quotas FROM tenants WHERE id = $1`
+
+	policy := &models.TenantPolicy{}
+	var rawSettings, rawQuotas []byte
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&policy.TenantID, &policy.Plan, &policy.Status, &rawSettings, &rawQuotas)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings models.TenantSettings
+	if len(rawSettings) > 0 {
+		if err := json.Unmarshal(rawSettings, &settings); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant settings: %w", err)
+		}
+	}
+	if len(rawQuotas) > 0 {
+		if err := json.Unmarshal(rawQuotas, &policy.Quotas); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant quotas: %w", err)
+		}
+	}
+
+	policy.AllowedScopes = settings.AllowedScopes
+	policy.AllowedTools = settings.AllowedTools
+	if policy.AllowedScopes == nil {
+		policy.AllowedScopes = []models.Scope{}
+	}
+	if policy.AllowedTools == nil {
+		policy.AllowedTools = []string{}
+	}
+	return policy, nil
+}
+
+// GetRateLimit retorna o limite customizado (req/min) do tenant; 0 indica que não há limite customizado
+func (s *TenantService) GetRateLimit(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `SELECT rate_limit_rpm FROM tenants WHERE id = $1`
+
+	var limit sql.NullInt64
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&limit)
+	if err == sql.ErrNoRows {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(limit.Int64), nil
+}
+
+// SetRateLimit define o limite customizado (req/min) do tenant; 0 remove o limite customizado
+func (s *TenantService) SetRateLimit(ctx context.Context, id uuid.UUID, limit int) error {
+	value := sql.NullInt64{Int64: int64(limit), Valid: limit > 0}
+
+	res, err := s.db.ExecContext(ctx, `UPDATE tenants SET rate_limit_rpm = $1, updated_at = $2 WHERE id = $3`, value, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// =============================================================================
+// ALERT SERVICE (PostgreSQL)
+// =============================================================================
+
+type AlertService struct {
+	db *DB
+}
+
+func NewAlertService(db *DB) *AlertService {
+	return &AlertService{db: db}
+}
+
+// AlertFilter filtros de consulta de alertas
+type AlertFilter struct {
+	// Status aceitos (vazio = todos)
+	Statuses []string
+	Severity string
+	Type     string
+	ClientID *uuid.UUID
+	BrandID  *uuid.UUID
+	From     *time.Time
+	To       *time.Time
+}
+
+// Each percorre os alertas do tenant que atendem ao filtro (mais recentes primeiro)
+// sem carregá-los em memória
+func (s *AlertService) Each(ctx context.Context, tenantID uuid.UUID, filter AlertFilter, fn func(*models.Alert) error) error {
+	where := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	if len(filter.Statuses) > 0 {
+		args = append(args, pq.Array(filter.Statuses))
+		where = append(where, fmt.Sprintf("status = ANY($%d)", len(args)))
+	}
+	if filter.Severity != "" {
+		args = append(args, filter.Severity)
+		where = append(where, fmt.Sprintf("severity = $%d", len(args)))
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		where = append(where, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if filter.ClientID != nil {
+		args = append(args, *filter.ClientID)
+		where = append(where, fmt.Sprintf("client_id = $%d", len(args)))
+	}
+	if filter.BrandID != nil {
+		args = append(args, *filter.BrandID)
+		where = append(where, fmt.Sprintf("brand_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where = append(where, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where = append(where, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	query := `SELECT id, tenant_id, client_id, brand_id, type, severity, title, COALESCE(description, ''), details, status, created_at, updated_at 
+			  FROM alerts WHERE ` + strings.Join(where, " AND ") + ` ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a models.Alert
+		var clientID, brandID uuid.NullUUID
+		var details []byte
+		if err := rows.Scan(&a.ID, &a.TenantID, &clientID, &brandID, &a.Type, &a.Severity, &a.Title, &a.Description, &details, &a.Status, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return err
+		}
+		a.ClientID = clientID.UUID
+		a.BrandID = brandID.UUID
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &a.Details); err != nil {
+				return fmt.Errorf("failed to decode alert details: %w", err)
+			}
+		}
+		if err := fn(&a); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Create persiste um alerta. Retorna ErrAlreadyExists se a dedup_key já existir no tenant.
+func (s *AlertService) Create(ctx context.Context, alert *models.Alert) error {
+	details, err := json.Marshal(alert.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert details: %w", err)
+	}
+
+	query := `INSERT INTO alerts (id, tenant_id, client_id, brand_id, type, severity, title, description, details, status, dedup_key, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			  ON CONFLICT (tenant_id, dedup_key) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query,
+		alert.ID, alert.TenantID, alert.ClientID, alert.BrandID, alert.Type, alert.Severity, alert.Title, alert.Description,
+		details, alert.Status, alert.DedupKey, alert.CreatedAt, alert.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create alert: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// =============================================================================
+// REPORT SERVICE (PostgreSQL)
+// =============================================================================
+
+// Quantidade de marcas no ranking do relatório
+const reportTopBrands = 10
+
+type ReportService struct {
+	db *DB
+}
+
+func NewReportService(db *DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// ReportFilter escopo do relatório: período [From, To) e, opcionalmente, um cliente
+type ReportFilter struct {
+	ClientID *uuid.UUID
+	From     time.Time
+	To       time.Time
+}
+
+// Summary agrega os alertas do período (totais, tendência diária e marcas mais
+// afetadas) e a situação atual do monitoramento das marcas
+func (s *ReportService) Summary(ctx context.Context, tenantID uuid.UUID, filter ReportFilter) (*models.ReportSummary, error) {
+	summary := &models.ReportSummary{
+		TenantID: tenantID,
+		ClientID: filter.ClientID,
+		From:     filter.From,
+		To:       filter.To,
+		Alerts: models.ReportAlertTotals{
+			BySeverity: make(map[string]int),
+			ByType:     make(map[string]int),
+		},
+		Trend:       []models.ReportTrendDay{},
+		TopBrands:   []models.ReportBrand{},
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	// Filtros comuns às consultas de alertas
+	where := "a.tenant_id = $1 AND a.created_at >= $2 AND a.created_at < $3"
+	args := []interface{}{tenantID, filter.From, filter.To}
+	if filter.ClientID != nil {
+		args = append(args, *filter.ClientID)
+		where += fmt.Sprintf(" AND a.client_id = $%d", len(args))
+	}
+
+	// Totais por severidade e tipo
+	rows, err := s.db.QueryContext(ctx, `SELECT a.severity, a.type, COUNT(*) FROM alerts a WHERE `+where+` GROUP BY a.severity, a.type`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var severity, alertType string
+		var count int
+		if err := rows.Scan(&severity, &alertType, &count); err != nil {
+			return nil, err
+		}
+		summary.Alerts.Total += count
+		summary.Alerts.BySeverity[severity] += count
+		summary.Alerts.ByType[alertType] += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Tendência diária (UTC); dias sem alertas aparecem zerados
+	days := make(map[string]*models.ReportTrendDay)
+	for day := filter.From.UTC().Truncate(24 * time.Hour); day.Before(filter.To); day = day.Add(24 * time.Hour) {
+		date := day.Format("2006-01-02")
+		summary.Trend = append(summary.Trend, models.ReportTrendDay{Date: date, BySeverity: make(map[string]int)})
+	}
+	for i := range summary.Trend {
+		days[summary.Trend[i].Date] = &summary.Trend[i]
+	}
+
+	trendQuery := `SELECT to_char(a.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD'), a.severity, COUNT(*) FROM alerts a WHERE ` + where + ` GROUP BY 1, 2`
+	trendRows, err := s.db.QueryContext(ctx, trendQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer trendRows.Close()
+	for trendRows.Next() {
+		var date, severity string
+		var count int
+		if err := trendRows.Scan(&date, &severity, &count); err != nil {
+			return nil, err
+		}
+		if day, ok := days[date]; ok {
+			day.Total += count
+			day.BySeverity[severity] += count
+		}
+	}
+	if err := trendRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Marcas com mais alertas no período
+	topQuery := fmt.Sprintf(`SELECT b.id, b.name, b.domain, COUNT(*), COUNT(*) FILTER (WHERE a.severity IN ('high', 'critical'))
+			  FROM alerts a JOIN brands b ON b.id = a.brand_id
+			  WHERE %s GROUP BY b.id, b.name, b.domain ORDER BY 4 DESC, 5 DESC LIMIT %d`, where, reportTopBrands)
+	topRows, err := s.db.QueryContext(ctx, topQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer topRows.Close()
+	for topRows.Next() {
+		var brand models.ReportBrand
+		if err := topRows.Scan(&brand.BrandID, &brand.Name, &brand.Domain, &brand.Alerts, &brand.Critical); err != nil {
+			return nil, err
+		}
+		summary.TopBrands = append(summary.TopBrands, brand)
+	}
+	if err := topRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Situação atual do monitoramento
+	brandQuery := `SELECT COUNT(*), COUNT(*) FILTER (WHERE monitoring_enabled), COALESCE(SUM(threats_found), 0) FROM brands WHERE tenant_id = $1`
+	brandArgs := []interface{}{tenantID}
+	if filter.ClientID != nil {
+		brandQuery += ` AND client_id = $2`
+		brandArgs = append(brandArgs, *filter.ClientID)
+	}
+	err = s.db.QueryRowContext(ctx, brandQuery, brandArgs...).Scan(
+		&summary.Monitoring.Brands, &summary.Monitoring.MonitoredBrands, &summary.Monitoring.ThreatsFound,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// =============================================================================
+// WEBHOOK DELIVERY SERVICE (PostgreSQL)
+// =============================================================================
+
+type WebhookDeliveryService struct {
+	db *DB
+}
+
+func NewWebhookDeliveryService(db *DB) *WebhookDeliveryService {
+	return &WebhookDeliveryService{db: db}
+}
+
+func (s *WebhookDeliveryService) Create(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `INSERT INTO webhook_deliveries (id, tenant_id, alert_id, job_id, channel, url, status, attempts, response_code, error, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		delivery.ID, delivery.TenantID, delivery.AlertID, delivery.JobID, delivery.Channel, delivery.URL, delivery.Status,
+		delivery.Attempts, delivery.ResponseCode, delivery.Error, delivery.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// =============================================================================
+// ASYNC JOB SERVICE (PostgreSQL)
+// =============================================================================
+
+// AsyncJobService registra os jobs assíncronos do MCP e seus callbacks
+type AsyncJobService struct {
+	db *DB
+}
+
+func NewAsyncJobService(db *DB) *AsyncJobService {
+	return &AsyncJobService{db: db}
+}
+
+const asyncJobColumns = `id, tenant_id, user_id, operation, target, COALESCE(priority, ''), COALESCE(callback_url, ''), status, result, COALESCE(error, ''), created_at, completed_at`
+
+// Create registra um job aceito pelo MCP. Retorna ErrAlreadyExists se o job_id já foi registrado.
+func (s *AsyncJobService) Create(ctx context.Context, job *models.AsyncJob) error {
+	query := `INSERT INTO async_jobs (id, tenant_id, user_id, operation, target, priority, callback_url, status, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+			  ON CONFLICT (id) DO NOTHING`
+
+	res, err := s.db.ExecContext(ctx, query,
+		job.ID, job.TenantID, job.UserID, job.Operation, job.Target, job.Priority, job.CallbackURL, job.Status, job.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create async job: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrAlreadyExists
+	}
+	return nil
+}
+
+// GetByID retorna um job do tenant
+func (s *AsyncJobService) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.AsyncJob, error) {
+	query := `SELECT ` + asyncJobColumns + ` FROM async_jobs WHERE id = $1 AND tenant_id = $2`
+	return scanAsyncJob(s.db.QueryRowContext(ctx, query, id, tenantID))
+}
+
+// Complete grava o resultado de um job pendente e o retorna. Só a primeira conclusão
+// é aceita: um job desconhecido retorna ErrNotFound e um já concluído ErrJobFinished,
+// para que avisos repetidos do MCP não disparem o callback de novo.
+func (s *AsyncJobService) Complete(ctx context.Context, id uuid.UUID, status string, result map[string]interface{}, jobErr string) (*models.AsyncJob, error) {
+	var raw []byte
+	if result != nil {
+		var err error
+		if raw, err = json.Marshal(result); err != nil {
+			return nil, fmt.Errorf("failed to encode job result: %w", err)
+		}
+	}
+
+	query := `UPDATE async_jobs SET status = $2, result = $3, error = NULLIF($4, ''), completed_at = $5
+			  WHERE id = $1 AND status = $6
+			  RETURNING ` + asyncJobColumns
+
+	job, err := scanAsyncJob(s.db.QueryRowContext(ctx, query, id, status, raw, jobErr, dbNow(), models.AsyncJobPending))
+	if !errors.Is(err, ErrNotFound) {
+		return job, err
+	}
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM async_jobs WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrJobFinished
+	}
+	return nil, ErrNotFound
+}
+
+func scanAsyncJob(row *Row) (*models.AsyncJob, error) {
+	job := &models.AsyncJob{}
+	var result []byte
+	var completedAt sql.NullTime
+	err := row.Scan(&job.ID, &job.TenantID, &job.UserID, &job.Operation, &job.Target, &job.Priority, &job.CallbackURL,
+		&job.Status, &result, &job.Error, &job.CreatedAt, &completedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &job.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode job result: %w", err)
+		}
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	return job, nil
+}
+
+// =============================================================================
+// AUDIT SERVICE (PostgreSQL)
+// =============================================================================
+
+type AuditService struct {
+	db *DB
+}
+
+func NewAuditService(db *DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// AuditFilter filtros de consulta do log de auditoria
+type AuditFilter struct {
+	Action string
+	UserID *uuid.UUID
+	From   *time.Time
+	To     *time.Time
+}
+
+// Log persiste um registro de auditoria
+func (s *AuditService) Log(ctx context.Context, entry *models.AuditLog) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	query := `INSERT INTO audit_logs (id, tenant_id, user_id, action, resource, resource_id, details, ip, user_agent, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = s.db.ExecContext(ctx, query,
+		entry.ID, entry.TenantID, entry.UserID, entry.Action, entry.Resource, entry.ResourceID,
+		details, entry.IP, entry.UserAgent, entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// List lista os registros de auditoria do tenant aplicando os filtros, do mais recente ao mais antigo
+func (s *AuditService) List(ctx context.Context, tenantID uuid.UUID, filter AuditFilter, page, perPage int) ([]*models.AuditLog, int64, error) {
+	offset := pageOffset(page, perPage)
+
+	where := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		where = append(where, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		where = append(where, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where = append(where, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where = append(where, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	// Count total
+	var total int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs WHERE `+whereClause, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// List items
+	query := fmt.Sprintf(`SELECT id, tenant_id, user_id, action, resource, resource_id, details, ip, user_agent, created_at 
+			  FROM audit_logs WHERE %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, whereClause, len(args)+1, len(args)+2)
+
+	rows, err := s.db.QueryContext(ctx, query, append(args, perPage, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditLog
+	for rows.Next() {
+		var e models.AuditLog
+		var userID, resourceID uuid.NullUUID
+		var details []byte
+		var ip, userAgent sql.NullString
+		if err := rows.Scan(&e.ID, &e.TenantID, &userID, &e.Action, &e.Resource, &resourceID, &details, &ip, &userAgent, &e.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			e.UserID = &userID.UUID
+		}
+		if resourceID.Valid {
+			e.ResourceID = &resourceID.UUID
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &e.Details); err != nil {
+				return nil, 0, fmt.Errorf("failed to decode audit details: %w", err)
+			}
+		}
+		e.IP = ip.String
+		e.UserAgent = userAgent.String
+		entries = append(entries, &e)
+	}
+
+	return entries, total, rows.Err()
+}
+
+// =============================================================================
+// LOGIN EVENT SERVICE (PostgreSQL)
+// =============================================================================
+
+type LoginEventService struct {
+	db *DB
+}
+
+func NewLoginEventService(db *DB) *LoginEventService {
+	return &LoginEventService{db: db}
+}
+
+// Record persiste uma tentativa de login
+func (s *LoginEventService) Record(ctx context.Context, event *models.LoginEvent) error {
+	query := `INSERT INTO login_events (id, tenant_id, user_id, success, failure_reason, ip, user_agent, created_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := s.db.ExecContext(ctx, query,
+		event.ID, event.TenantID, event.UserID, event.Success, sql.NullString{String: event.FailureReason, Valid: event.FailureReason != ""},
+		event.IP, event.UserAgent, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create login event: %w", err)
+	}
+	return nil
+}
+
+// ListByUser retorna as tentativas de login mais recentes do usuário
+func (s *LoginEventService) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*models.LoginEvent, error) {
+	query := `SELECT id, tenant_id, user_id, success, failure_reason, ip, user_agent, created_at 
+			  FROM login_events WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.LoginEvent{}
+	for rows.Next() {
+		var e models.LoginEvent
+		var failureReason, ip, userAgent sql.NullString
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.UserID, &e.Success, &failureReason, &ip, &userAgent, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.FailureReason = failureReason.String
+		e.IP = ip.String
+		e.UserAgent = userAgent.String
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/google/uuid"
+)
+
+// jsonArg decodifica o argumento JSON (JSONB) de uma consulta
+func jsonArg(t *testing.T, call fakeCall, index int, dest interface{}) {
+	t.Helper()
+	raw, ok := call.args[index].([]byte)
+	if !ok {
+		t.Fatalf("arg %d = %T, want JSON bytes", index, call.args[index])
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		t.Fatalf("arg %d: %v", index, err)
+	}
+}
+
+// =============================================================================
+// CLIENTS AND BRANDS
+// =============================================================================
+
+func TestClientUpdatePersistsDescriptionAndSettings(t *testing.T) {
+	db, backend := newFakeDB(t, false, nil)
+	clients := NewClientService(db)
+
+	client := &models.Client{
+		ID:          uuid.New(),
+		TenantID:    uuid.New(),
+		Name:        "Acme",
+		Description: "Retail",
+		Status:      models.StatusActive,
+		Settings:    models.ClientSettings{AlertEmail: "soc@acme.test", Priority: "high", ScanFrequency: "hourly"},
+	}
+	if err := clients.Update(context.Background(), client, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := backend.CallsMatching("UPDATE clients")
+	if len(calls) != 1 {
+		t.Fatalf("got %d UPDATE calls, want 1", len(calls))
+	}
+	if !strings.Contains(calls[0].query, "description =") || !strings.Contains(calls[0].query, "settings =") {
+		t.Fatalf("UPDATE does not write description and settings: %s", calls[0].query)
+	}
+	if calls[0].args[2] != "Retail" {
+		t.Errorf("description arg = %v, want Retail", calls[0].args[2])
+	}
+	var settings models.ClientSettings
+	jsonArg(t, calls[0], 5, &settings)
+	if settings.AlertEmail != "soc@acme.test" || settings.Priority != "high" {
+		t.Errorf("settings arg = %+v", settings)
+	}
+}
+
+func TestBrandUpdatePersistsConfig(t *testing.T) {
+	db, backend := newFakeDB(t, false, nil)
+	brands := NewBrandService(db)
+
+	brand := &models.Brand{
+		ID:            uuid.New(),
+		TenantID:      uuid.New(),
+		Name:          "Acme",
+		PrimaryDomain: "acme.test",
+		Config:        models.BrandConfig{ScanFrequencyMins: 30, AlertChannels: []string{"email", "slack"}},
+	}
+	if err := brands.Update(context.Background(), brand, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := backend.CallsMatching("UPDATE brands")
+	if len(calls) != 1 || !strings.Contains(calls[0].query, "config =") {
+		t.Fatalf("UPDATE does not write config: %+v", calls)
+	}
+	var config models.BrandConfig
+	jsonArg(t, calls[0], 4, &config)
+	if config.ScanFrequencyMins != 30 || len(config.AlertChannels) != 2 {
+		t.Errorf("config arg = %+v", config)
+	}
+}
+
+func TestClientGetByIDReadsDescriptionAndSettings(t *testing.T) {
+	id, tenantID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	db, _ := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		return resultRows([]driver.Value{
+			id.String(), tenantID.String(), "Acme", "acme", "Retail", "", "active",
+			[]byte(`{"alert_email":"soc@acme.test","priority":"high","scan_frequency":"hourly"}`), now, now,
+		}), nil
+	})
+
+	client, err := NewClientService(db).GetByID(context.Background(), id, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Description != "Retail" || client.Settings.AlertEmail != "soc@acme.test" || client.Settings.Priority != "high" {
+		t.Errorf("client = %+v", client)
+	}
+}
+
+func TestBrandGetByIDReadsConfig(t *testing.T) {
+	id, tenantID, clientID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	db, _ := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		return resultRows([]driver.Value{
+			id.String(), tenantID.String(), clientID.String(), "Acme", "acme.test", "", true, "active",
+			[]byte(`{"scan_frequency_mins":30,"alert_channels":["email"]}`), nil, nil, int64(2), now, now,
+		}), nil
+	})
+
+	brand, err := NewBrandService(db).GetByID(context.Background(), id, tenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if brand.Config.ScanFrequencyMins != 30 || len(brand.Config.AlertChannels) != 1 || brand.ThreatsFound != 2 {
+		t.Errorf("brand = %+v", brand)
+	}
+}
+
+func TestClientCreatePersistsDescriptionAndSettings(t *testing.T) {
+	db, backend := newFakeDB(t, false, nil)
+	client := &models.Client{
+		ID:          uuid.New(),
+		TenantID:    uuid.New(),
+		Name:        "Acme",
+		Description: "Retail",
+		Settings:    models.ClientSettings{AlertWebhook: "https://hooks.acme.test", ScanFrequency: "weekly"},
+	}
+	if err := NewClientService(db).Create(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := backend.CallsMatching("INSERT INTO clients")
+	if len(calls) != 1 {
+		t.Fatalf("got %d INSERT calls, want 1", len(calls))
+	}
+	if calls[0].args[4] != "Retail" {
+		t.Errorf("description arg = %v, want Retail", calls[0].args[4])
+	}
+	var settings models.ClientSettings
+	jsonArg(t, calls[0], 7, &settings)
+	if settings.AlertWebhook != "https://hooks.acme.test" || settings.ScanFrequency != "weekly" {
+		t.Errorf("settings arg = %+v", settings)
+	}
+}
+
+func TestBrandCreatePersistsConfig(t *testing.T) {
+	config := models.BrandConfig{ScanFrequencyMins: 15, AlertChannels: []string{"webhook"}}
+
+	t.Run("Create", func(t *testing.T) {
+		db, backend := newFakeDB(t, false, nil)
+		brand := &models.Brand{ID: uuid.New(), TenantID: uuid.New(), ClientID: uuid.New(), Name: "Acme", PrimaryDomain: "acme.test", Config: config}
+		if err := NewBrandService(db).Create(context.Background(), brand); err != nil {
+			t.Fatal(err)
+		}
+		assertBrandInsertConfig(t, backend, 1, config)
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		db, backend := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+			if strings.Contains(query, "COUNT(*)") {
+				return resultRows([]driver.Value{int64(0)}), nil
+			}
+			return fakeRows{affected: 1}, nil
+		})
+		tenantID := uuid.New()
+		brands := []*models.Brand{
+			{ID: uuid.New(), ClientID: uuid.New(), Name: "Acme", PrimaryDomain: "acme.test", Config: config},
+			{ID: uuid.New(), ClientID: uuid.New(), Name: "Acme Pay", PrimaryDomain: "pay.acme.test", Config: config},
+		}
+		results, err := NewBrandService(db).CreateMany(context.Background(), tenantID, brands, 0, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, err := range results {
+			if err != nil {
+				t.Errorf("brand %d: %v", i, err)
+			}
+		}
+		assertBrandInsertConfig(t, backend, 2, config)
+	})
+}
+
+// assertBrandInsertConfig verifica que cada INSERT de marca grava a config
+func assertBrandInsertConfig(t *testing.T, backend *fakeBackend, inserts int, want models.BrandConfig) {
+	t.Helper()
+	calls := backend.CallsMatching("INSERT INTO brands")
+	if len(calls) != inserts {
+		t.Fatalf("got %d INSERT calls, want %d", len(calls), inserts)
+	}
+	for _, call := range calls {
+		var config models.BrandConfig
+		jsonArg(t, call, 7, &config)
+		if config.ScanFrequencyMins != want.ScanFrequencyMins || len(config.AlertChannels) != len(want.AlertChannels) {
+			t.Errorf("config arg = %+v, want %+v", config, want)
+		}
+	}
+}
+
+// Contagem de marcas de uma página de clientes em uma única consulta; clientes sem
+// marcas ficam com 0
+func TestBrandCountByClientsUsesSingleQuery(t *testing.T) {
+	acme, globex, initech := uuid.New(), uuid.New(), uuid.New()
+	db, backend := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		return resultRows(
+			[]driver.Value{acme.String(), int64(3)},
+			[]driver.Value{globex.String(), int64(1)},
+		), nil
+	})
+	brands := NewBrandService(db)
+
+	counts, err := brands.CountByClients(context.Background(), []uuid.UUID{acme, globex, initech})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[uuid.UUID]int{acme: 3, globex: 1, initech: 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	calls := backend.Calls()
+	if len(calls) != 1 || !strings.Contains(calls[0].query, "ANY(") || !strings.Contains(calls[0].query, "GROUP BY client_id") {
+		t.Fatalf("queries = %+v, want a single GROUP BY over ANY($1)", calls)
+	}
+	if ids, _ := calls[0].args[0].(string); !strings.Contains(ids, acme.String()) || !strings.Contains(ids, initech.String()) {
+		t.Errorf("client ids arg = %v", calls[0].args[0])
+	}
+
+	// Lista vazia não consulta o banco
+	counts, err = brands.CountByClients(context.Background(), nil)
+	if err != nil || len(counts) != 0 {
+		t.Errorf("empty list: counts = %v, err = %v", counts, err)
+	}
+	if len(backend.Calls()) != 1 {
+		t.Errorf("empty list issued %d more queries", len(backend.Calls())-1)
+	}
+}
+
+// =============================================================================
+// USERS
+// =============================================================================
+
+// ListByEmail traz as contas de todos os tenants, em ordem estável; só GetByEmail para
+// na segunda linha para detectar ambiguidade
+func TestUserListByEmailReturnsEveryTenant(t *testing.T) {
+	now := time.Now().UTC()
+	var rows [][]driver.Value
+	for i := 0; i < 3; i++ {
+		rows = append(rows, []driver.Value{
+			uuid.New().String(), uuid.New().String(), "ana@acme.com", "hash", "Ana", "analyst", "active", "{}", nil, int64(1), now, now,
+		})
+	}
+	db, backend := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		if strings.Contains(query, "LIMIT 2") {
+			return resultRows(rows[:2]...), nil
+		}
+		return resultRows(rows...), nil
+	})
+	users := NewUserService(db)
+	ctx := context.Background()
+
+	list, err := users.ListByEmail(ctx, " Ana@Acme.com", uuid.Nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[2].ID.String() != rows[2][0] {
+		t.Errorf("listed %d users, want all 3 in query order", len(list))
+	}
+	query := backend.Calls()[0].query
+	if strings.Contains(query, "LIMIT") || !strings.Contains(query, "ORDER BY created_at, id") {
+		t.Errorf("list query = %q, want no LIMIT and a stable ORDER BY", query)
+	}
+
+	if _, err := users.GetByEmail(ctx, "ana@acme.com", uuid.Nil); err != ErrAmbiguousEmail {
+		t.Errorf("GetByEmail err = %v, want ErrAmbiguousEmail", err)
+	}
+	if calls := backend.Calls(); !strings.Contains(calls[len(calls)-1].query, "LIMIT 2") {
+		t.Errorf("GetByEmail query = %q, want LIMIT 2", calls[len(calls)-1].query)
+	}
+}
+
+// =============================================================================
+// TENANTS
+// =============================================================================
+
+// O tenant criado no registro volta do banco com slug, email, settings e quotas: o
+// fake guarda a linha do INSERT e a devolve no SELECT
+func TestTenantCreateWithTenantRoundTrip(t *testing.T) {
+	var stored []driver.Value
+	db, _ := newFakeDB(t, false, func(query string, args []driver.Value) (fakeRows, error) {
+		switch {
+		case strings.Contains(query, "INSERT INTO tenants"):
+			stored = args
+		case strings.Contains(query, "FROM tenants"):
+			if stored == nil {
+				return resultRows(), nil
+			}
+			return resultRows(stored), nil
+		}
+		return fakeRows{affected: 1}, nil
+	})
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	tenant := &models.Tenant{
+		ID:     uuid.New(),
+		Name:   "Acme Segurança",
+		Slug:   "acme-seguranca",
+		Email:  " Owner@Acme.com ",
+		Plan:   "enterprise",
+		Status: models.StatusActive,
+		Settings: models.TenantSettings{
+			AllowedScopes: []models.Scope{models.ScopeHuntingRead, models.ScopeMonitorWrite},
+			AllowedTools:  []string{"hunting"},
+		},
+		Quotas:    models.TenantQuotas{MaxClients: 5, MaxBrands: 25},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	user := &models.User{ID: uuid.New(), TenantID: tenant.ID, Email: "owner@acme.com", Role: models.RoleAdmin, Status: models.StatusActive}
+	if err := NewUserService(db).CreateWithTenant(context.Background(), tenant, user); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewTenantService(db).GetByID(context.Background(), tenant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Slug != "acme-seguranca" || got.Email != "owner@acme.com" {
+		t.Errorf("slug, email = %q, %q", got.Slug, got.Email)
+	}
+	if !reflect.DeepEqual(got.Settings.AllowedScopes, tenant.Settings.AllowedScopes) || !reflect.DeepEqual(got.Settings.AllowedTools, []string{"hunting"}) {
+		t.Errorf("settings = %+v, want %+v", got.Settings, tenant.Settings)
+	}
+	if got.Quotas != tenant.Quotas {
+		t.Errorf("quotas = %+v, want %+v", got.Quotas, tenant.Quotas)
+	}
+}
+
+// =============================================================================
+// PAGINATION
+// =============================================================================
+
+func TestPageOffsetEdges(t *testing.T) {
+	for _, tc := range []struct {
+		page, perPage, want int
+	}{
+		{1, 20, 0},
+		{3, 20, 40},
+		{0, 20, 0},
+		{-5, 20, 0},
+		{2, 0, 0},
+		{2, -1, 0},
+		{math.MaxInt, 20, math.MaxInt},
+		{math.MaxInt/20 + 2, 20, math.MaxInt},
+		{2, math.MaxInt, math.MaxInt},
+	} {
+		if got := pageOffset(tc.page, tc.perPage); got != tc.want {
+			t.Errorf("pageOffset(%d, %d) = %d, want %d", tc.page, tc.perPage, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package ssrf impede que alvos de hunt/scan/análise apontem para a rede interna:
+// o MCP busca as URLs recebidas, então um alvo como 169.254.169.254 ou 10.0.0.5
+// daria acesso a serviços internos a partir dele
+package ssrf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Erros de validação
+var (
+	ErrBlockedAddress = errors.New("target resolves to a private or local address")
+	ErrUnresolvable   = errors.New("target host could not be resolved")
+	ErrInvalidTarget  = errors.New("invalid target")
+)
+
+// Timeout padrão da resolução DNS de um alvo
+const defaultResolveTimeout = 2 * time.Second
+
+// Faixas bloqueadas além das cobertas por netip.Addr (privadas, loopback, link-local,
+// multicast e não especificado)
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "esta rede"
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reservado e broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, embute IPv4 arbitrário
+}
+
+// Resolver resolve nomes de host (implementado por net.Resolver)
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Config configuração do Guard
+type Config struct {
+	// Allow IPs ou CIDRs internos liberados (ex.: a rede de um cliente on-prem)
+	Allow []string
+	// Resolver usado nas consultas DNS (padrão net.DefaultResolver)
+	Resolver Resolver
+	// ResolveTimeout limita cada resolução (padrão 2s)
+	ResolveTimeout time.Duration
+}
+
+// Guard valida alvos resolvendo o host e rejeitando endereços internos. Um Guard
+// nil aceita qualquer alvo (proteção desligada).
+type Guard struct {
+	allow    []netip.Prefix
+	resolver Resolver
+	timeout  time.Duration
+}
+
+// NewGuard cria um Guard; entradas inválidas em Allow retornam erro
+func NewGuard(config Config) (*Guard, error) {
+	g := &Guard{
+		resolver: config.Resolver,
+		timeout:  config.ResolveTimeout,
+	}
+	if g.resolver == nil {
+		g.resolver = net.DefaultResolver
+	}
+	if g.timeout <= 0 {
+		g.timeout = defaultResolveTimeout
+	}
+
+	for _, entry := range config.Allow {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			g.allow = append(g.allow, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			g.allow = append(g.allow, netip.PrefixFrom(addr, addr.BitLen()))
+		} else {
+			return nil, fmt.Errorf("invalid SSRF allow entry %q", entry)
+		}
+	}
+
+	return g, nil
+}
+
+// CheckTarget valida um alvo que pode ser uma URL ("https://host/path") ou apenas
+// um host, com ou sem porta ("marca.com.br", "10.0.0.1:8080"). Nomes são resolvidos
+// e todos os endereços retornados precisam ser públicos (ou liberados em Allow):
+// basta um interno para rejeitar, já que o MCP pode usar qualquer um deles. Isso
+// também cobre nomes que resolvem para IPs internos de propósito (DNS rebinding)
+// no momento da validação.
+func (g *Guard) CheckTarget(ctx context.Context, target string) error {
+	if g == nil {
+		return nil
+	}
+
+	host, err := targetHost(target)
+	if err != nil {
+		return err
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.checkAddr(addr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		return ErrUnresolvable
+	}
+	for _, addr := range addrs {
+		if err := g.checkAddr(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Control valida o IP efetivamente conectado; usado como net.Dialer.Control, fecha
+// a janela entre CheckTarget e a conexão em que o DNS pode mudar (rebinding)
+func (g *Guard) Control(network, address string, _ syscall.RawConn) error {
+	if g == nil {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrInvalidTarget
+	}
+	return g.checkAddr(addrPort.Addr())
+}
+
+// Máximo de redirecionamentos seguidos pelo cliente de HTTPClient
+const maxRedirects = 10
+
+// HTTPClient cria um cliente HTTP que só conecta em endereços aceitos pelo Guard.
+// O proxy de ambiente é ignorado: com ele a conexão seria para o proxy e o destino
+// final não passaria pela validação. Redirecionamentos são validados antes de
+// seguidos, e o IP de cada conexão é validado de novo no dial.
+func (g *Guard) HTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   g.Control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if err := g.CheckTarget(req.Context(), req.URL.String()); err != nil {
+				return fmt.Errorf("redirect to %s: %w", req.URL.Host, err)
+			}
+			return nil
+		},
+	}
+}
+
+func (g *Guard) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range g.allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if isBlocked(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// isBlocked verifica se o endereço está fora da internet pública
+func isBlocked(addr netip.Addr) bool {
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetHost extrai o host de uma URL ou de "host[:porta][/path]"
+func targetHost(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if !strings.Contains(target, "://") {
+		target = "//" + target
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", ErrInvalidTarget
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", ErrInvalidTarget
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "127.0.0.1", nil
+	}
+	return host, nil
+}
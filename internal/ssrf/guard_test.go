@@ -0,0 +1,103 @@
+package ssrf
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// staticResolver resolve cada host para os endereços fixos do teste
+type staticResolver map[string][]netip.Addr
+
+func (r staticResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	return r[host], nil
+}
+
+func TestControlChecksDialedAddress(t *testing.T) {
+	guard, err := NewGuard(Config{Allow: []string{"10.1.0.0/16"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		address string
+		want    error
+	}{
+		{"93.184.216.34:443", nil},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", nil},
+		{"10.1.2.3:8080", nil},
+		{"127.0.0.1:80", ErrBlockedAddress},
+		{"169.254.169.254:80", ErrBlockedAddress},
+		{"10.2.0.1:80", ErrBlockedAddress},
+		{"[::1]:443", ErrBlockedAddress},
+		{"[::ffff:127.0.0.1]:443", ErrBlockedAddress},
+		{"not-an-ip:80", ErrInvalidTarget},
+	} {
+		if err := guard.Control("tcp", tc.address, nil); !errors.Is(err, tc.want) {
+			t.Errorf("Control(%s) = %v, want %v", tc.address, err, tc.want)
+		}
+	}
+
+	var disabled *Guard
+	if err := disabled.Control("tcp", "127.0.0.1:80", nil); err != nil {
+		t.Errorf("nil guard: %v", err)
+	}
+}
+
+// DNS rebinding: o nome resolve para um IP público na validação e para um interno
+// na conexão. O cliente valida o IP conectado, então a entrega é recusada.
+func TestHTTPClientRefusesRebindingAtDialTime(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer server.Close()
+
+	guard, err := NewGuard(Config{Resolver: staticResolver{
+		"hooks.acme.com": {netip.MustParseAddr("93.184.216.34")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.CheckTarget(context.Background(), "https://hooks.acme.com/arca"); err != nil {
+		t.Fatalf("CheckTarget: %v", err)
+	}
+
+	// Na conexão o nome já aponta para o servidor local
+	_, err = guard.HTTPClient(time.Second).Get(server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Get = %v, want %v", err, ErrBlockedAddress)
+	}
+	if hits != 0 {
+		t.Error("request reached a loopback address")
+	}
+}
+
+func TestHTTPClientRefusesRedirectToBlockedAddress(t *testing.T) {
+	for _, location := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/admin",
+		"http://[::1]:8080/",
+		"http://internal.acme.com/",
+	} {
+		t.Run(location, func(t *testing.T) {
+			server := httptest.NewServer(http.RedirectHandler(location, http.StatusFound))
+			defer server.Close()
+
+			// O servidor de teste (loopback) é liberado; o destino do redirect não
+			guard, err := NewGuard(Config{
+				Allow:    []string{"127.0.0.1"},
+				Resolver: staticResolver{"internal.acme.com": {netip.MustParseAddr("10.1.2.3")}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = guard.HTTPClient(time.Second).Get(server.URL)
+			if !errors.Is(err, ErrBlockedAddress) {
+				t.Errorf("Get = %v, want %v", err, ErrBlockedAddress)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package testutil reúne o necessário para testar handlers com app.Test, sem
+// PostgreSQL, Redis ou MCP: tokens JWT e requests autenticadas, um app Fiber com o
+// mesmo tratamento de erros do servidor e stores em memória que implementam as
+// interfaces de persistência dos handlers (handlers.UserStore, ClientStore, ...).
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/auth"
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Configuração do JWTManager de testes
+const (
+	JWTSecret   = "testutil-jwt-secret-not-for-production-use"
+	JWTIssuer   = "arca-gateway-test"
+	JWTAudience = "arca-gateway-test"
+)
+
+// NewJWTManager cria o gerenciador JWT de testes (segredo, issuer e audience fixos)
+func NewJWTManager() *auth.JWTManager {
+	return auth.NewJWTManager(JWTSecret, 15*time.Minute, 24*time.Hour, JWTIssuer, JWTAudience)
+}
+
+// NewUser cria (sem persistir) um usuário ativo de um tenant novo com o role e os
+// scopes informados; sem scopes, o usuário recebe os scopes padrão do role
+func NewUser(role models.Role, scopes ...models.Scope) *models.User {
+	if len(scopes) == 0 {
+		scopes = models.GetDefaultScopesForRole(role)
+	}
+	now := time.Now().UTC()
+	id := uuid.New()
+	return &models.User{
+		ID:        id,
+		TenantID:  uuid.New(),
+		Email:     "user-" + id.String()[:8] + "@example.com",
+		Name:      "Test User",
+		Role:      role,
+		Scopes:    scopes,
+		Status:    models.StatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewPasswordHasher cria um hasher com o custo mínimo do bcrypt, para testes rápidos
+func NewPasswordHasher() *auth.PasswordHasher {
+	hasher, err := auth.NewPasswordHasher(bcrypt.MinCost)
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}
+
+// SetPassword grava em user.PasswordHash o hash da senha (NewPasswordHasher)
+func SetPassword(t testing.TB, user *models.User, password string) *models.User {
+	t.Helper()
+	hash, err := NewPasswordHasher().Hash(password)
+	if err != nil {
+		t.Fatalf("testutil: hash password: %v", err)
+	}
+	user.PasswordHash = hash
+	return user
+}
+
+// Token gera um access token do usuário
+func Token(t testing.TB, jwtManager *auth.JWTManager, user *models.User) string {
+	t.Helper()
+	token, err := jwtManager.GenerateAccessToken(user)
+	if err != nil {
+		t.Fatalf("testutil: generate access token: %v", err)
+	}
+	return token
+}
+
+// NewRequest cria uma request para app.Test. body nil envia a request sem corpo;
+// string e []byte são enviados como estão e os demais valores codificados em JSON.
+func NewRequest(t testing.TB, method, target string, body interface{}) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	case []byte:
+		reader = bytes.NewBuffer(b)
+	default:
+		raw, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("testutil: encode request body: %v", err)
+		}
+		reader = bytes.NewBuffer(raw)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if reader != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	return req
+}
+
+// AuthRequest cria uma request (como NewRequest) autenticada com um access token do usuário
+func AuthRequest(t testing.TB, jwtManager *auth.JWTManager, user *models.User, method, target string, body interface{}) *http.Request {
+	t.Helper()
+	req := NewRequest(t, method, target, body)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+Token(t, jwtManager, user))
+	return req
+}
+
+// NewApp cria um app Fiber com o mesmo tratamento de erros do servidor: erros
+// retornados pelos handlers viram o envelope padrão de response
+func NewApp() *fiber.App {
+	return fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+		ErrorHandler:          errorHandler,
+	})
+}
+
+// errorHandler equivalente ao handler de erros global de cmd/server
+func errorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	message := "Internal Server Error"
+
+	if e, ok := err.(*fiber.Error); ok {
+		code = e.Code
+		message = e.Message
+	}
+
+	if code == fiber.StatusRequestEntityTooLarge {
+		return response.PayloadTooLarge(c, "Request body too large")
+	}
+	return response.Error(c, code, response.CodeForStatus(code), message)
+}
+
+// Decode lê o envelope da resposta e decodifica data em dest (nil ignora data).
+// Fecha o corpo da resposta.
+func Decode(t testing.TB, resp *http.Response, dest interface{}) *response.Response {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var envelope struct {
+		response.Response
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("testutil: decode response (status %d): %v", resp.StatusCode, err)
+	}
+	if dest != nil && len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, dest); err != nil {
+			t.Fatalf("testutil: decode response data: %v", err)
+		}
+	}
+
+	result := envelope.Response
+	result.Data = dest
+	return &result
+}
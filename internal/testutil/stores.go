@@ -0,0 +1,976 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/arcaintelligence/arca-gateway/internal/models"
+	"github.com/arcaintelligence/arca-gateway/internal/services"
+	"github.com/google/uuid"
+)
+
+// =============================================================================
+// MEMORY
+// =============================================================================
+
+// Memory banco em memória compartilhado pelos stores de teste. Os stores seguem a
+// semântica dos services do PostgreSQL (services.ErrNotFound, ErrStaleVersion,
+// ErrQuotaExceeded, ErrLastAdmin, ...) e guardam cópias: alterar um modelo
+// retornado não altera o store.
+//
+//	mem := testutil.NewMemory()
+//	handler := handlers.NewClientHandler(mem.Clients(), mem.Brands(), mem.Tenants(), fake, nil)
+type Memory struct {
+	mu          sync.Mutex
+	tenants     map[uuid.UUID]*models.Tenant
+	users       map[uuid.UUID]*models.User
+	clients     map[uuid.UUID]*models.Client
+	brands      map[uuid.UUID]*models.Brand
+	loginEvents []*models.LoginEvent
+	rateLimits  map[uuid.UUID]int
+}
+
+// NewMemory cria um banco em memória vazio
+func NewMemory() *Memory {
+	return &Memory{
+		tenants:    make(map[uuid.UUID]*models.Tenant),
+		users:      make(map[uuid.UUID]*models.User),
+		clients:    make(map[uuid.UUID]*models.Client),
+		brands:     make(map[uuid.UUID]*models.Brand),
+		rateLimits: make(map[uuid.UUID]int),
+	}
+}
+
+// Users store de usuários (handlers.UserStore)
+func (m *Memory) Users() *MemoryUsers { return &MemoryUsers{m} }
+
+// Clients store de clientes (handlers.ClientStore)
+func (m *Memory) Clients() *MemoryClients { return &MemoryClients{m} }
+
+// Brands store de marcas (handlers.BrandStore e monitoring.BrandStore)
+func (m *Memory) Brands() *MemoryBrands { return &MemoryBrands{m} }
+
+// Tenants store de tenants (handlers.TenantStore e handlers.TenantAdminStore)
+func (m *Memory) Tenants() *MemoryTenants { return &MemoryTenants{m} }
+
+// LoginEvents store do histórico de logins (handlers.LoginEventStore)
+func (m *Memory) LoginEvents() *MemoryLoginEvents { return &MemoryLoginEvents{m} }
+
+// AddTenant grava o tenant (ativo, com id e slug gerados se vazios) e o retorna
+func (m *Memory) AddTenant(tenant *models.Tenant) *models.Tenant {
+	if tenant.ID == uuid.Nil {
+		tenant.ID = uuid.New()
+	}
+	if tenant.Slug == "" {
+		tenant.Slug = "tenant-" + tenant.ID.String()[:8]
+	}
+	if tenant.Status == "" {
+		tenant.Status = models.StatusActive
+	}
+	if tenant.CreatedAt.IsZero() {
+		tenant.CreatedAt = dbNow()
+		tenant.UpdatedAt = tenant.CreatedAt
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *tenant
+	m.tenants[tenant.ID] = &stored
+	return tenant
+}
+
+// AddUser grava o usuário (ex.: criado por NewUser), junto com um tenant ativo se o
+// tenant dele ainda não existir, e o retorna
+func (m *Memory) AddUser(user *models.User) *models.User {
+	m.mu.Lock()
+	_, ok := m.tenants[user.TenantID]
+	m.mu.Unlock()
+	if !ok {
+		m.AddTenant(&models.Tenant{ID: user.TenantID, Name: "Test Tenant", Plan: "professional"})
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user.Email = models.NormalizeEmail(user.Email)
+	stored := *user
+	m.users[user.ID] = &stored
+	return user
+}
+
+// AddClient grava o cliente (com id e datas gerados se vazios) e o retorna
+func (m *Memory) AddClient(client *models.Client) *models.Client {
+	if client.ID == uuid.Nil {
+		client.ID = uuid.New()
+	}
+	if client.Status == "" {
+		client.Status = models.StatusActive
+	}
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = dbNow()
+		client.UpdatedAt = client.CreatedAt
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *client
+	m.clients[client.ID] = &stored
+	return client
+}
+
+// AddBrand grava a marca (com id e datas gerados se vazios) e a retorna
+func (m *Memory) AddBrand(brand *models.Brand) *models.Brand {
+	if brand.ID == uuid.Nil {
+		brand.ID = uuid.New()
+	}
+	if brand.CreatedAt.IsZero() {
+		brand.CreatedAt = dbNow()
+		brand.UpdatedAt = brand.CreatedAt
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *brand
+	m.brands[brand.ID] = &stored
+	return brand
+}
+
+// dbNow horário gravado nas escritas, com a precisão do PostgreSQL (como nos services)
+func dbNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// page aplica page/perPage (como LIMIT/OFFSET) a uma lista já ordenada
+func page[T any](items []T, pageNum, perPage int) []T {
+	if pageNum < 1 || perPage < 1 {
+		pageNum, perPage = 1, len(items)
+	}
+	start := (pageNum - 1) * perPage
+	if start >= len(items) {
+		return nil
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// keysetPage aplica a paginação por cursor (services.Keyset) a uma lista já ordenada
+// por created_at e id decrescentes; more indica itens depois da página
+func keysetPage[T any](items []T, key func(T) (time.Time, uuid.UUID), after services.Keyset, limit int) ([]T, bool) {
+	start := 0
+	if !after.IsZero() {
+		start = len(items)
+		for i, item := range items {
+			createdAt, id := key(item)
+			if keysetLess(createdAt, id, after.CreatedAt, after.ID) {
+				start = i
+				break
+			}
+		}
+	}
+	items = items[start:]
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
+
+// keysetLess compara (created_at, id) como o PostgreSQL compara as linhas
+func keysetLess(createdAt time.Time, id uuid.UUID, otherCreatedAt time.Time, otherID uuid.UUID) bool {
+	if !createdAt.Equal(otherCreatedAt) {
+		return createdAt.Before(otherCreatedAt)
+	}
+	return bytes.Compare(id[:], otherID[:]) < 0
+}
+
+// =============================================================================
+// USERS
+// =============================================================================
+
+// MemoryUsers usuários em memória
+type MemoryUsers struct {
+	m *Memory
+}
+
+func (s *MemoryUsers) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	user, ok := s.m.users[id]
+	if !ok {
+		return nil, services.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (s *MemoryUsers) GetWithCredentials(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return s.GetByID(ctx, id)
+}
+
+// GetByEmail busca pelo email normalizado; com uuid.Nil a busca é global e retorna
+// services.ErrAmbiguousEmail se o email existir em mais de um tenant
+func (s *MemoryUsers) GetByEmail(ctx context.Context, email string, tenantID uuid.UUID) (*models.User, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	email = models.NormalizeEmail(email)
+	var found *models.User
+	for _, user := range s.m.users {
+		if user.Email != email || (tenantID != uuid.Nil && user.TenantID != tenantID) {
+			continue
+		}
+		if found != nil {
+			return nil, services.ErrAmbiguousEmail
+		}
+		copied := *user
+		found = &copied
+	}
+	if found == nil {
+		return nil, services.ErrNotFound
+	}
+	return found, nil
+}
+
+// ListByEmail busca pelo email normalizado, em todos os tenants com uuid.Nil, na ordem
+// (created_at, id) do PostgreSQL
+func (s *MemoryUsers) ListByEmail(ctx context.Context, email string, tenantID uuid.UUID) ([]*models.User, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	email = models.NormalizeEmail(email)
+	var found []*models.User
+	for _, user := range s.m.users {
+		if user.Email == email && (tenantID == uuid.Nil || user.TenantID == tenantID) {
+			copied := *user
+			found = append(found, &copied)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return keysetLess(found[i].CreatedAt, found[i].ID, found[j].CreatedAt, found[j].ID)
+	})
+	return found, nil
+}
+
+// CreateWithTenant grava o tenant e o usuário; retorna services.ErrAlreadyExists se
+// o slug do tenant ou o email no tenant já existirem
+func (s *MemoryUsers) CreateWithTenant(ctx context.Context, tenant *models.Tenant, user *models.User) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	tenant.Email = models.NormalizeEmail(tenant.Email)
+	user.Email = models.NormalizeEmail(user.Email)
+
+	for _, existing := range s.m.tenants {
+		if existing.ID == tenant.ID || (tenant.Slug != "" && existing.Slug == tenant.Slug) {
+			return services.ErrAlreadyExists
+		}
+	}
+	for _, existing := range s.m.users {
+		if existing.ID == user.ID || (existing.TenantID == user.TenantID && existing.Email == user.Email) {
+			return services.ErrAlreadyExists
+		}
+	}
+
+	storedTenant, storedUser := *tenant, *user
+	s.m.tenants[tenant.ID] = &storedTenant
+	s.m.users[user.ID] = &storedUser
+	return nil
+}
+
+// Update altera nome, role, status e scopes do usuário
+func (s *MemoryUsers) Update(ctx context.Context, user *models.User) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	stored, ok := s.m.users[user.ID]
+	if !ok {
+		return services.ErrNotFound
+	}
+	user.UpdatedAt = dbNow()
+	stored.Name = user.Name
+	stored.Role = user.Role
+	stored.Status = user.Status
+	stored.Scopes = append([]models.Scope(nil), user.Scopes...)
+	stored.UpdatedAt = user.UpdatedAt
+	return nil
+}
+
+func (s *MemoryUsers) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return s.update(id, func(user *models.User) {
+		user.PasswordHash = passwordHash
+		user.UpdatedAt = dbNow()
+	})
+}
+
+func (s *MemoryUsers) TouchLastLogin(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return s.update(id, func(user *models.User) {
+		user.LastLoginAt = &at
+	})
+}
+
+// VerifyEmail ativa o usuário pendente com o id e o email informados
+func (s *MemoryUsers) VerifyEmail(ctx context.Context, id uuid.UUID, email string) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	user, ok := s.m.users[id]
+	if !ok || user.Email != email || user.Status != models.StatusPending {
+		return services.ErrNotFound
+	}
+	user.Status = models.StatusActive
+	user.UpdatedAt = dbNow()
+	return nil
+}
+
+func (s *MemoryUsers) BumpTokenVersion(ctx context.Context, id uuid.UUID) (int, error) {
+	var version int
+	err := s.update(id, func(user *models.User) {
+		user.TokenVersion++
+		user.UpdatedAt = dbNow()
+		version = user.TokenVersion
+	})
+	return version, err
+}
+
+// DeleteAccount desativa (hard=false) ou apaga o usuário e seu histórico de login;
+// retorna services.ErrLastAdmin se ele for o último admin ativo do tenant
+func (s *MemoryUsers) DeleteAccount(ctx context.Context, user *models.User, hard bool) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if user.Role == models.RoleAdmin {
+		others := 0
+		for _, other := range s.m.users {
+			if other.TenantID == user.TenantID && other.ID != user.ID &&
+				other.Role == models.RoleAdmin && other.Status == models.StatusActive {
+				others++
+			}
+		}
+		if others == 0 {
+			return services.ErrLastAdmin
+		}
+	}
+
+	stored, ok := s.m.users[user.ID]
+	if !ok {
+		return services.ErrNotFound
+	}
+	if !hard {
+		stored.Status = models.StatusInactive
+		stored.TokenVersion++
+		stored.UpdatedAt = dbNow()
+		return nil
+	}
+
+	delete(s.m.users, user.ID)
+	events := s.m.loginEvents[:0]
+	for _, event := range s.m.loginEvents {
+		if event.UserID != user.ID {
+			events = append(events, event)
+		}
+	}
+	s.m.loginEvents = events
+	return nil
+}
+
+// update aplica fn ao usuário gravado; services.ErrNotFound se ele não existir
+func (s *MemoryUsers) update(id uuid.UUID, fn func(*models.User)) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	user, ok := s.m.users[id]
+	if !ok {
+		return services.ErrNotFound
+	}
+	fn(user)
+	return nil
+}
+
+// =============================================================================
+// CLIENTS
+// =============================================================================
+
+// MemoryClients clientes em memória
+type MemoryClients struct {
+	m *Memory
+}
+
+func (s *MemoryClients) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Client, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	client, ok := s.m.clients[id]
+	if !ok || client.TenantID != tenantID {
+		return nil, services.ErrNotFound
+	}
+	found := *client
+	return &found, nil
+}
+
+// ListByTenant lista os clientes do tenant, mais recentes primeiro
+func (s *MemoryClients) ListByTenant(ctx context.Context, tenantID uuid.UUID, pageNum, perPage int) ([]*models.Client, int64, error) {
+	clients := s.byTenant(tenantID)
+	return page(clients, pageNum, perPage), int64(len(clients)), nil
+}
+
+// ListByTenantAfter lista uma página de clientes do tenant a partir da posição after
+func (s *MemoryClients) ListByTenantAfter(ctx context.Context, tenantID uuid.UUID, after services.Keyset, limit int) ([]*models.Client, bool, error) {
+	clients, more := keysetPage(s.byTenant(tenantID), func(c *models.Client) (time.Time, uuid.UUID) {
+		return c.CreatedAt, c.ID
+	}, after, limit)
+	return clients, more, nil
+}
+
+func (s *MemoryClients) Each(ctx context.Context, tenantID uuid.UUID, fn func(*models.Client) error) error {
+	for _, client := range s.byTenant(tenantID) {
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryClients) SlugExists(ctx context.Context, tenantID uuid.UUID, slug string, excludeID uuid.UUID) (bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	for _, client := range s.m.clients {
+		if client.TenantID == tenantID && client.Slug == slug && client.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *MemoryClients) Create(ctx context.Context, client *models.Client) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.clients[client.ID]; ok {
+		return services.ErrAlreadyExists
+	}
+	stored := *client
+	s.m.clients[client.ID] = &stored
+	return nil
+}
+
+// Update grava o cliente; com unmodifiedSince retorna services.ErrStaleVersion se o
+// cliente foi alterado depois dele
+func (s *MemoryClients) Update(ctx context.Context, client *models.Client, unmodifiedSince *time.Time) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	stored, ok := s.m.clients[client.ID]
+	if !ok || stored.TenantID != client.TenantID {
+		return services.ErrNotFound
+	}
+	if unmodifiedSince != nil && stored.UpdatedAt.After(*unmodifiedSince) {
+		return services.ErrStaleVersion
+	}
+
+	client.UpdatedAt = dbNow()
+	updated := *client
+	updated.CreatedAt = stored.CreatedAt
+	s.m.clients[client.ID] = &updated
+	return nil
+}
+
+// Delete apaga o cliente e suas marcas (ON DELETE CASCADE)
+func (s *MemoryClients) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	client, ok := s.m.clients[id]
+	if !ok || client.TenantID != tenantID {
+		return services.ErrNotFound
+	}
+	delete(s.m.clients, id)
+	for brandID, brand := range s.m.brands {
+		if brand.ClientID == id {
+			delete(s.m.brands, brandID)
+		}
+	}
+	return nil
+}
+
+// byTenant cópias dos clientes do tenant, mais recentes primeiro (id como desempate)
+func (s *MemoryClients) byTenant(tenantID uuid.UUID) []*models.Client {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var clients []*models.Client
+	for _, client := range s.m.clients {
+		if client.TenantID == tenantID {
+			copied := *client
+			clients = append(clients, &copied)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return keysetLess(clients[j].CreatedAt, clients[j].ID, clients[i].CreatedAt, clients[i].ID)
+	})
+	return clients
+}
+
+// =============================================================================
+// BRANDS
+// =============================================================================
+
+// MemoryBrands marcas em memória
+type MemoryBrands struct {
+	m *Memory
+}
+
+func (s *MemoryBrands) GetByID(ctx context.Context, id, tenantID uuid.UUID) (*models.Brand, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	brand, ok := s.m.brands[id]
+	if !ok || brand.TenantID != tenantID {
+		return nil, services.ErrNotFound
+	}
+	found := *brand
+	return &found, nil
+}
+
+// ListByClient lista as marcas do cliente, mais recentes primeiro
+func (s *MemoryBrands) ListByClient(ctx context.Context, clientID, tenantID uuid.UUID, pageNum, perPage int) ([]*models.Brand, int64, error) {
+	brands := s.byClient(clientID, tenantID)
+	return page(brands, pageNum, perPage), int64(len(brands)), nil
+}
+
+// ListByClientAfter lista uma página de marcas do cliente a partir da posição after
+func (s *MemoryBrands) ListByClientAfter(ctx context.Context, clientID, tenantID uuid.UUID, after services.Keyset, limit int) ([]*models.Brand, bool, error) {
+	brands, more := keysetPage(s.byClient(clientID, tenantID), func(b *models.Brand) (time.Time, uuid.UUID) {
+		return b.CreatedAt, b.ID
+	}, after, limit)
+	return brands, more, nil
+}
+
+func (s *MemoryBrands) EachByClient(ctx context.Context, clientID, tenantID uuid.UUID, fn func(*models.Brand) error) error {
+	for _, brand := range s.byClient(clientID, tenantID) {
+		if err := fn(brand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListMonitored marcas com job de monitoramento, de um tenant ou de todos (tenantID nil)
+func (s *MemoryBrands) ListMonitored(ctx context.Context, tenantID *uuid.UUID) ([]*models.Brand, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var brands []*models.Brand
+	for _, brand := range s.m.brands {
+		if brand.MonitoringJobID != nil && (tenantID == nil || brand.TenantID == *tenantID) {
+			copied := *brand
+			brands = append(brands, &copied)
+		}
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		if brands[i].TenantID != brands[j].TenantID {
+			return brands[i].TenantID.String() < brands[j].TenantID.String()
+		}
+		return brands[i].ID.String() < brands[j].ID.String()
+	})
+	return brands, nil
+}
+
+func (s *MemoryBrands) CountByClient(ctx context.Context, clientID uuid.UUID) (int, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	count := 0
+	for _, brand := range s.m.brands {
+		if brand.ClientID == clientID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemoryBrands) CountByClients(ctx context.Context, clientIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	counts := make(map[uuid.UUID]int, len(clientIDs))
+	for _, id := range clientIDs {
+		counts[id] = 0
+	}
+	for _, brand := range s.m.brands {
+		if _, ok := counts[brand.ClientID]; ok {
+			counts[brand.ClientID]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *MemoryBrands) Create(ctx context.Context, brand *models.Brand) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+	return s.insert(brand)
+}
+
+// CreateMany grava as marcas com a quota maxBrands do tenant (0 = sem limite): sem
+// allowPartial é tudo-ou-nada; com allowPartial os itens além da quota recebem
+// services.ErrQuotaExceeded
+func (s *MemoryBrands) CreateMany(ctx context.Context, tenantID uuid.UUID, brands []*models.Brand, maxBrands int, allowPartial bool) ([]error, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	existing := 0
+	for _, brand := range s.m.brands {
+		if brand.TenantID == tenantID {
+			existing++
+		}
+	}
+
+	remaining := len(brands)
+	if maxBrands > 0 {
+		remaining = maxBrands - existing
+		if remaining < len(brands) && !allowPartial {
+			return nil, services.ErrQuotaExceeded
+		}
+	}
+
+	results := make([]error, len(brands))
+	var inserted []uuid.UUID
+	for i, brand := range brands {
+		if len(inserted) >= remaining {
+			results[i] = services.ErrQuotaExceeded
+			continue
+		}
+		brand.TenantID = tenantID
+		if err := s.insert(brand); err != nil {
+			results[i] = err
+			if !allowPartial {
+				for _, id := range inserted {
+					delete(s.m.brands, id)
+				}
+				return results, err
+			}
+			continue
+		}
+		inserted = append(inserted, brand.ID)
+	}
+	return results, nil
+}
+
+// Update grava a marca; com unmodifiedSince retorna services.ErrStaleVersion se a
+// marca foi alterada depois dele
+func (s *MemoryBrands) Update(ctx context.Context, brand *models.Brand, unmodifiedSince *time.Time) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	stored, ok := s.m.brands[brand.ID]
+	if !ok || stored.TenantID != brand.TenantID {
+		return services.ErrNotFound
+	}
+	if unmodifiedSince != nil && stored.UpdatedAt.After(*unmodifiedSince) {
+		return services.ErrStaleVersion
+	}
+
+	brand.UpdatedAt = dbNow()
+	stored.Name = brand.Name
+	stored.PrimaryDomain = brand.PrimaryDomain
+	stored.Industry = brand.Industry
+	stored.MonitoringEnabled = brand.MonitoringEnabled
+	stored.Config = brand.Config
+	stored.UpdatedAt = brand.UpdatedAt
+	return nil
+}
+
+// UpdateMonitoring grava o estado de monitoramento da marca
+func (s *MemoryBrands) UpdateMonitoring(ctx context.Context, brand *models.Brand) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	stored, ok := s.m.brands[brand.ID]
+	if !ok || stored.TenantID != brand.TenantID {
+		return services.ErrNotFound
+	}
+
+	brand.UpdatedAt = dbNow()
+	stored.Status = brand.Status
+	stored.MonitoringJobID = brand.MonitoringJobID
+	stored.LastScanAt = brand.LastScanAt
+	stored.ThreatsFound = brand.ThreatsFound
+	stored.UpdatedAt = brand.UpdatedAt
+	return nil
+}
+
+func (s *MemoryBrands) IncrementThreatsFound(ctx context.Context, id, tenantID uuid.UUID) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	brand, ok := s.m.brands[id]
+	if !ok || brand.TenantID != tenantID {
+		return services.ErrNotFound
+	}
+	brand.ThreatsFound++
+	brand.UpdatedAt = dbNow()
+	return nil
+}
+
+func (s *MemoryBrands) Delete(ctx context.Context, id, tenantID uuid.UUID) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	brand, ok := s.m.brands[id]
+	if !ok || brand.TenantID != tenantID {
+		return services.ErrNotFound
+	}
+	delete(s.m.brands, id)
+	return nil
+}
+
+// insert grava uma cópia da marca; chamado com o lock
+func (s *MemoryBrands) insert(brand *models.Brand) error {
+	if _, ok := s.m.brands[brand.ID]; ok {
+		return services.ErrAlreadyExists
+	}
+	stored := *brand
+	s.m.brands[brand.ID] = &stored
+	return nil
+}
+
+// byClient cópias das marcas do cliente, mais recentes primeiro (id como desempate)
+func (s *MemoryBrands) byClient(clientID, tenantID uuid.UUID) []*models.Brand {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	var brands []*models.Brand
+	for _, brand := range s.m.brands {
+		if brand.ClientID == clientID && brand.TenantID == tenantID {
+			copied := *brand
+			brands = append(brands, &copied)
+		}
+	}
+	sort.Slice(brands, func(i, j int) bool {
+		return keysetLess(brands[j].CreatedAt, brands[j].ID, brands[i].CreatedAt, brands[i].ID)
+	})
+	return brands
+}
+
+// =============================================================================
+// TENANTS
+// =============================================================================
+
+// MemoryTenants tenants em memória
+type MemoryTenants struct {
+	m *Memory
+}
+
+func (s *MemoryTenants) GetByID(ctx context.Context, id uuid.UUID) (*models.Tenant, error) {
+	return s.get(id)
+}
+
+func (s *MemoryTenants) GetBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	for _, tenant := range s.m.tenants {
+		if tenant.Slug == slug {
+			found := *tenant
+			return &found, nil
+		}
+	}
+	return nil, services.ErrNotFound
+}
+
+func (s *MemoryTenants) SlugExists(ctx context.Context, slug string) (bool, error) {
+	_, err := s.GetBySlug(ctx, slug)
+	if err == services.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *MemoryTenants) GetStatus(ctx context.Context, id uuid.UUID) (models.Status, error) {
+	tenant, err := s.get(id)
+	if err != nil {
+		return "", err
+	}
+	return tenant.Status, nil
+}
+
+func (s *MemoryTenants) GetSettings(ctx context.Context, id uuid.UUID) (*models.TenantSettings, error) {
+	tenant, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return &tenant.Settings, nil
+}
+
+func (s *MemoryTenants) GetPolicy(ctx context.Context, id uuid.UUID) (*models.TenantPolicy, error) {
+	tenant, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &models.TenantPolicy{
+		TenantID:      tenant.ID,
+		Plan:          tenant.Plan,
+		Status:        tenant.Status,
+		AllowedScopes: tenant.Settings.AllowedScopes,
+		AllowedTools:  tenant.Settings.AllowedTools,
+		Quotas:        tenant.Quotas,
+	}
+	if policy.AllowedScopes == nil {
+		policy.AllowedScopes = []models.Scope{}
+	}
+	if policy.AllowedTools == nil {
+		policy.AllowedTools = []string{}
+	}
+	return policy, nil
+}
+
+// Update altera o nome, o email e o status do tenant
+func (s *MemoryTenants) Update(ctx context.Context, tenant *models.Tenant) error {
+	tenant.UpdatedAt = dbNow()
+	return s.update(tenant.ID, func(stored *models.Tenant) {
+		stored.Name = tenant.Name
+		stored.Email = tenant.Email
+		stored.Status = tenant.Status
+	})
+}
+
+func (s *MemoryTenants) UpdatePlan(ctx context.Context, id uuid.UUID, plan string) error {
+	return s.update(id, func(tenant *models.Tenant) { tenant.Plan = plan })
+}
+
+func (s *MemoryTenants) UpdateQuotas(ctx context.Context, id uuid.UUID, quotas models.TenantQuotas) error {
+	return s.update(id, func(tenant *models.Tenant) { tenant.Quotas = quotas })
+}
+
+func (s *MemoryTenants) UpdateSettings(ctx context.Context, id uuid.UUID, settings models.TenantSettings) error {
+	return s.update(id, func(tenant *models.Tenant) { tenant.Settings = settings })
+}
+
+// GetRateLimit limite customizado (req/min) do tenant; 0 sem limite customizado
+func (s *MemoryTenants) GetRateLimit(ctx context.Context, id uuid.UUID) (int, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.tenants[id]; !ok {
+		return 0, services.ErrNotFound
+	}
+	return s.m.rateLimits[id], nil
+}
+
+// SetRateLimit define o limite customizado (req/min) do tenant; 0 remove o limite
+func (s *MemoryTenants) SetRateLimit(ctx context.Context, id uuid.UUID, limit int) error {
+	return s.update(id, func(tenant *models.Tenant) {
+		if limit > 0 {
+			s.m.rateLimits[id] = limit
+		} else {
+			delete(s.m.rateLimits, id)
+		}
+	})
+}
+
+// Delete inativa o tenant (hard=false) ou o apaga com usuários, clientes, marcas e
+// histórico de login
+func (s *MemoryTenants) Delete(ctx context.Context, id uuid.UUID, hard bool) error {
+	if !hard {
+		return s.update(id, func(tenant *models.Tenant) { tenant.Status = models.StatusInactive })
+	}
+
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.tenants[id]; !ok {
+		return services.ErrNotFound
+	}
+	for userID, user := range s.m.users {
+		if user.TenantID == id {
+			delete(s.m.users, userID)
+		}
+	}
+	for clientID, client := range s.m.clients {
+		if client.TenantID == id {
+			delete(s.m.clients, clientID)
+		}
+	}
+	for brandID, brand := range s.m.brands {
+		if brand.TenantID == id {
+			delete(s.m.brands, brandID)
+		}
+	}
+	events := s.m.loginEvents[:0]
+	for _, event := range s.m.loginEvents {
+		if event.TenantID != id {
+			events = append(events, event)
+		}
+	}
+	s.m.loginEvents = events
+	delete(s.m.rateLimits, id)
+	delete(s.m.tenants, id)
+	return nil
+}
+
+// update aplica fn ao tenant gravado e atualiza updated_at; services.ErrNotFound
+// se ele não existir
+func (s *MemoryTenants) update(id uuid.UUID, fn func(*models.Tenant)) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	tenant, ok := s.m.tenants[id]
+	if !ok {
+		return services.ErrNotFound
+	}
+	fn(tenant)
+	tenant.UpdatedAt = dbNow()
+	return nil
+}
+
+// get cópia do tenant; services.ErrNotFound se ele não existir
+func (s *MemoryTenants) get(id uuid.UUID) (*models.Tenant, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	tenant, ok := s.m.tenants[id]
+	if !ok {
+		return nil, services.ErrNotFound
+	}
+	found := *tenant
+	return &found, nil
+}
+
+// =============================================================================
+// LOGIN EVENTS
+// =============================================================================
+
+// MemoryLoginEvents histórico de logins em memória
+type MemoryLoginEvents struct {
+	m *Memory
+}
+
+func (s *MemoryLoginEvents) Record(ctx context.Context, event *models.LoginEvent) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	stored := *event
+	s.m.loginEvents = append(s.m.loginEvents, &stored)
+	return nil
+}
+
+// ListByUser tentativas de login mais recentes do usuário
+func (s *MemoryLoginEvents) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*models.LoginEvent, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	events := []*models.LoginEvent{}
+	for i := len(s.m.loginEvents) - 1; i >= 0 && len(events) < limit; i-- {
+		if event := s.m.loginEvents[i]; event.UserID == userID {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,84 @@
+// Package buildinfo expõe a versão, o commit e a data do build, injetados em tempo
+// de compilação:
+//
+//	go build -ldflags "-X github.com/arcaintelligence/arca-gateway/pkg/buildinfo.Version=1.2.0 \
+//	  -X github.com/arcaintelligence/arca-gateway/pkg/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/arcaintelligence/arca-gateway/pkg/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Sem -ldflags, commit e data vêm das informações de VCS que o Go grava no binário
+// (quando disponíveis).
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Valores injetados via -ldflags "-X"
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Tamanho do hash curto do commit
+const shortCommitLen = 7
+
+// Info informações do build em execução
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get retorna as informações do build, completando commit e data com os dados de
+// VCS do binário quando não foram injetados
+func Get() Info {
+	once.Do(func() {
+		info = Info{
+			Version:   Version,
+			Commit:    Commit,
+			BuildDate: Date,
+			GoVersion: runtime.Version(),
+		}
+
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					if info.Commit == "" {
+						info.Commit = setting.Value
+					}
+				case "vcs.time":
+					if info.BuildDate == "" {
+						info.BuildDate = setting.Value
+					}
+				}
+			}
+		}
+
+		if len(info.Commit) > shortCommitLen {
+			info.Commit = info.Commit[:shortCommitLen]
+		}
+		if info.Commit == "" {
+			info.Commit = "unknown"
+		}
+		if info.BuildDate == "" {
+			info.BuildDate = "unknown"
+		}
+	})
+	return info
+}
+
+// String formata as informações para o banner e --version (ex.: "1.2.0 (commit abc1234, built 2024-01-01T00:00:00Z, go1.24.0)")
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion)
+}
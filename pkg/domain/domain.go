@@ -0,0 +1,143 @@
+// Package domain valida e normaliza nomes de domínio de marcas (domínio principal,
+// domínios adicionais e variações conhecidas) para que apenas hosts válidos, em uma
+// forma canônica, cheguem ao monitoramento
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ErrInvalidDomain indica um valor que não é um nome de domínio registrável
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// Limites de tamanho de nomes DNS (RFC 1035)
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// labelPattern label DNS em ASCII (IDNs já convertidos para punycode)
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// profile conversão IDNA usada em lookups: mapeia maiúsculas e caracteres
+// equivalentes e rejeita labels inválidos
+var profile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(true),
+	idna.Transitional(false),
+)
+
+// Normalize extrai o host de raw e retorna sua forma canônica: sem esquema, porta,
+// credenciais, path, query ou ponto final, em minúsculas e com IDNs em punycode.
+// O domínio precisa terminar em um sufixo público conhecido e não pode ser o
+// próprio sufixo nem um IP.
+// Ex: "HTTPS://Example.com:8443/login?x=1" -> "example.com",
+// "Exämple.com.br" -> "xn--exmple-cua.com.br".
+func Normalize(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", fmt.Errorf("%w: empty value", ErrInvalidDomain)
+	}
+	host := extractHost(raw)
+	if host == "" {
+		return "", fmt.Errorf("%w: %q is not a valid hostname", ErrInvalidDomain, raw)
+	}
+	if net.ParseIP(strings.Trim(host, "[]")) != nil {
+		return "", fmt.Errorf("%w: %q is an IP address", ErrInvalidDomain, raw)
+	}
+
+	ascii, err := profile.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q is not a valid hostname", ErrInvalidDomain, raw)
+	}
+	ascii = strings.TrimSuffix(strings.ToLower(ascii), ".")
+
+	if len(ascii) > maxDomainLength {
+		return "", fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidDomain, raw, maxDomainLength)
+	}
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("%w: %q has no top-level domain", ErrInvalidDomain, raw)
+	}
+	for _, label := range labels {
+		if len(label) > maxLabelLength || !labelPattern.MatchString(label) {
+			return "", fmt.Errorf("%w: %q is not a valid hostname", ErrInvalidDomain, raw)
+		}
+	}
+
+	// Sufixos fora da lista (ex.: ".local", ".invalid") voltam com icann=false e
+	// sem ponto; sufixos privados listados (ex.: "github.io") têm ponto
+	suffix, icann := publicsuffix.PublicSuffix(ascii)
+	if !icann && !strings.Contains(suffix, ".") {
+		return "", fmt.Errorf("%w: %q has an unknown top-level domain", ErrInvalidDomain, raw)
+	}
+	if _, err := publicsuffix.EffectiveTLDPlusOne(ascii); err != nil {
+		return "", fmt.Errorf("%w: %q is a public suffix", ErrInvalidDomain, raw)
+	}
+
+	return ascii, nil
+}
+
+// NormalizeList normaliza cada domínio de values e remove os repetidos (após a
+// normalização), mantendo a ordem da primeira ocorrência. O erro indica o índice
+// do primeiro valor inválido.
+func NormalizeList(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	normalized := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for i, value := range values {
+		domain, err := Normalize(value)
+		if err != nil {
+			return nil, &ListError{Index: i, Err: err}
+		}
+		if seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		normalized = append(normalized, domain)
+	}
+	return normalized, nil
+}
+
+// ListError domínio inválido em uma lista, com sua posição
+type ListError struct {
+	Index int
+	Err   error
+}
+
+func (e *ListError) Error() string {
+	return fmt.Sprintf("[%d]: %v", e.Index, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+// extractHost isola o host de uma URL ou de um domínio com path/porta
+func extractHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.ContainsAny(raw, " \t\r\n") {
+		return ""
+	}
+
+	if !strings.Contains(raw, "://") {
+		// "//" força o parse como authority: "example.com:8080/path" não vira esquema
+		raw = "//" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
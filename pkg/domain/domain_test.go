@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want string
+	}{
+		{"example.com", "example.com"},
+		{"HTTPS://Example.com:8443/login?x=1", "example.com"},
+		{"http://user:pw@Acme.com:8080/x?y#z", "acme.com"},
+		{"acme.com.", "acme.com"},
+		{"  Acme.COM.br  ", "acme.com.br"},
+		{"acme.github.io", "acme.github.io"},
+		// IDNs viram punycode; entradas já em punycode (em qualquer caixa) ficam iguais
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"MÜNCHEN.DE", "xn--mnchen-3ya.de"},
+		{"XN--MNCHEN-3YA.DE", "xn--mnchen-3ya.de"},
+		{"https://bücher.com.br/loja", "xn--bcher-kva.com.br"},
+		{"правительство.рф", "xn--80aealotwbjpid2k.xn--p1ai"},
+		{"例え.jp", "xn--r8jz45g.jp"},
+		// IDNA2008 (não transicional): ß é mantido em vez de virar "ss"
+		{"faß.de", "xn--fa-hia.de"},
+		// Caracteres de largura total são mapeados para ASCII
+		{"ｅｘａｍｐｌｅ.com", "example.com"},
+	} {
+		got, err := Normalize(tc.raw)
+		if err != nil || got != tc.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tc.raw, got, err, tc.want)
+		}
+	}
+}
+
+func TestNormalizeRejectsInvalidDomains(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"not a domain",
+		"localhost",
+		"acme..com",
+		"-acme.com",
+		"ex_ample.com",
+		"acme.local",
+		"com.br",
+		"github.io",
+		"192.168.0.1",
+		"[::1]",
+		// Punycode inválido
+		"xn--a.com",
+		"xn--zz.com",
+	} {
+		if got, err := Normalize(raw); !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("Normalize(%q) = %q, %v, want ErrInvalidDomain", raw, got, err)
+		}
+	}
+}
+
+// Forma Unicode, punycode e URL do mesmo domínio são a mesma entrada
+func TestNormalizeListDeduplicatesIDNForms(t *testing.T) {
+	got, err := NormalizeList([]string{"münchen.de", "xn--mnchen-3ya.de", "https://MÜNCHEN.de/x", "acme.com", "ACME.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"xn--mnchen-3ya.de", "acme.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NormalizeList = %v, want %v", got, want)
+	}
+
+	_, err = NormalizeList([]string{"acme.com", "münchen.de", "xn--a.com"})
+	var listErr *ListError
+	if !errors.As(err, &listErr) || listErr.Index != 2 || !errors.Is(err, ErrInvalidDomain) {
+		t.Errorf("err = %v, want ListError at index 2", err)
+	}
+
+	if got, err := NormalizeList(nil); got != nil || err != nil {
+		t.Errorf("NormalizeList(nil) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Chaves de c.Locals lidas pelos helpers de contexto. tenant_id e user_id são
+// gravados pelo middleware de autenticação; requestid pelo middleware de request id.
+const (
+	LocalsKey       = "logger"
+	localsRequestID = "requestid"
+	localsTenantID  = "tenant_id"
+	localsUserID    = "user_id"
+)
+
+// ToContext armazena o logger em c.Locals para ser recuperado por FromContext
+func ToContext(c *fiber.Ctx, l *Logger) {
+	c.Locals(LocalsKey, l)
+}
+
+// FromContext retorna um logger pré-populado com request_id, tenant_id, user_id e route
+// da request. Usa o logger armazenado pelo middleware ou, na ausência dele, o logger global.
+func FromContext(c *fiber.Ctx) *Logger {
+	l := fromValues(func(key string) interface{} { return c.Locals(key) })
+
+	if _, ok := l.fields["request_id"]; !ok {
+		if requestID := c.Get("X-Request-ID"); requestID != "" {
+			l.fields["request_id"] = requestID
+		}
+	}
+	if r := c.Route(); r != nil && r.Path != "" {
+		l.fields["route"] = r.Path
+	}
+
+	return l
+}
+
+// WithContext retorna um logger correlacionado a partir de um context.Context.
+// Funciona com c.Context() do Fiber, cujos valores são os mesmos de c.Locals,
+// permitindo que camadas sem acesso ao *fiber.Ctx (ex: cliente MCP) loguem com correlação.
+func WithContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return defaultLogger.clone()
+	}
+	return fromValues(func(key string) interface{} { return ctx.Value(key) })
+}
+
+// fromValues monta o logger a partir de uma função de lookup de valores da request
+func fromValues(value func(key string) interface{}) *Logger {
+	base, ok := value(LocalsKey).(*Logger)
+	if !ok || base == nil {
+		base = defaultLogger
+	}
+
+	l := base.clone()
+	if requestID, ok := value(localsRequestID).(string); ok && requestID != "" {
+		l.fields["request_id"] = requestID
+	}
+	if tenantID, ok := value(localsTenantID).(uuid.UUID); ok && tenantID != uuid.Nil {
+		l.fields["tenant_id"] = tenantID.String()
+		l.sampleKey = tenantID.String()
+	}
+	if userID, ok := value(localsUserID).(uuid.UUID); ok && userID != uuid.Nil {
+		l.fields["user_id"] = userID.String()
+	}
+
+	return l
+}
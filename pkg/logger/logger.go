@@ -6,7 +6,9 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -38,6 +40,23 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel converte uma string (debug, info, warn, error, fatal) em Level.
+// Valores desconhecidos retornam InfoLevel.
+func ParseLevel(level string) Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return DebugLevel
+	case "warn", "warning":
+		return WarnLevel
+	case "error":
+		return ErrorLevel
+	case "fatal":
+		return FatalLevel
+	default:
+		return InfoLevel
+	}
+}
+
 // Entry representa uma entrada de log
 type Entry struct {
 	Level     string                 `json:"level"`
@@ -45,15 +64,19 @@ type Entry struct {
 	Message   string                 `json:"message"`
 	Fields    map[string]interface{} `json:"fields,omitempty"`
 	Caller    string                 `json:"caller,omitempty"`
+	Stack     string                 `json:"stack,omitempty"`
 }
 
 // Logger logger estruturado
 type Logger struct {
-	mu        sync.Mutex
-	output    io.Writer
-	level     Level
-	fields    map[string]interface{}
-	addCaller bool
+	mu           sync.Mutex
+	output       io.Writer
+	level        *atomic.Int32
+	fields       map[string]interface{}
+	addCaller    bool
+	captureStack bool
+	sampler      *debugSampler
+	sampleKey    string
 }
 
 // Config configuração do logger
@@ -61,6 +84,24 @@ type Config struct {
 	Level     Level
 	Output    io.Writer
 	AddCaller bool
+	// Captura um stack trace curto nas entradas de nível Error e Fatal
+	CaptureStack bool
+	// Amostragem de logs de debug por tenant: com N=10 apenas 1 a cada 10
+	// entradas de debug de um mesmo tenant é escrita (0 ou 1 desabilita)
+	DebugSampleRate int
+}
+
+// debugSampler amostra logs de debug por chave (tenant)
+type debugSampler struct {
+	rate     uint64
+	counters sync.Map
+}
+
+// allow indica se a entrada de debug da chave deve ser escrita
+func (s *debugSampler) allow(key string) bool {
+	counter, _ := s.counters.LoadOrStore(key, new(uint64))
+	n := atomic.AddUint64(counter.(*uint64), 1)
+	return (n-1)%s.rate == 0
 }
 
 // New cria um novo logger
@@ -68,12 +109,29 @@ func New(cfg Config) *Logger {
 	if cfg.Output == nil {
 		cfg.Output = os.Stdout
 	}
-	return &Logger{
-		output:    cfg.Output,
-		level:     cfg.Level,
-		fields:    make(map[string]interface{}),
-		addCaller: cfg.AddCaller,
+	l := &Logger{
+		output:       cfg.Output,
+		level:        new(atomic.Int32),
+		fields:       make(map[string]interface{}),
+		addCaller:    cfg.AddCaller,
+		captureStack: cfg.CaptureStack,
+	}
+	l.level.Store(int32(cfg.Level))
+	if cfg.DebugSampleRate > 1 {
+		l.sampler = &debugSampler{rate: uint64(cfg.DebugSampleRate)}
 	}
+	return l
+}
+
+// SetLevel altera o nível mínimo de log em tempo de execução. O nível é
+// compartilhado com os loggers derivados (WithField, WithError, ...).
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+// GetLevel retorna o nível mínimo de log atual
+func (l *Logger) GetLevel() Level {
+	return Level(l.level.Load())
 }
 
 // Default retorna um logger padrão
@@ -87,39 +145,56 @@ func Default() *Logger {
 
 // WithField adiciona um campo ao logger
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	newLogger := &Logger{
-		output:    l.output,
-		level:     l.level,
-		fields:    make(map[string]interface{}),
-		addCaller: l.addCaller,
-	}
-	for k, v := range l.fields {
-		newLogger.fields[k] = v
-	}
+	newLogger := l.clone()
 	newLogger.fields[key] = value
 	return newLogger
 }
 
 // WithFields adiciona múltiplos campos ao logger
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	newLogger := l.clone()
+	for k, v := range fields {
+		newLogger.fields[k] = v
+	}
+	return newLogger
+}
+
+// WithError adiciona o erro (mensagem e tipo) ao logger:
+//
+//	log.WithError(err).Error("failed to send alert to %s", channel)
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l.clone()
+	}
+	return l.WithFields(map[string]interface{}{
+		"error":      err.Error(),
+		"error_type": fmt.Sprintf("%T", err),
+	})
+}
+
+// clone cria uma cópia do logger com os mesmos campos
+func (l *Logger) clone() *Logger {
 	newLogger := &Logger{
-		output:    l.output,
-		level:     l.level,
-		fields:    make(map[string]interface{}),
-		addCaller: l.addCaller,
+		output:       l.output,
+		level:        l.level,
+		fields:       make(map[string]interface{}, len(l.fields)),
+		addCaller:    l.addCaller,
+		captureStack: l.captureStack,
+		sampler:      l.sampler,
+		sampleKey:    l.sampleKey,
 	}
 	for k, v := range l.fields {
 		newLogger.fields[k] = v
 	}
-	for k, v := range fields {
-		newLogger.fields[k] = v
-	}
 	return newLogger
 }
 
 // log escreve uma entrada de log
 func (l *Logger) log(level Level, msg string, args ...interface{}) {
-	if level < l.level {
+	if level < l.GetLevel() {
+		return
+	}
+	if level == DebugLevel && l.sampler != nil && l.sampleKey != "" && !l.sampler.allow(l.sampleKey) {
 		return
 	}
 
@@ -140,6 +215,10 @@ func (l *Logger) log(level Level, msg string, args ...interface{}) {
 		}
 	}
 
+	if l.captureStack && level >= ErrorLevel {
+		entry.Stack = captureStack(3)
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -176,6 +255,26 @@ func (l *Logger) Fatal(msg string, args ...interface{}) {
 	l.log(FatalLevel, msg, args...)
 }
 
+// maxStackFrames limite de frames capturados no stack trace
+const maxStackFrames = 16
+
+// captureStack retorna um stack trace curto a partir do chamador, ignorando skip frames
+func captureStack(skip int) string {
+	pcs := make([]uintptr, maxStackFrames)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
 // =============================================================================
 // GLOBAL LOGGER
 // =============================================================================
@@ -221,3 +320,8 @@ func WithField(key string, value interface{}) *Logger {
 func WithFields(fields map[string]interface{}) *Logger {
 	return defaultLogger.WithFields(fields)
 }
+
+// WithError adiciona o erro ao logger global
+func WithError(err error) *Logger {
+	return defaultLogger.WithError(err)
+}
@@ -0,0 +1,163 @@
+// Package request faz o parse do body das requests e valida o resultado com as
+// tags `validate` das structs (go-playground/validator), para que os handlers não
+// repitam checagens de campos obrigatórios
+package request
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/arcaintelligence/arca-gateway/pkg/response"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrInvalidBody indica um body que não pôde ser lido (JSON malformado, tipo
+// errado, content-type não suportado)
+var ErrInvalidBody = errors.New("invalid request body")
+
+// ValidationError erros de validação por campo de um body lido com sucesso
+type ValidationError struct {
+	Fields []response.ValidationError
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		names[i] = field.Field
+	}
+	return "validation failed: " + strings.Join(names, ", ")
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Os erros usam o nome do campo no JSON, não o da struct
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// BindAndValidate lê o body em um T e aplica as tags `validate`. Falhas de parse
+// retornam um erro que satisfaz errors.Is(err, ErrInvalidBody); falhas de validação
+// retornam *ValidationError. Use RespondError para responder qualquer um dos dois.
+func BindAndValidate[T any](c *fiber.Ctx) (*T, error) {
+	req := new(T)
+	if err := c.BodyParser(req); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBody, err)
+	}
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Validate aplica as tags `validate` de uma struct já preenchida
+func Validate(v interface{}) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	result := &ValidationError{Fields: make([]response.ValidationError, len(fieldErrors))}
+	for i, fieldError := range fieldErrors {
+		result.Fields[i] = response.ValidationError{
+			Field:   fieldName(fieldError),
+			Message: fieldMessage(fieldError),
+		}
+	}
+	return result
+}
+
+// RespondError responde um erro de BindAndValidate: 400 para body inválido e 422
+// com os erros por campo para falhas de validação
+func RespondError(c *fiber.Ctx, err error) error {
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return response.ValidationErrors(c, validationErr.Fields)
+	case errors.Is(err, ErrInvalidBody):
+		return response.BadRequest(c, "Invalid request body")
+	default:
+		return response.InternalServerError(c, "Failed to validate request")
+	}
+}
+
+// fieldName caminho do campo sem o nome da struct raiz ("config.keywords[0]")
+func fieldName(fieldError validator.FieldError) string {
+	_, name, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+	return name
+}
+
+// fieldMessage mensagem legível para a regra que falhou
+func fieldMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	isString := fieldError.Kind() == reflect.String
+
+	switch fieldError.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	case "eq":
+		return "must be " + param
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "len":
+		if isString {
+			return "must be exactly " + param + " characters long"
+		}
+		return "must contain exactly " + param + " items"
+	case "min":
+		if isString {
+			return "must be at least " + param + " characters long"
+		}
+		if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+			return "must contain at least " + param + " items"
+		}
+		return "must be at least " + param
+	case "max":
+		if isString {
+			return "must be at most " + param + " characters long"
+		}
+		if fieldError.Kind() == reflect.Slice || fieldError.Kind() == reflect.Map {
+			return "must contain at most " + param + " items"
+		}
+		return "must be at most " + param
+	case "gt", "gte", "lt", "lte":
+		return "must be " + comparisons[fieldError.Tag()] + " " + param
+	case "fqdn", "hostname":
+		return "must be a valid domain name"
+	}
+	return "is invalid"
+}
+
+var comparisons = map[string]string{
+	"gt":  "greater than",
+	"gte": "greater than or equal to",
+	"lt":  "less than",
+	"lte": "less than or equal to",
+}
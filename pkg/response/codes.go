@@ -0,0 +1,188 @@
+package response
+
+import "github.com/gofiber/fiber/v2"
+
+// =============================================================================
+// ERROR CODES
+// =============================================================================
+
+// Códigos de erro (error.code) que os clientes podem tratar programaticamente.
+// Os genéricos (BAD_REQUEST, NOT_FOUND...) ficam para os casos sem código
+// específico; cada código tem o status HTTP em codeStatuses.
+const (
+	// Genéricos, por status HTTP
+	CodeBadRequest          = "BAD_REQUEST"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeNotFound            = "NOT_FOUND"
+	CodeMethodNotAllowed    = "METHOD_NOT_ALLOWED"
+	CodeRequestTimeout      = "REQUEST_TIMEOUT"
+	CodeConflict            = "CONFLICT"
+	CodePayloadTooLarge     = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMedia    = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessableEntity = "UNPROCESSABLE_ENTITY"
+	CodeValidationError     = "VALIDATION_ERROR"
+	CodeTooManyRequests     = "TOO_MANY_REQUESTS"
+	CodeInternalServerError = "INTERNAL_SERVER_ERROR"
+	CodeBadGateway          = "BAD_GATEWAY"
+	CodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	CodeGatewayTimeout      = "GATEWAY_TIMEOUT"
+
+	// Autenticação
+	CodeAuthTokenMissing       = "AUTH_TOKEN_MISSING"
+	CodeAuthTokenExpired       = "AUTH_TOKEN_EXPIRED"
+	CodeAuthTokenRevoked       = "AUTH_TOKEN_REVOKED"
+	CodeAuthTokenInvalid       = "AUTH_TOKEN_INVALID"
+	CodeAuthInvalidCredentials = "AUTH_INVALID_CREDENTIALS"
+	CodeEmailNotVerified       = "EMAIL_NOT_VERIFIED"
+	CodeAccountInactive        = "ACCOUNT_INACTIVE"
+	CodeCaptchaRequired        = "CAPTCHA_REQUIRED"
+	CodeCaptchaFailed          = "CAPTCHA_FAILED"
+
+	// Autorização
+	CodeRoleRequired          = "ROLE_REQUIRED"
+	CodeScopeMissing          = "SCOPE_MISSING"
+	CodeNoScopes              = "NO_SCOPES"
+	CodeServiceAuthRequired   = "SERVICE_AUTH_REQUIRED"
+	CodePlatformAdminRequired = "PLATFORM_ADMIN_REQUIRED"
+	CodeTenantAccessDenied    = "TENANT_ACCESS_DENIED"
+	CodeClientAccessDenied    = "CLIENT_ACCESS_DENIED"
+	CodeIPBlocked             = "IP_BLOCKED"
+	CodeCountryBlocked        = "COUNTRY_BLOCKED"
+
+	// Tenant e limites
+	CodeTenantRequired    = "TENANT_REQUIRED"
+	CodeTenantSuspended   = "TENANT_SUSPENDED"
+	CodeQuotaExceeded     = "QUOTA_EXCEEDED"
+	CodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
+	CodeJobLimitExceeded  = "JOB_LIMIT_EXCEEDED"
+	CodePlanRequired      = "PLAN_REQUIRED"
+
+	// Recursos
+	CodeVersionConflict = "VERSION_CONFLICT"
+	CodeLastAdmin       = "LAST_ADMIN"
+	CodeTargetBlocked   = "TARGET_BLOCKED"
+
+	// Banco de dados
+	CodeDatabaseTimeout = "DATABASE_TIMEOUT"
+
+	// MCP
+	CodeMCPError         = "MCP_ERROR"
+	CodeMCPAuthFailed    = "MCP_AUTH_FAILED"
+	CodeMCPToolForbidden = "MCP_TOOL_FORBIDDEN"
+	CodeMCPNotFound      = "MCP_NOT_FOUND"
+	CodeMCPRateLimited   = "MCP_RATE_LIMITED"
+	CodeMCPTimeout       = "MCP_TIMEOUT"
+	CodeMCPUnavailable   = "MCP_UNAVAILABLE"
+)
+
+// codeStatuses status HTTP de cada código
+var codeStatuses = map[string]int{
+	CodeBadRequest:          fiber.StatusBadRequest,
+	CodeUnauthorized:        fiber.StatusUnauthorized,
+	CodeForbidden:           fiber.StatusForbidden,
+	CodeNotFound:            fiber.StatusNotFound,
+	CodeMethodNotAllowed:    fiber.StatusMethodNotAllowed,
+	CodeRequestTimeout:      fiber.StatusRequestTimeout,
+	CodeConflict:            fiber.StatusConflict,
+	CodePayloadTooLarge:     fiber.StatusRequestEntityTooLarge,
+	CodeUnsupportedMedia:    fiber.StatusUnsupportedMediaType,
+	CodeUnprocessableEntity: fiber.StatusUnprocessableEntity,
+	CodeValidationError:     fiber.StatusUnprocessableEntity,
+	CodeTooManyRequests:     fiber.StatusTooManyRequests,
+	CodeInternalServerError: fiber.StatusInternalServerError,
+	CodeBadGateway:          fiber.StatusBadGateway,
+	CodeServiceUnavailable:  fiber.StatusServiceUnavailable,
+	CodeGatewayTimeout:      fiber.StatusGatewayTimeout,
+
+	CodeAuthTokenMissing:       fiber.StatusUnauthorized,
+	CodeAuthTokenExpired:       fiber.StatusUnauthorized,
+	CodeAuthTokenRevoked:       fiber.StatusUnauthorized,
+	CodeAuthTokenInvalid:       fiber.StatusUnauthorized,
+	CodeAuthInvalidCredentials: fiber.StatusUnauthorized,
+	CodeEmailNotVerified:       fiber.StatusForbidden,
+	CodeAccountInactive:        fiber.StatusForbidden,
+	CodeCaptchaRequired:        fiber.StatusBadRequest,
+	CodeCaptchaFailed:          fiber.StatusBadRequest,
+
+	CodeRoleRequired:          fiber.StatusForbidden,
+	CodeScopeMissing:          fiber.StatusForbidden,
+	CodeNoScopes:              fiber.StatusForbidden,
+	CodeServiceAuthRequired:   fiber.StatusForbidden,
+	CodePlatformAdminRequired: fiber.StatusForbidden,
+	CodeTenantAccessDenied:    fiber.StatusForbidden,
+	CodeClientAccessDenied:    fiber.StatusForbidden,
+	CodeIPBlocked:             fiber.StatusForbidden,
+	CodeCountryBlocked:        fiber.StatusForbidden,
+
+	CodeTenantRequired:    fiber.StatusConflict,
+	CodeTenantSuspended:   fiber.StatusForbidden,
+	CodeQuotaExceeded:     fiber.StatusForbidden,
+	CodeRateLimitExceeded: fiber.StatusTooManyRequests,
+	CodeJobLimitExceeded:  fiber.StatusTooManyRequests,
+	CodePlanRequired:      fiber.StatusForbidden,
+
+	CodeVersionConflict: fiber.StatusConflict,
+	CodeLastAdmin:       fiber.StatusConflict,
+	CodeTargetBlocked:   fiber.StatusUnprocessableEntity,
+
+	CodeDatabaseTimeout: fiber.StatusGatewayTimeout,
+
+	CodeMCPError:         fiber.StatusBadGateway,
+	CodeMCPAuthFailed:    fiber.StatusUnauthorized,
+	CodeMCPToolForbidden: fiber.StatusForbidden,
+	CodeMCPNotFound:      fiber.StatusNotFound,
+	CodeMCPRateLimited:   fiber.StatusTooManyRequests,
+	CodeMCPTimeout:       fiber.StatusGatewayTimeout,
+	CodeMCPUnavailable:   fiber.StatusServiceUnavailable,
+}
+
+// StatusFor retorna o status HTTP do código; códigos desconhecidos são 500
+func StatusFor(code string) int {
+	if status, ok := codeStatuses[code]; ok {
+		return status
+	}
+	return fiber.StatusInternalServerError
+}
+
+// statusCodes código genérico de cada status HTTP, usado para erros sem código
+// próprio (ex.: *fiber.Error do roteamento ou do parse da request)
+var statusCodes = map[int]string{
+	fiber.StatusBadRequest:            CodeBadRequest,
+	fiber.StatusUnauthorized:          CodeUnauthorized,
+	fiber.StatusForbidden:             CodeForbidden,
+	fiber.StatusNotFound:              CodeNotFound,
+	fiber.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	fiber.StatusRequestTimeout:        CodeRequestTimeout,
+	fiber.StatusConflict:              CodeConflict,
+	fiber.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	fiber.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	fiber.StatusUnprocessableEntity:   CodeUnprocessableEntity,
+	fiber.StatusTooManyRequests:       CodeTooManyRequests,
+	fiber.StatusInternalServerError:   CodeInternalServerError,
+	fiber.StatusBadGateway:            CodeBadGateway,
+	fiber.StatusServiceUnavailable:    CodeServiceUnavailable,
+	fiber.StatusGatewayTimeout:        CodeGatewayTimeout,
+}
+
+// CodeForStatus retorna o código genérico do status HTTP; status sem código
+// próprio usam BAD_REQUEST (4xx) ou INTERNAL_SERVER_ERROR (5xx)
+func CodeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 400 && status < 500 {
+		return CodeBadRequest
+	}
+	return CodeInternalServerError
+}
+
+// Fail retorna o erro do código com o status HTTP definido para ele
+func Fail(c *fiber.Ctx, code, message string) error {
+	return Error(c, StatusFor(code), code, message)
+}
+
+// FailWithDetails retorna o erro do código com detalhes e o status HTTP definido para ele
+func FailWithDetails(c *fiber.Ctx, code, message string, details map[string]string) error {
+	return ErrorWithDetails(c, StatusFor(code), code, message, details)
+}
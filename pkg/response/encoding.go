@@ -0,0 +1,228 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// =============================================================================
+// CONTENT NEGOTIATION
+// =============================================================================
+
+// Formatos aceitos no header Accept além de JSON (o padrão)
+const (
+	MIMEApplicationMsgpack  = "application/msgpack"
+	MIMEApplicationXMsgpack = "application/x-msgpack"
+)
+
+// Ordem de preferência quando o Accept aceita mais de um formato com o mesmo peso
+var offeredFormats = []string{
+	fiber.MIMEApplicationJSON,
+	MIMEApplicationMsgpack,
+	MIMEApplicationXMsgpack,
+	fiber.MIMEApplicationXML,
+	fiber.MIMETextXML,
+}
+
+// send codifica o envelope no formato pedido pelo Accept (JSON, msgpack ou XML),
+// com JSON como padrão. msgpack e XML são gerados a partir do JSON do envelope,
+// então os nomes dos campos, a omissão de vazios e a representação de UUIDs e
+// datas são iguais nos três formatos.
+func send(c *fiber.Ctx, statusCode int, body interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	c.Status(statusCode)
+
+	switch format := c.Accepts(offeredFormats...); format {
+	case MIMEApplicationMsgpack, MIMEApplicationXMsgpack:
+		encoded, err := encodeMsgpack(body)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, format)
+		return c.Send(encoded)
+	case fiber.MIMEApplicationXML, fiber.MIMETextXML:
+		encoded, err := encodeXML(body)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, format+"; charset=utf-8")
+		return c.Send(encoded)
+	default:
+		// Accept ausente, JSON ou sem nenhum formato suportado
+		return c.JSON(body)
+	}
+}
+
+// encodeMsgpack converte o JSON do envelope para msgpack. Inteiros continuam
+// inteiros; chaves de objetos são ordenadas para uma saída determinística.
+func encodeMsgpack(body interface{}) ([]byte, error) {
+	value, err := jsonValue(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonValue decodifica o JSON de body em valores genéricos, com números como
+// int64 quando inteiros e float64 caso contrário
+func jsonValue(body interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(value), nil
+}
+
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// encodeXML converte o JSON do envelope para XML mantendo a ordem dos campos:
+// o envelope vira <response>, cada campo de objeto um elemento com o nome do
+// campo e cada item de lista um <item>. Chaves que não são nomes XML válidos
+// (ex.: "items[0]" em details) viram <entry key="...">.
+func encodeXML(body interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+
+	if err := writeXMLValue(enc, dec, xmlElement("response")); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXMLValue escreve o próximo valor do decoder JSON dentro do elemento start
+func writeXMLValue(enc *xml.Encoder, dec *json.Decoder, start xml.StartElement) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			for dec.More() {
+				keyToken, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return errors.New("response: invalid JSON object key")
+				}
+				if err := writeXMLValue(enc, dec, xmlElement(key)); err != nil {
+					return err
+				}
+			}
+		case '[':
+			for dec.More() {
+				if err := writeXMLValue(enc, dec, xmlElement("item")); err != nil {
+					return err
+				}
+			}
+		}
+		// Consome o '}' ou ']' de fechamento
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		if err := enc.EncodeToken(xml.CharData(t)); err != nil {
+			return err
+		}
+	case json.Number:
+		if err := enc.EncodeToken(xml.CharData(t.String())); err != nil {
+			return err
+		}
+	case bool:
+		if err := enc.EncodeToken(xml.CharData(strconv.FormatBool(t))); err != nil {
+			return err
+		}
+	case nil:
+		// null vira elemento vazio
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// xmlElement cria o elemento do campo; nomes inválidos em XML vão para o atributo key
+func xmlElement(name string) xml.StartElement {
+	if isXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+// isXMLName verifica se name pode ser usado como nome de elemento (sem namespace)
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(name)
+	if first != '_' && !unicode.IsLetter(first) {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && r != '-' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return len(name) < 3 || !strings.EqualFold(name[:3], "xml")
+}
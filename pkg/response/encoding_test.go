@@ -0,0 +1,105 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type encodingPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	Ratio     float64   `json:"ratio"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// msgpackEnvelope executa a request com Accept: application/msgpack e decodifica o
+// envelope (data em dest) passando pelo mapa genérico do msgpack
+func msgpackEnvelope(t *testing.T, app *fiber.App, method, target string, dest interface{}) (int, *Response) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(`{}`)))
+	req.Header.Set(fiber.HeaderAccept, MIMEApplicationMsgpack)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get(fiber.HeaderContentType); got != MIMEApplicationMsgpack {
+		t.Fatalf("Content-Type = %q, want %s", got, MIMEApplicationMsgpack)
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+	asJSON, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope := &Response{Data: dest}
+	if err := json.Unmarshal(asJSON, envelope); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	return resp.StatusCode, envelope
+}
+
+func TestMsgpackEnvelopeRoundTrip(t *testing.T) {
+	want := encodingPayload{
+		ID:        uuid.New(),
+		Name:      "Ação Ltda",
+		Count:     42,
+		Ratio:     0.75,
+		Tags:      []string{"phishing", "typosquat"},
+		CreatedAt: time.Date(2026, 10, 16, 12, 30, 0, 0, time.UTC),
+	}
+
+	app := fiber.New()
+	app.Post("/ok", func(c *fiber.Ctx) error { return Created(c, want) })
+	app.Post("/page", func(c *fiber.Ctx) error { return Paginated(c, []encodingPayload{want}, 2, 1, 3) })
+	app.Post("/fail", func(c *fiber.Ctx) error {
+		return ErrorWithDetails(c, fiber.StatusUnprocessableEntity, CodeValidationError, "Invalid request", map[string]string{"items[0]": "required"})
+	})
+
+	var got encodingPayload
+	status, envelope := msgpackEnvelope(t, app, fiber.MethodPost, "/ok", &got)
+	if status != fiber.StatusCreated || !envelope.Success || envelope.Timestamp == "" {
+		t.Errorf("created: status = %d, envelope = %+v", status, envelope)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("data = %+v, want %+v", got, want)
+	}
+
+	var items []encodingPayload
+	page := PaginatedData{Items: &items}
+	status, _ = msgpackEnvelope(t, app, fiber.MethodPost, "/page", &page)
+	if status != fiber.StatusOK || len(items) != 1 || !reflect.DeepEqual(items[0], want) {
+		t.Errorf("paginated: status = %d, items = %+v", status, items)
+	}
+	if meta := page.Meta; meta.Page != 2 || meta.PerPage != 1 || meta.Total != 3 || meta.TotalPages != 3 ||
+		meta.NextPage == nil || *meta.NextPage != 3 || meta.PrevPage == nil || *meta.PrevPage != 1 {
+		t.Errorf("meta = %+v", page.Meta)
+	}
+
+	status, envelope = msgpackEnvelope(t, app, fiber.MethodPost, "/fail", nil)
+	if status != fiber.StatusUnprocessableEntity || envelope.Success || envelope.Error == nil {
+		t.Fatalf("error: status = %d, envelope = %+v", status, envelope)
+	}
+	wantErr := ErrorInfo{Code: CodeValidationError, Message: "Invalid request", Details: map[string]string{"items[0]": "required"}}
+	if !reflect.DeepEqual(*envelope.Error, wantErr) {
+		t.Errorf("error = %+v, want %+v", *envelope.Error, wantErr)
+	}
+}
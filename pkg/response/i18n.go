@@ -0,0 +1,198 @@
+package response
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =============================================================================
+// I18N
+// =============================================================================
+
+// Locales suportados. Em inglês as mensagens passadas aos helpers são usadas
+// como estão; os demais têm um catálogo por código de erro.
+const (
+	LocaleEnglish    = "en"
+	LocalePortuguese = "pt-BR"
+	LocaleSpanish    = "es"
+)
+
+// catalogs mensagens traduzidas por locale e código de erro. Códigos fora do
+// catálogo mantêm a mensagem informada pelo handler.
+var catalogs = map[string]map[string]string{
+	LocalePortuguese: {
+		CodeBadRequest:             "Requisição inválida",
+		CodeUnauthorized:           "Autenticação necessária ou credenciais inválidas",
+		CodeForbidden:              "Acesso negado",
+		CodeNotFound:               "Recurso não encontrado",
+		CodeMethodNotAllowed:       "Método não permitido",
+		CodeRequestTimeout:         "Tempo limite da requisição esgotado",
+		CodeConflict:               "Conflito com o estado atual do recurso",
+		CodePayloadTooLarge:        "Corpo da requisição muito grande",
+		CodeUnsupportedMedia:       "Tipo de conteúdo não suportado",
+		CodeUnprocessableEntity:    "Dados inválidos",
+		CodeValidationError:        "Falha na validação dos dados",
+		CodeTooManyRequests:        "Limite de requisições excedido; tente novamente mais tarde",
+		CodeInternalServerError:    "Erro interno do servidor",
+		CodeBadGateway:             "Falha ao comunicar com um serviço externo",
+		CodeServiceUnavailable:     "Serviço temporariamente indisponível",
+		CodeGatewayTimeout:         "O serviço demorou demais para responder",
+		CodeAuthTokenMissing:       "Token de autenticação ausente ou malformado",
+		CodeAuthTokenExpired:       "Token expirado",
+		CodeAuthTokenRevoked:       "Token revogado",
+		CodeAuthTokenInvalid:       "Token inválido",
+		CodeAuthInvalidCredentials: "Credenciais inválidas",
+		CodeEmailNotVerified:       "Email não verificado",
+		CodeAccountInactive:        "Conta inativa",
+		CodeRoleRequired:           "Permissões insuficientes",
+		CodeScopeMissing:           "Escopo necessário ausente",
+		CodeTenantAccessDenied:     "Acesso negado a este tenant",
+		CodeIPBlocked:              "Endereço IP bloqueado",
+		CodeCountryBlocked:         "País bloqueado",
+		CodeTenantSuspended:        "Conta suspensa",
+		CodeQuotaExceeded:          "Cota do plano excedida",
+		CodeRateLimitExceeded:      "Limite de requisições excedido; tente novamente mais tarde",
+	},
+	LocaleSpanish: {
+		CodeBadRequest:             "Solicitud no válida",
+		CodeUnauthorized:           "Autenticación requerida o credenciales no válidas",
+		CodeForbidden:              "Acceso denegado",
+		CodeNotFound:               "Recurso no encontrado",
+		CodeMethodNotAllowed:       "Método no permitido",
+		CodeRequestTimeout:         "Tiempo de espera de la solicitud agotado",
+		CodeConflict:               "Conflicto con el estado actual del recurso",
+		CodePayloadTooLarge:        "Cuerpo de la solicitud demasiado grande",
+		CodeUnsupportedMedia:       "Tipo de contenido no admitido",
+		CodeUnprocessableEntity:    "Datos no válidos",
+		CodeValidationError:        "Error de validación de los datos",
+		CodeTooManyRequests:        "Límite de solicitudes excedido; inténtelo de nuevo más tarde",
+		CodeInternalServerError:    "Error interno del servidor",
+		CodeBadGateway:             "Error al comunicarse con un servicio externo",
+		CodeServiceUnavailable:     "Servicio no disponible temporalmente",
+		CodeGatewayTimeout:         "El servicio tardó demasiado en responder",
+		CodeAuthTokenMissing:       "Token de autenticación ausente o mal formado",
+		CodeAuthTokenExpired:       "Token caducado",
+		CodeAuthTokenRevoked:       "Token revocado",
+		CodeAuthTokenInvalid:       "Token no válido",
+		CodeAuthInvalidCredentials: "Credenciales no válidas",
+		CodeEmailNotVerified:       "Correo electrónico no verificado",
+		CodeAccountInactive:        "Cuenta inactiva",
+		CodeRoleRequired:           "Permisos insuficientes",
+		CodeScopeMissing:           "Falta un alcance requerido",
+		CodeTenantAccessDenied:     "Acceso denegado a este tenant",
+		CodeIPBlocked:              "Dirección IP bloqueada",
+		CodeCountryBlocked:         "País bloqueado",
+		CodeTenantSuspended:        "Cuenta suspendida",
+		CodeQuotaExceeded:          "Cuota del plan excedida",
+		CodeRateLimitExceeded:      "Límite de solicitudes excedido; inténtelo de nuevo más tarde",
+	},
+}
+
+// Locale usado quando o Accept-Language está ausente ou não tem locale suportado
+// (SERVER_DEFAULT_LOCALE)
+var defaultLocale atomic.Value
+
+func init() {
+	defaultLocale.Store(LocaleEnglish)
+}
+
+// SetDefaultLocale altera o locale padrão; locales não suportados são ignorados
+func SetDefaultLocale(locale string) {
+	if supported, ok := supportedLocale(locale); ok {
+		defaultLocale.Store(supported)
+	}
+}
+
+// DefaultLocale retorna o locale padrão atual
+func DefaultLocale() string {
+	return defaultLocale.Load().(string)
+}
+
+// IsSupportedLocale verifica se locale (ou o idioma dele, ex.: "pt" ou "es-MX") é suportado
+func IsSupportedLocale(locale string) bool {
+	_, ok := supportedLocale(locale)
+	return ok
+}
+
+// Locale resolve o locale da requisição pelo Accept-Language, na ordem de
+// preferência (q) do cliente, com o idioma como fallback da região ("pt-PT" e
+// "pt" usam pt-BR, "es-MX" usa es)
+func Locale(c *fiber.Ctx) string {
+	for _, tag := range acceptedLanguages(c.Get(fiber.HeaderAcceptLanguage)) {
+		if tag == "*" {
+			break
+		}
+		if locale, ok := supportedLocale(tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale()
+}
+
+// localize retorna a mensagem do código no locale da requisição ou, sem
+// tradução, a mensagem informada
+func localize(c *fiber.Ctx, code, message string) string {
+	locale := Locale(c)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentLanguage, locale)
+	if translated, ok := catalogs[locale][code]; ok {
+		return translated
+	}
+	return message
+}
+
+// supportedLocale mapeia uma language tag para o locale suportado correspondente
+func supportedLocale(tag string) (string, bool) {
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	switch language {
+	case "en":
+		return LocaleEnglish, true
+	case "pt":
+		return LocalePortuguese, true
+	case "es":
+		return LocaleSpanish, true
+	}
+	return "", false
+}
+
+// acceptedLanguages retorna as language tags do Accept-Language ordenadas por q,
+// sem as recusadas (q=0)
+func acceptedLanguages(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name == "q" {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, weighted{tag: tag, q: q})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	tags := make([]string, len(ranges))
+	for i, r := range ranges {
+		tags[i] = r.tag
+	}
+	return tags
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestParsePaginationClampsEdges(t *testing.T) {
+	SetMaxPerPage(50)
+	defer SetMaxPerPage(DefaultMaxPerPage)
+
+	for _, tc := range []struct {
+		query       string
+		page, per   int
+		description string
+	}{
+		{"", 1, DefaultPerPage, "defaults"},
+		{"?page=0&per_page=10", 1, 10, "page 0"},
+		{"?page=-3&per_page=-1", 1, DefaultPerPage, "negative page and per_page"},
+		{"?page=" + strconv.Itoa(math.MaxInt), math.MaxInt, DefaultPerPage, "huge page"},
+		{"?page=99999999999999999999999", 1, DefaultPerPage, "page beyond int"},
+		{"?per_page=51", 1, 50, "per_page above the maximum"},
+		{"?per_page=1000000", 1, 50, "per_page far above the maximum"},
+		{"?page=abc&per_page=xyz", 1, DefaultPerPage, "non-numeric values"},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			app := fiber.New()
+			var page, perPage int
+			app.Get("/", func(c *fiber.Ctx) error {
+				page, perPage = ParsePagination(c)
+				return nil
+			})
+			if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+tc.query, nil)); err != nil {
+				t.Fatal(err)
+			}
+			if page != tc.page || perPage != tc.per {
+				t.Errorf("ParsePagination(%q) = %d, %d; want %d, %d", tc.query, page, perPage, tc.page, tc.per)
+			}
+		})
+	}
+}
@@ -1,41 +1,98 @@
 package response
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
 )
 
 // Response estrutura padrão de resposta da API
 type Response struct {
-	Success   bool        `json:"success"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     *ErrorInfo  `json:"error,omitempty"`
-	Meta      *Meta       `json:"meta,omitempty"`
-	RequestID string      `json:"request_id,omitempty"`
-	Timestamp string      `json:"timestamp"`
+	XMLName   xml.Name    `json:"-" xml:"response"`
+	Success   bool        `json:"success" xml:"success"`
+	Data      interface{} `json:"data,omitempty" xml:"data,omitempty"`
+	Error     *ErrorInfo  `json:"error,omitempty" xml:"error,omitempty"`
+	Meta      *Meta       `json:"meta,omitempty" xml:"meta,omitempty"`
+	RequestID string      `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Timestamp string      `json:"timestamp" xml:"timestamp"`
 }
 
 // ErrorInfo informações de erro
 type ErrorInfo struct {
-	Code    string            `json:"code"`
-	Message string            `json:"message"`
-	Details map[string]string `json:"details,omitempty"`
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
+	// encoding/xml não serializa mapas; nas respostas em XML details vem do JSON (ver send)
+	Details map[string]string `json:"details,omitempty" xml:"-"`
 }
 
 // Meta informações de paginação e metadata
 type Meta struct {
-	Page       int   `json:"page,omitempty"`
-	PerPage    int   `json:"per_page,omitempty"`
-	Total      int64 `json:"total,omitempty"`
-	TotalPages int   `json:"total_pages,omitempty"`
+	Page       int   `json:"page,omitempty" xml:"page,omitempty"`
+	PerPage    int   `json:"per_page,omitempty" xml:"per_page,omitempty"`
+	Total      int64 `json:"total,omitempty" xml:"total,omitempty"`
+	TotalPages int   `json:"total_pages,omitempty" xml:"total_pages,omitempty"`
+	// Páginas vizinhas; null na primeira/última página
+	NextPage *int   `json:"next_page" xml:"next_page"`
+	PrevPage *int   `json:"prev_page" xml:"prev_page"`
+	Links    *Links `json:"links,omitempty" xml:"links,omitempty"`
+	// Cursor opaco da próxima página na paginação por cursor; ausente na última
+	NextCursor string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	// Job assíncrono que continua o processamento (respostas 202)
+	JobID string `json:"job_id,omitempty" xml:"job_id,omitempty"`
+}
+
+// Links URLs de navegação da listagem (path + query da requisição, com page trocado)
+type Links struct {
+	Self  string `json:"self" xml:"self"`
+	First string `json:"first" xml:"first"`
+	Last  string `json:"last" xml:"last"`
+	Next  string `json:"next,omitempty" xml:"next,omitempty"`
+	Prev  string `json:"prev,omitempty" xml:"prev,omitempty"`
 }
 
 // PaginatedData dados com paginação
 type PaginatedData struct {
-	Items interface{} `json:"items"`
-	Meta  Meta        `json:"meta"`
+	Items interface{} `json:"items" xml:"items"`
+	Meta  Meta        `json:"meta" xml:"meta"`
+}
+
+// =============================================================================
+// REQUEST ID
+// =============================================================================
+
+// Header e chave de c.Locals do request id (os mesmos do middleware requestid)
+const (
+	HeaderRequestID = "X-Request-ID"
+	localsRequestID = "requestid"
+)
+
+// RequestID retorna o request id da requisição: o registrado pelo middleware de
+// request id ou, quando ele não rodou (rejeições anteriores a ele, panics), o do
+// header da requisição ou um novo. O id obtido aqui é guardado em c.Locals e no
+// header da resposta, para que logs e corpo usem o mesmo valor.
+func RequestID(c *fiber.Ctx) string {
+	if requestID, ok := c.Locals(localsRequestID).(string); ok && requestID != "" {
+		return requestID
+	}
+
+	requestID := c.Get(HeaderRequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	c.Locals(localsRequestID, requestID)
+	c.Set(HeaderRequestID, requestID)
+	return requestID
 }
 
 // =============================================================================
@@ -44,30 +101,41 @@ type PaginatedData struct {
 
 // Success retorna uma resposta de sucesso
 func Success(c *fiber.Ctx, data interface{}) error {
-	return c.Status(fiber.StatusOK).JSON(Response{
+	return send(c, fiber.StatusOK, Response{
 		Success:   true,
 		Data:      data,
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
 // Created retorna uma resposta de recurso criado
 func Created(c *fiber.Ctx, data interface{}) error {
-	return c.Status(fiber.StatusCreated).JSON(Response{
+	return send(c, fiber.StatusCreated, Response{
 		Success:   true,
 		Data:      data,
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
 // Accepted retorna uma resposta de requisição aceita (async)
 func Accepted(c *fiber.Ctx, data interface{}) error {
-	return c.Status(fiber.StatusAccepted).JSON(Response{
+	return send(c, fiber.StatusAccepted, Response{
 		Success:   true,
 		Data:      data,
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// SuccessWithMeta retorna uma resposta de sucesso com o status e o meta informados
+func SuccessWithMeta(c *fiber.Ctx, statusCode int, data interface{}, meta *Meta) error {
+	return send(c, statusCode, Response{
+		Success:   true,
+		Data:      data,
+		Meta:      meta,
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
@@ -79,106 +147,329 @@ func NoContent(c *fiber.Ctx) error {
 
 // Paginated retorna uma resposta paginada
 func Paginated(c *fiber.Ctx, items interface{}, page, perPage int, total int64) error {
-	totalPages := int(total) / perPage
-	if int(total)%perPage > 0 {
-		totalPages++
-	}
+	return send(c, fiber.StatusOK, Response{
+		Success: true,
+		Data: PaginatedData{
+			Items: items,
+			Meta:  pageMeta(page, perPage, total),
+		},
+		RequestID: RequestID(c),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// PaginatedWithLinks retorna uma resposta paginada com links de navegação (self/first/last/next/prev)
+func PaginatedWithLinks(c *fiber.Ctx, items interface{}, page, perPage int, total int64) error {
+	meta := pageMeta(page, perPage, total)
+	meta.Links = pageLinks(c, meta)
+
+	return send(c, fiber.StatusOK, Response{
+		Success: true,
+		Data: PaginatedData{
+			Items: items,
+			Meta:  meta,
+		},
+		RequestID: RequestID(c),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	})
+}
 
-	return c.Status(fiber.StatusOK).JSON(Response{
+// CursorPaginated retorna uma página da paginação por cursor. Sem total nem números de
+// página; nextCursor vazio indica a última página.
+func CursorPaginated(c *fiber.Ctx, items interface{}, perPage int, nextCursor string) error {
+	return send(c, fiber.StatusOK, Response{
 		Success: true,
 		Data: PaginatedData{
 			Items: items,
-			Meta: Meta{
-				Page:       page,
-				PerPage:    perPage,
-				Total:      total,
-				TotalPages: totalPages,
-			},
+			Meta:  Meta{PerPage: perPage, NextCursor: nextCursor},
 		},
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
+// =============================================================================
+// PAGINATION HELPERS
+// =============================================================================
+
+// Padrões de paginação das listagens
+const (
+	DefaultPerPage    = 20
+	DefaultMaxPerPage = 100
+)
+
+// Limite de per_page aplicado por ParsePagination (SERVER_MAX_PER_PAGE)
+var maxPerPage atomic.Int64
+
+func init() {
+	maxPerPage.Store(DefaultMaxPerPage)
+}
+
+// SetMaxPerPage altera o limite de per_page; valores menores que 1 são ignorados
+func SetMaxPerPage(n int) {
+	if n >= 1 {
+		maxPerPage.Store(int64(n))
+	}
+}
+
+// MaxPerPage retorna o limite atual de per_page
+func MaxPerPage() int {
+	return int(maxPerPage.Load())
+}
+
+// ParsePagination lê page e per_page da query: page menor que 1 vira 1, per_page
+// ausente ou menor que 1 vira DefaultPerPage (ou o limite, se menor) e acima de
+// MaxPerPage() é limitado
+func ParsePagination(c *fiber.Ctx) (page, perPage int) {
+	limit := MaxPerPage()
+
+	page = c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage = c.QueryInt("per_page", DefaultPerPage)
+	if perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > limit {
+		perPage = limit
+	}
+	return page, perPage
+}
+
+// cursorPosition conteúdo do cursor: created_at (microssegundos, a precisão do
+// PostgreSQL) e id do último item da página
+type cursorPosition struct {
+	CreatedAt int64     `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// EncodeCursor gera o cursor da posição depois do item (createdAt, id). O conteúdo é
+// codificado em base64 URL-safe para que os clientes o tratem como opaco.
+func EncodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw, _ := json.Marshal(cursorPosition{CreatedAt: createdAt.UnixMicro(), ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor lê a posição de um cursor gerado por EncodeCursor
+func DecodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errInvalidCursor
+	}
+	var pos cursorPosition
+	if err := json.Unmarshal(raw, &pos); err != nil || pos.ID == uuid.Nil {
+		return time.Time{}, uuid.Nil, errInvalidCursor
+	}
+	return time.UnixMicro(pos.CreatedAt).UTC(), pos.ID, nil
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// pageMeta calcula total de páginas e páginas vizinhas
+func pageMeta(page, perPage int, total int64) Meta {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = int((total + int64(perPage) - 1) / int64(perPage))
+	}
+
+	meta := Meta{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
+	if page < totalPages {
+		next := page + 1
+		meta.NextPage = &next
+	}
+	if page > 1 {
+		prev := page - 1
+		if prev > totalPages && totalPages > 0 {
+			prev = totalPages
+		}
+		meta.PrevPage = &prev
+	}
+	return meta
+}
+
+// pageLinks monta os links de navegação a partir do path e da query da requisição
+func pageLinks(c *fiber.Ctx, meta Meta) *Links {
+	last := meta.TotalPages
+	if last < 1 {
+		last = 1
+	}
+	links := &Links{
+		Self:  pageURL(c, meta.Page, meta.PerPage),
+		First: pageURL(c, 1, meta.PerPage),
+		Last:  pageURL(c, last, meta.PerPage),
+	}
+	if meta.NextPage != nil {
+		links.Next = pageURL(c, *meta.NextPage, meta.PerPage)
+	}
+	if meta.PrevPage != nil {
+		links.Prev = pageURL(c, *meta.PrevPage, meta.PerPage)
+	}
+	return links
+}
+
+// pageURL retorna o path da requisição com a query original, page substituído e
+// per_page (se informado) já ajustado ao valor efetivo
+func pageURL(c *fiber.Ctx, page, perPage int) string {
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+
+	c.Request().URI().QueryArgs().CopyTo(args)
+	args.SetUint("page", page)
+	if args.Has("per_page") {
+		args.SetUint("per_page", perPage)
+	}
+	return c.Path() + "?" + args.String()
+}
+
+// =============================================================================
+// ETAG / CONDITIONAL REQUESTS
+// =============================================================================
+
+// TimestampETag gera um ETag fraco a partir do id e do UpdatedAt do recurso.
+// Só é seguro quando todo campo da resposta muda junto com updated_at.
+func TimestampETag(id uuid.UUID, updatedAt time.Time) string {
+	return weakETag(id.String() + ":" + strconv.FormatInt(updatedAt.UTC().UnixNano(), 10))
+}
+
+// ContentETag gera um ETag fraco a partir do JSON de data. Só o recurso entra
+// no hash; request_id e timestamp do envelope não invalidam o ETag.
+func ContentETag(data interface{}) (string, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return weakETag(string(body)), nil
+}
+
+// SuccessWithETag retorna uma resposta de sucesso com o header ETag, ou 304
+// Not Modified sem corpo se o If-None-Match da requisição casar com etag
+func SuccessWithETag(c *fiber.Ctx, data interface{}, etag string) error {
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return Success(c, data)
+}
+
+// weakETag retorna W/"<hash>" do valor
+func weakETag(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches aplica a comparação fraca do If-None-Match (RFC 9110 13.1.2):
+// aceita lista separada por vírgulas e "*", ignorando o prefixo W/
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // =============================================================================
 // ERROR RESPONSES
 // =============================================================================
 
-// Error retorna uma resposta de erro genérica
+// Error retorna uma resposta de erro genérica. A mensagem é traduzida para o
+// locale do Accept-Language quando o código tem tradução no catálogo.
 func Error(c *fiber.Ctx, statusCode int, code, message string) error {
-	return c.Status(statusCode).JSON(Response{
+	return send(c, statusCode, Response{
 		Success: false,
 		Error: &ErrorInfo{
 			Code:    code,
-			Message: message,
+			Message: localize(c, code, message),
 		},
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
 // ErrorWithDetails retorna uma resposta de erro com detalhes
 func ErrorWithDetails(c *fiber.Ctx, statusCode int, code, message string, details map[string]string) error {
-	return c.Status(statusCode).JSON(Response{
+	return send(c, statusCode, Response{
 		Success: false,
 		Error: &ErrorInfo{
 			Code:    code,
-			Message: message,
+			Message: localize(c, code, message),
 			Details: details,
 		},
-		RequestID: c.Get("X-Request-ID"),
+		RequestID: RequestID(c),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
 // BadRequest retorna erro 400
 func BadRequest(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusBadRequest, "BAD_REQUEST", message)
+	return Error(c, fiber.StatusBadRequest, CodeBadRequest, message)
 }
 
 // BadRequestWithDetails retorna erro 400 com detalhes
 func BadRequestWithDetails(c *fiber.Ctx, message string, details map[string]string) error {
-	return ErrorWithDetails(c, fiber.StatusBadRequest, "BAD_REQUEST", message, details)
+	return ErrorWithDetails(c, fiber.StatusBadRequest, CodeBadRequest, message, details)
 }
 
 // Unauthorized retorna erro 401
 func Unauthorized(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusUnauthorized, "UNAUTHORIZED", message)
+	return Error(c, fiber.StatusUnauthorized, CodeUnauthorized, message)
 }
 
 // Forbidden retorna erro 403
 func Forbidden(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusForbidden, "FORBIDDEN", message)
+	return Error(c, fiber.StatusForbidden, CodeForbidden, message)
 }
 
 // NotFound retorna erro 404
 func NotFound(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusNotFound, "NOT_FOUND", message)
+	return Error(c, fiber.StatusNotFound, CodeNotFound, message)
 }
 
 // Conflict retorna erro 409
 func Conflict(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusConflict, "CONFLICT", message)
+	return Error(c, fiber.StatusConflict, CodeConflict, message)
+}
+
+// PayloadTooLarge retorna erro 413
+func PayloadTooLarge(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusRequestEntityTooLarge, CodePayloadTooLarge, message)
 }
 
 // UnprocessableEntity retorna erro 422
 func UnprocessableEntity(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusUnprocessableEntity, "UNPROCESSABLE_ENTITY", message)
+	return Error(c, fiber.StatusUnprocessableEntity, CodeUnprocessableEntity, message)
 }
 
 // TooManyRequests retorna erro 429
 func TooManyRequests(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusTooManyRequests, "TOO_MANY_REQUESTS", message)
+	return Error(c, fiber.StatusTooManyRequests, CodeTooManyRequests, message)
 }
 
 // InternalServerError retorna erro 500
 func InternalServerError(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusInternalServerError, "INTERNAL_SERVER_ERROR", message)
+	return Error(c, fiber.StatusInternalServerError, CodeInternalServerError, message)
 }
 
 // ServiceUnavailable retorna erro 503
 func ServiceUnavailable(c *fiber.Ctx, message string) error {
-	return Error(c, fiber.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", message)
+	return Error(c, fiber.StatusServiceUnavailable, CodeServiceUnavailable, message)
+}
+
+// GatewayTimeout retorna erro 504
+func GatewayTimeout(c *fiber.Ctx, message string) error {
+	return Error(c, fiber.StatusGatewayTimeout, CodeGatewayTimeout, message)
 }
 
 // =============================================================================
@@ -191,13 +482,24 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
-// ValidationErrors retorna erros de validação
+// ValidationErrors retorna erro 422 com os erros de validação por campo
 func ValidationErrors(c *fiber.Ctx, errors []ValidationError) error {
 	details := make(map[string]string)
 	for _, err := range errors {
 		details[err.Field] = err.Message
 	}
-	return ErrorWithDetails(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "Validation failed", details)
+	return ErrorWithDetails(c, fiber.StatusUnprocessableEntity, CodeValidationError, "Validation failed", details)
+}
+
+// ParseUUIDParam lê o parâmetro de rota name como UUID. Se não for um UUID válido,
+// retorna um *fiber.Error 400 ("Invalid <name>") que o handler deve apenas
+// retornar: o error handler global o responde com o envelope de erro padrão.
+func ParseUUIDParam(c *fiber.Ctx, name string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params(name))
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "Invalid "+name)
+	}
+	return id, nil
 }
 
 // =============================================================================
@@ -232,22 +534,43 @@ type HealthResponse struct {
 	Version   string            `json:"version"`
 	Timestamp string            `json:"timestamp"`
 	Services  map[string]string `json:"services,omitempty"`
+	// Detalhes por serviço (ex.: versão e ferramentas degradadas do MCP)
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
 // Health retorna resposta de health check
 func Health(c *fiber.Ctx, version string, services map[string]string) error {
-	status := "healthy"
-	for _, svcStatus := range services {
-		if svcStatus != "healthy" {
-			status = "degraded"
-			break
-		}
+	return c.Status(fiber.StatusOK).JSON(HealthResponse{
+		Status:    healthStatus(services),
+		Version:   version,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Services:  services,
+	})
+}
+
+// Readiness retorna resposta de readiness: 503 se algum serviço não estiver saudável
+func Readiness(c *fiber.Ctx, version string, services map[string]string, details map[string]interface{}) error {
+	status := healthStatus(services)
+	statusCode := fiber.StatusOK
+	if status != "healthy" {
+		statusCode = fiber.StatusServiceUnavailable
 	}
 
-	return c.Status(fiber.StatusOK).JSON(HealthResponse{
+	return c.Status(statusCode).JSON(HealthResponse{
 		Status:    status,
 		Version:   version,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Services:  services,
+		Details:   details,
 	})
 }
+
+// healthStatus retorna "healthy" se todos os serviços estiverem saudáveis, senão "degraded"
+func healthStatus(services map[string]string) string {
+	for _, svcStatus := range services {
+		if svcStatus != "healthy" {
+			return "degraded"
+		}
+	}
+	return "healthy"
+}
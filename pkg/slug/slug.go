@@ -0,0 +1,101 @@
+// Package slug gera identificadores amigáveis para URLs a partir de nomes livres
+package slug
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Número máximo de sufixos testados por Unique antes de desistir
+const maxAttempts = 100
+
+// Fallback usado quando o nome não contém nenhum caractere aproveitável
+const fallback = "item"
+
+// ErrExhausted indica que nenhum slug livre foi encontrado dentro do limite de tentativas
+var ErrExhausted = errors.New("slug: no unique slug available")
+
+// ExistsFunc informa se um slug já está em uso no escopo desejado (ex: tenant)
+type ExistsFunc func(ctx context.Context, slug string) (bool, error)
+
+// transliterations mapeia caracteres acentuados comuns (já em minúsculas) para ASCII
+var transliterations = map[rune]string{
+	'á': "a", 'à': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ó': "o", 'ò': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'œ': "oe",
+	'ř': "r",
+	'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss",
+	'ť': "t", 'ţ': "t", 'þ': "th",
+	'ú': "u", 'ù': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// Make converte um nome em slug: translitera acentos, converte para minúsculas,
+// troca qualquer sequência de caracteres não alfanuméricos por um único hífen e
+// remove hífens nas pontas. Ex: "Café & Cia. Ltda" -> "cafe-cia-ltda".
+func Make(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	dash := false
+	for _, r := range name {
+		r = unicode.ToLower(r)
+
+		var out string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			out = string(r)
+		default:
+			out = transliterations[r]
+		}
+
+		if out == "" {
+			// Separador: só emite hífen entre partes já escritas
+			dash = b.Len() > 0
+			continue
+		}
+
+		if dash {
+			b.WriteByte('-')
+			dash = false
+		}
+		b.WriteString(out)
+	}
+
+	return b.String()
+}
+
+// Unique gera o slug de name e, se já estiver em uso segundo exists, acrescenta
+// um sufixo numérico (-2, -3, ...) até encontrar um livre.
+func Unique(ctx context.Context, name string, exists ExistsFunc) (string, error) {
+	base := Make(name)
+	if base == "" {
+		base = fallback
+	}
+
+	candidate := base
+	for n := 2; n <= maxAttempts+1; n++ {
+		taken, err := exists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+
+	return "", ErrExhausted
+}
@@ -0,0 +1,87 @@
+package slug
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"Ação Ltda", "acao-ltda"},
+		{"Café & Cia. Ltda", "cafe-cia-ltda"},
+		{"ÉCOLE Française", "ecole-francaise"},
+		{"Straße Œuvre Ærø", "strasse-oeuvre-aero"},
+		{"Łódź Špeditör", "lodz-speditor"},
+		{" - ", ""},
+		{"--Acme---Corp--", "acme-corp"},
+		{"  Acme   2024  ", "acme-2024"},
+		{"東京 Acme", "acme"},
+		{"", ""},
+	} {
+		if got := Make(tc.name); got != tc.want {
+			t.Errorf("Make(%q) = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// taken simula o store com os slugs informados já em uso
+func taken(slugs ...string) ExistsFunc {
+	set := make(map[string]bool, len(slugs))
+	for _, s := range slugs {
+		set[s] = true
+	}
+	return func(ctx context.Context, slug string) (bool, error) {
+		return set[slug], nil
+	}
+}
+
+func TestUniqueAppendsSuffixOnCollision(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"Ação Ltda", nil, "acao-ltda"},
+		{"Ação Ltda", []string{"acao-ltda"}, "acao-ltda-2"},
+		{"Ação Ltda", []string{"acao-ltda", "acao-ltda-2", "acao-ltda-3"}, "acao-ltda-4"},
+		// Um sufixo livre no meio da sequência é aproveitado
+		{"Acme", []string{"acme", "acme-3"}, "acme-2"},
+		// Nome sem caracteres aproveitáveis usa o fallback
+		{"!!!", nil, fallback},
+		{"東京", []string{fallback}, fallback + "-2"},
+	} {
+		got, err := Unique(ctx, tc.name, taken(tc.taken...))
+		if err != nil || got != tc.want {
+			t.Errorf("Unique(%q) with %v taken = %q, %v, want %q", tc.name, tc.taken, got, err, tc.want)
+		}
+	}
+}
+
+func TestUniqueGivesUpAfterMaxAttempts(t *testing.T) {
+	lookups := 0
+	_, err := Unique(context.Background(), "Acme", func(ctx context.Context, slug string) (bool, error) {
+		lookups++
+		return true, nil
+	})
+	if !errors.Is(err, ErrExhausted) {
+		t.Errorf("err = %v, want ErrExhausted", err)
+	}
+	if lookups != maxAttempts {
+		t.Errorf("lookups = %d, want %d", lookups, maxAttempts)
+	}
+}
+
+func TestUniquePropagatesLookupError(t *testing.T) {
+	lookupErr := errors.New("db down")
+	_, err := Unique(context.Background(), "Acme", func(ctx context.Context, slug string) (bool, error) {
+		return false, lookupErr
+	})
+	if !errors.Is(err, lookupErr) {
+		t.Errorf("err = %v, want %v", err, lookupErr)
+	}
+}
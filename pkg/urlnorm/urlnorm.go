@@ -0,0 +1,101 @@
+// Package urlnorm valida e normaliza URLs de alvos (scan, análise) para que
+// variações equivalentes da mesma URL gerem uma única requisição ao MCP
+package urlnorm
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Erros de validação
+var (
+	ErrInvalidURL        = errors.New("invalid URL")
+	ErrUnsupportedScheme = errors.New("URL scheme must be http or https")
+	ErrPrivateAddress    = errors.New("URL points to a private or local address")
+)
+
+// Portas padrão removidas da URL normalizada
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Options opções de normalização
+type Options struct {
+	// StripFragment remove o fragmento (#...), que não é enviado ao servidor
+	StripFragment bool
+	// BlockPrivate rejeita hosts que são IPs privados, de loopback, link-local ou
+	// não especificados, e o nome localhost. Nomes de host não são resolvidos.
+	BlockPrivate bool
+}
+
+// Normalize valida uma URL http(s) e retorna sua forma canônica: esquema e host em
+// minúsculas, sem porta padrão, sem barra final no path e com os parâmetros da query
+// ordenados. Ex: "HTTP://Example.com:80/a/?b=2&a=1" -> "http://example.com/a?a=1&b=2".
+func Normalize(raw string, opts Options) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", ErrUnsupportedScheme
+	}
+	if u.Opaque != "" {
+		return "", ErrInvalidURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || strings.ContainsAny(host, " \t") {
+		return "", ErrInvalidURL
+	}
+	if opts.BlockPrivate && isPrivateHost(host) {
+		return "", ErrPrivateAddress
+	}
+
+	port := u.Port()
+	if port == defaultPorts[u.Scheme] {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	if u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return "", ErrInvalidURL
+		}
+		// Encode ordena pelas chaves e mantém a ordem dos valores repetidos
+		u.RawQuery = query.Encode()
+	}
+	u.ForceQuery = false
+	if opts.StripFragment {
+		u.Fragment, u.RawFragment = "", ""
+	}
+
+	return u.String(), nil
+}
+
+// isPrivateHost verifica se o host é localhost ou um IP fora da internet pública
+func isPrivateHost(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, _, _ := strings.Cut(host, "%") // zona IPv6 (fe80::1%eth0)
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
@@ -1,57 +0,0 @@
--- ARCA Gateway Schema
-
-CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
-
--- Tenants (Empresas/Organizações que usam a plataforma)
-CREATE TABLE IF NOT EXISTS tenants (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    name VARCHAR(255) NOT NULL,
-    plan VARCHAR(50) NOT NULL DEFAULT 'free',
-    status VARCHAR(50) NOT NULL DEFAULT 'active',
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
--- Users (Usuários do sistema)
-CREATE TABLE IF NOT EXISTS users (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    tenant_id UUID REFERENCES tenants(id),
-    email VARCHAR(255) NOT NULL UNIQUE,
-    password_hash VARCHAR(255) NOT NULL,
-    name VARCHAR(255) NOT NULL,
-    role VARCHAR(50) NOT NULL DEFAULT 'user',
-    status VARCHAR(50) NOT NULL DEFAULT 'active',
-    last_login_at TIMESTAMP WITH TIME ZONE,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
--- Clients (Clientes finais monitorados pelo Tenant - ex: subsidiárias ou clientes de um MSSP)
-CREATE TABLE IF NOT EXISTS clients (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    tenant_id UUID REFERENCES tenants(id),
-    name VARCHAR(255) NOT NULL,
-    industry VARCHAR(100),
-    contact_email VARCHAR(255),
-    status VARCHAR(50) NOT NULL DEFAULT 'active',
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
--- Brands (Marcas específicas monitoradas)
-CREATE TABLE IF NOT EXISTS brands (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    tenant_id UUID REFERENCES tenants(id),
-    client_id UUID REFERENCES clients(id),
-    name VARCHAR(255) NOT NULL,
-    domain VARCHAR(255) NOT NULL,
-    industry VARCHAR(100),
-    monitoring_enabled BOOLEAN DEFAULT FALSE,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
-
--- Indexes
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-CREATE INDEX IF NOT EXISTS idx_clients_tenant ON clients(tenant_id);
-CREATE INDEX IF NOT EXISTS idx_brands_client ON brands(client_id);